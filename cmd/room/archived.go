@@ -0,0 +1,41 @@
+package room
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/synctv-org/synctv/internal/bootstrap"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+var ArchivedCmd = &cobra.Command{
+	Use:   "archived",
+	Short: "list archived rooms",
+	Long:  `list archived rooms`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return bootstrap.New(bootstrap.WithContext(cmd.Context())).Add(
+			bootstrap.InitDiscardLog,
+			bootstrap.InitConfig,
+			bootstrap.InitDatabase,
+		).Run()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rooms, err := db.GetAllRooms(db.WhereStatus(model.RoomStatusArchived), db.OrderByAsc("archived_at"))
+		if err != nil {
+			return err
+		}
+		if len(rooms) == 0 {
+			fmt.Println("no archived room")
+			return nil
+		}
+		for _, r := range rooms {
+			fmt.Printf("id: %s\tname: %s\tcreator_id: %s\tlast_active_at: %s\tarchived_at: %s\n", r.ID, r.Name, r.CreatorID, r.LastActiveAt, r.ArchivedAt)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RoomCmd.AddCommand(ArchivedCmd)
+}
@@ -0,0 +1,44 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/synctv-org/synctv/internal/bootstrap"
+	"github.com/synctv-org/synctv/internal/db"
+)
+
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "restore archived room with room id",
+	Long:  "restore archived room with room id",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return bootstrap.New(bootstrap.WithContext(cmd.Context())).Add(
+			bootstrap.InitDiscardLog,
+			bootstrap.InitConfig,
+			bootstrap.InitDatabase,
+		).Run()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("missing room id")
+		}
+		r, err := db.GetRoomByID(args[0])
+		if err != nil {
+			fmt.Printf("get room failed: %s\n", err)
+			return nil
+		}
+		err = db.RestoreArchivedRoom(r.ID)
+		if err != nil {
+			fmt.Printf("restore room failed: %s\n", err)
+			return nil
+		}
+		fmt.Printf("restore room success: %s\n", r.Name)
+		return nil
+	},
+}
+
+func init() {
+	RoomCmd.AddCommand(RestoreCmd)
+}
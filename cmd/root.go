@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/synctv-org/synctv/cmd/admin"
 	"github.com/synctv-org/synctv/cmd/flags"
+	"github.com/synctv-org/synctv/cmd/room"
 	"github.com/synctv-org/synctv/cmd/root"
 	"github.com/synctv-org/synctv/cmd/setting"
 	"github.com/synctv-org/synctv/cmd/user"
@@ -110,6 +111,7 @@ func init() {
 func init() {
 	RootCmd.AddCommand(admin.AdminCmd)
 	RootCmd.AddCommand(user.UserCmd)
+	RootCmd.AddCommand(room.RoomCmd)
 	RootCmd.AddCommand(setting.SettingCmd)
 	RootCmd.AddCommand(root.RootCmd)
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/synctv-org/synctv/internal/rtmp"
 	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
 	"github.com/synctv-org/synctv/server"
+	"github.com/synctv-org/synctv/server/handlers"
 )
 
 var ServerCmd = &cobra.Command{
@@ -33,7 +34,15 @@ var ServerCmd = &cobra.Command{
 			bootstrap.InitOp,
 			bootstrap.InitRtmp,
 			bootstrap.InitVendorBackend,
+			bootstrap.InitVendorPlugins,
 			bootstrap.InitSetting,
+			bootstrap.InitRoomStats,
+			bootstrap.InitTrafficUsage,
+			bootstrap.InitRoomArchive,
+			bootstrap.InitWatchHistory,
+			bootstrap.InitMovieTrash,
+			bootstrap.InitLiveRecord,
+			bootstrap.InitMovieHealthCheck,
 		)
 		if !flags.Server.DisableUpdateCheck {
 			boot.Add(bootstrap.InitCheckUpdate)
@@ -100,6 +109,19 @@ func Server(cmd *cobra.Command, args []string) {
 
 	e := server.NewAndInit()
 
+	// stop the imports before the database is closed
+	err = sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"vendor-import",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			handlers.StopVendorImports()
+			return nil
+		},
+	))
+	if err != nil {
+		log.Panic(err)
+	}
+
 	if conf.Conf.Server.RTMP.Enable {
 		if useMux {
 			muxer := cmux.New(httpListener)
@@ -64,6 +64,14 @@ func optConfigPath(conf *conf.Config) error {
 	if err != nil {
 		return fmt.Errorf("get proxy cache path error: %w", err)
 	}
+	conf.Server.SubtitlePath, err = utils.OptFilePath(conf.Server.SubtitlePath)
+	if err != nil {
+		return fmt.Errorf("get subtitle path error: %w", err)
+	}
+	conf.Server.RecordPath, err = utils.OptFilePath(conf.Server.RecordPath)
+	if err != nil {
+		return fmt.Errorf("get record path error: %w", err)
+	}
 	conf.Server.HTTP.CertPath, err = utils.OptFilePath(conf.Server.HTTP.CertPath)
 	if err != nil {
 		return fmt.Errorf("get http cert path error: %w", err)
@@ -82,6 +90,12 @@ func optConfigPath(conf *conf.Config) error {
 			return fmt.Errorf("get oauth2 plugin file path error: %w", err)
 		}
 	}
+	for i := range conf.VendorPlugins {
+		conf.VendorPlugins[i].PluginFile, err = utils.OptFilePath(conf.VendorPlugins[i].PluginFile)
+		if err != nil {
+			return fmt.Errorf("get vendor plugin file path error: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,25 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/synctv-org/synctv/internal/op"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+func InitLiveRecord(ctx context.Context) error {
+	// keep the recordings as movies, before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"live-record",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			op.StopLiveRecords()
+			return nil
+		},
+	))
+	if err != nil {
+		return err
+	}
+
+	return op.RecoverLiveRecords()
+}
@@ -0,0 +1,72 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+const (
+	movieHealthCheckTick      = time.Minute
+	movieHealthCheckBatchSize = 100
+)
+
+func InitMovieHealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	// stop the checks before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"movie-health-check",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			cancel()
+			<-done
+			op.StopRoomMoviesHealthChecks()
+			return nil
+		},
+	))
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer close(done)
+		t := time.NewTicker(movieHealthCheckTick)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				checkMoviesHealth(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// checkMoviesHealth checks a batch of the movies due, the rest are left to the next tick
+func checkMoviesHealth(ctx context.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("check movies health panic: %v", err)
+		}
+	}()
+	interval := settings.MovieHealthCheckInterval.Get()
+	if interval <= 0 {
+		return
+	}
+	movies, err := db.GetMoviesToCheckHealth(time.Now().Add(-time.Duration(interval)*time.Hour), movieHealthCheckBatchSize)
+	if err != nil {
+		log.Errorf("get movies to check health error: %v", err)
+		return
+	}
+	op.CheckMoviesHealth(ctx, movies)
+}
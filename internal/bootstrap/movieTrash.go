@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+)
+
+const movieTrashPurgeInterval = time.Hour
+
+func InitMovieTrash(ctx context.Context) error {
+	go func() {
+		t := time.NewTicker(movieTrashPurgeInterval)
+		defer t.Stop()
+		for {
+			purgeMovieTrash()
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func purgeMovieTrash() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("purge movie trash panic: %v", err)
+		}
+	}()
+	before := time.Now().Add(-time.Duration(settings.MovieTrashRetention.Get()) * time.Hour * 24)
+	if err := db.DeleteTrashedMoviesBefore(before); err != nil {
+		log.Errorf("purge movie trash error: %v", err)
+	}
+	// the subtitles of the purged movies are deleted by cascade, their files are left on disk
+	if err := op.CleanupSubtitleFiles(); err != nil {
+		log.Errorf("cleanup subtitle files error: %v", err)
+	}
+	// so are the records of the purged recording movies
+	if err := op.CleanupLiveRecordFiles(); err != nil {
+		log.Errorf("cleanup live record files error: %v", err)
+	}
+}
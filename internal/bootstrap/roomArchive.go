@@ -0,0 +1,62 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/op"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+const roomArchiveInterval = time.Hour
+
+func InitRoomArchive(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	// wait for the running pass to finish, before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"room-archive",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			cancel()
+			<-done
+			return nil
+		},
+	))
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer close(done)
+		t := time.NewTicker(roomArchiveInterval)
+		defer t.Stop()
+		for {
+			archiveRooms()
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func archiveRooms() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("archive rooms panic: %v", err)
+		}
+	}()
+	if err := op.ArchiveInactiveRooms(); err != nil {
+		log.Errorf("archive inactive rooms error: %v", err)
+	}
+	if err := op.DeleteExpiredArchivedRooms(); err != nil {
+		log.Errorf("delete expired archived rooms error: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+const roomStatSampleInterval = time.Minute
+
+func InitRoomStats(ctx context.Context) error {
+	// flush counters not yet sampled, before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"room-stats",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			op.SampleRoomStats(0)
+			return nil
+		},
+	))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		t := time.NewTicker(roomStatSampleInterval)
+		defer t.Stop()
+		last := time.Now()
+		lastPurge := time.Time{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				func() {
+					defer func() {
+						if err := recover(); err != nil {
+							log.Errorf("sample room stats panic: %v", err)
+						}
+					}()
+					op.SampleRoomStats(now.Sub(last))
+					last = now
+					if now.Sub(lastPurge) < time.Hour {
+						return
+					}
+					lastPurge = now
+					err := db.DeleteRoomStatsBefore(now.Add(-time.Duration(settings.RoomStatsRetention.Get()) * time.Hour * 24))
+					if err != nil {
+						log.Errorf("purge room stats error: %v", err)
+					}
+				}()
+			}
+		}
+	}()
+
+	return nil
+}
@@ -44,6 +44,9 @@ func validateRoom(room *op.Room) error {
 	if room.IsPending() {
 		return fmt.Errorf("rtmp: room %s is pending, need admin approval", room.ID)
 	}
+	if room.IsArchived() {
+		return fmt.Errorf("rtmp: room %s is archived", room.ID)
+	}
 	return nil
 }
 
@@ -0,0 +1,49 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/op"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+const trafficFlushInterval = time.Minute
+
+func InitTrafficUsage(ctx context.Context) error {
+	// save the bytes not yet flushed, before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"traffic-usage",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			op.FlushTrafficUsage()
+			return nil
+		},
+	))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		t := time.NewTicker(trafficFlushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				func() {
+					defer func() {
+						if err := recover(); err != nil {
+							log.Errorf("flush traffic usage panic: %v", err)
+						}
+					}()
+					op.FlushTrafficUsage()
+				}()
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-hclog"
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/cmd/flags"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
+)
+
+func InitVendorPlugins(ctx context.Context) error {
+	logOur := log.StandardLogger().Writer()
+	logLevle := hclog.Info
+	if flags.Global.Dev {
+		logLevle = hclog.Debug
+	}
+	for _, vp := range conf.Conf.VendorPlugins {
+		log.Infof("load vendor plugin: %s", vp.PluginFile)
+		v, err := vendorplugins.InitVendorPlugins(vp.PluginFile, vp.Args, hclog.New(&hclog.LoggerOptions{
+			Name:   vp.PluginFile,
+			Level:  logLevle,
+			Output: logOur,
+			Color:  hclog.ForceColor,
+		}))
+		if err != nil {
+			log.Fatalf("load vendor plugin: %s failed: %s", vp.PluginFile, err)
+			return err
+		}
+		log.Infof("vendor plugin %s loaded: %s", vp.PluginFile, v.Name())
+	}
+	return nil
+}
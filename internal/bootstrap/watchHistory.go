@@ -0,0 +1,49 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/op"
+	sysnotify "github.com/synctv-org/synctv/internal/sysnotify"
+)
+
+const watchHistoryFlushInterval = 30 * time.Second
+
+func InitWatchHistory(ctx context.Context) error {
+	// save positions not yet flushed, before the database is closed
+	err := sysnotify.RegisterSysNotifyTask(-1, sysnotify.NewSysNotifyTask(
+		"watch-history",
+		sysnotify.NotifyTypeEXIT,
+		func() error {
+			op.FlushWatchHistories()
+			return nil
+		},
+	))
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		t := time.NewTicker(watchHistoryFlushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				func() {
+					defer func() {
+						if err := recover(); err != nil {
+							log.Errorf("flush watch histories panic: %v", err)
+						}
+					}()
+					op.FlushWatchHistories()
+				}()
+			}
+		}
+	}()
+
+	return nil
+}
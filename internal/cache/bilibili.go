@@ -14,6 +14,7 @@ import (
 	"github.com/synctv-org/synctv/internal/model"
 	"github.com/synctv-org/synctv/internal/vendor"
 	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/subtitle"
 	"github.com/synctv-org/vendors/api/bilibili"
 	"github.com/zencoder/go-dash/v3/mpd"
 	"github.com/zijiren233/gencontainer/refreshcache"
@@ -310,27 +311,16 @@ func BilibiliSubtitleCacheInitFunc(ctx context.Context, movie *model.Movie, args
 }
 
 func convertToSRT(subtitles *bilibiliSubtitleResp) []byte {
-	srt := bytes.NewBuffer(nil)
-	counter := 0
-	for _, subtitle := range subtitles.Body {
-		srt.WriteString(
-			fmt.Sprintf("%d\n%s --> %s\n%s\n\n",
-				counter,
-				formatTime(subtitle.From),
-				formatTime(subtitle.To),
-				subtitle.Content))
-		counter++
+	cues := make([]*subtitle.Cue, len(subtitles.Body))
+	for i, v := range subtitles.Body {
+		cues[i] = &subtitle.Cue{
+			Start: time.Duration(math.Round(v.From*1000)) * time.Millisecond,
+			End:   time.Duration(math.Round(v.To*1000)) * time.Millisecond,
+			Text:  v.Content,
+		}
 	}
-	return srt.Bytes()
-}
-
-func formatTime(seconds float64) string {
-	hours := int(seconds) / 3600
-	seconds = math.Mod(seconds, 3600)
-	minutes := int(seconds) / 60
-	seconds = math.Mod(seconds, 60)
-	milliseconds := int((seconds - float64(int(seconds))) * 1000)
-	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, int(seconds), milliseconds)
+	srt, _ := subtitle.Encode(cues, subtitle.FormatSRT)
+	return srt
 }
 
 func translateBilibiliSubtitleToSrt(ctx context.Context, url string) ([]byte, error) {
@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/jellyfin"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/gencontainer/refreshcache"
+	"github.com/zijiren233/gencontainer/refreshcache0"
+	"github.com/zijiren233/gencontainer/refreshcache1"
+	"github.com/zijiren233/go-uhc"
+)
+
+type JellyfinUserCache = MapCache0[*JellyfinUserCacheData]
+
+type JellyfinUserCacheData struct {
+	Host     string
+	ServerID string
+	APIKey   string
+	UserID   string
+	// the api key is not a session token
+	UseAPIKey bool
+}
+
+func (d *JellyfinUserCacheData) Client() *jellyfin.Client {
+	return jellyfin.NewClient(d.Host, jellyfin.WithToken(d.APIKey), jellyfin.WithUserID(d.UserID))
+}
+
+func NewJellyfinUserCache(userID string) *JellyfinUserCache {
+	return newMapCache0(func(ctx context.Context, key string) (*JellyfinUserCacheData, error) {
+		return JellyfinAuthorizationCacheWithUserIDInitFunc(userID, key)
+	}, -1)
+}
+
+func JellyfinAuthorizationCacheWithUserIDInitFunc(userID, serverID string) (*JellyfinUserCacheData, error) {
+	if serverID == "" {
+		return nil, errors.New("serverID is required")
+	}
+	v, err := db.GetJellyfinVendor(userID, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if v.APIKey == "" || v.Host == "" {
+		return nil, db.NotFoundError(db.ErrVendorNotFound)
+	}
+	return &JellyfinUserCacheData{
+		Host:      v.Host,
+		ServerID:  v.ServerID,
+		APIKey:    v.APIKey,
+		UserID:    v.JellyfinUserID,
+		UseAPIKey: v.UseAPIKey,
+	}, nil
+}
+
+type JellyfinSource struct {
+	URL         string
+	Name        string
+	Subtitles   []*JellyfinSubtitleCache
+	IsTranscode bool
+}
+
+type JellyfinSubtitleCache struct {
+	Cache *refreshcache0.RefreshCache[[]byte]
+	URL   string
+	Type  string
+	Name  string
+}
+
+type JellyfinMovieCacheData struct {
+	TranscodeSessionID string
+	Sources            []JellyfinSource
+}
+
+type JellyfinMovieCache = refreshcache1.RefreshCache[*JellyfinMovieCacheData, *JellyfinUserCache]
+
+func NewJellyfinMovieCache(movie *model.Movie, subPath string) *JellyfinMovieCache {
+	cache := refreshcache1.NewRefreshCache(NewJellyfinMovieCacheInitFunc(movie, subPath), -1)
+	cache.SetClearFunc(NewJellyfinMovieClearCacheFunc(movie, subPath))
+	return cache
+}
+
+// NewJellyfinMovieClearCacheFunc stops the transcoding of the replaced playback session
+func NewJellyfinMovieClearCacheFunc(movie *model.Movie, subPath string) func(ctx context.Context, args *JellyfinUserCache) error {
+	return func(ctx context.Context, args *JellyfinUserCache) error {
+		if !movie.MovieBase.VendorInfo.Jellyfin.Transcode {
+			return nil
+		}
+		if args == nil {
+			return errors.New("need jellyfin user cache")
+		}
+
+		serverID, err := movie.MovieBase.VendorInfo.Jellyfin.ServerID()
+		if err != nil {
+			return err
+		}
+
+		oldVal, ok := ctx.Value(refreshcache.OldValKey).(*JellyfinMovieCacheData)
+		if !ok || oldVal.TranscodeSessionID == "" {
+			return nil
+		}
+
+		jucd, err := args.LoadOrStore(ctx, serverID)
+		if err != nil {
+			return err
+		}
+		if jucd.Host == "" || jucd.APIKey == "" {
+			return errors.New("not bind jellyfin vendor")
+		}
+		if err := jucd.Client().StopEncoding(ctx, oldVal.TranscodeSessionID); err != nil {
+			log.Errorf("stop jellyfin encoding: %v", err)
+		}
+		return nil
+	}
+}
+
+func NewJellyfinMovieCacheInitFunc(movie *model.Movie, subPath string) func(ctx context.Context, args *JellyfinUserCache) (*JellyfinMovieCacheData, error) {
+	return func(ctx context.Context, args *JellyfinUserCache) (*JellyfinMovieCacheData, error) {
+		if args == nil {
+			return nil, errors.New("need jellyfin user cache")
+		}
+		if movie.IsFolder && subPath == "" {
+			return nil, errors.New("sub path is empty")
+		}
+
+		serverID, itemID, err := movie.MovieBase.VendorInfo.Jellyfin.ServerIDAndFilePath()
+		if err != nil {
+			return nil, err
+		}
+		if movie.IsFolder {
+			itemID = subPath
+		}
+
+		jucd, err := args.LoadOrStore(ctx, serverID)
+		if err != nil {
+			return nil, err
+		}
+		if jucd.Host == "" || jucd.APIKey == "" {
+			return nil, errors.New("not bind jellyfin vendor")
+		}
+
+		cli := jucd.Client()
+		transcode := movie.MovieBase.VendorInfo.Jellyfin.Transcode
+		data, err := cli.PlaybackInfo(ctx, itemID, transcode)
+		if err != nil {
+			return nil, fmt.Errorf("playback info: %w", err)
+		}
+
+		resp := &JellyfinMovieCacheData{
+			Sources: make([]JellyfinSource, 0, len(data.MediaSources)),
+		}
+		for _, ms := range data.MediaSources {
+			source := JellyfinSource{
+				Name:      ms.Name,
+				Subtitles: newJellyfinSubtitles(cli, itemID, ms),
+			}
+			if transcode && ms.TranscodingURL != "" {
+				source.URL = cli.TranscodingURL(ms)
+				source.IsTranscode = true
+				resp.TranscodeSessionID = data.PlaySessionID
+			} else {
+				source.URL = cli.StreamURL(itemID, ms)
+			}
+			resp.Sources = append(resp.Sources, source)
+		}
+
+		return resp, nil
+	}
+}
+
+// newJellyfinSubtitles returns the text subtitles of the media source, jellyfin
+// extracts the embedded ones and converts them to srt
+func newJellyfinSubtitles(cli *jellyfin.Client, itemID string, ms *jellyfin.MediaSource) []*JellyfinSubtitleCache {
+	subtitles := make([]*JellyfinSubtitleCache, 0, len(ms.MediaStreams))
+	for _, stream := range ms.MediaStreams {
+		if stream.Type != "Subtitle" || !stream.IsTextSubtitleStream {
+			continue
+		}
+
+		subtitleType := "srt"
+		url := cli.SubtitleURL(itemID, ms.ID, stream.Index, subtitleType)
+
+		name := stream.DisplayTitle
+		if name == "" {
+			if stream.Title != "" {
+				name = stream.Title
+			} else {
+				name = stream.Language
+			}
+		}
+
+		subtitles = append(subtitles, &JellyfinSubtitleCache{
+			URL:   url,
+			Type:  subtitleType,
+			Name:  name,
+			Cache: refreshcache0.NewRefreshCache(newJellyfinSubtitleCacheInitFunc(url), -1),
+		})
+	}
+	return subtitles
+}
+
+func newJellyfinSubtitleCacheInitFunc(url string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", utils.UA)
+		resp, err := uhc.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New("bad status code")
+		}
+		return io.ReadAll(resp.Body)
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+// newJellyfinStub serves PlaybackInfo with one media source and its subtitle streams
+func newJellyfinStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /Items/{id}/PlaybackInfo", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EnableTranscoding bool
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		source := `"Id":"ms1","Name":"1080p","Container":"mkv","MediaStreams":[` +
+			`{"Type":"Video","Index":0},` +
+			`{"Type":"Subtitle","Index":2,"Language":"eng","DisplayTitle":"English","IsTextSubtitleStream":true},` +
+			`{"Type":"Subtitle","Index":3,"Language":"chi","IsTextSubtitleStream":false},` +
+			`{"Type":"Subtitle","Index":4,"Language":"jpn","Title":"Signs","IsTextSubtitleStream":true},` +
+			`{"Type":"Subtitle","Index":5,"Language":"fre","IsTextSubtitleStream":true}]`
+		if body.EnableTranscoding {
+			source += `,"TranscodingUrl":"/videos/` + r.PathValue("id") + `/master.m3u8?PlaySessionId=ps1"`
+		}
+		_, _ = io.WriteString(w, `{"PlaySessionId":"ps1","MediaSources":[{`+source+`}]}`)
+	})
+	mux.HandleFunc("GET /Videos/{id}/{source}/Subtitles/{index}/Stream.srt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "1\n00:00:01,000 --> 00:00:02,000\n"+r.PathValue("index")+"\n")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestJellyfinMovieCacheInitFunc(t *testing.T) {
+	srv := newJellyfinStub(t)
+	users := newMapCache0(func(ctx context.Context, key string) (*JellyfinUserCacheData, error) {
+		return &JellyfinUserCacheData{Host: srv.URL, ServerID: key, APIKey: "token", UserID: "u1"}, nil
+	}, -1)
+	tests := []struct {
+		name        string
+		transcode   bool
+		url         string
+		isTranscode bool
+		sessionID   string
+	}{
+		{
+			name: "direct stream",
+			url:  srv.URL + "/Videos/movie/stream.mkv?api_key=token&mediaSourceId=ms1&static=true",
+		},
+		{
+			name:        "transcode",
+			transcode:   true,
+			url:         srv.URL + "/videos/movie/master.m3u8?PlaySessionId=ps1",
+			isTranscode: true,
+			sessionID:   "ps1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movie := &model.Movie{
+				MovieBase: model.MovieBase{
+					VendorInfo: model.VendorInfo{
+						Vendor: model.VendorJellyfin,
+						Jellyfin: &model.JellyfinStreamingInfo{
+							Path:      model.FormatJellyfinPath("srv", "movie"),
+							Transcode: tt.transcode,
+						},
+					},
+				},
+			}
+			data, err := NewJellyfinMovieCacheInitFunc(movie, "")(context.Background(), users)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Sources) != 1 {
+				t.Fatalf("sources = %d", len(data.Sources))
+			}
+			source := data.Sources[0]
+			if source.URL != tt.url || source.IsTranscode != tt.isTranscode || data.TranscodeSessionID != tt.sessionID {
+				t.Errorf("source = %s %v %q", source.URL, source.IsTranscode, data.TranscodeSessionID)
+			}
+
+			// the image subtitles are skipped, the names fall back to the title and the language
+			wantNames := []string{"English", "Signs", "fre"}
+			if len(source.Subtitles) != len(wantNames) {
+				t.Fatalf("subtitles = %d, want %d", len(source.Subtitles), len(wantNames))
+			}
+			for i, subt := range source.Subtitles {
+				if subt.Name != wantNames[i] || subt.Type != "srt" {
+					t.Errorf("subtitle %d = %s %s", i, subt.Name, subt.Type)
+				}
+			}
+			b, err := source.Subtitles[1].Cache.Get(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(string(b), "\n4\n") {
+				t.Errorf("subtitle data = %q", b)
+			}
+		})
+	}
+}
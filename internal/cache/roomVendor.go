@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/gencontainer/refreshcache"
+	"github.com/zijiren233/gencontainer/refreshcache0"
+)
+
+// room vendor caches share the user cache types,
+// so the movie caches can be fed with either of them
+
+func NewRoomAlistUserCache(roomID string) *AlistUserCache {
+	return newMapCache[*AlistUserCacheData, struct{}](func(ctx context.Context, key string, args ...struct{}) (*AlistUserCacheData, error) {
+		v, err := db.GetRoomAlistVendor(roomID, key)
+		if err != nil {
+			return nil, err
+		}
+		return AlistAuthorizationCacheWithConfigInitFunc(ctx, &model.AlistVendor{
+			Backend:        v.Backend,
+			ServerID:       v.ServerID,
+			Host:           v.Host,
+			Username:       v.Username,
+			HashedPassword: v.HashedPassword,
+		})
+	}, -1)
+}
+
+func NewRoomEmbyUserCache(roomID string) *EmbyUserCache {
+	return newMapCache0(func(ctx context.Context, key string) (*EmbyUserCacheData, error) {
+		if key == "" {
+			return nil, errors.New("serverID is required")
+		}
+		v, err := db.GetRoomEmbyVendor(roomID, key)
+		if err != nil {
+			return nil, err
+		}
+		if v.APIKey == "" || v.Host == "" {
+			return nil, db.NotFoundError(db.ErrVendorNotFound)
+		}
+		return &EmbyUserCacheData{
+			Host:     v.Host,
+			ServerID: v.ServerID,
+			APIKey:   v.APIKey,
+			UserID:   v.EmbyUserID,
+			Backend:  v.Backend,
+		}, nil
+	}, -1)
+}
+
+func NewRoomBilibiliUserCache(roomID string) *BilibiliUserCache {
+	return refreshcache.NewRefreshCache(func(ctx context.Context, args ...struct{}) (*BilibiliUserCacheData, error) {
+		v, err := db.GetRoomBilibiliVendor(roomID)
+		if err != nil {
+			return nil, err
+		}
+		return &BilibiliUserCacheData{
+			Cookies: utils.MapToHTTPCookie(v.Cookies),
+			Backend: v.Backend,
+		}, nil
+	}, -1)
+}
+
+// RoomVendorBinds is the index of the vendors bound to a room,
+// it avoids hitting the database when a room has no binding
+type RoomVendorBinds struct {
+	Alist    map[string]struct{}
+	Emby     map[string]struct{}
+	Bilibili bool
+}
+
+type RoomVendorBindsCache = refreshcache0.RefreshCache[*RoomVendorBinds]
+
+func NewRoomVendorBindsCache(roomID string) *RoomVendorBindsCache {
+	return refreshcache0.NewRefreshCache(func(ctx context.Context) (*RoomVendorBinds, error) {
+		alistVendors, err := db.GetRoomAlistVendors(roomID)
+		if err != nil {
+			return nil, err
+		}
+		embyVendors, err := db.GetRoomEmbyVendors(roomID)
+		if err != nil {
+			return nil, err
+		}
+		binds := &RoomVendorBinds{
+			Alist: make(map[string]struct{}, len(alistVendors)),
+			Emby:  make(map[string]struct{}, len(embyVendors)),
+		}
+		for _, v := range alistVendors {
+			binds.Alist[v.ServerID] = struct{}{}
+		}
+		for _, v := range embyVendors {
+			binds.Emby[v.ServerID] = struct{}{}
+		}
+		_, err = db.GetRoomBilibiliVendor(roomID)
+		switch {
+		case err == nil:
+			binds.Bilibili = true
+		case !errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)):
+			return nil, err
+		}
+		return binds, nil
+	}, -1)
+}
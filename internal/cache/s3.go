@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/s3"
+)
+
+type S3UserCache = MapCache0[*S3UserCacheData]
+
+type S3UserCacheData struct {
+	ServerID  string
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+func (d *S3UserCacheData) Client() (*s3.Client, error) {
+	return s3.NewClient(d.Endpoint, d.Bucket,
+		s3.WithRegion(d.Region),
+		s3.WithCredentials(d.AccessKey, d.SecretKey),
+		s3.WithPathStyle(d.PathStyle),
+	)
+}
+
+// Name returns the display name of the server
+func (d *S3UserCacheData) Name() string {
+	return d.Bucket + "/" + d.Prefix
+}
+
+func NewS3UserCache(userID string) *S3UserCache {
+	return newMapCache0(func(ctx context.Context, key string) (*S3UserCacheData, error) {
+		return S3AuthorizationCacheWithUserIDInitFunc(userID, key)
+	}, -1)
+}
+
+func S3AuthorizationCacheWithUserIDInitFunc(userID, serverID string) (*S3UserCacheData, error) {
+	if serverID == "" {
+		return nil, errors.New("serverID is required")
+	}
+	v, err := db.GetS3Vendor(userID, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if v.Endpoint == "" {
+		return nil, db.NotFoundError(db.ErrVendorNotFound)
+	}
+	return &S3UserCacheData{
+		ServerID:  v.ServerID,
+		Endpoint:  v.Endpoint,
+		Region:    v.Region,
+		Bucket:    v.Bucket,
+		Prefix:    v.Prefix,
+		AccessKey: v.AccessKey,
+		SecretKey: v.SecretKey,
+		PathStyle: v.PathStyle,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/webdav"
+)
+
+type WebDAVUserCache = MapCache0[*WebDAVUserCacheData]
+
+type WebDAVUserCacheData struct {
+	Host     string
+	ServerID string
+	Username string
+	Password string
+}
+
+func (d *WebDAVUserCacheData) Client() (*webdav.Client, error) {
+	return webdav.NewClient(d.Host, d.Username, d.Password)
+}
+
+func NewWebDAVUserCache(userID string) *WebDAVUserCache {
+	return newMapCache0(func(ctx context.Context, key string) (*WebDAVUserCacheData, error) {
+		return WebDAVAuthorizationCacheWithUserIDInitFunc(userID, key)
+	}, -1)
+}
+
+func WebDAVAuthorizationCacheWithUserIDInitFunc(userID, serverID string) (*WebDAVUserCacheData, error) {
+	if serverID == "" {
+		return nil, errors.New("serverID is required")
+	}
+	v, err := db.GetWebDAVVendor(userID, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if v.Host == "" {
+		return nil, db.NotFoundError(db.ErrVendorNotFound)
+	}
+	return &WebDAVUserCacheData{
+		Host:     v.Host,
+		ServerID: v.ServerID,
+		Username: v.Username,
+		Password: v.Password,
+	}, nil
+}
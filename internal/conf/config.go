@@ -21,6 +21,9 @@ type Config struct {
 	// Oauth2Plugins
 	Oauth2Plugins Oauth2Plugins `yaml:"oauth2_plugins"`
 
+	// VendorPlugins
+	VendorPlugins VendorPlugins `yaml:"vendor_plugins"`
+
 	// RateLimit
 	RateLimit RateLimitConfig `yaml:"rate_limit"`
 }
@@ -46,6 +49,9 @@ func DefaultConfig() *Config {
 		// OAuth2
 		Oauth2Plugins: DefaultOauth2Plugins(),
 
+		// Vendor plugins
+		VendorPlugins: DefaultVendorPlugins(),
+
 		// RateLimit
 		RateLimit: DefaultRateLimitConfig(),
 	}
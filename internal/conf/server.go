@@ -2,10 +2,13 @@ package conf
 
 //nolint:tagliatelle
 type ServerConfig struct {
-	HTTP           HttpServerConfig `yaml:"http"`
-	RTMP           RtmpServerConfig `yaml:"rtmp"`
-	ProxyCachePath string           `env:"SERVER_PROXY_CACHE_PATH" hc:"proxy cache path storage path, empty means use memory cache" yaml:"proxy_cache_path"`
-	ProxyCacheSize string           `env:"SERVER_PROXY_CACHE_SIZE" hc:"proxy cache max size, example: 1MB 1GB, default 1GB"         yaml:"proxy_cache_size"`
+	HTTP            HttpServerConfig `yaml:"http"`
+	RTMP            RtmpServerConfig `yaml:"rtmp"`
+	ProxyCachePath  string           `env:"SERVER_PROXY_CACHE_PATH"  hc:"proxy cache path storage path, empty means use memory cache"                              yaml:"proxy_cache_path"`
+	ProxyCacheSize  string           `env:"SERVER_PROXY_CACHE_SIZE"  hc:"proxy cache max size, example: 1MB 1GB, default 1GB"                                      yaml:"proxy_cache_size"`
+	ProxyCacheRedis string           `env:"SERVER_PROXY_CACHE_REDIS" hc:"redis url of the proxy cache shared by instances, takes precedence over proxy cache path" yaml:"proxy_cache_redis"`
+	SubtitlePath    string           `env:"SERVER_SUBTITLE_PATH"     hc:"uploaded subtitles storage path"                                                          yaml:"subtitle_path"`
+	RecordPath      string           `env:"SERVER_RECORD_PATH"       hc:"live recordings storage path"                                                             yaml:"record_path"`
 }
 
 //nolint:tagliatelle
@@ -36,5 +39,7 @@ func DefaultServerConfig() ServerConfig {
 			Port:   0,
 		},
 		ProxyCachePath: "",
+		SubtitlePath:   "subtitles",
+		RecordPath:     "recordings",
 	}
 }
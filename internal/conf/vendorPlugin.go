@@ -0,0 +1,11 @@
+package conf
+
+//nolint:tagliatelle
+type VendorPlugins []struct {
+	PluginFile string   `yaml:"plugin_file"`
+	Args       []string `yaml:"args"`
+}
+
+func DefaultVendorPlugins() VendorPlugins {
+	return nil
+}
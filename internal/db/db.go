@@ -27,6 +27,7 @@ func Init(d *gorm.DB, t conf.DatabaseType) error {
 	if err != nil {
 		return err
 	}
+	initRoomSearchIndex()
 	err = initGuestUser()
 	if err != nil {
 		return err
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"github.com/synctv-org/synctv/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	ErrLiveRecordNotFound = "live record"
+)
+
+func CreateLiveRecord(record *model.LiveRecord) error {
+	return db.Create(record).Error
+}
+
+func GetLiveRecord(roomID, id string) (*model.LiveRecord, error) {
+	var record model.LiveRecord
+	err := db.Where("room_id = ? AND id = ?", roomID, id).First(&record).Error
+	return &record, HandleNotFound(err, ErrLiveRecordNotFound)
+}
+
+func GetLiveRecords(roomID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.LiveRecord, error) {
+	var records []*model.LiveRecord
+	err := db.Scopes(scopes...).Where("room_id = ?", roomID).Find(&records).Error
+	return records, err
+}
+
+func GetLiveRecordsCount(roomID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Scopes(scopes...).Where("room_id = ?", roomID).Model(&model.LiveRecord{}).Count(&count).Error
+	return count, err
+}
+
+// GetLiveRecordsByStatus returns the records of all the rooms in the status
+func GetLiveRecordsByStatus(status model.LiveRecordStatus) ([]*model.LiveRecord, error) {
+	var records []*model.LiveRecord
+	err := db.Where("status = ?", status).Find(&records).Error
+	return records, err
+}
+
+func WhereLiveRecordSourceMovieID(movieID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("source_movie_id = ?", movieID)
+	}
+}
+
+func WhereLiveRecordStatus(status model.LiveRecordStatus) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ?", status)
+	}
+}
+
+// FinishLiveRecord saves the result of a stopped recording
+func FinishLiveRecord(record *model.LiveRecord) error {
+	result := db.Model(&model.LiveRecord{}).
+		Where("room_id = ? AND id = ?", record.RoomID, record.ID).
+		Select("movie_id", "status", "stop_reason", "error", "size", "duration", "stopped_at").
+		Updates(record)
+	return HandleUpdateResult(result, ErrLiveRecordNotFound)
+}
+
+// GetLiveRecordIDsByRoomID returns the ids of all the records in the room,
+// used to find the orphan files left by deleted movies and rooms
+func GetLiveRecordIDsByRoomID(roomID string) ([]string, error) {
+	var ids []string
+	err := db.Model(&model.LiveRecord{}).Where("room_id = ?", roomID).Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+const (
+	ErrLocalRootNotFound = "local root"
+)
+
+func CreateLocalRoot(root *model.LocalRoot) error {
+	return db.Create(root).Error
+}
+
+func GetLocalRoots() ([]*model.LocalRoot, error) {
+	var roots []*model.LocalRoot
+	err := db.Order("created_at ASC").Find(&roots).Error
+	return roots, err
+}
+
+func GetLocalRootByID(id string) (*model.LocalRoot, error) {
+	var root model.LocalRoot
+	err := db.Where("id = ?", id).First(&root).Error
+	return &root, HandleNotFound(err, ErrLocalRootNotFound)
+}
+
+func SaveLocalRoot(root *model.LocalRoot) error {
+	result := db.Omit("created_at").Save(root)
+	return HandleUpdateResult(result, ErrLocalRootNotFound)
+}
+
+func DeleteLocalRootByID(id string) error {
+	result := db.Where("id = ?", id).Delete(&model.LocalRoot{})
+	return HandleUpdateResult(result, ErrLocalRootNotFound)
+}
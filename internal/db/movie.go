@@ -1,6 +1,11 @@
 package db
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/synctv-org/synctv/internal/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -18,6 +23,19 @@ func CreateMovies(movies []*model.Movie) error {
 	return db.CreateInBatches(movies, 100).Error
 }
 
+// CreateMovieLevels creates the levels in order in one transaction,
+// so the folders exist before their children and a failure creates nothing
+func CreateMovieLevels(levels [][]*model.Movie) error {
+	return Transactional(func(tx *gorm.DB) error {
+		for _, level := range levels {
+			if err := tx.CreateInBatches(level, 100).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func WithParentMovieID(parentMovieID string) func(*gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if parentMovieID == "" {
@@ -55,13 +73,211 @@ func DeleteMoviesByID(roomID string, ids []string) error {
 	return HandleUpdateResult(result, ErrRoomOrMovieNotFound)
 }
 
-func DeleteMoviesByRoomID(roomID string, scopes ...func(*gorm.DB) *gorm.DB) error {
-	result := db.Where("room_id = ?", roomID).Scopes(scopes...).Delete(&model.Movie{})
+// MoveMovies moves the movies under the parent, the positions are in the order of ids
+func MoveMovies(roomID string, ids []string, parentID string, positions []uint) error {
+	return Transactional(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			result := tx.Model(&model.Movie{}).
+				Where("room_id = ? AND id = ?", roomID, id).
+				Updates(map[string]any{
+					"base_parent_id": model.EmptyNullString(parentID),
+					"position":       positions[i],
+				})
+			if err := HandleUpdateResult(result, ErrRoomOrMovieNotFound); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReorderMovies sorts the movies in the folder by the order of ids, the positions of the given movies
+// are reassigned among themselves, so the movies not given keep their places
+func ReorderMovies(roomID, parentID string, ids []string) error {
+	return Transactional(func(tx *gorm.DB) error {
+		var movies []*model.Movie
+		err := tx.Select("id", "position").
+			Where("room_id = ? AND id IN ?", roomID, ids).
+			Scopes(WithParentMovieID(parentID)).
+			Find(&movies).Error
+		if err != nil {
+			return err
+		}
+		if len(movies) != len(ids) {
+			return NotFoundError(ErrRoomOrMovieNotFound)
+		}
+		positions := make([]uint, len(movies))
+		for i, m := range movies {
+			positions[i] = m.Position
+		}
+		slices.Sort(positions)
+		for i, id := range ids {
+			// movies added in the same millisecond share the position
+			if i > 0 && positions[i] <= positions[i-1] {
+				positions[i] = positions[i-1] + 1
+			}
+			err := tx.Model(&model.Movie{}).
+				Where("room_id = ? AND id = ?", roomID, id).
+				Update("position", positions[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TrashMoviesByID moves the movies and their subtrees to the trash,
+// only the movies deleted directly record who deleted them, the subtrees are restored with them
+func TrashMoviesByID(roomID string, ids []string, deletedBy string) error {
+	return Transactional(func(tx *gorm.DB) error {
+		return trashMovies(tx, roomID, ids, deletedBy)
+	})
+}
+
+func TrashMoviesByParentID(roomID, parentID string, deletedBy string) error {
+	return Transactional(func(tx *gorm.DB) error {
+		var ids []string
+		err := tx.Model(&model.Movie{}).
+			Where("room_id = ?", roomID).
+			Scopes(WithParentMovieID(parentID)).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return NotFoundError(ErrRoomOrMovieNotFound)
+		}
+		return trashMovies(tx, roomID, ids, deletedBy)
+	})
+}
+
+func trashMovies(tx *gorm.DB, roomID string, ids []string, deletedBy string) error {
+	now := time.Now()
+	result := tx.Model(&model.Movie{}).
+		Where("room_id = ? AND id IN ?", roomID, ids).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"deleted_by": deletedBy,
+		})
+	if err := HandleUpdateResult(result, ErrRoomOrMovieNotFound); err != nil {
+		return err
+	}
+	for parentIDs := ids; len(parentIDs) != 0; {
+		var children []string
+		err := tx.Model(&model.Movie{}).
+			Where("room_id = ? AND base_parent_id IN ?", roomID, parentIDs).
+			Pluck("id", &children).Error
+		if err != nil {
+			return err
+		}
+		if len(children) == 0 {
+			break
+		}
+		err = tx.Model(&model.Movie{}).
+			Where("room_id = ? AND id IN ?", roomID, children).
+			Update("deleted_at", now).Error
+		if err != nil {
+			return err
+		}
+		parentIDs = children
+	}
+	return nil
+}
+
+// WithTrashedRoot filters the movies deleted directly, the roots of the trashed subtrees
+func WithTrashedRoot(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_by IS NOT NULL")
+}
+
+func GetTrashedMovies(roomID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.Movie, error) {
+	var movies []*model.Movie
+	err := db.Scopes(WithTrashedRoot).
+		Where("room_id = ?", roomID).
+		Order("deleted_at DESC").
+		Scopes(scopes...).
+		Find(&movies).Error
+	return movies, err
+}
+
+func GetTrashedMoviesCount(roomID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Model(&model.Movie{}).
+		Scopes(WithTrashedRoot).
+		Where("room_id = ?", roomID).
+		Scopes(scopes...).
+		Count(&count).Error
+	return count, err
+}
+
+func GetTrashedMovieByID(roomID, id string) (*model.Movie, error) {
+	var movie model.Movie
+	err := db.Scopes(WithTrashedRoot).Where("room_id = ? AND id = ?", roomID, id).First(&movie).Error
+	return &movie, HandleNotFound(err, ErrRoomOrMovieNotFound)
+}
+
+// RestoreMovies moves the trashed movies and their subtrees back,
+// the parent id and position are kept in trash, so they go back to where they were
+func RestoreMovies(roomID string, ids []string) error {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	return Transactional(func(tx *gorm.DB) error {
+		var roots []*model.Movie
+		err := tx.Scopes(WithTrashedRoot).
+			Where("room_id = ? AND id IN ?", roomID, ids).
+			Find(&roots).Error
+		if err != nil {
+			return err
+		}
+		if len(roots) != len(ids) {
+			return NotFoundError(ErrRoomOrMovieNotFound)
+		}
+		for _, root := range roots {
+			if root.ParentID == "" {
+				continue
+			}
+			err := tx.Where("room_id = ? AND id = ?", roomID, root.ParentID).First(&model.Movie{}).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("the parent folder of %s is in trash, restore it first", root.Name)
+				}
+				return err
+			}
+		}
+		restore := ids
+		for parentIDs := ids; len(parentIDs) != 0; {
+			var children []string
+			// children deleted directly are in trash on their own
+			err := tx.Unscoped().
+				Model(&model.Movie{}).
+				Where("room_id = ? AND base_parent_id IN ? AND deleted_at IS NOT NULL AND deleted_by IS NULL", roomID, parentIDs).
+				Pluck("id", &children).Error
+			if err != nil {
+				return err
+			}
+			restore = append(restore, children...)
+			parentIDs = children
+		}
+		return tx.Unscoped().
+			Model(&model.Movie{}).
+			Where("room_id = ? AND id IN ?", roomID, restore).
+			Updates(map[string]any{
+				"deleted_at": nil,
+				"deleted_by": nil,
+			}).Error
+	})
+}
+
+// DeleteTrashedMoviesByID deletes the trashed movies permanently, their subtrees are deleted by cascade
+func DeleteTrashedMoviesByID(roomID string, ids []string) error {
+	result := db.Scopes(WithTrashedRoot).Where("room_id = ? AND id IN ?", roomID, ids).Delete(&model.Movie{})
 	return HandleUpdateResult(result, ErrRoomOrMovieNotFound)
 }
 
-func DeleteMoviesByRoomIDAndParentID(roomID, parentID string) error {
-	return DeleteMoviesByRoomID(roomID, WithParentMovieID(parentID))
+// DeleteTrashedMoviesBefore purges the movies trashed before the time
+func DeleteTrashedMoviesBefore(before time.Time) error {
+	return db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&model.Movie{}).Error
 }
 
 func UpdateMovie(movie *model.Movie, columns ...clause.Column) error {
@@ -74,6 +290,49 @@ func SaveMovie(movie *model.Movie, columns ...clause.Column) error {
 	return HandleUpdateResult(result, ErrRoomOrMovieNotFound)
 }
 
+func UpdateMovieMeta(roomID, id string, meta *model.MovieMeta) error {
+	result := db.Model(&model.Movie{}).Where("room_id = ? AND id = ?", roomID, id).Updates(map[string]any{
+		"meta_video_codec": meta.VideoCodec,
+		"meta_audio_codec": meta.AudioCodec,
+		"meta_format":      meta.Format,
+		"meta_duration":    meta.Duration,
+		"meta_width":       meta.Width,
+		"meta_height":      meta.Height,
+		"meta_probed_at":   meta.ProbedAt,
+	})
+	return HandleUpdateResult(result, ErrRoomOrMovieNotFound)
+}
+
+// UpdateMovieHealth keeps updated_at, the health is not an edit of the movie
+func UpdateMovieHealth(roomID, id string, health *model.MovieHealth) error {
+	result := db.Model(&model.Movie{}).Where("room_id = ? AND id = ?", roomID, id).UpdateColumns(map[string]any{
+		"health_status":     health.Status,
+		"health_error":      health.Error,
+		"health_checked_at": health.CheckedAt,
+	})
+	return HandleUpdateResult(result, ErrRoomOrMovieNotFound)
+}
+
+// WhereMovieHealthCheckable filters the plain url movies, which are not checked after checkedBefore
+func WhereMovieHealthCheckable(checkedBefore time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("base_vendor_info_vendor = '' OR base_vendor_info_vendor IS NULL").
+			Where("base_is_folder = ? AND base_rtmp_source = ? AND base_url <> ''", false, false).
+			Where("health_checked_at < ? OR health_checked_at IS NULL", checkedBefore.UnixMilli())
+	}
+}
+
+// GetMoviesToCheckHealth returns the movies of all the rooms checked longest ago
+func GetMoviesToCheckHealth(checkedBefore time.Time, limit int) ([]*model.Movie, error) {
+	var movies []*model.Movie
+	err := db.Scopes(WhereMovieHealthCheckable(checkedBefore)).
+		Order("health_checked_at ASC").
+		Limit(limit).
+		Find(&movies).Error
+	return movies, err
+}
+
 func SwapMoviePositions(roomID, movie1ID, movie2ID string) error {
 	return db.Transaction(func(tx *gorm.DB) error {
 		var movie1, movie2 model.Movie
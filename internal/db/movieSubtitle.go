@@ -0,0 +1,44 @@
+package db
+
+import (
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+const (
+	ErrMovieSubtitleNotFound = "movie subtitle"
+)
+
+func CreateMovieSubtitle(subtitle *model.MovieSubtitle) error {
+	return db.Create(subtitle).Error
+}
+
+func GetMovieSubtitles(roomID, movieID string) ([]*model.MovieSubtitle, error) {
+	var subtitles []*model.MovieSubtitle
+	err := db.Where("room_id = ? AND movie_id = ?", roomID, movieID).Order("created_at ASC").Find(&subtitles).Error
+	return subtitles, err
+}
+
+func GetMovieSubtitlesCount(roomID, movieID string) (int64, error) {
+	var count int64
+	err := db.Model(&model.MovieSubtitle{}).Where("room_id = ? AND movie_id = ?", roomID, movieID).Count(&count).Error
+	return count, err
+}
+
+func GetMovieSubtitle(roomID, id string) (*model.MovieSubtitle, error) {
+	var subtitle model.MovieSubtitle
+	err := db.Where("room_id = ? AND id = ?", roomID, id).First(&subtitle).Error
+	return &subtitle, HandleNotFound(err, ErrMovieSubtitleNotFound)
+}
+
+func DeleteMovieSubtitle(roomID, id string) error {
+	result := db.Where("room_id = ? AND id = ?", roomID, id).Delete(&model.MovieSubtitle{})
+	return HandleUpdateResult(result, ErrMovieSubtitleNotFound)
+}
+
+// GetMovieSubtitleIDsByRoomID returns the ids of all the subtitles in the room,
+// used to find the orphan files left by deleted movies and rooms
+func GetMovieSubtitleIDsByRoomID(roomID string) ([]string, error) {
+	var ids []string
+	err := db.Model(&model.MovieSubtitle{}).Where("room_id = ?", roomID).Pluck("id", &ids).Error
+	return ids, err
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/synctv-org/synctv/internal/model"
 	"github.com/zijiren233/stream"
@@ -92,6 +93,9 @@ func CreateRoom(name, password string, maxCount int64, conf ...CreateRoomConfig)
 			}
 			return fmt.Errorf("failed to create room: %w", err)
 		}
+		if r.Settings != nil && len(r.Settings.Tags) != 0 {
+			return saveRoomTags(tx, r.ID, r.Settings.Tags)
+		}
 		return nil
 	})
 	if err != nil {
@@ -117,21 +121,67 @@ func CreateOrLoadRoomSettings(roomID string) (*model.RoomSettings, error) {
 
 func SaveRoomSettings(roomID string, settings *model.RoomSettings) error {
 	settings.ID = roomID
-	return HandleNotFound(db.Save(settings).Error, "room settings")
+	return Transactional(func(tx *gorm.DB) error {
+		if err := tx.Save(settings).Error; err != nil {
+			return HandleNotFound(err, "room settings")
+		}
+		return saveRoomTags(tx, roomID, settings.Tags)
+	})
 }
 
 func UpdateRoomSettings(roomID string, settings map[string]interface{}) (*model.RoomSettings, error) {
 	var rs model.RoomSettings
-	err := db.Model(&model.RoomSettings{ID: roomID}).
-		Clauses(clause.Returning{}).
-		Updates(settings).
-		First(&rs).Error
-	return &rs, HandleNotFound(err, "room settings")
+	err := Transactional(func(tx *gorm.DB) error {
+		err := tx.Model(&model.RoomSettings{ID: roomID}).
+			Clauses(clause.Returning{}).
+			Updates(settings).
+			First(&rs).Error
+		if err != nil {
+			return HandleNotFound(err, "room settings")
+		}
+		if _, ok := settings["tags"]; ok {
+			return saveRoomTags(tx, roomID, rs.Tags)
+		}
+		return nil
+	})
+	return &rs, err
+}
+
+// saveRoomTags replaces the indexed tags of the room
+func saveRoomTags(tx *gorm.DB, roomID string, tags model.RoomTags) error {
+	if err := tx.Where("room_id = ?", roomID).Delete(&model.RoomTag{}).Error; err != nil {
+		return fmt.Errorf("failed to delete room tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rts := make([]*model.RoomTag, len(tags))
+	for i, tag := range tags {
+		rts[i] = &model.RoomTag{
+			RoomID: roomID,
+			Tag:    tag,
+		}
+	}
+	if err := tx.Create(rts).Error; err != nil {
+		return fmt.Errorf("failed to create room tags: %w", err)
+	}
+	return nil
+}
+
+func GetAllRoomTags() ([]string, error) {
+	var tags []string
+	err := db.Model(&model.RoomTag{}).Distinct("tag").Order("tag asc").Pluck("tag", &tags).Error
+	return tags, err
 }
 
 func DeleteRoomByID(roomID string) error {
-	result := db.Unscoped().Select(clause.Associations).Delete(&model.Room{ID: roomID})
-	return HandleUpdateResult(result, ErrRoomNotFound)
+	return Transactional(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Select(clause.Associations).Delete(&model.Room{ID: roomID})
+		if err := HandleUpdateResult(result, ErrRoomNotFound); err != nil {
+			return err
+		}
+		return tx.Where("room_id = ?", roomID).Delete(&model.RoomTag{}).Error
+	})
 }
 
 func SetRoomPassword(roomID, password string) error {
@@ -180,6 +230,59 @@ func SetRoomStatus(roomID string, status model.RoomStatus) error {
 	return HandleUpdateResult(result, ErrRoomNotFound)
 }
 
+func SetRoomLastActiveAt(roomID string, lastActiveAt time.Time) error {
+	result := db.Model(&model.Room{}).Where("id = ?", roomID).Update("last_active_at", lastActiveAt)
+	return HandleUpdateResult(result, ErrRoomNotFound)
+}
+
+// GetRoomArchiveAfterDaysOverrides returns the distinct positive archive_after_days of all rooms
+func GetRoomArchiveAfterDaysOverrides() ([]int64, error) {
+	var days []int64
+	err := db.Model(&model.RoomSettings{}).
+		Where("archive_after_days > 0").
+		Distinct().
+		Pluck("archive_after_days", &days).Error
+	return days, err
+}
+
+// GetInactiveRooms returns active rooms whose archive_after_days is archiveAfterDays
+// and nobody has joined since before
+func GetInactiveRooms(archiveAfterDays int64, before time.Time) ([]*model.Room, error) {
+	var rooms []*model.Room
+	err := db.Select("rooms.*").
+		Joins("LEFT JOIN room_settings ON room_settings.id = rooms.id").
+		Where("rooms.status = ? AND rooms.last_active_at < ? AND COALESCE(room_settings.archive_after_days, 0) = ?", model.RoomStatusActive, before, archiveAfterDays).
+		Find(&rooms).Error
+	return rooms, err
+}
+
+func GetArchivedRoomsBefore(before time.Time) ([]*model.Room, error) {
+	var rooms []*model.Room
+	err := db.Where("status = ? AND archived_at < ?", model.RoomStatusArchived, before).Find(&rooms).Error
+	return rooms, err
+}
+
+func ArchiveRoom(roomID string, archivedAt time.Time) error {
+	result := db.Model(&model.Room{}).
+		Where("id = ? AND status = ?", roomID, model.RoomStatusActive).
+		Updates(map[string]any{
+			"status":      model.RoomStatusArchived,
+			"archived_at": archivedAt,
+		})
+	return HandleUpdateResult(result, ErrRoomNotFound)
+}
+
+func RestoreArchivedRoom(roomID string) error {
+	result := db.Model(&model.Room{}).
+		Where("id = ? AND status = ?", roomID, model.RoomStatusArchived).
+		Updates(map[string]any{
+			"status":         model.RoomStatusActive,
+			"archived_at":    nil,
+			"last_active_at": time.Now(),
+		})
+	return HandleUpdateResult(result, ErrRoomNotFound)
+}
+
 func SetRoomStatusByCreator(userID string, status model.RoomStatus) error {
 	result := db.Model(&model.Room{}).Where("creator_id = ?", userID).Update("status", status)
 	return HandleUpdateResult(result, ErrRoomNotFound)
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"time"
+
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func greatest(column string, value int64) clause.Expr {
+	switch dbType {
+	case conf.DatabaseTypeSqlite3:
+		return gorm.Expr("MAX(room_stats."+column+", ?)", value)
+	default:
+		return gorm.Expr("GREATEST(room_stats."+column+", ?)", value)
+	}
+}
+
+// AddRoomStat accumulates the stat into the bucket of the same room and hour
+func AddRoomStat(stat *model.RoomStat) error {
+	stat.StartAt = stat.StartAt.Truncate(time.Hour)
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "room_id"}, {Name: "start_at"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"viewer_minutes": gorm.Expr("room_stats.viewer_minutes + ?", stat.ViewerMinutes),
+			"peak_viewers":   greatest("peak_viewers", stat.PeakViewers),
+			"movies_played":  gorm.Expr("room_stats.movies_played + ?", stat.MoviesPlayed),
+			"chat_messages":  gorm.Expr("room_stats.chat_messages + ?", stat.ChatMessages),
+		}),
+	}).Create(stat).Error
+}
+
+func GetRoomStats(roomID string, since time.Time) ([]*model.RoomStat, error) {
+	var stats []*model.RoomStat
+	err := db.Where("room_id = ? AND start_at >= ?", roomID, since.Truncate(time.Hour)).
+		Order("start_at ASC").
+		Find(&stats).Error
+	return stats, err
+}
+
+func GetAllRoomStats(since time.Time) ([]*model.RoomStat, error) {
+	var stats []*model.RoomStat
+	err := db.Where("start_at >= ?", since.Truncate(time.Hour)).Find(&stats).Error
+	return stats, err
+}
+
+func DeleteRoomStatsBefore(before time.Time) error {
+	return db.Where("start_at < ?", before).Delete(&model.RoomStat{}).Error
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/synctv-org/synctv/internal/model"
+	"gorm.io/gorm"
+)
+
+func GetRoomBilibiliVendor(roomID string) (*model.RoomBilibiliVendor, error) {
+	var vendor model.RoomBilibiliVendor
+	err := db.Where("room_id = ?", roomID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveRoomBilibiliVendor(vendorInfo *model.RoomBilibiliVendor) (*model.RoomBilibiliVendor, error) {
+	if vendorInfo.RoomID == "" {
+		return nil, errors.New("room_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.RoomBilibiliVendor{
+			RoomID: vendorInfo.RoomID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteRoomBilibiliVendor(roomID string) error {
+	result := db.Where("room_id = ?", roomID).Delete(&model.RoomBilibiliVendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
+
+func GetRoomAlistVendors(roomID string) ([]*model.RoomAlistVendor, error) {
+	var vendors []*model.RoomAlistVendor
+	err := db.Where("room_id = ?", roomID).Find(&vendors).Error
+	return vendors, err
+}
+
+func GetRoomAlistVendor(roomID, serverID string) (*model.RoomAlistVendor, error) {
+	var vendor model.RoomAlistVendor
+	err := db.Where("room_id = ? AND server_id = ?", roomID, serverID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveRoomAlistVendor(vendorInfo *model.RoomAlistVendor) (*model.RoomAlistVendor, error) {
+	if vendorInfo.RoomID == "" || vendorInfo.ServerID == "" {
+		return nil, errors.New("room_id and server_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.RoomAlistVendor{
+			RoomID:   vendorInfo.RoomID,
+			ServerID: vendorInfo.ServerID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteRoomAlistVendor(roomID, serverID string) error {
+	result := db.Where("room_id = ? AND server_id = ?", roomID, serverID).Delete(&model.RoomAlistVendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
+
+func GetRoomEmbyVendors(roomID string) ([]*model.RoomEmbyVendor, error) {
+	var vendors []*model.RoomEmbyVendor
+	err := db.Where("room_id = ?", roomID).Find(&vendors).Error
+	return vendors, err
+}
+
+func GetRoomEmbyVendor(roomID, serverID string) (*model.RoomEmbyVendor, error) {
+	var vendor model.RoomEmbyVendor
+	err := db.Where("room_id = ? AND server_id = ?", roomID, serverID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveRoomEmbyVendor(vendorInfo *model.RoomEmbyVendor) (*model.RoomEmbyVendor, error) {
+	if vendorInfo.RoomID == "" || vendorInfo.ServerID == "" {
+		return nil, errors.New("room_id and server_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.RoomEmbyVendor{
+			RoomID:   vendorInfo.RoomID,
+			ServerID: vendorInfo.ServerID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteRoomEmbyVendor(roomID, serverID string) error {
+	result := db.Where("room_id = ? AND server_id = ?", roomID, serverID).Delete(&model.RoomEmbyVendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+// roomDescriptionFullText is true when the database has a full-text index on the room description,
+// otherwise the description is matched with LIKE
+var roomDescriptionFullText bool
+
+const (
+	roomDescriptionIndexName = "idx_room_settings_description_fts"
+	roomDescriptionFtsTable  = "room_settings_fts"
+)
+
+func initRoomSearchIndex() {
+	var err error
+	switch dbType {
+	case conf.DatabaseTypePostgres:
+		err = db.Exec(fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS %s ON room_settings USING GIN (to_tsvector('simple', coalesce(description, '')))",
+			roomDescriptionIndexName,
+		)).Error
+	case conf.DatabaseTypeMysql:
+		if !db.Migrator().HasIndex("room_settings", roomDescriptionIndexName) {
+			err = db.Exec(fmt.Sprintf(
+				"ALTER TABLE room_settings ADD FULLTEXT INDEX %s (description)",
+				roomDescriptionIndexName,
+			)).Error
+		}
+	case conf.DatabaseTypeSqlite3:
+		err = initSqliteRoomSearchIndex()
+	default:
+		err = fmt.Errorf("unknown database type: %s", dbType)
+	}
+	if err != nil {
+		log.Warnf("failed to init room description full-text index, fallback to like: %v", err)
+		return
+	}
+	roomDescriptionFullText = true
+}
+
+// sqlite uses an external content fts5 table kept in sync by triggers
+func initSqliteRoomSearchIndex() error {
+	if db.Migrator().HasTable(roomDescriptionFtsTable) {
+		return nil
+	}
+	return Transactional(func(tx *gorm.DB) error {
+		stmts := []string{
+			fmt.Sprintf(
+				"CREATE VIRTUAL TABLE %[1]s USING fts5(description, content='room_settings', content_rowid='rowid')",
+				roomDescriptionFtsTable,
+			),
+			fmt.Sprintf(
+				`CREATE TRIGGER %[1]s_ai AFTER INSERT ON room_settings BEGIN
+	INSERT INTO %[1]s(rowid, description) VALUES (new.rowid, new.description);
+END`,
+				roomDescriptionFtsTable,
+			),
+			fmt.Sprintf(
+				`CREATE TRIGGER %[1]s_ad AFTER DELETE ON room_settings BEGIN
+	INSERT INTO %[1]s(%[1]s, rowid, description) VALUES ('delete', old.rowid, old.description);
+END`,
+				roomDescriptionFtsTable,
+			),
+			fmt.Sprintf(
+				`CREATE TRIGGER %[1]s_au AFTER UPDATE OF description ON room_settings BEGIN
+	INSERT INTO %[1]s(%[1]s, rowid, description) VALUES ('delete', old.rowid, old.description);
+	INSERT INTO %[1]s(rowid, description) VALUES (new.rowid, new.description);
+END`,
+				roomDescriptionFtsTable,
+			),
+			fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", roomDescriptionFtsTable),
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ftsQuote quotes every word so that user input can't be parsed as fts operators
+func ftsQuote(keyword string) string {
+	fields := strings.Fields(keyword)
+	for i, f := range fields {
+		fields[i] = `"` + strings.ReplaceAll(f, `"`, `""`) + `"`
+	}
+	return strings.Join(fields, " ")
+}
+
+// mysqlBooleanQuote requires every word in boolean mode and strips operators
+func mysqlBooleanQuote(keyword string) string {
+	fields := strings.Fields(keyword)
+	for i, f := range fields {
+		fields[i] = `+"` + strings.ReplaceAll(f, `"`, "") + `"`
+	}
+	return strings.Join(fields, " ")
+}
+
+func whereRoomDescriptionMatchSQL(keyword string) (string, []any) {
+	if !roomDescriptionFullText {
+		switch dbType {
+		case conf.DatabaseTypePostgres:
+			return "room_settings.description ILIKE ?", []any{utils.LIKE(keyword)}
+		default:
+			return "room_settings.description LIKE ?", []any{utils.LIKE(keyword)}
+		}
+	}
+	switch dbType {
+	case conf.DatabaseTypePostgres:
+		return "to_tsvector('simple', coalesce(room_settings.description, '')) @@ plainto_tsquery('simple', ?)", []any{keyword}
+	case conf.DatabaseTypeMysql:
+		return "MATCH(room_settings.description) AGAINST (? IN BOOLEAN MODE)", []any{mysqlBooleanQuote(keyword)}
+	default:
+		return fmt.Sprintf(
+			"room_settings.rowid IN (SELECT rowid FROM %s WHERE %s MATCH ?)",
+			roomDescriptionFtsTable,
+			roomDescriptionFtsTable,
+		), []any{ftsQuote(keyword)}
+	}
+}
+
+// need join room_settings
+func WhereRoomDescriptionMatch(keyword string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query, args := whereRoomDescriptionMatchSQL(keyword)
+		return db.Where(query, args...)
+	}
+}
+
+// need join room_settings
+func WhereRoomNameLikeOrCreatorInOrRoomsIDLikeOrDescriptionMatch(name string, ids []string, id string, description string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		query, args := whereRoomDescriptionMatchSQL(description)
+		switch dbType {
+		case conf.DatabaseTypePostgres:
+			return db.Where(
+				"name ILIKE ? OR creator_id IN ? OR rooms.id ILIKE ? OR "+query,
+				append([]any{utils.LIKE(name), ids, id}, args...)...,
+			)
+		default:
+			return db.Where(
+				"name LIKE ? OR creator_id IN ? OR rooms.id LIKE ? OR "+query,
+				append([]any{utils.LIKE(name), ids, id}, args...)...,
+			)
+		}
+	}
+}
+
+// need join room_settings
+func WhereRoomCategory(category string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("room_settings.category = ?", category)
+	}
+}
+
+// WhereRoomTagsIn matches rooms that have all the tags
+func WhereRoomTagsIn(tags []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"rooms.id IN (SELECT room_id FROM room_tags WHERE tag IN ? GROUP BY room_id HAVING COUNT(tag) = ?)",
+			tags,
+			len(tags),
+		)
+	}
+}
+
+func PreloadRoomSettings(db *gorm.DB) *gorm.DB {
+	return db.Preload("Settings")
+}
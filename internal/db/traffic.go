@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/synctv-org/synctv/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ErrTrafficLimitNotFound = "traffic limit"
+)
+
+// GetTrafficLimit returns an empty limit if the subject uses the default settings
+func GetTrafficLimit(subjectType model.TrafficSubjectType, subjectID string) (*model.TrafficLimit, error) {
+	limit := model.TrafficLimit{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+	}
+	err := db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).
+		Limit(1).
+		Find(&limit).Error
+	return &limit, err
+}
+
+func GetTrafficLimits(scopes ...func(*gorm.DB) *gorm.DB) ([]*model.TrafficLimit, error) {
+	var limits []*model.TrafficLimit
+	err := db.Scopes(scopes...).Find(&limits).Error
+	return limits, err
+}
+
+func SaveTrafficLimit(limit *model.TrafficLimit) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subject_type"}, {Name: "subject_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "bandwidth", "monthly_quota"}),
+	}).Create(limit).Error
+}
+
+func DeleteTrafficLimit(subjectType model.TrafficSubjectType, subjectID string) error {
+	result := db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).Delete(&model.TrafficLimit{})
+	return HandleUpdateResult(result, ErrTrafficLimitNotFound)
+}
+
+// AddTrafficUsage accumulates the bytes into the usage of the same subject and month
+func AddTrafficUsage(usage *model.TrafficUsage) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject_type"}, {Name: "subject_id"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"bytes": gorm.Expr("traffic_usages.bytes + ?", usage.Bytes),
+		}),
+	}).Create(usage).Error
+}
+
+func GetTrafficUsageBytes(subjectType model.TrafficSubjectType, subjectID, month string) (int64, error) {
+	var bytes int64
+	err := db.Model(&model.TrafficUsage{}).
+		Where("subject_type = ? AND subject_id = ? AND month = ?", subjectType, subjectID, month).
+		Select("COALESCE(SUM(bytes), 0)").
+		Scan(&bytes).Error
+	return bytes, err
+}
+
+func GetTrafficUsages(month string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.TrafficUsage, error) {
+	var usages []*model.TrafficUsage
+	err := db.Where("month = ?", month).Scopes(scopes...).Find(&usages).Error
+	return usages, err
+}
+
+func GetTrafficUsagesCount(month string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Model(&model.TrafficUsage{}).Where("month = ?", month).Scopes(scopes...).Count(&count).Error
+	return count, err
+}
+
+func WhereTrafficSubjectType(subjectType model.TrafficSubjectType) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("subject_type = ?", subjectType)
+	}
+}
+
+func WhereTrafficSubjectID(subjectID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("subject_id = ?", subjectID)
+	}
+}
+
+func WhereTrafficSubjectIDIn(subjectIDs []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("subject_id IN ?", subjectIDs)
+	}
+}
@@ -15,7 +15,7 @@ type dbVersion struct {
 	NextVersion string
 }
 
-const CurrentVersion = "0.0.10"
+const CurrentVersion = "0.0.26"
 
 var models = []any{
 	new(model.Setting),
@@ -23,12 +23,26 @@ var models = []any{
 	new(model.UserProvider),
 	new(model.Room),
 	new(model.RoomSettings),
+	new(model.RoomTag),
 	new(model.RoomMember),
+	new(model.RoomStat),
 	new(model.Movie),
 	new(model.BilibiliVendor),
 	new(model.AlistVendor),
 	new(model.EmbyVendor),
+	new(model.JellyfinVendor),
+	new(model.WebDAVVendor),
+	new(model.S3Vendor),
+	new(model.RoomBilibiliVendor),
+	new(model.RoomAlistVendor),
+	new(model.RoomEmbyVendor),
 	new(model.VendorBackend),
+	new(model.WatchHistory),
+	new(model.MovieSubtitle),
+	new(model.LocalRoot),
+	new(model.TrafficLimit),
+	new(model.TrafficUsage),
+	new(model.LiveRecord),
 }
 
 var dbVersions = map[string]dbVersion{
@@ -76,6 +90,58 @@ var dbVersions = map[string]dbVersion{
 		NextVersion: "0.0.10",
 	},
 	"0.0.10": {
+		NextVersion: "0.0.11",
+	},
+	"0.0.11": {
+		NextVersion: "0.0.12",
+	},
+	"0.0.12": {
+		NextVersion: "0.0.13",
+		Upgrade: func(d *gorm.DB) error {
+			// rooms created before inactivity tracking count as active since their last update
+			return d.Exec("UPDATE rooms SET last_active_at = updated_at WHERE last_active_at IS NULL").Error
+		},
+	},
+	"0.0.13": {
+		NextVersion: "0.0.14",
+	},
+	"0.0.14": {
+		NextVersion: "0.0.15",
+	},
+	"0.0.15": {
+		NextVersion: "0.0.16",
+	},
+	"0.0.16": {
+		NextVersion: "0.0.17",
+	},
+	"0.0.17": {
+		NextVersion: "0.0.18",
+	},
+	"0.0.18": {
+		NextVersion: "0.0.19",
+	},
+	"0.0.19": {
+		NextVersion: "0.0.20",
+	},
+	"0.0.20": {
+		NextVersion: "0.0.21",
+	},
+	"0.0.21": {
+		NextVersion: "0.0.22",
+	},
+	"0.0.22": {
+		NextVersion: "0.0.23",
+	},
+	"0.0.23": {
+		NextVersion: "0.0.24",
+	},
+	"0.0.24": {
+		NextVersion: "0.0.25",
+	},
+	"0.0.25": {
+		NextVersion: "0.0.26",
+	},
+	"0.0.26": {
 		NextVersion: "",
 	},
 }
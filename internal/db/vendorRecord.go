@@ -76,6 +76,84 @@ func DeleteAlistVendor(userID, serverID string) error {
 	return HandleUpdateResult(result, ErrVendorNotFound)
 }
 
+func GetWebDAVVendors(userID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.WebDAVVendor, error) {
+	var vendors []*model.WebDAVVendor
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Find(&vendors).Error
+	return vendors, err
+}
+
+func GetWebDAVVendorsCount(userID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Model(&model.WebDAVVendor{}).Count(&count).Error
+	return count, err
+}
+
+func GetWebDAVVendor(userID, serverID string) (*model.WebDAVVendor, error) {
+	var vendor model.WebDAVVendor
+	err := db.Where("user_id = ? AND server_id = ?", userID, serverID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveWebDAVVendor(vendorInfo *model.WebDAVVendor) (*model.WebDAVVendor, error) {
+	if vendorInfo.UserID == "" || vendorInfo.ServerID == "" {
+		return nil, errors.New("user_id and server_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.WebDAVVendor{
+			UserID:   vendorInfo.UserID,
+			ServerID: vendorInfo.ServerID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteWebDAVVendor(userID, serverID string) error {
+	result := db.Where("user_id = ? AND server_id = ?", userID, serverID).Delete(&model.WebDAVVendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
+
+func GetS3Vendors(userID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.S3Vendor, error) {
+	var vendors []*model.S3Vendor
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Find(&vendors).Error
+	return vendors, err
+}
+
+func GetS3VendorsCount(userID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Model(&model.S3Vendor{}).Count(&count).Error
+	return count, err
+}
+
+func GetS3Vendor(userID, serverID string) (*model.S3Vendor, error) {
+	var vendor model.S3Vendor
+	err := db.Where("user_id = ? AND server_id = ?", userID, serverID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveS3Vendor(vendorInfo *model.S3Vendor) (*model.S3Vendor, error) {
+	if vendorInfo.UserID == "" || vendorInfo.ServerID == "" {
+		return nil, errors.New("user_id and server_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.S3Vendor{
+			UserID:   vendorInfo.UserID,
+			ServerID: vendorInfo.ServerID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteS3Vendor(userID, serverID string) error {
+	result := db.Where("user_id = ? AND server_id = ?", userID, serverID).Delete(&model.S3Vendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
+
 func GetEmbyVendors(userID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.EmbyVendor, error) {
 	var vendors []*model.EmbyVendor
 	err := db.Scopes(scopes...).Where("user_id = ?", userID).Find(&vendors).Error
@@ -120,3 +198,42 @@ func DeleteEmbyVendor(userID, serverID string) error {
 	result := db.Where("user_id = ? AND server_id = ?", userID, serverID).Delete(&model.EmbyVendor{})
 	return HandleUpdateResult(result, ErrVendorNotFound)
 }
+
+func GetJellyfinVendors(userID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.JellyfinVendor, error) {
+	var vendors []*model.JellyfinVendor
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Find(&vendors).Error
+	return vendors, err
+}
+
+func GetJellyfinVendorsCount(userID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Model(&model.JellyfinVendor{}).Count(&count).Error
+	return count, err
+}
+
+func GetJellyfinVendor(userID, serverID string) (*model.JellyfinVendor, error) {
+	var vendor model.JellyfinVendor
+	err := db.Where("user_id = ? AND server_id = ?", userID, serverID).First(&vendor).Error
+	return &vendor, HandleNotFound(err, ErrVendorNotFound)
+}
+
+func CreateOrSaveJellyfinVendor(vendorInfo *model.JellyfinVendor) (*model.JellyfinVendor, error) {
+	if vendorInfo.UserID == "" || vendorInfo.ServerID == "" {
+		return nil, errors.New("user_id and server_id must not be empty")
+	}
+	return vendorInfo, Transactional(func(tx *gorm.DB) error {
+		if errors.Is(tx.First(&model.JellyfinVendor{
+			UserID:   vendorInfo.UserID,
+			ServerID: vendorInfo.ServerID,
+		}).Error, gorm.ErrRecordNotFound) {
+			return tx.Create(&vendorInfo).Error
+		}
+		result := tx.Omit("created_at").Save(&vendorInfo)
+		return HandleUpdateResult(result, ErrVendorNotFound)
+	})
+}
+
+func DeleteJellyfinVendor(userID, serverID string) error {
+	result := db.Where("user_id = ? AND server_id = ?", userID, serverID).Delete(&model.JellyfinVendor{})
+	return HandleUpdateResult(result, ErrVendorNotFound)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/synctv-org/synctv/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ErrWatchHistoryNotFound = "watch history"
+)
+
+func SaveWatchHistories(histories []*model.WatchHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "media_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at",
+			"room_id",
+			"movie_id",
+			"sub_path",
+			"name",
+			"vendor",
+			"position",
+			"duration",
+			"completed",
+		}),
+	}).CreateInBatches(histories, 100).Error
+}
+
+func GetWatchHistory(userID, mediaKey string) (*model.WatchHistory, error) {
+	var history model.WatchHistory
+	err := db.Where("user_id = ? AND media_key = ?", userID, mediaKey).First(&history).Error
+	return &history, HandleNotFound(err, ErrWatchHistoryNotFound)
+}
+
+func GetWatchHistories(userID string, scopes ...func(*gorm.DB) *gorm.DB) ([]*model.WatchHistory, error) {
+	var histories []*model.WatchHistory
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Find(&histories).Error
+	return histories, err
+}
+
+func GetWatchHistoriesCount(userID string, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Scopes(scopes...).Where("user_id = ?", userID).Model(&model.WatchHistory{}).Count(&count).Error
+	return count, err
+}
+
+func WhereWatchHistoryCompleted(completed bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("completed = ?", completed)
+	}
+}
+
+func DeleteWatchHistory(userID, mediaKey string) error {
+	result := db.Where("user_id = ? AND media_key = ?", userID, mediaKey).Delete(&model.WatchHistory{})
+	return HandleUpdateResult(result, ErrWatchHistoryNotFound)
+}
+
+func DeleteWatchHistories(userID string) error {
+	return db.Where("user_id = ?", userID).Delete(&model.WatchHistory{}).Error
+}
@@ -62,6 +62,7 @@ var (
 	testTemplate             *template.Template
 	captchaTemplate          *template.Template
 	retrievePasswordTemplate *template.Template
+	roomArchivedTemplate     *template.Template
 )
 
 func init() {
@@ -106,6 +107,20 @@ func init() {
 		log.Fatalf("parse retrieve password template error: %v", err)
 	}
 	retrievePasswordTemplate = t
+
+	body, err = mjml.ToHTML(
+		context.Background(),
+		stream.BytesToString(emailtemplate.RoomArchivedMjml),
+		mjml.WithMinify(true),
+	)
+	if err != nil {
+		log.Fatalf("mjml room archived template error: %v", err)
+	}
+	t, err = template.New("").Parse(body)
+	if err != nil {
+		log.Fatalf("parse room archived template error: %v", err)
+	}
+	roomArchivedTemplate = t
 }
 
 type testPayload struct {
@@ -127,6 +142,46 @@ type retrievePasswordPayload struct {
 	Year int
 }
 
+type roomArchivedPayload struct {
+	Username string
+	RoomName string
+	DeleteAt string
+
+	Year int
+}
+
+func SendRoomArchivedEmail(username, email, roomName string, deleteAt time.Time) error {
+	if !EnableEmail.Get() {
+		return ErrEmailNotEnabled
+	}
+
+	if email == "" {
+		return errors.New("email is empty")
+	}
+
+	pool, err := getSmtpPool()
+	if err != nil {
+		return err
+	}
+
+	out := bytes.NewBuffer(nil)
+	err = roomArchivedTemplate.Execute(out, roomArchivedPayload{
+		Username: username,
+		RoomName: roomName,
+		DeleteAt: deleteAt.Format(time.DateTime),
+		Year:     time.Now().Year(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return pool.SendEmail(
+		[]string{email},
+		"SyncTV Room Archived",
+		out.String(),
+	)
+}
+
 func SendBindCaptchaEmail(userID, userEmail string) error {
 	if !EnableEmail.Get() {
 		return ErrEmailNotEnabled
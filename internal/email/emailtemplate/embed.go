@@ -11,4 +11,7 @@ var (
 
 	//go:embed retrieve_password.mjml
 	RetrievePasswordMjml []byte
+
+	//go:embed room_archived.mjml
+	RoomArchivedMjml []byte
 )
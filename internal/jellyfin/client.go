@@ -0,0 +1,283 @@
+// Package jellyfin is a minimal client of the Jellyfin HTTP API, only the
+// endpoints needed to browse the libraries and play the items are implemented.
+package jellyfin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/version"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/go-uhc"
+)
+
+const (
+	clientName = "SyncTV"
+	deviceName = "SyncTV"
+	deviceID   = "synctv"
+)
+
+type Client struct {
+	host   string
+	token  string
+	userID string
+}
+
+type ClientOption func(*Client)
+
+// WithToken sets the access token or the api key
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithUserID(userID string) ClientOption {
+	return func(c *Client) {
+		c.userID = userID
+	}
+}
+
+func NewClient(host string, opts ...ClientOption) *Client {
+	c := &Client{
+		host: strings.TrimRight(host, "/"),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) Host() string {
+	return c.host
+}
+
+func (c *Client) authorization() string {
+	auth := fmt.Sprintf(`MediaBrowser Client="%s", Device="%s", DeviceId="%s", Version="%s"`,
+		clientName, deviceName, deviceID, version.Version)
+	if c.token != "" {
+		auth += fmt.Sprintf(`, Token="%s"`, c.token)
+	}
+	return auth
+}
+
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("jellyfin: bad status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("jellyfin: bad status code: %d: %s", e.StatusCode, e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, p string, query url.Values, body, resp any) error {
+	u, err := url.Parse(c.host + p)
+	if err != nil {
+		return err
+	}
+	if len(query) != 0 {
+		u.RawQuery = query.Encode()
+	}
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", c.authorization())
+	req.Header.Set("User-Agent", utils.UA)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := uhc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return &StatusError{
+			StatusCode: res.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+	if resp == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
+
+// AuthenticateByName logs in with the username and password
+func (c *Client) AuthenticateByName(ctx context.Context, username, password string) (*AuthenticateResp, error) {
+	var resp AuthenticateResp
+	err := c.do(ctx, http.MethodPost, "/Users/AuthenticateByName", nil, map[string]string{
+		"Username": username,
+		"Pw":       password,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Me returns the user of the access token, api keys are not bound to a user
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var resp User
+	if err := c.do(ctx, http.MethodGet, "/Users/Me", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Users(ctx context.Context) ([]*User, error) {
+	var resp []*User
+	if err := c.do(ctx, http.MethodGet, "/Users", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) SystemInfo(ctx context.Context) (*SystemInfo, error) {
+	var resp SystemInfo
+	if err := c.do(ctx, http.MethodGet, "/System/Info", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/Sessions/Logout", nil, nil, nil)
+}
+
+func (c *Client) Item(ctx context.Context, itemID string) (*Item, error) {
+	query := url.Values{}
+	query.Set("userId", c.userID)
+	var resp Item
+	if err := c.do(ctx, http.MethodGet, "/Items/"+url.PathEscape(itemID), query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) UserViews(ctx context.Context, q *ItemsQuery) (*ItemsResp, error) {
+	query := q.values()
+	query.Set("userId", c.userID)
+	var resp ItemsResp
+	if err := c.do(ctx, http.MethodGet, "/UserViews", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Items(ctx context.Context, q *ItemsQuery) (*ItemsResp, error) {
+	query := q.values()
+	query.Set("userId", c.userID)
+	var resp ItemsResp
+	if err := c.do(ctx, http.MethodGet, "/Items", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Seasons(ctx context.Context, seriesID string, q *ItemsQuery) (*ItemsResp, error) {
+	query := q.values()
+	query.Set("userId", c.userID)
+	var resp ItemsResp
+	if err := c.do(ctx, http.MethodGet, "/Shows/"+url.PathEscape(seriesID)+"/Seasons", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) Episodes(ctx context.Context, seriesID, seasonID string, q *ItemsQuery) (*ItemsResp, error) {
+	query := q.values()
+	query.Set("userId", c.userID)
+	query.Set("seasonId", seasonID)
+	var resp ItemsResp
+	if err := c.do(ctx, http.MethodGet, "/Shows/"+url.PathEscape(seriesID)+"/Episodes", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PlaybackInfo opens a playback session, the sources only get a transcoding url
+// when transcode is true
+func (c *Client) PlaybackInfo(ctx context.Context, itemID string, transcode bool) (*PlaybackInfoResp, error) {
+	query := url.Values{}
+	query.Set("userId", c.userID)
+	var resp PlaybackInfoResp
+	err := c.do(ctx, http.MethodPost, "/Items/"+url.PathEscape(itemID)+"/PlaybackInfo", query, &playbackInfoReq{
+		UserID:              c.userID,
+		EnableDirectPlay:    !transcode,
+		EnableDirectStream:  !transcode,
+		EnableTranscoding:   transcode,
+		AutoOpenLiveStream:  true,
+		MaxStreamingBitrate: 120000000,
+		DeviceProfile:       newDeviceProfile(transcode),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StopEncoding stops the transcoding of the playback session
+func (c *Client) StopEncoding(ctx context.Context, playSessionID string) error {
+	query := url.Values{}
+	query.Set("deviceId", deviceID)
+	query.Set("playSessionId", playSessionID)
+	return c.do(ctx, http.MethodDelete, "/Videos/ActiveEncodings", query, nil, nil)
+}
+
+// StreamURL returns the static stream url of the media source
+func (c *Client) StreamURL(itemID string, source *MediaSource) string {
+	query := url.Values{}
+	query.Set("static", "true")
+	query.Set("mediaSourceId", source.ID)
+	query.Set("api_key", c.token)
+	p := fmt.Sprintf("/Videos/%s/stream", url.PathEscape(itemID))
+	if source.Container != "" {
+		// the container may be a list like `mov,mp4,m4a`
+		container, _, _ := strings.Cut(source.Container, ",")
+		p += "." + container
+	}
+	return fmt.Sprintf("%s%s?%s", c.host, p, query.Encode())
+}
+
+// TranscodingURL returns the absolute url of the transcoding url of the media source
+func (c *Client) TranscodingURL(source *MediaSource) string {
+	if source.TranscodingURL == "" {
+		return ""
+	}
+	return c.host + "/" + strings.TrimLeft(source.TranscodingURL, "/")
+}
+
+// SubtitleURL returns the url of the subtitle stream converted to the format
+func (c *Client) SubtitleURL(itemID, mediaSourceID string, index int64, format string) string {
+	query := url.Values{}
+	query.Set("api_key", c.token)
+	return fmt.Sprintf("%s/Videos/%s/%s/Subtitles/%d/Stream.%s?%s",
+		c.host,
+		url.PathEscape(itemID),
+		url.PathEscape(mediaSourceID),
+		index,
+		format,
+		query.Encode(),
+	)
+}
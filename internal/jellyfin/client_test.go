@@ -0,0 +1,312 @@
+package jellyfin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/jellyfin"
+)
+
+// stubItems are the items of the stub server by id, the children are found by the
+// parentId, seriesId and seasonId
+var stubItems = map[string]string{
+	"lib-movies": `{"Id":"lib-movies","Name":"Movies","Type":"CollectionFolder","IsFolder":true,"CollectionType":"movies"}`,
+	"lib-shows":  `{"Id":"lib-shows","Name":"Shows","Type":"CollectionFolder","IsFolder":true,"CollectionType":"tvshows"}`,
+	"series":     `{"Id":"series","Name":"Show","Type":"Series","IsFolder":true}`,
+	"season":     `{"Id":"season","Name":"Season 1","Type":"Season","IsFolder":true,"SeriesId":"series"}`,
+	"movie":      `{"Id":"movie","Name":"Movie","Type":"Movie","IsFolder":false}`,
+}
+
+// newStubServer serves the endpoints used by the client, the requests are
+// authorized by the access token "token" or the api key "apikey"
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	items := func(w http.ResponseWriter, ids ...string) {
+		list := make([]string, 0, len(ids))
+		for _, id := range ids {
+			list = append(list, stubItems[id])
+		}
+		_, _ = io.WriteString(w, `{"Items":[`+strings.Join(list, ",")+`],"TotalRecordCount":`+strconv.Itoa(len(ids))+`}`)
+	}
+	mux.HandleFunc("POST /Users/AuthenticateByName", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Username string
+			Pw       string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Username != "alice" || body.Pw != "password" {
+			http.Error(w, "Error processing request.", http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"User":{"Id":"u1","Name":"alice","ServerId":"srv"},"AccessToken":"token","ServerId":"srv"}`)
+	})
+	mux.HandleFunc("GET /System/Info", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"Id":"srv","ServerName":"stub","Version":"10.9.0"}`)
+	})
+	mux.HandleFunc("GET /Users", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `[`+
+			`{"Id":"u0","Name":"disabled","Policy":{"IsAdministrator":true,"IsDisabled":true}},`+
+			`{"Id":"u1","Name":"alice","Policy":{"IsAdministrator":false}},`+
+			`{"Id":"u2","Name":"admin","Policy":{"IsAdministrator":true}}]`)
+	})
+	mux.HandleFunc("GET /UserViews", func(w http.ResponseWriter, r *http.Request) {
+		items(w, "lib-movies", "lib-shows")
+	})
+	mux.HandleFunc("GET /Items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		item, ok := stubItems[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, item)
+	})
+	mux.HandleFunc("GET /Items", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("searchTerm") != "":
+			items(w, "movie")
+		case q.Get("parentId") == "lib-movies" && q.Get("includeItemTypes") == "Movie" && q.Get("recursive") == "true":
+			items(w, "movie")
+		case q.Get("parentId") == "lib-shows" && q.Get("includeItemTypes") == "Series" && q.Get("recursive") == "true":
+			items(w, "series")
+		default:
+			items(w)
+		}
+	})
+	mux.HandleFunc("GET /Shows/{id}/Seasons", func(w http.ResponseWriter, r *http.Request) {
+		items(w, "season")
+	})
+	mux.HandleFunc("GET /Shows/{id}/Episodes", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("seasonId") != "season" {
+			items(w)
+			return
+		}
+		items(w, "movie")
+	})
+	mux.HandleFunc("POST /Items/{id}/PlaybackInfo", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			EnableDirectStream bool
+			EnableTranscoding  bool
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		source := `"Id":"ms1","Name":"1080p","Container":"mkv,webm","SupportsDirectStream":` +
+			map[bool]string{true: "true", false: "false"}[body.EnableDirectStream] +
+			`,"MediaStreams":[` +
+			`{"Type":"Video","Index":0,"Codec":"hevc"},` +
+			`{"Type":"Subtitle","Index":2,"Codec":"subrip","Language":"eng","DisplayTitle":"English","IsTextSubtitleStream":true},` +
+			`{"Type":"Subtitle","Index":3,"Codec":"PGSSUB","Language":"chi","IsTextSubtitleStream":false},` +
+			`{"Type":"Subtitle","Index":4,"Codec":"ass","Language":"jpn","IsTextSubtitleStream":true}]`
+		if body.EnableTranscoding {
+			source += `,"TranscodingUrl":"/videos/` + r.PathValue("id") + `/master.m3u8?MediaSourceId=ms1&PlaySessionId=ps1"`
+		}
+		_, _ = io.WriteString(w, `{"PlaySessionId":"ps1","MediaSources":[{`+source+`}]}`)
+	})
+	mux.HandleFunc("GET /Videos/{id}/{source}/Subtitles/{index}/Stream.srt", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, "1\n00:00:01,000 --> 00:00:02,000\n"+r.PathValue("index")+"\n")
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the media urls are authorized by the api_key query
+		if strings.HasPrefix(r.URL.Path, "/Videos/") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, `MediaBrowser Client="SyncTV"`) {
+			http.Error(w, "missing authorization", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/Users/AuthenticateByName" &&
+			!strings.HasSuffix(auth, `Token="token"`) && !strings.HasSuffix(auth, `Token="apikey"`) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthenticateByName(t *testing.T) {
+	srv := newStubServer(t)
+	cli := jellyfin.NewClient(srv.URL + "/")
+	ctx := context.Background()
+
+	resp, err := cli.AuthenticateByName(ctx, "alice", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken != "token" || resp.ServerID != "srv" || resp.User == nil || resp.User.ID != "u1" {
+		t.Errorf("authenticate = %+v", resp)
+	}
+
+	_, err = cli.AuthenticateByName(ctx, "alice", "wrong")
+	var statusErr *jellyfin.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("err = %v, want 401", err)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	srv := newStubServer(t)
+	ctx := context.Background()
+
+	cli := jellyfin.NewClient(srv.URL, jellyfin.WithToken("apikey"))
+	info, err := cli.SystemInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != "srv" {
+		t.Errorf("system info = %+v", info)
+	}
+	users, err := cli.Users(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || !users[0].Policy.IsDisabled || !users[2].Policy.IsAdministrator {
+		t.Errorf("users = %+v", users)
+	}
+
+	if _, err := jellyfin.NewClient(srv.URL, jellyfin.WithToken("wrong")).Users(ctx); err == nil {
+		t.Error("wrong api key is not rejected")
+	}
+}
+
+func TestList(t *testing.T) {
+	srv := newStubServer(t)
+	cli := jellyfin.NewClient(srv.URL, jellyfin.WithToken("token"), jellyfin.WithUserID("u1"))
+	tests := []struct {
+		name  string
+		req   *jellyfin.ListReq
+		items []string
+		paths []string
+	}{
+		{
+			name:  "user views",
+			req:   &jellyfin.ListReq{},
+			items: []string{"lib-movies", "lib-shows"},
+			paths: []string{"Home"},
+		},
+		{
+			name:  "movies library",
+			req:   &jellyfin.ListReq{Path: "lib-movies"},
+			items: []string{"movie"},
+			paths: []string{"Home", "Movies"},
+		},
+		{
+			name:  "tvshows library",
+			req:   &jellyfin.ListReq{Path: "lib-shows"},
+			items: []string{"series"},
+			paths: []string{"Home", "Shows"},
+		},
+		{
+			name:  "series",
+			req:   &jellyfin.ListReq{Path: "series"},
+			items: []string{"season"},
+			paths: []string{"Home", "Show"},
+		},
+		{
+			name:  "season",
+			req:   &jellyfin.ListReq{Path: "season"},
+			items: []string{"movie"},
+			paths: []string{"Home", "Season 1"},
+		},
+		{
+			name:  "search",
+			req:   &jellyfin.ListReq{Path: "lib-movies", SearchTerm: "mov"},
+			items: []string{"movie"},
+			paths: []string{"Home", "Movies"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := cli.List(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var items, paths []string
+			for _, i := range resp.Items {
+				items = append(items, i.ID)
+			}
+			for _, p := range resp.Paths {
+				paths = append(paths, p.Name)
+			}
+			if strings.Join(items, ",") != strings.Join(tt.items, ",") || resp.Total != uint64(len(tt.items)) {
+				t.Errorf("items = %v (%d), want %v", items, resp.Total, tt.items)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.paths, ",") {
+				t.Errorf("paths = %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+
+	if _, err := cli.List(context.Background(), &jellyfin.ListReq{Path: "movie"}); err == nil {
+		t.Error("listing a movie is not rejected")
+	}
+}
+
+func TestPlaybackInfo(t *testing.T) {
+	srv := newStubServer(t)
+	cli := jellyfin.NewClient(srv.URL, jellyfin.WithToken("token"), jellyfin.WithUserID("u1"))
+	ctx := context.Background()
+
+	direct, err := cli.PlaybackInfo(ctx, "movie", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(direct.MediaSources) != 1 || direct.MediaSources[0].TranscodingURL != "" {
+		t.Fatalf("direct stream sources = %+v", direct.MediaSources)
+	}
+	u, err := url.Parse(cli.StreamURL("movie", direct.MediaSources[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/Videos/movie/stream.mkv" || u.Query().Get("static") != "true" ||
+		u.Query().Get("mediaSourceId") != "ms1" || u.Query().Get("api_key") != "token" {
+		t.Errorf("stream url = %s", u)
+	}
+
+	transcode, err := cli.PlaybackInfo(ctx, "movie", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transcode.PlaySessionID != "ps1" || len(transcode.MediaSources) != 1 {
+		t.Fatalf("transcode = %+v", transcode)
+	}
+	want := srv.URL + "/videos/movie/master.m3u8?MediaSourceId=ms1&PlaySessionId=ps1"
+	if got := cli.TranscodingURL(transcode.MediaSources[0]); got != want {
+		t.Errorf("transcoding url = %s, want %s", got, want)
+	}
+}
+
+func TestSubtitleURL(t *testing.T) {
+	srv := newStubServer(t)
+	cli := jellyfin.NewClient(srv.URL, jellyfin.WithToken("token"))
+	u := cli.SubtitleURL("movie", "ms1", 2, "srt")
+	if want := srv.URL + "/Videos/movie/ms1/Subtitles/2/Stream.srt?api_key=token"; u != want {
+		t.Fatalf("subtitle url = %s, want %s", u, want)
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(b), "-->") {
+		t.Errorf("subtitle = %d %q", resp.StatusCode, b)
+	}
+}
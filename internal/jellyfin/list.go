@@ -0,0 +1,102 @@
+package jellyfin
+
+import (
+	"context"
+	"fmt"
+)
+
+type Path struct {
+	Name string
+	ID   string
+}
+
+type ListReq struct {
+	// the item id of the folder, empty means the user views
+	Path       string
+	SearchTerm string
+	StartIndex uint64
+	Limit      uint64
+}
+
+type ListResp struct {
+	Items []*Item
+	Paths []*Path
+	Total uint64
+}
+
+// List lists the children of the folder the way the jellyfin web client browses,
+// libraries list their movies or series, series list seasons and seasons list episodes
+func (c *Client) List(ctx context.Context, req *ListReq) (*ListResp, error) {
+	q := &ItemsQuery{
+		StartIndex: req.StartIndex,
+		Limit:      req.Limit,
+	}
+	resp := &ListResp{
+		Paths: []*Path{
+			{Name: "Home"},
+		},
+	}
+
+	var (
+		data *ItemsResp
+		err  error
+	)
+	switch {
+	case req.SearchTerm != "":
+		q.SearchTerm = req.SearchTerm
+		q.Recursive = true
+		if req.Path != "" {
+			item, err := c.Item(ctx, req.Path)
+			if err != nil {
+				return nil, err
+			}
+			q.ParentID = item.ID
+			resp.Paths = append(resp.Paths, &Path{Name: item.Name, ID: item.ID})
+		}
+		data, err = c.Items(ctx, q)
+	case req.Path == "":
+		data, err = c.UserViews(ctx, q)
+	default:
+		item, err := c.Item(ctx, req.Path)
+		if err != nil {
+			return nil, err
+		}
+		resp.Paths = append(resp.Paths, &Path{Name: item.Name, ID: item.ID})
+		switch item.Type {
+		case "CollectionFolder":
+			q.ParentID = item.ID
+			q.Recursive = true
+			switch item.CollectionType {
+			case "movies":
+				q.IncludeItemTypes = "Movie"
+			case "tvshows":
+				q.IncludeItemTypes = "Series"
+			case "music":
+				q.IncludeItemTypes = "Audio"
+			default:
+				q.Recursive = false
+			}
+			data, err = c.Items(ctx, q)
+		case "Series":
+			data, err = c.Seasons(ctx, item.ID, q)
+		case "Season":
+			data, err = c.Episodes(ctx, item.SeriesID, item.ID, q)
+		default:
+			if !item.IsFolder {
+				return nil, fmt.Errorf("%s is not a folder", item.Name)
+			}
+			q.ParentID = item.ID
+			data, err = c.Items(ctx, q)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Items = data.Items
+	resp.Total = data.TotalRecordCount
+	return resp, nil
+}
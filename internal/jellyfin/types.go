@@ -0,0 +1,168 @@
+package jellyfin
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// the json fields of jellyfin are PascalCase, json-iterator matches them case-insensitively
+
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ServerID string `json:"serverId"`
+	Policy   struct {
+		IsAdministrator bool `json:"isAdministrator"`
+		IsDisabled      bool `json:"isDisabled"`
+	} `json:"policy"`
+}
+
+type AuthenticateResp struct {
+	User        *User  `json:"user"`
+	AccessToken string `json:"accessToken"`
+	ServerID    string `json:"serverId"`
+}
+
+type SystemInfo struct {
+	ID              string `json:"id"`
+	ServerName      string `json:"serverName"`
+	Version         string `json:"version"`
+	ProductName     string `json:"productName"`
+	OperatingSystem string `json:"operatingSystem"`
+	LocalAddress    string `json:"localAddress"`
+}
+
+type MediaStream struct {
+	Type                 string `json:"type"`
+	Index                int64  `json:"index"`
+	Codec                string `json:"codec"`
+	Language             string `json:"language"`
+	Title                string `json:"title"`
+	DisplayTitle         string `json:"displayTitle"`
+	IsDefault            bool   `json:"isDefault"`
+	IsExternal           bool   `json:"isExternal"`
+	IsTextSubtitleStream bool   `json:"isTextSubtitleStream"`
+}
+
+type MediaSource struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	Path                 string         `json:"path"`
+	Protocol             string         `json:"protocol"`
+	Container            string         `json:"container"`
+	SupportsDirectStream bool           `json:"supportsDirectStream"`
+	TranscodingURL       string         `json:"transcodingUrl"`
+	MediaStreams         []*MediaStream `json:"mediaStreams"`
+}
+
+type Item struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	IsFolder       bool           `json:"isFolder"`
+	ParentID       string         `json:"parentId"`
+	SeriesID       string         `json:"seriesId"`
+	SeasonID       string         `json:"seasonId"`
+	CollectionType string         `json:"collectionType"`
+	MediaSources   []*MediaSource `json:"mediaSources"`
+}
+
+type ItemsResp struct {
+	Items            []*Item `json:"items"`
+	TotalRecordCount uint64  `json:"totalRecordCount"`
+}
+
+type PlaybackInfoResp struct {
+	PlaySessionID string         `json:"playSessionId"`
+	MediaSources  []*MediaSource `json:"mediaSources"`
+}
+
+type ItemsQuery struct {
+	ParentID         string
+	SearchTerm       string
+	IncludeItemTypes string
+	Recursive        bool
+	StartIndex       uint64
+	Limit            uint64
+}
+
+func (q *ItemsQuery) values() url.Values {
+	query := url.Values{}
+	if q == nil {
+		return query
+	}
+	query.Set("sortBy", "SortName")
+	query.Set("sortOrder", "Ascending")
+	if q.ParentID != "" {
+		query.Set("parentId", q.ParentID)
+	}
+	if q.SearchTerm != "" {
+		query.Set("searchTerm", q.SearchTerm)
+	}
+	if q.IncludeItemTypes != "" {
+		query.Set("includeItemTypes", q.IncludeItemTypes)
+	}
+	if q.Recursive {
+		query.Set("recursive", "true")
+	}
+	if q.StartIndex != 0 {
+		query.Set("startIndex", strconv.FormatUint(q.StartIndex, 10))
+	}
+	if q.Limit != 0 {
+		query.Set("limit", strconv.FormatUint(q.Limit, 10))
+	}
+	return query
+}
+
+type playbackInfoReq struct {
+	UserID              string         `json:"UserId"`
+	EnableDirectPlay    bool           `json:"EnableDirectPlay"`
+	EnableDirectStream  bool           `json:"EnableDirectStream"`
+	EnableTranscoding   bool           `json:"EnableTranscoding"`
+	AutoOpenLiveStream  bool           `json:"AutoOpenLiveStream"`
+	MaxStreamingBitrate int64          `json:"MaxStreamingBitrate"`
+	DeviceProfile       *deviceProfile `json:"DeviceProfile"`
+}
+
+type directPlayProfile struct {
+	Type string `json:"Type"`
+}
+
+type transcodingProfile struct {
+	Container  string `json:"Container"`
+	Type       string `json:"Type"`
+	VideoCodec string `json:"VideoCodec"`
+	AudioCodec string `json:"AudioCodec"`
+	Protocol   string `json:"Protocol"`
+	Context    string `json:"Context"`
+}
+
+type deviceProfile struct {
+	DirectPlayProfiles  []*directPlayProfile  `json:"DirectPlayProfiles"`
+	TranscodingProfiles []*transcodingProfile `json:"TranscodingProfiles"`
+}
+
+// newDeviceProfile describes a browser player, the sources are transcoded to hls
+// h264/aac when transcode is true, otherwise every container can be played directly
+func newDeviceProfile(transcode bool) *deviceProfile {
+	p := &deviceProfile{
+		DirectPlayProfiles: []*directPlayProfile{},
+		TranscodingProfiles: []*transcodingProfile{
+			{
+				Container:  "ts",
+				Type:       "Video",
+				VideoCodec: "h264",
+				AudioCodec: "aac",
+				Protocol:   "hls",
+				Context:    "Streaming",
+			},
+		},
+	}
+	if !transcode {
+		p.DirectPlayProfiles = []*directPlayProfile{
+			{Type: "Video"},
+			{Type: "Audio"},
+		}
+	}
+	return p
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+// LiveRecordScheme is the url scheme of the movies playing a live recording, e.g. record://id
+const LiveRecordScheme = "record"
+
+type LiveRecordStatus string
+
+const (
+	LiveRecordStatusRecording LiveRecordStatus = "recording"
+	LiveRecordStatusFinished  LiveRecordStatus = "finished"
+	LiveRecordStatusFailed    LiveRecordStatus = "failed"
+)
+
+type LiveRecordStopReason string
+
+const (
+	LiveRecordStopManual LiveRecordStopReason = "manual"
+	// the live movie was deleted or edited
+	LiveRecordStopClosed      LiveRecordStopReason = "closed"
+	LiveRecordStopMaxSize     LiveRecordStopReason = "max_size"
+	LiveRecordStopMaxDuration LiveRecordStopReason = "max_duration"
+	// the server stopped while recording
+	LiveRecordStopInterrupted LiveRecordStopReason = "interrupted"
+	LiveRecordStopError       LiveRecordStopReason = "error"
+)
+
+// LiveRecord is a live movie recorded to a flv file on disk,
+// a movie playing the file is added to the room when the recording stops
+type LiveRecord struct {
+	ID        string    `gorm:"primaryKey;type:char(32)"     json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"-"`
+	RoomID    string    `gorm:"not null;index;type:char(32)" json:"-"`
+	// the live movie recorded
+	SourceMovieID string `gorm:"not null;index;type:char(32)" json:"sourceMovieId"`
+	// the movie playing the recording, null until the recording stops
+	MovieID    EmptyNullString      `gorm:"index;type:char(32)"         json:"movieId"`
+	CreatorID  string               `gorm:"index;type:char(32)"         json:"creatorId"`
+	Name       string               `gorm:"not null;type:varchar(256)"  json:"name"`
+	Status     LiveRecordStatus     `gorm:"not null;type:varchar(16)"   json:"status"`
+	StopReason LiveRecordStopReason `gorm:"type:varchar(16)"            json:"stopReason,omitempty"`
+	Error      string               `gorm:"type:varchar(512)"           json:"error,omitempty"`
+	Size       int64                `gorm:"not null;default:0"          json:"size"`
+	// milliseconds
+	Duration  int64      `gorm:"not null;default:0" json:"duration"`
+	StoppedAt *time.Time `json:"stoppedAt,omitempty"`
+}
+
+func (r *LiveRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = utils.SortUUID()
+	}
+	return nil
+}
+
+func (r *LiveRecord) URL() string {
+	return LiveRecordScheme + "://" + r.ID
+}
+
+// GetLiveRecordIDFromURL returns the id of the recording played by a movie url
+func GetLiveRecordIDFromURL(u *url.URL) (string, error) {
+	if u.Scheme != LiveRecordScheme || len(u.Host) != 32 {
+		return "", errors.New("live record url is invalid")
+	}
+	return u.Host, nil
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+// LocalRoot is a directory of the server filesystem shared by the local vendor
+type LocalRoot struct {
+	ID        string    `gorm:"primaryKey;type:char(32)"              json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Name      string    `gorm:"not null;uniqueIndex;type:varchar(64)" json:"name"`
+	Path      string    `gorm:"not null;type:varchar(4096)"           json:"path"`
+	// the users with this role or higher can access the root, 0 means only the listed users
+	Role    Role     `gorm:"not null;default:0"            json:"role"`
+	UserIDs []string `gorm:"serializer:fastjson;type:text" json:"userIds"`
+}
+
+func (r *LocalRoot) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = utils.SortUUID()
+	}
+	return nil
+}
+
+func (r *LocalRoot) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is empty")
+	}
+	if !filepath.IsAbs(r.Path) {
+		return errors.New("path must be absolute")
+	}
+	if r.Role != 0 && r.Role < RoleUser {
+		return errors.New("role must be user or higher")
+	}
+	return nil
+}
+
+// CanAccess reports whether the user can browse and push the files of the root,
+// the site admins can access all the roots
+func (r *LocalRoot) CanAccess(u *User) bool {
+	if u.IsAdmin() {
+		return true
+	}
+	if r.Role != 0 && u.Role >= r.Role {
+		return true
+	}
+	return slices.Contains(r.UserIDs, u.ID)
+}
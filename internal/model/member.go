@@ -115,6 +115,7 @@ const (
 	PermissionSetRoomSettings
 	PermissionSetRoomPassword
 	PermissionDeleteRoom
+	PermissionSetRoomVendor
 
 	AllAdminPermissions     RoomAdminPermission = math.MaxUint32
 	NoAdminPermission       RoomAdminPermission = 0
@@ -122,7 +123,8 @@ const (
 		PermissionBanRoomMember |
 		PermissionSetUserPermission |
 		PermissionSetRoomSettings |
-		PermissionSetRoomPassword
+		PermissionSetRoomPassword |
+		PermissionSetRoomVendor
 )
 
 func (p RoomAdminPermission) Has(permission RoomAdminPermission) bool {
@@ -19,7 +19,13 @@ type Movie struct {
 	CreatorID string    `gorm:"index;type:char(32)"                                              json:"creatorId"`
 	Childrens []*Movie  `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 	MovieBase `gorm:"embedded;embeddedPrefix:base_"                                    json:"base"`
-	Position  uint `gorm:"not null"                                                         json:"-"`
+	Meta      MovieMeta        `gorm:"embedded;embeddedPrefix:meta_"                             json:"meta"`
+	Health    MovieHealth      `gorm:"embedded;embeddedPrefix:health_"                           json:"health"`
+	Position  uint             `gorm:"not null"                                                  json:"-"`
+	DeletedAt gorm.DeletedAt   `gorm:"index"                                                     json:"-"`
+	DeletedBy EmptyNullString  `gorm:"type:char(32)"                                             json:"-"`
+	Uploads   []*MovieSubtitle `gorm:"foreignKey:MovieID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Records   []*LiveRecord    `gorm:"foreignKey:MovieID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 }
 
 func (m *Movie) Clone() *Movie {
@@ -31,6 +37,8 @@ func (m *Movie) Clone() *Movie {
 		RoomID:    m.RoomID,
 		CreatorID: m.CreatorID,
 		MovieBase: *m.MovieBase.Clone(),
+		Meta:      m.Meta,
+		Health:    m.Health,
 		Childrens: m.Childrens,
 	}
 }
@@ -59,6 +67,35 @@ func (m *Movie) BeforeSave(tx *gorm.DB) (err error) {
 	return
 }
 
+// MovieMeta is probed from the media source, and reset when the movie is edited
+type MovieMeta struct {
+	VideoCodec string `gorm:"type:varchar(32)" json:"videoCodec,omitempty"`
+	AudioCodec string `gorm:"type:varchar(32)" json:"audioCodec,omitempty"`
+	Format     string `gorm:"type:varchar(16)" json:"format,omitempty"`
+	// seconds
+	Duration float64 `json:"duration,omitempty"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	// unix milli, 0 means not probed yet
+	ProbedAt int64 `json:"probedAt,omitempty"`
+}
+
+type MovieHealthStatus = string
+
+const (
+	MovieHealthUnknown   MovieHealthStatus = ""
+	MovieHealthOK        MovieHealthStatus = "ok"
+	MovieHealthUnhealthy MovieHealthStatus = "unhealthy"
+)
+
+// MovieHealth is the result of the last link check, and reset when the movie is edited
+type MovieHealth struct {
+	Status MovieHealthStatus `gorm:"type:varchar(16)"  json:"status,omitempty"`
+	Error  string            `gorm:"type:varchar(512)" json:"error,omitempty"`
+	// unix milli, 0 means not checked yet
+	CheckedAt int64 `gorm:"not null;default:0;index" json:"checkedAt,omitempty"`
+}
+
 type MoreSource struct {
 	Name string `json:"name"`
 	Type string `json:"type"`
@@ -166,12 +203,22 @@ const (
 	VendorBilibili VendorName = "bilibili"
 	VendorAlist    VendorName = "alist"
 	VendorEmby     VendorName = "emby"
+	VendorLocal    VendorName = "local"
+	VendorJellyfin VendorName = "jellyfin"
+	VendorWebDAV   VendorName = "webdav"
+	VendorS3       VendorName = "s3"
+	VendorPlugin   VendorName = "plugin"
 )
 
 type VendorInfo struct {
 	Bilibili *BilibiliStreamingInfo `gorm:"embedded;embeddedPrefix:bilibili_" json:"bilibili,omitempty"`
 	Alist    *AlistStreamingInfo    `gorm:"embedded;embeddedPrefix:alist_"    json:"alist,omitempty"`
 	Emby     *EmbyStreamingInfo     `gorm:"embedded;embeddedPrefix:emby_"     json:"emby,omitempty"`
+	Local    *LocalStreamingInfo    `gorm:"embedded;embeddedPrefix:local_"    json:"local,omitempty"`
+	Jellyfin *JellyfinStreamingInfo `gorm:"embedded;embeddedPrefix:jellyfin_" json:"jellyfin,omitempty"`
+	WebDAV   *WebDAVStreamingInfo   `gorm:"embedded;embeddedPrefix:webdav_"   json:"webdav,omitempty"`
+	S3       *S3StreamingInfo       `gorm:"embedded;embeddedPrefix:s3_"       json:"s3,omitempty"`
+	Plugin   *PluginStreamingInfo   `gorm:"embedded;embeddedPrefix:plugin_"   json:"plugin,omitempty"`
 	Vendor   VendorName             `gorm:"type:varchar(32)"                  json:"vendor"`
 	Backend  string                 `gorm:"type:varchar(64)"                  json:"backend"`
 }
@@ -314,3 +361,162 @@ func (e *EmbyStreamingInfo) Validate() error {
 	}
 	return nil
 }
+
+type JellyfinStreamingInfo struct {
+	// {/}serverId/ItemId
+	Path      string `gorm:"type:varchar(80)" json:"path,omitempty"`
+	Transcode bool   `json:"transcode,omitempty"`
+}
+
+func GetJellyfinServerIDFromPath(path string) (serverID string, filePath string, err error) {
+	if s := strings.Split(strings.TrimLeft(path, "/"), "/"); len(s) == 2 {
+		return s[0], s[1], nil
+	}
+	return "", path, errors.New("path is invalid")
+}
+
+func FormatJellyfinPath(serverID, filePath string) string {
+	return fmt.Sprintf("%s/%s", serverID, filePath)
+}
+
+func (j *JellyfinStreamingInfo) ServerID() (string, error) {
+	serverID, _, err := GetJellyfinServerIDFromPath(j.Path)
+	return serverID, err
+}
+
+func (j *JellyfinStreamingInfo) ServerIDAndFilePath() (serverID, filePath string, err error) {
+	return GetJellyfinServerIDFromPath(j.Path)
+}
+
+func (j *JellyfinStreamingInfo) Validate() error {
+	if j.Path == "" {
+		return errors.New("path is empty")
+	}
+	return nil
+}
+
+type WebDAVStreamingInfo struct {
+	// {/}serverId/Path
+	Path string `gorm:"type:varchar(4096)" json:"path,omitempty"`
+}
+
+func GetWebDAVServerIDFromPath(p string) (serverID string, filePath string, err error) {
+	before, after, _ := strings.Cut(strings.TrimLeft(p, "/"), "/")
+	if before == "" {
+		return "", p, errors.New("path is invalid")
+	}
+	return before, "/" + after, nil
+}
+
+func FormatWebDAVPath(serverID, filePath string) string {
+	return fmt.Sprintf("%s/%s", serverID, strings.Trim(filePath, "/"))
+}
+
+func (w *WebDAVStreamingInfo) ServerIDAndFilePath() (serverID, filePath string, err error) {
+	return GetWebDAVServerIDFromPath(w.Path)
+}
+
+func (w *WebDAVStreamingInfo) Validate() error {
+	if w.Path == "" {
+		return errors.New("path is empty")
+	}
+	_, filePath, err := w.ServerIDAndFilePath()
+	if err != nil {
+		return err
+	}
+	for _, v := range strings.Split(filePath, "/") {
+		if v == ".." {
+			return errors.New("path is invalid")
+		}
+	}
+	return nil
+}
+
+type S3StreamingInfo struct {
+	// {/}serverId/Key, the key is relative to the prefix of the server
+	Path string `gorm:"type:varchar(4096)" json:"path,omitempty"`
+}
+
+func GetS3ServerIDFromPath(p string) (serverID string, key string, err error) {
+	before, after, _ := strings.Cut(strings.TrimLeft(p, "/"), "/")
+	if before == "" {
+		return "", p, errors.New("path is invalid")
+	}
+	return before, after, nil
+}
+
+func FormatS3Path(serverID, key string) string {
+	return fmt.Sprintf("%s/%s", serverID, strings.TrimLeft(key, "/"))
+}
+
+func (s *S3StreamingInfo) ServerIDAndKey() (serverID, key string, err error) {
+	return GetS3ServerIDFromPath(s.Path)
+}
+
+func (s *S3StreamingInfo) Validate() error {
+	if s.Path == "" {
+		return errors.New("path is empty")
+	}
+	_, key, err := s.ServerIDAndKey()
+	if err != nil {
+		return err
+	}
+	// the key must stay in the prefix of the server
+	for _, v := range strings.Split(key, "/") {
+		if v == ".." || v == "." {
+			return errors.New("path is invalid")
+		}
+	}
+	return nil
+}
+
+// PluginStreamingInfo is the movie of the vendor served by the plugin, the path
+// is only meaningful to the plugin
+type PluginStreamingInfo struct {
+	Name string `gorm:"type:varchar(64)"   json:"name,omitempty"`
+	Path string `gorm:"type:varchar(4096)" json:"path,omitempty"`
+}
+
+func (p *PluginStreamingInfo) Validate() error {
+	if p.Name == "" {
+		return errors.New("plugin name is empty")
+	}
+	return nil
+}
+
+type LocalStreamingInfo struct {
+	// {/}rootId/Path
+	Path string `gorm:"type:varchar(4096)" json:"path,omitempty"`
+}
+
+func GetLocalRootIDFromPath(p string) (rootID string, filePath string, err error) {
+	before, after, _ := strings.Cut(strings.TrimLeft(p, "/"), "/")
+	if before == "" {
+		return "", p, errors.New("path is invalid")
+	}
+	return before, "/" + after, nil
+}
+
+func FormatLocalPath(rootID, filePath string) string {
+	return fmt.Sprintf("%s/%s", rootID, strings.Trim(filePath, "/"))
+}
+
+func (l *LocalStreamingInfo) RootIDAndFilePath() (rootID, filePath string, err error) {
+	return GetLocalRootIDFromPath(l.Path)
+}
+
+func (l *LocalStreamingInfo) Validate() error {
+	if l.Path == "" {
+		return errors.New("path is empty")
+	}
+	_, filePath, err := l.RootIDAndFilePath()
+	if err != nil {
+		return err
+	}
+	for _, v := range strings.Split(filePath, "/") {
+		if v == ".." {
+			return errors.New("path is invalid")
+		}
+	}
+	return nil
+}
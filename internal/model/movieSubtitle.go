@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+// MovieSubtitle is a subtitle file uploaded to a movie, the content is stored on disk
+type MovieSubtitle struct {
+	ID        string    `gorm:"primaryKey;type:char(32)"        json:"id"`
+	CreatedAt time.Time `json:"-"`
+	RoomID    string    `gorm:"not null;index;type:char(32)"    json:"-"`
+	MovieID   string    `gorm:"not null;index;type:char(32)"    json:"movieId"`
+	CreatorID string    `gorm:"index;type:char(32)"             json:"creatorId"`
+	Name      string    `gorm:"not null;type:varchar(256)"      json:"name"`
+	// srt, ass or vtt
+	Type string `gorm:"not null;type:varchar(8)" json:"type"`
+	Size int64  `gorm:"not null;default:0"       json:"size"`
+}
+
+func (s *MovieSubtitle) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = utils.SortUUID()
+	}
+	return nil
+}
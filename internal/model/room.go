@@ -1,8 +1,13 @@
 package model
 
 import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	json "github.com/json-iterator/go"
 	"github.com/synctv-org/synctv/utils"
 	"github.com/zijiren233/stream"
 	"golang.org/x/crypto/bcrypt"
@@ -15,6 +20,8 @@ const (
 	RoomStatusBanned  RoomStatus = 1
 	RoomStatusPending RoomStatus = 2
 	RoomStatusActive  RoomStatus = 3
+	// archived rooms are read-only and hidden, and will be deleted after a while
+	RoomStatusArchived RoomStatus = 4
 )
 
 func (r RoomStatus) String() string {
@@ -25,6 +32,8 @@ func (r RoomStatus) String() string {
 		return "pending"
 	case RoomStatusActive:
 		return "active"
+	case RoomStatusArchived:
+		return "archived"
 	default:
 		return "unknown"
 	}
@@ -38,15 +47,25 @@ type Room struct {
 	Name           string        `gorm:"not null;uniqueIndex;type:varchar(32)"`
 	CreatorID      string        `gorm:"index;type:char(32)"`
 	HashedPassword []byte
-	RoomMembers    []*RoomMember `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Movies         []*Movie      `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Status         RoomStatus    `gorm:"not null;default:2"`
+	RoomMembers    []*RoomMember       `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Movies         []*Movie            `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	RoomStats      []*RoomStat         `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	BilibiliVendor *RoomBilibiliVendor `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	AlistVendor    []*RoomAlistVendor  `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	EmbyVendor     []*RoomEmbyVendor   `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	LiveRecords    []*LiveRecord       `gorm:"foreignKey:RoomID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Status         RoomStatus          `gorm:"not null;default:2"`
+	LastActiveAt   time.Time           `gorm:"index"`
+	ArchivedAt     *time.Time          `gorm:"index"`
 }
 
 func (r *Room) BeforeCreate(tx *gorm.DB) error {
 	if r.ID == "" {
 		r.ID = utils.SortUUID()
 	}
+	if r.LastActiveAt.IsZero() {
+		r.LastActiveAt = time.Now()
+	}
 	return nil
 }
 
@@ -70,6 +89,10 @@ func (r *Room) IsActive() bool {
 	return r.Status == RoomStatusActive
 }
 
+func (r *Room) IsArchived() bool {
+	return r.Status == RoomStatusArchived
+}
+
 //nolint:tagliatelle
 type RoomSettings struct {
 	UpdatedAt              time.Time            `gorm:"autoUpdateTime"           json:"-"`
@@ -87,6 +110,18 @@ type RoomSettings struct {
 	CanSetCurrentMovie     bool                 `gorm:"default:true"             json:"can_set_current_movie"`
 	CanSetCurrentStatus    bool                 `gorm:"default:true"             json:"can_set_current_status"`
 	CanSendChatMessage     bool                 `gorm:"default:true"             json:"can_send_chat_message"`
+	Category               string               `gorm:"index;type:varchar(32)"   json:"category"`
+	Description            string               `gorm:"type:text"                json:"description"`
+	Tags                   RoomTags             `gorm:"type:text"                json:"tags"`
+	ArchiveAfterDays       int64                `gorm:"default:0"                json:"archive_after_days"`
+	Announcement           string               `gorm:"type:text"                json:"announcement"`
+	AnnouncementExpireAt   int64                `gorm:"default:0"                json:"announcement_expire_at"`
+}
+
+// HasAnnouncement reports whether the room has an announcement not expired at now
+func (s *RoomSettings) HasAnnouncement(now time.Time) bool {
+	return s.Announcement != "" &&
+		(s.AnnouncementExpireAt == 0 || now.UnixMilli() < s.AnnouncementExpireAt)
 }
 
 func DefaultRoomSettings() *RoomSettings {
@@ -107,3 +142,88 @@ func DefaultRoomSettings() *RoomSettings {
 		CanSendChatMessage:  true,
 	}
 }
+
+const (
+	MaxRoomTagCount           = 10
+	MaxRoomTagLength          = 32
+	MaxRoomCategoryLength     = 32
+	MaxRoomDescriptionLength  = 4096
+	MaxRoomAnnouncementLength = 8192
+)
+
+// RoomTag is the normalized form of RoomSettings.Tags, used to filter rooms by tag with an index
+type RoomTag struct {
+	RoomID string `gorm:"primaryKey;type:char(32)"`
+	Tag    string `gorm:"primaryKey;index;type:varchar(32)"`
+}
+
+type RoomTags []string
+
+// Scan implements the [Scanner] interface.
+func (t *RoomTags) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type: %T", v)
+	}
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// Value implements the [driver.Valuer] interface.
+func (t RoomTags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (t RoomTags) Has(tag string) bool {
+	tag = NormalizeRoomTag(tag)
+	for _, v := range t {
+		if v == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func NormalizeRoomTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NewRoomTags trims, lowercases and deduplicates tags
+func NewRoomTags(tags []string) (RoomTags, error) {
+	rt := make(RoomTags, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeRoomTag(tag)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > MaxRoomTagLength {
+			return nil, fmt.Errorf("tag %s too long", tag)
+		}
+		if rt.Has(tag) {
+			continue
+		}
+		rt = append(rt, tag)
+	}
+	if len(rt) > MaxRoomTagCount {
+		return nil, errors.New("too many tags")
+	}
+	return rt, nil
+}
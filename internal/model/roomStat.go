@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// RoomStat is the aggregated activity of a room in one hour
+type RoomStat struct {
+	RoomID string `gorm:"primaryKey;type:char(32)" json:"roomId"`
+	// start of the hour
+	StartAt       time.Time `gorm:"primaryKey;index"   json:"startAt"`
+	ViewerMinutes int64     `gorm:"not null;default:0" json:"viewerMinutes"`
+	PeakViewers   int64     `gorm:"not null;default:0" json:"peakViewers"`
+	MoviesPlayed  int64     `gorm:"not null;default:0" json:"moviesPlayed"`
+	ChatMessages  int64     `gorm:"not null;default:0" json:"chatMessages"`
+}
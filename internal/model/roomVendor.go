@@ -0,0 +1,147 @@
+package model
+
+import (
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/stream"
+	"gorm.io/gorm"
+)
+
+// room vendors are owned by the room instead of a user,
+// so they are encrypted with a key derived from the room id
+func genRoomVendorCryptoKey(roomID string) []byte {
+	return utils.GenCryptoKey("room:" + roomID)
+}
+
+type RoomBilibiliVendor struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Cookies   map[string]string `gorm:"not null;serializer:fastjson;type:text"`
+	RoomID    string            `gorm:"primaryKey;type:char(32)"`
+	Backend   string            `gorm:"type:varchar(64)"`
+	// the user who bound the vendor to the room
+	BoundBy string `gorm:"type:char(32)"`
+}
+
+func (b *RoomBilibiliVendor) BeforeSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(b.RoomID)
+	for k, v := range b.Cookies {
+		value, err := utils.CryptoToBase64([]byte(v), key)
+		if err != nil {
+			return err
+		}
+		b.Cookies[k] = value
+	}
+	return nil
+}
+
+func (b *RoomBilibiliVendor) AfterSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(b.RoomID)
+	for k, v := range b.Cookies {
+		value, err := utils.DecryptoFromBase64(v, key)
+		if err != nil {
+			return err
+		}
+		b.Cookies[k] = stream.BytesToString(value)
+	}
+	return nil
+}
+
+func (b *RoomBilibiliVendor) AfterFind(tx *gorm.DB) error {
+	return b.AfterSave(tx)
+}
+
+type RoomAlistVendor struct {
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	RoomID         string `gorm:"primaryKey;type:char(32)"`
+	Backend        string `gorm:"type:varchar(64)"`
+	ServerID       string `gorm:"primaryKey;type:char(32)"`
+	Host           string `gorm:"not null;type:varchar(256)"`
+	Username       string `gorm:"type:varchar(256)"`
+	HashedPassword []byte
+	BoundBy        string `gorm:"type:char(32)"`
+}
+
+func (a *RoomAlistVendor) BeforeSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(a.RoomID)
+	var err error
+	if a.Host, err = utils.CryptoToBase64([]byte(a.Host), key); err != nil {
+		return err
+	}
+	if a.Username, err = utils.CryptoToBase64([]byte(a.Username), key); err != nil {
+		return err
+	}
+	if a.HashedPassword, err = utils.Crypto(a.HashedPassword, key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *RoomAlistVendor) AfterSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(a.RoomID)
+	host, err := utils.DecryptoFromBase64(a.Host, key)
+	if err != nil {
+		return err
+	}
+	a.Host = stream.BytesToString(host)
+	username, err := utils.DecryptoFromBase64(a.Username, key)
+	if err != nil {
+		return err
+	}
+	a.Username = stream.BytesToString(username)
+	hashedPassword, err := utils.Decrypto(a.HashedPassword, key)
+	if err != nil {
+		return err
+	}
+	a.HashedPassword = hashedPassword
+	return nil
+}
+
+func (a *RoomAlistVendor) AfterFind(tx *gorm.DB) error {
+	return a.AfterSave(tx)
+}
+
+type RoomEmbyVendor struct {
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	RoomID     string `gorm:"primaryKey;type:char(32)"`
+	Backend    string `gorm:"type:varchar(64)"`
+	ServerID   string `gorm:"primaryKey;type:char(32)"`
+	Host       string `gorm:"not null;type:varchar(256)"`
+	APIKey     string `gorm:"not null;type:varchar(256)"`
+	EmbyUserID string `gorm:"type:varchar(32)"`
+	BoundBy    string `gorm:"type:char(32)"`
+}
+
+func (e *RoomEmbyVendor) BeforeSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(e.RoomID)
+	var err error
+	if e.Host, err = utils.CryptoToBase64(stream.StringToBytes(e.Host), key); err != nil {
+		return err
+	}
+	if e.APIKey, err = utils.CryptoToBase64(stream.StringToBytes(e.APIKey), key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *RoomEmbyVendor) AfterSave(tx *gorm.DB) error {
+	key := genRoomVendorCryptoKey(e.RoomID)
+	host, err := utils.DecryptoFromBase64(e.Host, key)
+	if err != nil {
+		return err
+	}
+	e.Host = stream.BytesToString(host)
+	apiKey, err := utils.DecryptoFromBase64(e.APIKey, key)
+	if err != nil {
+		return err
+	}
+	e.APIKey = stream.BytesToString(apiKey)
+	return nil
+}
+
+func (e *RoomEmbyVendor) AfterFind(tx *gorm.DB) error {
+	return e.AfterSave(tx)
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type TrafficSubjectType string
+
+const (
+	TrafficSubjectUser TrafficSubjectType = "user"
+	TrafficSubjectRoom TrafficSubjectType = "room"
+)
+
+// TrafficLimit overrides the default proxy bandwidth and monthly quota of a user or a room,
+// 0 means the default setting and negative means unlimited
+type TrafficLimit struct {
+	SubjectType TrafficSubjectType `gorm:"primaryKey;type:varchar(8)" json:"subjectType"`
+	SubjectID   string             `gorm:"primaryKey;type:char(32)"   json:"subjectId"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	// KB per second
+	Bandwidth int64 `gorm:"not null;default:0" json:"bandwidth"`
+	// MB per month
+	MonthlyQuota int64 `gorm:"not null;default:0" json:"monthlyQuota"`
+}
+
+// TrafficUsage is the bytes proxied to a user or a room in one month
+type TrafficUsage struct {
+	SubjectType TrafficSubjectType `gorm:"primaryKey;type:varchar(8)" json:"subjectType"`
+	SubjectID   string             `gorm:"primaryKey;type:char(32)"   json:"subjectId"`
+	// formatted as 2006-01
+	Month string `gorm:"primaryKey;type:char(7);index" json:"month"`
+	Bytes int64  `gorm:"not null;default:0"            json:"bytes"`
+}
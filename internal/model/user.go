@@ -42,19 +42,23 @@ type User struct {
 	ID                    string `gorm:"primaryKey;type:char(32)" json:"id"`
 	CreatedAt             time.Time
 	UpdatedAt             time.Time
-	Username              string          `gorm:"not null;uniqueIndex;type:varchar(32)"`
-	Email                 EmptyNullString `gorm:"type:varchar(128);uniqueIndex"`
-	HashedPassword        []byte          `gorm:"not null"`
-	BilibiliVendor        *BilibiliVendor `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Movies                []*Movie        `gorm:"foreignKey:CreatorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
-	UserProviders         []*UserProvider `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	RoomMembers           []*RoomMember   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Rooms                 []*Room         `gorm:"foreignKey:CreatorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	AlistVendor           []*AlistVendor  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	EmbyVendor            []*EmbyVendor   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Role                  Role            `gorm:"not null;default:2"`
-	RegisteredByProvider  bool            `gorm:"not null;default:false"`
-	RegisteredByEmail     bool            `gorm:"not null;default:false"`
+	Username              string            `gorm:"not null;uniqueIndex;type:varchar(32)"`
+	Email                 EmptyNullString   `gorm:"type:varchar(128);uniqueIndex"`
+	HashedPassword        []byte            `gorm:"not null"`
+	BilibiliVendor        *BilibiliVendor   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Movies                []*Movie          `gorm:"foreignKey:CreatorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	UserProviders         []*UserProvider   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	RoomMembers           []*RoomMember     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Rooms                 []*Room           `gorm:"foreignKey:CreatorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	AlistVendor           []*AlistVendor    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	EmbyVendor            []*EmbyVendor     `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	JellyfinVendor        []*JellyfinVendor `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	WebDAVVendor          []*WebDAVVendor   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	S3Vendor              []*S3Vendor       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	WatchHistories        []*WatchHistory   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Role                  Role              `gorm:"not null;default:2"`
+	RegisteredByProvider  bool              `gorm:"not null;default:false"`
+	RegisteredByEmail     bool              `gorm:"not null;default:false"`
 	autoAddUsernameSuffix bool
 }
 
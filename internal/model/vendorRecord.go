@@ -101,6 +101,115 @@ func (a *AlistVendor) AfterFind(tx *gorm.DB) error {
 	return a.AfterSave(tx)
 }
 
+type WebDAVVendor struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    string `gorm:"primaryKey;type:char(32)"`
+	ServerID  string `gorm:"primaryKey;type:char(32)"`
+	Host      string `gorm:"not null;type:varchar(512)"`
+	Username  string `gorm:"type:varchar(256)"`
+	Password  string `gorm:"type:varchar(512)"`
+}
+
+// GenWebDAVServerID binds the same share with different users as different servers
+func GenWebDAVServerID(w *WebDAVVendor) {
+	if w.ServerID == "" {
+		w.ServerID = utils.SortUUIDWithUUID(uuid.NewMD5(uuid.NameSpaceURL, []byte(w.Username+"@"+w.Host)))
+	}
+}
+
+func (w *WebDAVVendor) BeforeSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(w.UserID)
+	var err error
+	if w.Host, err = utils.CryptoToBase64([]byte(w.Host), key); err != nil {
+		return err
+	}
+	if w.Username, err = utils.CryptoToBase64([]byte(w.Username), key); err != nil {
+		return err
+	}
+	if w.Password, err = utils.CryptoToBase64([]byte(w.Password), key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *WebDAVVendor) AfterSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(w.UserID)
+	host, err := utils.DecryptoFromBase64(w.Host, key)
+	if err != nil {
+		return err
+	}
+	w.Host = stream.BytesToString(host)
+	username, err := utils.DecryptoFromBase64(w.Username, key)
+	if err != nil {
+		return err
+	}
+	w.Username = stream.BytesToString(username)
+	password, err := utils.DecryptoFromBase64(w.Password, key)
+	if err != nil {
+		return err
+	}
+	w.Password = stream.BytesToString(password)
+	return nil
+}
+
+func (w *WebDAVVendor) AfterFind(tx *gorm.DB) error {
+	return w.AfterSave(tx)
+}
+
+type S3Vendor struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    string `gorm:"primaryKey;type:char(32)"`
+	ServerID  string `gorm:"primaryKey;type:char(32)"`
+	Endpoint  string `gorm:"not null;type:varchar(512)"`
+	Region    string `gorm:"type:varchar(64)"`
+	Bucket    string `gorm:"not null;type:varchar(256)"`
+	Prefix    string `gorm:"type:text"`
+	AccessKey string `gorm:"type:varchar(512)"`
+	SecretKey string `gorm:"type:varchar(512)"`
+	PathStyle bool
+}
+
+// GenS3ServerID binds the same prefix of the bucket with different keys as different servers
+func GenS3ServerID(s *S3Vendor) {
+	if s.ServerID == "" {
+		s.ServerID = utils.SortUUIDWithUUID(uuid.NewMD5(uuid.NameSpaceURL, []byte(s.AccessKey+"@"+s.Endpoint+"/"+s.Bucket+"/"+s.Prefix)))
+	}
+}
+
+func (s *S3Vendor) encryptedFields() []*string {
+	return []*string{&s.Endpoint, &s.Bucket, &s.Prefix, &s.AccessKey, &s.SecretKey}
+}
+
+func (s *S3Vendor) BeforeSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(s.UserID)
+	for _, f := range s.encryptedFields() {
+		v, err := utils.CryptoToBase64([]byte(*f), key)
+		if err != nil {
+			return err
+		}
+		*f = v
+	}
+	return nil
+}
+
+func (s *S3Vendor) AfterSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(s.UserID)
+	for _, f := range s.encryptedFields() {
+		v, err := utils.DecryptoFromBase64(*f, key)
+		if err != nil {
+			return err
+		}
+		*f = stream.BytesToString(v)
+	}
+	return nil
+}
+
+func (s *S3Vendor) AfterFind(tx *gorm.DB) error {
+	return s.AfterSave(tx)
+}
+
 type EmbyVendor struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
@@ -142,3 +251,46 @@ func (e *EmbyVendor) AfterSave(tx *gorm.DB) error {
 func (e *EmbyVendor) AfterFind(tx *gorm.DB) error {
 	return e.AfterSave(tx)
 }
+
+type JellyfinVendor struct {
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         string `gorm:"primaryKey;type:char(32)"`
+	ServerID       string `gorm:"primaryKey;type:char(32)"`
+	Host           string `gorm:"not null;type:varchar(256)"`
+	APIKey         string `gorm:"not null;type:varchar(256)"`
+	JellyfinUserID string `gorm:"type:varchar(32)"`
+	// the api key is created by the jellyfin admin, it is not revoked on logout
+	UseAPIKey bool `gorm:"not null;default:false"`
+}
+
+func (j *JellyfinVendor) BeforeSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(j.ServerID)
+	var err error
+	if j.Host, err = utils.CryptoToBase64(stream.StringToBytes(j.Host), key); err != nil {
+		return err
+	}
+	if j.APIKey, err = utils.CryptoToBase64(stream.StringToBytes(j.APIKey), key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (j *JellyfinVendor) AfterSave(tx *gorm.DB) error {
+	key := utils.GenCryptoKey(j.ServerID)
+	host, err := utils.DecryptoFromBase64(j.Host, key)
+	if err != nil {
+		return err
+	}
+	j.Host = stream.BytesToString(host)
+	apiKey, err := utils.DecryptoFromBase64(j.APIKey, key)
+	if err != nil {
+		return err
+	}
+	j.APIKey = stream.BytesToString(apiKey)
+	return nil
+}
+
+func (j *JellyfinVendor) AfterFind(tx *gorm.DB) error {
+	return j.AfterSave(tx)
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// WatchHistory is the last watched position of a user on a media,
+// vendor media is keyed by its vendor identity so it is shared across rooms
+type WatchHistory struct {
+	UserID string `gorm:"primaryKey;type:char(32)" json:"-"`
+	// sha256 of the media identity
+	MediaKey  string     `gorm:"primaryKey;type:char(64)" json:"mediaKey"`
+	UpdatedAt time.Time  `gorm:"index"                    json:"updatedAt"`
+	RoomID    string     `gorm:"type:char(32)"            json:"roomId"`
+	MovieID   string     `gorm:"type:char(32)"            json:"movieId"`
+	SubPath   string     `gorm:"type:varchar(4096)"       json:"subPath"`
+	Name      string     `gorm:"type:varchar(256)"        json:"name"`
+	Vendor    VendorName `gorm:"type:varchar(32)"         json:"vendor"`
+	Position  float64    `gorm:"not null;default:0"       json:"position"`
+	Duration  float64    `gorm:"not null;default:0"       json:"duration"`
+	Completed bool       `gorm:"not null;default:false"   json:"completed"`
+}
@@ -0,0 +1,109 @@
+package op
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/email"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+)
+
+func ArchiveRoom(room *model.Room) error {
+	if err := db.ArchiveRoom(room.ID, time.Now()); err != nil {
+		return err
+	}
+	// the room will be reloaded as archived on next access
+	return CloseRoomByID(room.ID)
+}
+
+func RestoreArchivedRoom(roomID string) error {
+	if err := db.RestoreArchivedRoom(roomID); err != nil {
+		return err
+	}
+	return CloseRoomByID(roomID)
+}
+
+// ArchiveInactiveRooms archives the active rooms nobody has joined within their archive window
+func ArchiveInactiveRooms() error {
+	now := time.Now()
+	if days := settings.RoomArchiveAfterDays.Get(); days > 0 {
+		if err := archiveInactiveRooms(0, days, now); err != nil {
+			return err
+		}
+	}
+	overrides, err := db.GetRoomArchiveAfterDaysOverrides()
+	if err != nil {
+		return err
+	}
+	for _, days := range overrides {
+		if err := archiveInactiveRooms(days, days, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func archiveInactiveRooms(archiveAfterDays, days int64, now time.Time) error {
+	rooms, err := db.GetInactiveRooms(archiveAfterDays, now.Add(-time.Duration(days)*time.Hour*24))
+	if err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		// someone joined long ago and is still watching
+		if ViewerCount(room.ID) > 0 {
+			continue
+		}
+		if err := ArchiveRoom(room); err != nil {
+			logrus.Errorf("archive room %s failed: %v", room.ID, err)
+			continue
+		}
+		logrus.Infof("room %s archived due to inactivity", room.Name)
+		if err := notifyRoomArchived(room, now); err != nil {
+			logrus.Errorf("send room %s archived email failed: %v", room.ID, err)
+		}
+	}
+	return nil
+}
+
+func notifyRoomArchived(room *model.Room, archivedAt time.Time) error {
+	days := settings.RoomArchiveDeleteAfterDays.Get()
+	if days == 0 || !email.EnableEmail.Get() {
+		return nil
+	}
+	creatorE, err := LoadOrInitUserByID(room.CreatorID)
+	if err != nil {
+		return err
+	}
+	creator := creatorE.Value()
+	if creator.Email == "" {
+		return nil
+	}
+	return email.SendRoomArchivedEmail(
+		creator.Username,
+		string(creator.Email),
+		room.Name,
+		archivedAt.Add(time.Duration(days)*time.Hour*24),
+	)
+}
+
+// DeleteExpiredArchivedRooms deletes the rooms archived for longer than the delete window
+func DeleteExpiredArchivedRooms() error {
+	days := settings.RoomArchiveDeleteAfterDays.Get()
+	if days == 0 {
+		return nil
+	}
+	rooms, err := db.GetArchivedRoomsBefore(time.Now().Add(-time.Duration(days) * time.Hour * 24))
+	if err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		if err := DeleteRoomByID(room.ID); err != nil {
+			logrus.Errorf("delete archived room %s failed: %v", room.ID, err)
+			continue
+		}
+		logrus.Infof("archived room %s deleted", room.Name)
+	}
+	return nil
+}
@@ -49,6 +49,7 @@ func (c *Client) SendChatMessage(message string) error {
 	if !c.u.HasRoomPermission(c.r, model.PermissionSendChatMessage) {
 		return model.ErrNoPermission
 	}
+	c.r.stat.chatMessages.Add(1)
 	return c.Broadcast(&pb.Message{
 		Type:      pb.MessageType_CHAT,
 		Timestamp: time.Now().UnixMilli(),
@@ -11,8 +11,9 @@ type current struct {
 }
 
 type Current struct {
-	Movie  CurrentMovie
-	Status Status
+	Movie    CurrentMovie
+	Status   Status
+	Subtitle SubtitleStatus
 }
 
 type CurrentMovie struct {
@@ -35,6 +36,14 @@ type Status struct {
 	IsPlaying    bool      `json:"isPlaying"`
 }
 
+// SubtitleStatus is the subtitle shared by the room
+type SubtitleStatus struct {
+	// the name of the subtitle in the movie subtitles, empty means off
+	Track string `json:"track"`
+	// milliseconds, positive values show the subtitle later
+	Delay int64 `json:"delay"`
+}
+
 func newStatus() Status {
 	return Status{
 		CurrentTime:  0,
@@ -57,6 +66,19 @@ func (c *current) SetMovie(movie CurrentMovie, play bool) {
 	c.current.Movie = movie
 	c.current.SetSeek(0, 0)
 	c.current.Status.IsPlaying = play
+	c.current.Subtitle = SubtitleStatus{}
+}
+
+// SetSubtitle returns ErrNoCurrentMovie if no movie is playing
+func (c *current) SetSubtitle(subtitle SubtitleStatus) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.current.Movie.ID == "" {
+		return ErrNoCurrentMovie
+	}
+	c.current.Subtitle = subtitle
+	return nil
 }
 
 func (c *current) Status() Status {
@@ -0,0 +1,375 @@
+package op
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/gencontainer/rwmap"
+	"github.com/zijiren233/livelib/protocol/httpflv"
+	rtmps "github.com/zijiren233/livelib/server"
+)
+
+const (
+	liveRecordBufferSize = 64 * 1024
+	// orphan record files younger than this are kept, the row may be not created yet
+	liveRecordOrphanGracePeriod = time.Hour
+	maxLiveRecordErrorLength    = 512
+)
+
+var (
+	ErrLiveRecording     = errors.New("live is already being recorded")
+	ErrLiveNotRecording  = errors.New("live is not being recorded")
+	errLiveRecordMaxSize = errors.New("live record max size reached")
+)
+
+func LiveRecordFilePath(roomID, id string) string {
+	return filepath.Join(conf.Conf.Server.RecordPath, roomID, id+".flv")
+}
+
+// liveRecorder writes the flv stream of a live channel to a file
+type liveRecorder struct {
+	record    *model.LiveRecord
+	channel   *rtmps.Channel
+	writer    *httpflv.HttpFlvWriter
+	file      *os.File
+	buf       *bufio.Writer
+	size      atomic.Int64
+	maxSize   int64
+	startedAt time.Time
+
+	stopOnce sync.Once
+	reason   atomic.Pointer[model.LiveRecordStopReason]
+	done     chan struct{}
+}
+
+// liveRecorders are the running recordings by the live movie id
+var liveRecorders rwmap.RWMap[string, *liveRecorder]
+
+func (l *liveRecorder) Write(p []byte) (int, error) {
+	if l.maxSize > 0 && l.size.Load()+int64(len(p)) > l.maxSize {
+		return 0, errLiveRecordMaxSize
+	}
+	n, err := l.buf.Write(p)
+	l.size.Add(int64(n))
+	return n, err
+}
+
+// stop detaches the recorder from the channel, the recording is finished in the background
+func (l *liveRecorder) stop(reason model.LiveRecordStopReason) {
+	l.stopOnce.Do(func() {
+		l.reason.CompareAndSwap(nil, &reason)
+		// closing the writer ends SendPacket after the queued packets are written
+		_ = l.channel.DelPlayer(l.writer)
+		_ = l.writer.Close()
+	})
+}
+
+func (l *liveRecorder) run() {
+	defer close(l.done)
+	if d := settings.LiveRecordMaxDuration.Get(); d > 0 {
+		t := time.AfterFunc(time.Duration(d)*time.Minute, func() {
+			l.stop(model.LiveRecordStopMaxDuration)
+		})
+		defer t.Stop()
+	}
+
+	var recordErr error
+	if err := l.writer.SendPacket(); err != nil {
+		reason := model.LiveRecordStopError
+		if errors.Is(err, errLiveRecordMaxSize) {
+			reason = model.LiveRecordStopMaxSize
+		} else {
+			recordErr = err
+		}
+		l.stop(reason)
+	} else {
+		// the writer is closed with the channel if nobody stopped the recording
+		l.stop(model.LiveRecordStopClosed)
+	}
+	if err := l.buf.Flush(); err != nil && recordErr == nil {
+		recordErr = err
+	}
+	if err := l.file.Close(); err != nil && recordErr == nil {
+		recordErr = err
+	}
+	liveRecorders.CompareAndDelete(l.record.SourceMovieID, l)
+
+	// l.record is read by LiveRecording until the recorder is removed
+	record := *l.record
+	record.Size = l.size.Load()
+	record.Duration = time.Since(l.startedAt).Milliseconds()
+	finishLiveRecord(&record, *l.reason.Load(), recordErr)
+}
+
+// StartLiveRecord records the live movie until it is stopped, a limit is reached or the live movie is closed
+func (r *Room) StartLiveRecord(movieID, creatorID string) (*model.LiveRecord, error) {
+	if !settings.LiveRecord.Get() {
+		return nil, errors.New("live record is not enabled")
+	}
+	// the recordings are served like the proxied movies
+	if !settings.MovieProxy.Get() {
+		return nil, errors.New("movie proxy is not enabled")
+	}
+	m, err := r.GetMovieByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Live {
+		return nil, errors.New("only live movie can be recorded")
+	}
+	c, err := m.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	l := &liveRecorder{
+		record: &model.LiveRecord{
+			ID:            utils.SortUUID(),
+			RoomID:        r.ID,
+			SourceMovieID: movieID,
+			CreatorID:     creatorID,
+			Name:          fmt.Sprintf("%s (%s)", m.Name, now.Format("2006-01-02 15:04")),
+			Status:        model.LiveRecordStatusRecording,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		channel:   c,
+		maxSize:   settings.LiveRecordMaxSize.Get() * 1024 * 1024,
+		startedAt: now,
+		done:      make(chan struct{}),
+	}
+	if _, loaded := liveRecorders.LoadOrStore(movieID, l); loaded {
+		return nil, ErrLiveRecording
+	}
+	if err := l.open(); err != nil {
+		liveRecorders.CompareAndDelete(movieID, l)
+		return nil, err
+	}
+	go l.run()
+	return l.record, nil
+}
+
+func (l *liveRecorder) open() error {
+	p := LiveRecordFilePath(l.record.RoomID, l.record.ID)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if err := db.CreateLiveRecord(l.record); err != nil {
+		f.Close()
+		_ = os.Remove(p)
+		return err
+	}
+	l.file = f
+	l.buf = bufio.NewWriterSize(f, liveRecordBufferSize)
+	l.writer = httpflv.NewHttpFLVWriter(l)
+	if err := l.channel.AddPlayer(l.writer); err != nil {
+		f.Close()
+		_ = os.Remove(p)
+		record := *l.record
+		record.Status = model.LiveRecordStatusFailed
+		record.StopReason = model.LiveRecordStopError
+		record.Error = truncateLiveRecordError(err.Error())
+		if err := db.FinishLiveRecord(&record); err != nil {
+			log.Errorf("save live record %s error: %v", record.ID, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// StopLiveRecord stops the recording of the live movie and waits for the movie of the recording
+func (r *Room) StopLiveRecord(movieID string) (*model.LiveRecord, error) {
+	l, ok := liveRecorders.Load(movieID)
+	if !ok || l.record.RoomID != r.ID {
+		return nil, ErrLiveNotRecording
+	}
+	l.stop(model.LiveRecordStopManual)
+	<-l.done
+	return db.GetLiveRecord(r.ID, l.record.ID)
+}
+
+// LiveRecording returns the recording of the live movie, nil if it is not being recorded
+func (r *Room) LiveRecording(movieID string) *model.LiveRecord {
+	l, ok := liveRecorders.Load(movieID)
+	if !ok || l.record.RoomID != r.ID {
+		return nil
+	}
+	record := *l.record
+	record.Size = l.size.Load()
+	record.Duration = time.Since(l.startedAt).Milliseconds()
+	return &record
+}
+
+func (r *Room) GetLiveRecord(id string) (*model.LiveRecord, error) {
+	return db.GetLiveRecord(r.ID, id)
+}
+
+// StopLiveRecords stops all the recordings and waits for them to be finished
+func StopLiveRecords() {
+	var recorders []*liveRecorder
+	liveRecorders.Range(func(_ string, l *liveRecorder) bool {
+		recorders = append(recorders, l)
+		return true
+	})
+	for _, l := range recorders {
+		l.stop(model.LiveRecordStopInterrupted)
+	}
+	for _, l := range recorders {
+		<-l.done
+	}
+}
+
+// finishLiveRecord adds the movie of the recording to the room,
+// the recordings without any data are marked as failed and their files are removed
+func finishLiveRecord(record *model.LiveRecord, reason model.LiveRecordStopReason, recordErr error) {
+	now := time.Now()
+	record.StopReason = reason
+	record.StoppedAt = &now
+	record.Status = model.LiveRecordStatusFinished
+	if recordErr != nil {
+		record.Error = truncateLiveRecordError(recordErr.Error())
+	}
+
+	err := addLiveRecordMovie(record)
+	if err != nil {
+		log.Errorf("add movie of live record %s error: %v", record.ID, err)
+		record.Status = model.LiveRecordStatusFailed
+		if record.Error == "" {
+			record.Error = truncateLiveRecordError(err.Error())
+		}
+		if err := os.Remove(LiveRecordFilePath(record.RoomID, record.ID)); err != nil && !os.IsNotExist(err) {
+			log.Errorf("remove live record %s error: %v", record.ID, err)
+		}
+	}
+	if err := db.FinishLiveRecord(record); err != nil {
+		log.Errorf("save live record %s error: %v", record.ID, err)
+	}
+}
+
+func addLiveRecordMovie(record *model.LiveRecord) error {
+	if record.Size == 0 {
+		return errors.New("nothing was recorded")
+	}
+	roomE, err := LoadOrInitRoomByID(record.RoomID)
+	if err != nil {
+		return err
+	}
+	room := roomE.Value()
+	movie := &model.Movie{
+		ID:        utils.SortUUID(),
+		CreatorID: record.CreatorID,
+		MovieBase: model.MovieBase{
+			Name:  record.Name,
+			URL:   record.URL(),
+			Type:  "flv",
+			Proxy: true,
+		},
+	}
+	if err := room.AddMovie(movie); err != nil {
+		return err
+	}
+	record.MovieID = model.EmptyNullString(movie.ID)
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_MOVIES,
+		Sender: &pb.Sender{
+			Username: GetUserName(record.CreatorID),
+			UserId:   record.CreatorID,
+		},
+	})
+}
+
+func truncateLiveRecordError(s string) string {
+	if len(s) > maxLiveRecordErrorLength {
+		return s[:maxLiveRecordErrorLength]
+	}
+	return s
+}
+
+// RecoverLiveRecords finishes the recordings interrupted by a restart,
+// the data written before the restart is kept as a movie
+func RecoverLiveRecords() error {
+	records, err := db.GetLiveRecordsByStatus(model.LiveRecordStatusRecording)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if _, ok := liveRecorders.Load(record.SourceMovieID); ok {
+			continue
+		}
+		info, err := os.Stat(LiveRecordFilePath(record.RoomID, record.ID))
+		if err == nil {
+			record.Size = info.Size()
+			record.Duration = info.ModTime().Sub(record.CreatedAt).Milliseconds()
+		}
+		finishLiveRecord(record, model.LiveRecordStopInterrupted, nil)
+	}
+	return nil
+}
+
+// CleanupLiveRecordFiles removes the files whose records were deleted with their movies or rooms
+func CleanupLiveRecordFiles() error {
+	root := conf.Conf.Server.RecordPath
+	rooms, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, roomDir := range rooms {
+		if !roomDir.IsDir() {
+			continue
+		}
+		ids, err := db.GetLiveRecordIDsByRoomID(roomDir.Name())
+		if err != nil {
+			return err
+		}
+		exists := make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			exists[id] = struct{}{}
+		}
+		dir := filepath.Join(root, roomDir.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		remain := len(files)
+		for _, file := range files {
+			name := file.Name()
+			if _, ok := exists[strings.TrimSuffix(name, filepath.Ext(name))]; ok {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil || time.Since(info.ModTime()) < liveRecordOrphanGracePeriod {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			remain--
+		}
+		if remain == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/zijiren233/gencontainer/rwmap"
+)
+
+var ErrLocalPathOutsideRoot = errors.New("path is outside the root")
+
+var localRootCache rwmap.RWMap[string, *model.LocalRoot]
+
+func LoadLocalRoot(id string) (*model.LocalRoot, error) {
+	if root, ok := localRootCache.Load(id); ok {
+		return root, nil
+	}
+	root, err := db.GetLocalRootByID(id)
+	if err != nil {
+		return nil, err
+	}
+	root, _ = localRootCache.LoadOrStore(id, root)
+	return root, nil
+}
+
+func GetLocalRoots() ([]*model.LocalRoot, error) {
+	return db.GetLocalRoots()
+}
+
+func checkLocalRoot(root *model.LocalRoot) error {
+	if err := root.Validate(); err != nil {
+		return err
+	}
+	root.Path = filepath.Clean(root.Path)
+	fi, err := os.Stat(root.Path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", root.Path)
+	}
+	return nil
+}
+
+func CreateLocalRoot(root *model.LocalRoot) error {
+	if err := checkLocalRoot(root); err != nil {
+		return err
+	}
+	if err := db.CreateLocalRoot(root); err != nil {
+		return err
+	}
+	localRootCache.Store(root.ID, root)
+	return nil
+}
+
+func UpdateLocalRoot(root *model.LocalRoot) error {
+	if err := checkLocalRoot(root); err != nil {
+		return err
+	}
+	if err := db.SaveLocalRoot(root); err != nil {
+		return err
+	}
+	localRootCache.Store(root.ID, root)
+	return nil
+}
+
+func DeleteLocalRoot(id string) error {
+	if err := db.DeleteLocalRootByID(id); err != nil {
+		return err
+	}
+	localRootCache.Delete(id)
+	return nil
+}
+
+// ResolveLocalPath returns the real path of the file under the root, symlinks are resolved
+// so that neither `..` nor a link can escape from the root
+func ResolveLocalPath(root *model.LocalRoot, filePath string) (string, error) {
+	rootPath, err := filepath.EvalSymlinks(root.Path)
+	if err != nil {
+		return "", err
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(rootPath, filepath.FromSlash(path.Clean("/"+filePath))))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(rootPath, p)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrLocalPathOutsideRoot
+	}
+	return p, nil
+}
+
+func (u *User) LoadLocalRoot(id string) (*model.LocalRoot, error) {
+	root, err := LoadLocalRoot(id)
+	if err != nil {
+		return nil, err
+	}
+	if !root.CanAccess(&u.User) {
+		return nil, model.ErrNoPermission
+	}
+	return root, nil
+}
+
+// GetLocalRoots returns the roots the user can access
+func (u *User) GetLocalRoots() ([]*model.LocalRoot, error) {
+	roots, err := GetLocalRoots()
+	if err != nil {
+		return nil, err
+	}
+	accessible := roots[:0]
+	for _, root := range roots {
+		if root.CanAccess(&u.User) {
+			accessible = append(accessible, root)
+		}
+	}
+	return accessible, nil
+}
+
+// checkLocalMovie checks the user can access the local path of the movie
+func (u *User) checkLocalMovie(movie *model.MovieBase) error {
+	if movie.VendorInfo.Local == nil {
+		return errors.New("local payload is nil")
+	}
+	if err := movie.VendorInfo.Local.Validate(); err != nil {
+		return err
+	}
+	rootID, filePath, err := movie.VendorInfo.Local.RootIDAndFilePath()
+	if err != nil {
+		return err
+	}
+	root, err := u.LoadLocalRoot(rootID)
+	if err != nil {
+		return err
+	}
+	p, err := ResolveLocalPath(root, filePath)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() != movie.IsFolder {
+		if movie.IsFolder {
+			return errors.New("local path is not a directory")
+		}
+		return errors.New("local path is a directory")
+	}
+	return nil
+}
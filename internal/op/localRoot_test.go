@@ -0,0 +1,85 @@
+package op
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+func TestResolveLocalPath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPath := filepath.Join(dir, "root")
+	for _, p := range []string{
+		filepath.Join(rootPath, "movies", "movie.mkv"),
+		filepath.Join(dir, "secret.txt"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		filepath.Join(rootPath, "inside.mkv"):     filepath.Join(rootPath, "movies", "movie.mkv"),
+		filepath.Join(rootPath, "relative.mkv"):   filepath.Join("movies", "movie.mkv"),
+		filepath.Join(rootPath, "outside.txt"):    filepath.Join(dir, "secret.txt"),
+		filepath.Join(rootPath, "escape.txt"):     filepath.Join("..", "secret.txt"),
+		filepath.Join(rootPath, "parent"):         dir,
+		filepath.Join(dir, "link-to-root"):        rootPath,
+		filepath.Join(rootPath, "movies", "self"): filepath.Join(rootPath, "movies"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlink is not supported: %v", err)
+		}
+	}
+	movie := filepath.Join(rootPath, "movies", "movie.mkv")
+
+	tests := []struct {
+		name     string
+		rootPath string
+		filePath string
+		want     string
+		wantErr  error
+	}{
+		{"file", rootPath, "movies/movie.mkv", movie, nil},
+		{"dir", rootPath, "movies", filepath.Join(rootPath, "movies"), nil},
+		{"root", rootPath, "", rootPath, nil},
+		{"dot dot in the root", rootPath, "movies/../movies/movie.mkv", movie, nil},
+		{"dot dot escape", rootPath, "../secret.txt", "", os.ErrNotExist},
+		{"nested dot dot escape", rootPath, "movies/../../../secret.txt", "", os.ErrNotExist},
+		{"absolute path", rootPath, filepath.ToSlash(filepath.Join(dir, "secret.txt")), "", os.ErrNotExist},
+		{"link in the root", rootPath, "inside.mkv", movie, nil},
+		{"relative link in the root", rootPath, "relative.mkv", movie, nil},
+		{"link loop in the root", rootPath, "movies/self/self/movie.mkv", movie, nil},
+		{"link out of the root", rootPath, "outside.txt", "", ErrLocalPathOutsideRoot},
+		{"relative link out of the root", rootPath, "escape.txt", "", ErrLocalPathOutsideRoot},
+		{"dir link out of the root", rootPath, "parent/secret.txt", "", ErrLocalPathOutsideRoot},
+		{"linked root", filepath.Join(dir, "link-to-root"), "movies/movie.mkv", movie, nil},
+		{"missing root", filepath.Join(dir, "missing"), "movie.mkv", "", os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveLocalPath(&model.LocalRoot{Path: tt.rootPath}, tt.filePath)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("path = %q, err = %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("path = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
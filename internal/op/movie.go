@@ -13,6 +13,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/synctv-org/synctv/internal/cache"
 	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
 	"github.com/synctv-org/synctv/internal/model"
 	"github.com/synctv-org/synctv/internal/settings"
 	"github.com/synctv-org/synctv/utils"
@@ -31,7 +32,10 @@ type Movie struct {
 	alistCache    atomic.Pointer[cache.AlistMovieCache]
 	bilibiliCache atomic.Pointer[cache.BilibiliMovieCache]
 	embyCache     atomic.Pointer[cache.EmbyMovieCache]
-	subPath       string
+	jellyfinCache atomic.Pointer[cache.JellyfinMovieCache]
+	// the vendor credentials owned by the room, nil for movies not loaded by a room
+	vendors *roomVendors
+	subPath string
 }
 
 func (m *Movie) SubPath() string {
@@ -74,11 +78,23 @@ func (m *Movie) ClearCache() error {
 
 	emc := m.embyCache.Swap(nil)
 	if emc != nil {
+		uc, err := m.EmbyUserCache(context.Background())
+		if err != nil {
+			return err
+		}
+		err = emc.Clear(context.Background(), uc)
+		if err != nil {
+			return err
+		}
+	}
+
+	jmc := m.jellyfinCache.Swap(nil)
+	if jmc != nil {
 		u, err := LoadOrInitUserByID(m.CreatorID)
 		if err != nil {
 			return err
 		}
-		err = emc.Clear(context.Background(), u.Value().EmbyCache())
+		err = jmc.Clear(context.Background(), u.Value().JellyfinCache())
 		if err != nil {
 			return err
 		}
@@ -120,6 +136,17 @@ func (m *Movie) EmbyCache() *cache.EmbyMovieCache {
 	return c
 }
 
+func (m *Movie) JellyfinCache() *cache.JellyfinMovieCache {
+	c := m.jellyfinCache.Load()
+	if c == nil {
+		c = cache.NewJellyfinMovieCache(m.Movie, m.subPath)
+		if !m.jellyfinCache.CompareAndSwap(nil, c) {
+			return m.JellyfinCache()
+		}
+	}
+	return c
+}
+
 func (m *Movie) Channel() (*rtmps.Channel, error) {
 	if m.IsFolder {
 		return nil, errors.New("this is a folder")
@@ -307,6 +334,8 @@ func (m *Movie) validateURLAndProxy() error {
 	}
 
 	switch {
+	case u.Scheme == model.LiveRecordScheme:
+		return m.validateLiveRecord(u)
 	case m.Live && m.RtmpSource:
 		return nil
 	case m.Live && m.Proxy:
@@ -348,6 +377,28 @@ func (m *Movie) validateMovieProxy(u *url.URL) error {
 	return nil
 }
 
+// validateLiveRecord only allows a recording to be played by the movie added for it
+func (m *Movie) validateLiveRecord(u *url.URL) error {
+	if m.Live || !m.Proxy {
+		return errors.New("live record must be played through the proxy")
+	}
+	if !settings.MovieProxy.Get() {
+		return errors.New("movie proxy is not enabled")
+	}
+	id, err := model.GetLiveRecordIDFromURL(u)
+	if err != nil {
+		return err
+	}
+	record, err := db.GetLiveRecord(m.RoomID, id)
+	if err != nil {
+		return err
+	}
+	if record.MovieID != "" && record.MovieID.String() != m.ID {
+		return errors.New("live record belongs to another movie")
+	}
+	return nil
+}
+
 func (m *Movie) validateDirectURL(u *url.URL) error {
 	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "magnet" {
 		return fmt.Errorf("unsupported scheme: %s", u.Scheme)
@@ -369,6 +420,34 @@ func (m *Movie) validateVendorMovie() error {
 	case model.VendorEmby:
 		return m.Movie.MovieBase.VendorInfo.Emby.Validate()
 
+	case model.VendorJellyfin:
+		return m.Movie.MovieBase.VendorInfo.Jellyfin.Validate()
+
+	case model.VendorWebDAV:
+		if m.Live {
+			return errors.New("webdav live not support")
+		}
+		return m.Movie.MovieBase.VendorInfo.WebDAV.Validate()
+
+	case model.VendorS3:
+		if m.Live {
+			return errors.New("s3 live not support")
+		}
+		return m.Movie.MovieBase.VendorInfo.S3.Validate()
+
+	case model.VendorPlugin:
+		return m.Movie.MovieBase.VendorInfo.Plugin.Validate()
+
+	case model.VendorLocal:
+		// local files are always served by the server
+		if !settings.MovieProxy.Get() {
+			return errors.New("movie proxy is not enabled")
+		}
+		if m.Live {
+			return errors.New("local live not support")
+		}
+		return m.Movie.MovieBase.VendorInfo.Local.Validate()
+
 	default:
 		return errors.New("vendor not implement validate")
 	}
@@ -0,0 +1,314 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/go-uhc"
+)
+
+const (
+	movieHealthCheckTimeout     = 30 * time.Second
+	maxMovieHealthErrorLength   = 512
+	maxHealthCheckMoviesPerCall = 1000
+)
+
+var errMovieHealthNotCheckable = errors.New("movie health can't be checked")
+
+// movieHealthLimiter limits the concurrency and the request rate of all the health checks
+var movieHealthLimiter = newHealthLimiter()
+
+// the checks started by the rooms are canceled at shutdown
+var (
+	roomHealthCheckCtx, cancelRoomHealthChecks = context.WithCancel(context.Background())
+	roomHealthChecks                           sync.WaitGroup
+)
+
+type healthLimiter struct {
+	lock    sync.Mutex
+	running int64
+	next    time.Time
+	// released is closed and replaced once a check is released,
+	// the concurrency setting may change so a fixed size semaphore can't be used
+	released chan struct{}
+}
+
+func newHealthLimiter() *healthLimiter {
+	return &healthLimiter{
+		released: make(chan struct{}),
+	}
+}
+
+func (l *healthLimiter) acquire(ctx context.Context) error {
+	l.lock.Lock()
+	for l.running >= settings.MovieHealthCheckConcurrency.Get() {
+		released := l.released
+		l.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+		l.lock.Lock()
+	}
+	l.running++
+	var wait time.Duration
+	if rate := settings.MovieHealthCheckRate.Get(); rate > 0 {
+		now := time.Now()
+		if l.next.Before(now) {
+			l.next = now
+		}
+		wait = l.next.Sub(now)
+		l.next = l.next.Add(time.Second / time.Duration(rate))
+	}
+	l.lock.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (l *healthLimiter) release() {
+	l.lock.Lock()
+	l.running--
+	close(l.released)
+	l.released = make(chan struct{})
+	l.lock.Unlock()
+}
+
+func isMovieHealthCheckable(m *model.Movie) bool {
+	return m.VendorInfo.Vendor == "" && !m.IsFolder && !m.RtmpSource && m.URL != ""
+}
+
+// checkMovieURL requests the url with HEAD, and falls back to a one byte range GET
+// since some servers reject HEAD
+func checkMovieURL(ctx context.Context, m *model.Movie) error {
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errMovieHealthNotCheckable
+	}
+	if !settings.AllowProxyToLocal.Get() && utils.IsLocalIP(u.Host) {
+		if m.Proxy {
+			return errors.New("not allow proxy to local")
+		}
+		// the client requests it directly, which can't be checked from the server
+		return errMovieHealthNotCheckable
+	}
+	err = doMovieHealthRequest(ctx, m, http.MethodHead)
+	if err == nil {
+		return nil
+	}
+	return doMovieHealthRequest(ctx, m, http.MethodGet)
+}
+
+func doMovieHealthRequest(ctx context.Context, m *model.Movie, method string) error {
+	req, err := http.NewRequestWithContext(ctx, method, m.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range m.Headers {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", utils.UA)
+	}
+	if method == http.MethodGet && !m.Live {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return err
+	}
+	// the body is not needed, and live streams never end
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &movieHealthStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+type movieHealthStatusError struct {
+	statusCode int
+}
+
+func (e *movieHealthStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.statusCode)
+}
+
+func checkMovieHealth(ctx context.Context, m *model.Movie) *model.MovieHealth {
+	health := &model.MovieHealth{
+		Status:    model.MovieHealthOK,
+		CheckedAt: time.Now().UnixMilli(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, movieHealthCheckTimeout)
+	defer cancel()
+	err := checkMovieURL(ctx, m)
+	switch {
+	case err == nil:
+	case errors.Is(err, errMovieHealthNotCheckable):
+		health.Status = model.MovieHealthUnknown
+	default:
+		health.Status = model.MovieHealthUnhealthy
+		health.Error = movieHealthErrorMessage(err)
+		if len(health.Error) > maxMovieHealthErrorLength {
+			health.Error = health.Error[:maxMovieHealthErrorLength]
+		}
+	}
+	return health
+}
+
+// movieHealthErrorMessage returns the class of the error, the error itself is not saved
+// since the request errors contain the url hidden from the members of the proxied movies
+func movieHealthErrorMessage(err error) string {
+	var (
+		statusErr *movieHealthStatusError
+		dnsErr    *net.DNSError
+		opErr     *net.OpError
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Error()
+	case errors.Is(err, context.DeadlineExceeded), os.IsTimeout(err):
+		return "request timeout"
+	case errors.As(err, &dnsErr):
+		return "dns lookup failed"
+	case errors.As(err, &opErr):
+		return fmt.Sprintf("%s failed", opErr.Op)
+	default:
+		return "request failed"
+	}
+}
+
+func updateMovieHealth(m *model.Movie, health *model.MovieHealth) error {
+	if r, err := LoadRoomByID(m.RoomID); err == nil {
+		return r.Value().movies.UpdateHealth(m.ID, health)
+	}
+	return db.UpdateMovieHealth(m.RoomID, m.ID, health)
+}
+
+// CheckMoviesHealth checks the movies and saves their health,
+// the rooms are notified to reload the movie list once any status changed
+func CheckMoviesHealth(ctx context.Context, movies []*model.Movie) {
+	var (
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		changed = make(map[string]struct{})
+	)
+	for _, m := range movies {
+		if !isMovieHealthCheckable(m) {
+			continue
+		}
+		if err := movieHealthLimiter.acquire(ctx); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func(m *model.Movie) {
+			defer func() {
+				movieHealthLimiter.release()
+				wg.Done()
+			}()
+			health := checkMovieHealth(ctx, m)
+			if err := updateMovieHealth(m, health); err != nil {
+				log.WithFields(log.Fields{
+					"rid": m.RoomID,
+					"mid": m.ID,
+				}).Errorf("update movie health error: %v", err)
+				return
+			}
+			if health.Status != m.Health.Status {
+				lock.Lock()
+				changed[m.RoomID] = struct{}{}
+				lock.Unlock()
+			}
+		}(m)
+	}
+	wg.Wait()
+	for roomID := range changed {
+		r, err := LoadRoomByID(roomID)
+		if err != nil {
+			continue
+		}
+		if err := r.Value().Broadcast(&pb.Message{
+			Type: pb.MessageType_MOVIES,
+		}); err != nil {
+			log.WithField("rid", roomID).Errorf("broadcast movies error: %v", err)
+		}
+	}
+}
+
+// CheckMoviesHealth checks the movies of the room in background, all the plain url
+// movies are checked when ids is empty
+func (r *Room) CheckMoviesHealth(ids []string) error {
+	var movies []*model.Movie
+	if len(ids) == 0 {
+		ms, err := db.GetMoviesByRoomID(r.ID, db.WhereMovieHealthCheckable(time.Now()))
+		if err != nil {
+			return err
+		}
+		movies = ms
+	} else {
+		movies = make([]*model.Movie, 0, len(ids))
+		for _, id := range ids {
+			m, err := r.GetMovieByID(id)
+			if err != nil {
+				return err
+			}
+			if !isMovieHealthCheckable(m.Movie) {
+				return fmt.Errorf("movie %s is not a url movie", id)
+			}
+			movies = append(movies, m.Movie.Clone())
+		}
+	}
+	if len(movies) > maxHealthCheckMoviesPerCall {
+		movies = movies[:maxHealthCheckMoviesPerCall]
+	}
+	if !r.healthChecking.CompareAndSwap(false, true) {
+		return errors.New("movies health check is running")
+	}
+	roomHealthChecks.Add(1)
+	go func() {
+		defer func() {
+			r.healthChecking.Store(false)
+			roomHealthChecks.Done()
+		}()
+		CheckMoviesHealth(roomHealthCheckCtx, movies)
+	}()
+	return nil
+}
+
+// StopRoomMoviesHealthChecks cancels the checks started by the rooms and waits for them
+func StopRoomMoviesHealthChecks() {
+	cancelRoomHealthChecks()
+	roomHealthChecks.Wait()
+}
+
+func (u *User) CheckRoomMoviesHealth(room *Room, ids []string) error {
+	if !u.HasRoomPermission(room, model.PermissionEditMovie) {
+		return model.ErrNoPermission
+	}
+	return room.CheckMoviesHealth(ids)
+}
@@ -0,0 +1,207 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/subtitle"
+)
+
+// orphan subtitle files younger than this are kept, the row may be not created yet
+const subtitleOrphanGracePeriod = time.Hour
+
+func movieSubtitleFilePath(roomID, id, format string) string {
+	return filepath.Join(conf.Conf.Server.SubtitlePath, roomID, id+"."+format)
+}
+
+// canEditMovieSubtitles checks the archived room before the movie creator,
+// who is not asked for the room permissions
+func (u *User) canEditMovieSubtitles(room *Room, m *Movie) bool {
+	if room.IsArchived() {
+		return false
+	}
+	return m.CreatorID == u.ID || u.HasRoomPermission(room, model.PermissionEditMovie)
+}
+
+func (u *User) GetRoomMovieSubtitles(room *Room, movieID string) ([]*model.MovieSubtitle, error) {
+	if !u.HasRoomPermission(room, model.PermissionGetMovieList) {
+		return nil, model.ErrNoPermission
+	}
+	return db.GetMovieSubtitles(room.ID, movieID)
+}
+
+// AddRoomMovieSubtitle stores the uploaded subtitle, the format is taken from the
+// file extension of the name and sniffed from the content when unknown
+func (u *User) AddRoomMovieSubtitle(room *Room, movieID, name string, data []byte) (*model.MovieSubtitle, error) {
+	m, err := room.GetMovieByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.canEditMovieSubtitles(room, m) {
+		return nil, model.ErrNoPermission
+	}
+	if m.IsFolder {
+		return nil, errors.New("cannot add subtitle to folder")
+	}
+	if m.Live {
+		return nil, errors.New("cannot add subtitle to live")
+	}
+	if maxSize := settings.SubtitleMaxSize.Get() * 1024; int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("subtitle size exceeds the limit of %d KB", maxSize/1024)
+	}
+	format := subtitle.NormalizeFormat(filepath.Ext(name))
+	if format == "" {
+		format = subtitle.Detect(data)
+		if format == "" {
+			return nil, subtitle.ErrUnknownFormat
+		}
+	}
+	if _, err := subtitle.Parse(data, format); err != nil {
+		return nil, fmt.Errorf("invalid %s subtitle: %w", format, err)
+	}
+	count, err := db.GetMovieSubtitlesCount(room.ID, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= settings.MovieMaxSubtitles.Get() {
+		return nil, errors.New("movie subtitles limit reached")
+	}
+	s := &model.MovieSubtitle{
+		ID:        utils.SortUUID(),
+		RoomID:    room.ID,
+		MovieID:   movieID,
+		CreatorID: u.ID,
+		Name:      strings.TrimSuffix(name, filepath.Ext(name)),
+		Type:      format,
+		Size:      int64(len(data)),
+	}
+	p := movieSubtitleFilePath(room.ID, s.ID, format)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		return nil, err
+	}
+	if err := db.CreateMovieSubtitle(s); err != nil {
+		_ = os.Remove(p)
+		return nil, err
+	}
+	return s, u.broadcastMovieSubtitlesChanged(room, movieID)
+}
+
+func (u *User) DeleteRoomMovieSubtitle(room *Room, id string) error {
+	// the uploader is not asked for the room permissions
+	if room.IsArchived() {
+		return model.ErrNoPermission
+	}
+	s, err := db.GetMovieSubtitle(room.ID, id)
+	if err != nil {
+		return err
+	}
+	if s.CreatorID != u.ID {
+		m, err := room.GetMovieByID(s.MovieID)
+		if err != nil {
+			return err
+		}
+		if !u.canEditMovieSubtitles(room, m) {
+			return model.ErrNoPermission
+		}
+	}
+	if err := db.DeleteMovieSubtitle(room.ID, id); err != nil {
+		return err
+	}
+	if err := os.Remove(movieSubtitleFilePath(room.ID, s.ID, s.Type)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return u.broadcastMovieSubtitlesChanged(room, s.MovieID)
+}
+
+// broadcastMovieSubtitlesChanged lets the clients reload the subtitles of the current movie
+func (u *User) broadcastMovieSubtitlesChanged(room *Room, movieID string) error {
+	if room.CurrentMovie().ID != movieID {
+		return nil
+	}
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_CURRENT,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+	})
+}
+
+func (r *Room) GetMovieSubtitles(movieID string) ([]*model.MovieSubtitle, error) {
+	return db.GetMovieSubtitles(r.ID, movieID)
+}
+
+// ReadMovieSubtitle returns the uploaded subtitle and its content
+func (r *Room) ReadMovieSubtitle(id string) (*model.MovieSubtitle, []byte, error) {
+	s, err := db.GetMovieSubtitle(r.ID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(movieSubtitleFilePath(r.ID, s.ID, s.Type))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, data, nil
+}
+
+// CleanupSubtitleFiles removes the files whose subtitles were deleted with their movies or rooms
+func CleanupSubtitleFiles() error {
+	root := conf.Conf.Server.SubtitlePath
+	rooms, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, roomDir := range rooms {
+		if !roomDir.IsDir() {
+			continue
+		}
+		ids, err := db.GetMovieSubtitleIDsByRoomID(roomDir.Name())
+		if err != nil {
+			return err
+		}
+		exists := make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			exists[id] = struct{}{}
+		}
+		dir := filepath.Join(root, roomDir.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		remain := len(files)
+		for _, file := range files {
+			name := file.Name()
+			if _, ok := exists[strings.TrimSuffix(name, filepath.Ext(name))]; ok {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil || time.Since(info.ModTime()) < subtitleOrphanGracePeriod {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			remain--
+		}
+		if remain == 0 {
+			_ = os.Remove(dir)
+		}
+	}
+	return nil
+}
@@ -5,22 +5,26 @@ import (
 	"fmt"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/synctv-org/synctv/internal/db"
 	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/playlist"
 	"github.com/zijiren233/gencontainer/rwmap"
 	rtmps "github.com/zijiren233/livelib/server"
 	"gorm.io/gorm"
 )
 
 type movies struct {
-	roomID string
-	cache  rwmap.RWMap[string, *Movie]
+	roomID  string
+	vendors *roomVendors
+	cache   rwmap.RWMap[string, *Movie]
 }
 
 func (m *movies) AddMovie(mo *model.Movie) error {
 	mo.Position = uint(time.Now().UnixMilli())
 	movie := &Movie{
-		Movie: mo,
+		Movie:   mo,
+		vendors: m.vendors,
 	}
 
 	err := movie.Validate()
@@ -42,10 +46,13 @@ func (m *movies) AddMovie(mo *model.Movie) error {
 
 func (m *movies) AddMovies(mos []*model.Movie) error {
 	inited := make([]*Movie, 0, len(mos))
-	for _, mo := range mos {
-		mo.Position = uint(time.Now().UnixMilli())
+	position := uint(time.Now().UnixMilli())
+	for i, mo := range mos {
+		// keep the order of the movies
+		mo.Position = position + uint(i)
 		movie := &Movie{
-			Movie: mo,
+			Movie:   mo,
+			vendors: m.vendors,
 		}
 
 		err := movie.Validate()
@@ -71,6 +78,67 @@ func (m *movies) AddMovies(mos []*model.Movie) error {
 	return nil
 }
 
+// ImportMovies validates all the movies, then creates the levels in one transaction
+func (m *movies) ImportMovies(levels [][]*model.Movie) error {
+	var inited []*Movie
+	position := uint(time.Now().UnixMilli())
+	for _, level := range levels {
+		for _, mo := range level {
+			// keep the order of the movies
+			mo.Position = position
+			position++
+			movie := &Movie{
+				Movie:   mo,
+				vendors: m.vendors,
+			}
+			if err := movie.Validate(); err != nil {
+				return fmt.Errorf("validate movie %s error: %w", mo.Name, err)
+			}
+			inited = append(inited, movie)
+		}
+	}
+
+	if err := db.CreateMovieLevels(levels); err != nil {
+		return err
+	}
+
+	for _, mo := range inited {
+		old, ok := m.cache.Swap(mo.Movie.ID, mo)
+		if ok {
+			_ = old.Close()
+		}
+	}
+	return nil
+}
+
+// GetMovieTree returns the static movies under parentID recursively,
+// the children of dynamic folders are not included, neither are the proxied movies
+// of other creators since their url and headers are hidden from userID
+func (m *movies) GetMovieTree(parentID, userID string) ([]*playlist.Item, error) {
+	ms, err := db.GetMoviesByRoomID(m.roomID, db.WithParentMovieID(parentID))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*playlist.Item, 0, len(ms))
+	for _, mv := range ms {
+		if mv.Proxy && mv.CreatorID != userID {
+			continue
+		}
+		item := &playlist.Item{
+			Movie: mv.MovieBase,
+		}
+		item.Movie.ParentID = ""
+		if mv.IsFolder && !mv.IsDynamicFolder() {
+			item.Children, err = m.GetMovieTree(mv.ID, userID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 func (m *movies) GetChannel(id string) (*rtmps.Channel, error) {
 	if id == "" {
 		return nil, errors.New("channel name is nil")
@@ -88,6 +156,9 @@ func (m *movies) Update(movieID string, movie *model.MovieBase) error {
 		return err
 	}
 	mv.MovieBase = *movie
+	// the source may have changed, probe it again
+	mv.Meta = model.MovieMeta{}
+	mv.Health = model.MovieHealth{}
 	err = db.SaveMovie(mv)
 	if err != nil {
 		return err
@@ -99,8 +170,35 @@ func (m *movies) Update(movieID string, movie *model.MovieBase) error {
 	return nil
 }
 
-func (m *movies) Clear() error {
-	return m.DeleteMovieByParentID("")
+func (m *movies) UpdateMeta(movieID string, meta *model.MovieMeta) error {
+	err := db.UpdateMovieMeta(m.roomID, movieID, meta)
+	if err != nil {
+		return err
+	}
+	mm, loaded := m.cache.LoadAndDelete(movieID)
+	if loaded {
+		_ = mm.Close()
+	}
+	return nil
+}
+
+// UpdateHealth saves the health and drops the cached movie instead of writing it while
+// the handlers read it, a live movie being played is kept with the health loaded before
+func (m *movies) UpdateHealth(movieID string, health *model.MovieHealth) error {
+	err := db.UpdateMovieHealth(m.roomID, movieID, health)
+	if err != nil {
+		return err
+	}
+	if mm, ok := m.cache.Load(movieID); ok && mm.channel.Load() == nil {
+		if m.cache.CompareAndDelete(movieID, mm) {
+			_ = mm.Close()
+		}
+	}
+	return nil
+}
+
+func (m *movies) Clear(deletedBy string) error {
+	return m.DeleteMovieByParentID("", deletedBy)
 }
 
 func (m *movies) ClearCache() {
@@ -111,13 +209,26 @@ func (m *movies) ClearCache() {
 	})
 }
 
+// ClearVendorCache drops the caches of the movies played with the vendor's credentials
+func (m *movies) ClearVendorCache(vendor model.VendorName) {
+	m.cache.Range(func(key string, value *Movie) bool {
+		if value.VendorInfo.Vendor == vendor {
+			if err := value.ClearCache(); err != nil {
+				log.Errorf("clear movie %s cache failed: %v", key, err)
+			}
+		}
+		return true
+	})
+}
+
 func (m *movies) Close() error {
 	m.ClearCache()
 	return nil
 }
 
-func (m *movies) DeleteMovieByParentID(parentID string) error {
-	err := db.DeleteMoviesByRoomIDAndParentID(m.roomID, parentID)
+// DeleteMovieByParentID moves the movies under parentID to the trash
+func (m *movies) DeleteMovieByParentID(parentID string, deletedBy string) error {
+	err := db.TrashMoviesByParentID(m.roomID, parentID, deletedBy)
 	if err != nil {
 		return err
 	}
@@ -125,13 +236,8 @@ func (m *movies) DeleteMovieByParentID(parentID string) error {
 	return nil
 }
 
-func (m *movies) DeleteMovieByID(id string) error {
-	err := db.DeleteMovieByID(m.roomID, id)
-	if err != nil {
-		return err
-	}
-	m.DeleteMovieAndChiledCache(id)
-	return nil
+func (m *movies) DeleteMovieByID(id string, deletedBy string) error {
+	return m.DeleteMoviesByID([]string{id}, deletedBy)
 }
 
 func (m *movies) DeleteMovieAndChiledCache(id ...string) {
@@ -163,15 +269,45 @@ func (m *movies) deleteMovieAndChiledCache(ids map[model.EmptyNullString]struct{
 	}
 }
 
-func (m *movies) DeleteMoviesByID(ids []string) error {
-	err := db.DeleteMoviesByID(m.roomID, ids)
+// DeleteMoviesByID moves the movies to the trash, they can be restored until purged
+func (m *movies) DeleteMoviesByID(ids []string, deletedBy string) error {
+	err := db.TrashMoviesByID(m.roomID, ids, deletedBy)
 	if err != nil {
 		return err
 	}
+	for _, id := range ids {
+		if mm, ok := m.cache.LoadAndDelete(id); ok {
+			_ = mm.Close()
+		}
+	}
 	m.DeleteMovieAndChiledCache(ids...)
 	return nil
 }
 
+func (m *movies) GetTrashedMoviesWithPage(page, pageSize int) ([]*model.Movie, int64, error) {
+	count, err := db.GetTrashedMoviesCount(m.roomID)
+	if err != nil {
+		return nil, 0, err
+	}
+	movies, err := db.GetTrashedMovies(m.roomID, db.Paginate(page, pageSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	return movies, count, nil
+}
+
+func (m *movies) GetTrashedMovieByID(id string) (*model.Movie, error) {
+	return db.GetTrashedMovieByID(m.roomID, id)
+}
+
+func (m *movies) RestoreMovies(ids []string) error {
+	return db.RestoreMovies(m.roomID, ids)
+}
+
+func (m *movies) DeleteTrashedMovies(ids []string) error {
+	return db.DeleteTrashedMoviesByID(m.roomID, ids)
+}
+
 func (m *movies) GetMovieByID(id string) (*Movie, error) {
 	if id == "" {
 		return nil, errors.New("movie id is nil")
@@ -184,10 +320,33 @@ func (m *movies) GetMovieByID(id string) (*Movie, error) {
 	if err != nil {
 		return nil, err
 	}
-	mm, _ = m.cache.LoadOrStore(mv.ID, &Movie{Movie: mv})
+	mm, _ = m.cache.LoadOrStore(mv.ID, &Movie{Movie: mv, vendors: m.vendors})
 	return mm, nil
 }
 
+// MoveMovies moves the movies to the end of the parent folder, keeping their order
+func (m *movies) MoveMovies(ids []string, parentID string) error {
+	position := uint(time.Now().UnixMilli())
+	positions := make([]uint, len(ids))
+	for i := range ids {
+		positions[i] = position + uint(i)
+	}
+	err := db.MoveMovies(m.roomID, ids, parentID, positions)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if mm, ok := m.cache.LoadAndDelete(id); ok {
+			_ = mm.Close()
+		}
+	}
+	return nil
+}
+
+func (m *movies) ReorderMovies(parentID string, ids []string) error {
+	return db.ReorderMovies(m.roomID, parentID, ids)
+}
+
 func (m *movies) SwapMoviePositions(id1, id2 string) error {
 	return db.SwapMoviePositions(m.roomID, id1, id2)
 }
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 	"github.com/synctv-org/synctv/internal/db"
 	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/playlist"
 	"github.com/synctv-org/synctv/internal/settings"
+	pb "github.com/synctv-org/synctv/proto/message"
 	"github.com/zijiren233/gencontainer/rwmap"
 	rtmps "github.com/zijiren233/livelib/server"
 	"github.com/zijiren233/stream"
@@ -20,7 +23,12 @@ type Room struct {
 	current *current
 	hub     atomic.Pointer[Hub]
 	movies  *movies
+	vendors *roomVendors
 	members rwmap.RWMap[string, *model.RoomMember]
+	stat    roomStat
+	// unix time of the last time last_active_at was written
+	lastActiveUpdatedAt atomic.Int64
+	healthChecking      atomic.Bool
 	model.Room
 }
 
@@ -77,6 +85,8 @@ func (r *Room) GetChannel(channelName string) (*rtmps.Channel, error) {
 }
 
 func (r *Room) close() {
+	// the counters of the rooms removed from the cache are not sampled anymore
+	r.saveStat(time.Now(), 0)
 	if h := r.hub.Load(); h != nil {
 		if r.hub.CompareAndSwap(h, nil) {
 			h.Close()
@@ -94,6 +104,10 @@ func (r *Room) UpdateMovie(movieID string, movie *model.MovieBase) error {
 	return r.movies.Update(movieID, movie)
 }
 
+func (r *Room) UpdateMovieMeta(movieID string, meta *model.MovieMeta) error {
+	return r.movies.UpdateMeta(movieID, meta)
+}
+
 func (r *Room) AddMovie(m *model.Movie) error {
 	m.RoomID = r.ID
 	return r.movies.AddMovie(m)
@@ -106,6 +120,21 @@ func (r *Room) AddMovies(movies []*model.Movie) error {
 	return r.movies.AddMovies(movies)
 }
 
+// ImportMovies adds the movies level by level, so that folders are created before their children,
+// all movies are validated before any of them is added and nothing is added if one level fails
+func (r *Room) ImportMovies(levels [][]*model.Movie) error {
+	for _, level := range levels {
+		for _, m := range level {
+			m.RoomID = r.ID
+		}
+	}
+	return r.movies.ImportMovies(levels)
+}
+
+func (r *Room) GetMovieTree(parentID, userID string) ([]*playlist.Item, error) {
+	return r.movies.GetMovieTree(parentID, userID)
+}
+
 func (r *Room) UserRole(userID string) (model.RoomMemberRole, error) {
 	if r.IsCreator(userID) {
 		return model.RoomMemberRoleCreator, nil
@@ -135,6 +164,10 @@ func (r *Room) IsGuest(userID string) bool {
 }
 
 func (r *Room) HasPermission(userID string, permission model.RoomMemberPermission) bool {
+	if r.IsArchived() {
+		return permission == model.PermissionGetMovieList
+	}
+
 	if r.IsCreator(userID) {
 		return true
 	}
@@ -163,6 +196,10 @@ func (r *Room) HasPermission(userID string, permission model.RoomMemberPermissio
 }
 
 func (r *Room) HasAdminPermission(userID string, permission model.RoomAdminPermission) bool {
+	if r.IsArchived() {
+		return false
+	}
+
 	if r.IsCreator(userID) {
 		return true
 	}
@@ -376,32 +413,89 @@ func (r *Room) checkCanModifyMovies(ids []string) error {
 	return nil
 }
 
-func (r *Room) DeleteMovieByID(id string) error {
+func (r *Room) DeleteMovieByID(id string, deletedBy string) error {
 	err := r.checkCanModifyMovie(id)
 	if err != nil {
 		return err
 	}
-	return r.movies.DeleteMovieByID(id)
+	return r.movies.DeleteMovieByID(id, deletedBy)
 }
 
-func (r *Room) DeleteMoviesByID(ids []string) error {
+func (r *Room) DeleteMoviesByID(ids []string, deletedBy string) error {
 	err := r.checkCanModifyMovies(ids)
 	if err != nil {
 		return err
 	}
-	return r.movies.DeleteMoviesByID(ids)
+	return r.movies.DeleteMoviesByID(ids, deletedBy)
 }
 
-func (r *Room) ClearMovies() error {
-	return r.ClearMoviesByParentID("")
+func (r *Room) ClearMovies(deletedBy string) error {
+	return r.ClearMoviesByParentID("", deletedBy)
 }
 
-func (r *Room) ClearMoviesByParentID(parentID string) error {
+func (r *Room) ClearMoviesByParentID(parentID string, deletedBy string) error {
 	err := r.checkCanModifyMovie(parentID)
 	if err != nil {
 		return err
 	}
-	return r.movies.DeleteMovieByParentID(parentID)
+	return r.movies.DeleteMovieByParentID(parentID, deletedBy)
+}
+
+func (r *Room) MoveMovies(ids []string, parentID string) error {
+	err := r.checkCanModifyMovies(ids)
+	if err != nil {
+		return err
+	}
+	if parentID != "" {
+		parent, err := r.GetMovieByID(parentID)
+		if err != nil {
+			return fmt.Errorf("get parent movie failed: %w", err)
+		}
+		if !parent.IsFolder || parent.IsDynamicFolder() {
+			return errors.New("parent is not a static folder")
+		}
+	}
+	for _, id := range ids {
+		if id == parentID {
+			return errors.New("cannot move a folder into itself")
+		}
+		m, err := r.GetMovieByID(id)
+		if err != nil {
+			return err
+		}
+		if !m.IsFolder || parentID == "" {
+			continue
+		}
+		// the target is inside the folder
+		ok, err := r.movies.IsParentFolder(parentID, id)
+		if err != nil {
+			return fmt.Errorf("check parent failed: %w", err)
+		}
+		if ok {
+			return errors.New("cannot move a folder into its subfolder")
+		}
+	}
+	return r.movies.MoveMovies(ids, parentID)
+}
+
+func (r *Room) ReorderMovies(parentID string, ids []string) error {
+	return r.movies.ReorderMovies(parentID, ids)
+}
+
+func (r *Room) GetTrashedMoviesWithPage(page, pageSize int) ([]*model.Movie, int64, error) {
+	return r.movies.GetTrashedMoviesWithPage(page, pageSize)
+}
+
+func (r *Room) GetTrashedMovieByID(id string) (*model.Movie, error) {
+	return r.movies.GetTrashedMovieByID(id)
+}
+
+func (r *Room) RestoreMovies(ids []string) error {
+	return r.movies.RestoreMovies(ids)
+}
+
+func (r *Room) DeleteTrashedMovies(ids []string) error {
+	return r.movies.DeleteTrashedMovies(ids)
 }
 
 func (r *Room) GetMovieByID(id string) (*Movie, error) {
@@ -467,6 +561,7 @@ func (r *Room) SetCurrentMovie(movieID string, subPath string, play bool) error
 		ID:     m.ID,
 		IsLive: m.Live,
 	}, play)
+	r.stat.moviesPlayed.Add(1)
 	return m.ClearCache()
 }
 
@@ -479,6 +574,10 @@ func (r *Room) GetMoviesWithPage(keyword string, page, pageSize int, parentID st
 }
 
 func (r *Room) NewClient(user *User, conn *websocket.Conn) (*Client, error) {
+	if r.IsArchived() {
+		return nil, ErrRoomArchived
+	}
+	r.updateLastActiveAt()
 	h := r.lazyInitHub()
 	cli := newClient(user, r, h, conn)
 	err := h.RegClient(cli)
@@ -488,6 +587,19 @@ func (r *Room) NewClient(user *User, conn *websocket.Conn) (*Client, error) {
 	return cli, nil
 }
 
+// updateLastActiveAt records someone joined the room, at most once an hour
+func (r *Room) updateLastActiveAt() {
+	now := time.Now()
+	last := r.lastActiveUpdatedAt.Load()
+	if now.Unix()-last < int64(time.Hour/time.Second) ||
+		!r.lastActiveUpdatedAt.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	if err := db.SetRoomLastActiveAt(r.ID, now); err != nil {
+		logrus.Errorf("update room %s last active at failed: %v", r.ID, err)
+	}
+}
+
 func (r *Room) RegClient(cli *Client) error {
 	return r.lazyInitHub().RegClient(cli)
 }
@@ -508,6 +620,30 @@ func (r *Room) SetCurrentStatus(playing bool, seek float64, rate float64, timeDi
 	return r.current.SetStatus(playing, seek, rate, timeDiff)
 }
 
+const (
+	// the subtitle delay is limited to ten minutes in both directions
+	MaxSubtitleDelay = 10 * 60 * 1000
+	// the track is broadcast to the whole room
+	MaxSubtitleTrackLength = 256
+)
+
+func (r *Room) SetCurrentSubtitle(track string, delay int64) (*SubtitleStatus, error) {
+	if len(track) > MaxSubtitleTrackLength {
+		return nil, fmt.Errorf("subtitle track must be at most %d bytes", MaxSubtitleTrackLength)
+	}
+	if delay > MaxSubtitleDelay || delay < -MaxSubtitleDelay {
+		return nil, fmt.Errorf("subtitle delay must be within %d ms", MaxSubtitleDelay)
+	}
+	s := SubtitleStatus{
+		Track: track,
+		Delay: delay,
+	}
+	if err := r.current.SetSubtitle(s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
 func (r *Room) SetCurrentSeekRate(seek float64, rate float64, timeDiff float64) *Status {
 	return r.current.SetSeekRate(seek, rate, timeDiff)
 }
@@ -532,13 +668,37 @@ func (r *Room) afterUpdateSettings(rs *model.RoomSettings) error {
 	if r.Settings.GuestPermissions != rs.GuestPermissions {
 		r.members.Delete(db.GuestUserID)
 	}
+	announcementChanged := r.Settings.Announcement != rs.Announcement ||
+		r.Settings.AnnouncementExpireAt != rs.AnnouncementExpireAt
 	r.Settings = rs
+	if announcementChanged {
+		if err := r.Broadcast(r.AnnouncementMessage()); err != nil {
+			logrus.Errorf("broadcast room %s announcement failed: %v", r.ID, err)
+		}
+	}
 	if rs.DisableGuest {
 		return r.KickUser(db.GuestUserID)
 	}
 	return nil
 }
 
+// AnnouncementMessage returns the announcement message of the room,
+// the content is empty if there is no announcement or it has expired
+func (r *Room) AnnouncementMessage() *pb.Message {
+	announcement := &pb.Announcement{}
+	if r.Settings.HasAnnouncement(time.Now()) {
+		announcement.Content = r.Settings.Announcement
+		announcement.ExpireAt = r.Settings.AnnouncementExpireAt
+	}
+	return &pb.Message{
+		Type:      pb.MessageType_ANNOUNCEMENT,
+		Timestamp: time.Now().UnixMilli(),
+		Payload: &pb.Message_Announcement{
+			Announcement: announcement,
+		},
+	}
+}
+
 func (r *Room) ResetMemberPermissions(userID string) error {
 	return r.SetMemberPermissions(userID, r.Settings.UserDefaultPermissions)
 }
@@ -706,7 +866,7 @@ func (r *Room) SetStatus(status model.RoomStatus) error {
 		return err
 	}
 	r.Status = status
-	if status == model.RoomStatusBanned || status == model.RoomStatusPending {
+	if status == model.RoomStatusBanned || status == model.RoomStatusPending || status == model.RoomStatusArchived {
 		r.close()
 	}
 	return nil
@@ -0,0 +1,55 @@
+package op
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+type roomStat struct {
+	moviesPlayed atomic.Int64
+	chatMessages atomic.Int64
+}
+
+// takeStat returns the stat accumulated since the last take,
+// viewers are counted as viewer minutes over the elapsed duration
+func (r *Room) takeStat(now time.Time, elapsed time.Duration) *model.RoomStat {
+	viewers := r.ViewerCount()
+	return &model.RoomStat{
+		RoomID:        r.ID,
+		StartAt:       now,
+		ViewerMinutes: int64(float64(viewers) * elapsed.Minutes()),
+		PeakViewers:   viewers,
+		MoviesPlayed:  r.stat.moviesPlayed.Swap(0),
+		ChatMessages:  r.stat.chatMessages.Swap(0),
+	}
+}
+
+// saveStat saves the stat accumulated since the last take,
+// the counters are kept for the next take if the stat can't be saved
+func (r *Room) saveStat(now time.Time, elapsed time.Duration) {
+	stat := r.takeStat(now, elapsed)
+	if stat.ViewerMinutes == 0 &&
+		stat.PeakViewers == 0 &&
+		stat.MoviesPlayed == 0 &&
+		stat.ChatMessages == 0 {
+		return
+	}
+	if err := db.AddRoomStat(stat); err != nil {
+		r.stat.moviesPlayed.Add(stat.MoviesPlayed)
+		r.stat.chatMessages.Add(stat.ChatMessages)
+		logrus.Errorf("save room %s stat failed: %v", r.ID, err)
+	}
+}
+
+// SampleRoomStats records the activity of all cached rooms since the last sample
+func SampleRoomStats(elapsed time.Duration) {
+	now := time.Now()
+	RangeRoomCache(func(_ string, value *RoomEntry) bool {
+		value.Value().saveStat(now, elapsed)
+		return true
+	})
+}
@@ -0,0 +1,92 @@
+package op
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// initTestDB initializes the database, the settings and the caches like the bootstrap
+func initTestDB(t *testing.T) *gorm.DB {
+	conf.Conf = conf.DefaultConfig()
+	logrus.SetOutput(io.Discard)
+	d, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "synctv.db")), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Init(d, conf.DatabaseTypeSqlite3); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		b, ok := settings.PopNeedInit()
+		if !ok {
+			break
+		}
+		if err := b.Init(b.DefaultString()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Init(4096); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestSaveRoomStat(t *testing.T) {
+	d := initTestDB(t)
+	u, err := CreateUser("stat", "password", db.WithRole(model.RoleUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomE, err := CreateRoom("stat", "", 0, db.WithCreator(&u.Value().User), db.WithStatus(model.RoomStatusActive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	room := roomE.Value()
+	since := time.Now().Add(-time.Hour)
+
+	room.stat.moviesPlayed.Add(2)
+	room.stat.chatMessages.Add(3)
+	if err := d.Migrator().DropTable(&model.RoomStat{}); err != nil {
+		t.Fatal(err)
+	}
+	SampleRoomStats(time.Minute)
+	if room.stat.moviesPlayed.Load() != 2 || room.stat.chatMessages.Load() != 3 {
+		t.Fatalf("counters = %d, %d, want them kept after a failed save",
+			room.stat.moviesPlayed.Load(), room.stat.chatMessages.Load())
+	}
+	if err := d.AutoMigrate(&model.RoomStat{}); err != nil {
+		t.Fatal(err)
+	}
+
+	room.stat.chatMessages.Add(1)
+	CloseRoomWithRoomEntry(roomE)
+	stats, err := db.GetRoomStats(room.ID, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moviesPlayed, chatMessages int64
+	for _, s := range stats {
+		moviesPlayed += s.MoviesPlayed
+		chatMessages += s.ChatMessages
+	}
+	if moviesPlayed != 2 || chatMessages != 4 {
+		t.Fatalf("saved counters = %d, %d, want them saved when the room is closed", moviesPlayed, chatMessages)
+	}
+	if room.stat.moviesPlayed.Load() != 0 || room.stat.chatMessages.Load() != 0 {
+		t.Error("the saved counters are not reset")
+	}
+}
@@ -0,0 +1,296 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+// roomVendors holds the vendor credentials owned by a room,
+// it is shared by the room and all its movies.
+//
+// The credentials used to play a movie are resolved in this order:
+//   - alist / emby: the room binding of the movie's server, then the creator's binding
+//   - shared bilibili: the room binding, then the creator's cookies
+//   - non-shared bilibili: the viewer's cookies, then the room binding, anonymous otherwise
+type roomVendors struct {
+	binds    *cache.RoomVendorBindsCache
+	alist    *cache.AlistUserCache
+	emby     *cache.EmbyUserCache
+	bilibili *cache.BilibiliUserCache
+}
+
+func newRoomVendors(roomID string) *roomVendors {
+	return &roomVendors{
+		binds:    cache.NewRoomVendorBindsCache(roomID),
+		alist:    cache.NewRoomAlistUserCache(roomID),
+		emby:     cache.NewRoomEmbyUserCache(roomID),
+		bilibili: cache.NewRoomBilibiliUserCache(roomID),
+	}
+}
+
+func (v *roomVendors) hasAlist(ctx context.Context, serverID string) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	binds, err := v.binds.Get(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := binds.Alist[serverID]
+	return ok, nil
+}
+
+func (v *roomVendors) hasEmby(ctx context.Context, serverID string) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	binds, err := v.binds.Get(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := binds.Emby[serverID]
+	return ok, nil
+}
+
+func (v *roomVendors) hasBilibili(ctx context.Context) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	binds, err := v.binds.Get(ctx)
+	if err != nil {
+		return false, err
+	}
+	return binds.Bilibili, nil
+}
+
+// AlistUserCache returns the alist credentials used to play the movie
+func (m *Movie) AlistUserCache(ctx context.Context) (*cache.AlistUserCache, error) {
+	serverID, err := m.VendorInfo.Alist.ServerID()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.vendors.hasAlist(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return m.vendors.alist, nil
+	}
+	u, err := LoadOrInitUserByID(m.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Value().AlistCache(), nil
+}
+
+// EmbyUserCache returns the emby credentials used to play the movie
+func (m *Movie) EmbyUserCache(ctx context.Context) (*cache.EmbyUserCache, error) {
+	serverID, err := m.VendorInfo.Emby.ServerID()
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.vendors.hasEmby(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return m.vendors.emby, nil
+	}
+	u, err := LoadOrInitUserByID(m.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Value().EmbyCache(), nil
+}
+
+// BilibiliSharedUserCache returns the bilibili cookies shared by all viewers of the movie,
+// the key identifies the cookies owner in BilibiliMovieCache.NoSharedMovie
+func (m *Movie) BilibiliSharedUserCache(ctx context.Context) (key string, c *cache.BilibiliUserCache, err error) {
+	ok, err := m.vendors.hasBilibili(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if ok {
+		return "room:" + m.RoomID, m.vendors.bilibili, nil
+	}
+	u, err := LoadOrInitUserByID(m.CreatorID)
+	if err != nil {
+		return "", nil, err
+	}
+	return m.CreatorID, u.Value().BilibiliCache(), nil
+}
+
+// BilibiliUserCache returns the bilibili cookies used to play the movie for the viewer
+func (m *Movie) BilibiliUserCache(ctx context.Context, viewer *User) (key string, c *cache.BilibiliUserCache, err error) {
+	if m.VendorInfo.Bilibili.Shared {
+		return m.BilibiliSharedUserCache(ctx)
+	}
+	_, err = viewer.BilibiliCache().Get(ctx)
+	switch {
+	case err == nil:
+		return viewer.ID, viewer.BilibiliCache(), nil
+	case !errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)):
+		return "", nil, err
+	}
+	ok, err := m.vendors.hasBilibili(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if ok {
+		return "room:" + m.RoomID, m.vendors.bilibili, nil
+	}
+	return viewer.ID, viewer.BilibiliCache(), nil
+}
+
+type RoomVendorBind struct {
+	Vendor   model.VendorName
+	ServerID string
+	Host     string
+	BoundBy  string
+}
+
+func (r *Room) VendorBinds() ([]*RoomVendorBind, error) {
+	alistVendors, err := db.GetRoomAlistVendors(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	embyVendors, err := db.GetRoomEmbyVendors(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	binds := make([]*RoomVendorBind, 0, len(alistVendors)+len(embyVendors)+1)
+	bv, err := db.GetRoomBilibiliVendor(r.ID)
+	switch {
+	case err == nil:
+		binds = append(binds, &RoomVendorBind{
+			Vendor:  model.VendorBilibili,
+			BoundBy: bv.BoundBy,
+		})
+	case !errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)):
+		return nil, err
+	}
+	for _, v := range alistVendors {
+		binds = append(binds, &RoomVendorBind{
+			Vendor:   model.VendorAlist,
+			ServerID: v.ServerID,
+			Host:     v.Host,
+			BoundBy:  v.BoundBy,
+		})
+	}
+	for _, v := range embyVendors {
+		binds = append(binds, &RoomVendorBind{
+			Vendor:   model.VendorEmby,
+			ServerID: v.ServerID,
+			Host:     v.Host,
+			BoundBy:  v.BoundBy,
+		})
+	}
+	return binds, nil
+}
+
+// BindVendor copies the user's own vendor binding to the room,
+// so the room keeps working after the user unbinds it or leaves
+func (r *Room) BindVendor(user *User, vendor model.VendorName, serverID string) error {
+	switch vendor {
+	case model.VendorAlist:
+		v, err := db.GetAlistVendor(user.ID, serverID)
+		if err != nil {
+			return err
+		}
+		r.movies.ClearVendorCache(vendor)
+		_, err = db.CreateOrSaveRoomAlistVendor(&model.RoomAlistVendor{
+			RoomID:         r.ID,
+			Backend:        v.Backend,
+			ServerID:       v.ServerID,
+			Host:           v.Host,
+			Username:       v.Username,
+			HashedPassword: v.HashedPassword,
+			BoundBy:        user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		r.vendors.alist.Delete(serverID)
+	case model.VendorEmby:
+		v, err := db.GetEmbyVendor(user.ID, serverID)
+		if err != nil {
+			return err
+		}
+		r.movies.ClearVendorCache(vendor)
+		_, err = db.CreateOrSaveRoomEmbyVendor(&model.RoomEmbyVendor{
+			RoomID:     r.ID,
+			Backend:    v.Backend,
+			ServerID:   v.ServerID,
+			Host:       v.Host,
+			APIKey:     v.APIKey,
+			EmbyUserID: v.EmbyUserID,
+			BoundBy:    user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		r.vendors.emby.Delete(serverID)
+	case model.VendorBilibili:
+		v, err := db.GetBilibiliVendor(user.ID)
+		if err != nil {
+			return err
+		}
+		r.movies.ClearVendorCache(vendor)
+		_, err = db.CreateOrSaveRoomBilibiliVendor(&model.RoomBilibiliVendor{
+			RoomID:  r.ID,
+			Backend: v.Backend,
+			Cookies: v.Cookies,
+			BoundBy: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+		_ = r.vendors.bilibili.Clear(context.Background())
+	default:
+		return fmt.Errorf("vendor %s can not be bound to room", vendor)
+	}
+	if _, err := r.vendors.binds.Refresh(context.Background()); err != nil {
+		return err
+	}
+	// a movie resolved before the refresh is cached with the old binding
+	r.movies.ClearVendorCache(vendor)
+	return nil
+}
+
+// UnbindVendor removes the room binding, the movies fall back to the creator's credentials
+func (r *Room) UnbindVendor(vendor model.VendorName, serverID string) error {
+	// the movie caches are cleared with the credentials they were resolved with
+	switch vendor {
+	case model.VendorAlist:
+		r.movies.ClearVendorCache(vendor)
+		if err := db.DeleteRoomAlistVendor(r.ID, serverID); err != nil {
+			return err
+		}
+		r.vendors.alist.Delete(serverID)
+	case model.VendorEmby:
+		r.movies.ClearVendorCache(vendor)
+		if err := db.DeleteRoomEmbyVendor(r.ID, serverID); err != nil {
+			return err
+		}
+		r.vendors.emby.Delete(serverID)
+	case model.VendorBilibili:
+		r.movies.ClearVendorCache(vendor)
+		if err := db.DeleteRoomBilibiliVendor(r.ID); err != nil {
+			return err
+		}
+		_ = r.vendors.bilibili.Clear(context.Background())
+	default:
+		return fmt.Errorf("vendor %s can not be bound to room", vendor)
+	}
+	if _, err := r.vendors.binds.Refresh(context.Background()); err != nil {
+		return err
+	}
+	// a movie resolved before the refresh is cached with the old binding
+	r.movies.ClearVendorCache(vendor)
+	return nil
+}
@@ -17,6 +17,7 @@ var (
 	ErrRoomCreatorPending = errors.New("room creator is pending approval, please wait for admin to review")
 	ErrInvalidRoomID      = errors.New("invalid room ID: must be 32 characters long")
 	ErrRoomNotInCache     = errors.New("room not found in cache")
+	ErrRoomArchived       = errors.New("room is archived")
 )
 
 type RoomEntry = synccache.Entry[*Room]
@@ -54,10 +55,12 @@ func LoadOrInitRoom(room *model.Room) (*RoomEntry, error) {
 		return nil, err
 	}
 
+	vendors := newRoomVendors(room.ID)
 	i, _ := roomCache.LoadOrStore(room.ID, &Room{
 		Room:    *room,
 		current: newCurrent(),
-		movies:  &movies{roomID: room.ID},
+		vendors: vendors,
+		movies:  &movies{roomID: room.ID, vendors: vendors},
 	}, time.Duration(settings.RoomTTL.Get())*time.Hour)
 	return i, nil
 }
@@ -0,0 +1,316 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/zijiren233/gencontainer/rwmap"
+)
+
+const (
+	trafficMonthLayout = "2006-01"
+	// the bandwidth can be used ahead up to this time
+	trafficMaxBurst = time.Second
+	// subjects without traffic in this time are dropped from the memory
+	trafficSubjectTTL = 30 * time.Minute
+)
+
+var ErrTrafficQuotaExceeded = errors.New("monthly traffic quota exceeded")
+
+func TrafficMonth(t time.Time) string {
+	return t.Format(trafficMonthLayout)
+}
+
+// trafficSubject is the token bucket and the byte counter of a user or a room
+type trafficSubject struct {
+	subjectType model.TrafficSubjectType
+	subjectID   string
+
+	lock   sync.Mutex
+	limit  *model.TrafficLimit
+	tokens float64
+	last   time.Time
+	month  string
+	// bytes of the month saved in the database
+	saved int64
+
+	// bytes not saved yet
+	pending atomic.Int64
+	active  atomic.Int64
+}
+
+var trafficSubjects rwmap.RWMap[string, *trafficSubject]
+
+func trafficSubjectKey(subjectType model.TrafficSubjectType, subjectID string) string {
+	return string(subjectType) + ":" + subjectID
+}
+
+func loadTrafficSubject(subjectType model.TrafficSubjectType, subjectID string) (*trafficSubject, error) {
+	key := trafficSubjectKey(subjectType, subjectID)
+	if s, ok := trafficSubjects.Load(key); ok {
+		return s, nil
+	}
+	limit, err := db.GetTrafficLimit(subjectType, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	month := TrafficMonth(time.Now())
+	saved, err := db.GetTrafficUsageBytes(subjectType, subjectID, month)
+	if err != nil {
+		return nil, err
+	}
+	s := &trafficSubject{
+		subjectType: subjectType,
+		subjectID:   subjectID,
+		limit:       limit,
+		month:       month,
+		saved:       saved,
+	}
+	s.active.Store(time.Now().Unix())
+	s, _ = trafficSubjects.LoadOrStore(key, s)
+	return s, nil
+}
+
+// bandwidth returns the bytes per second, 0 means unlimited
+func (s *trafficSubject) bandwidth() int64 {
+	return EffectiveTrafficBandwidth(s.limit) * 1024
+}
+
+// quota returns the bytes per month, 0 means unlimited
+func (s *trafficSubject) quota() int64 {
+	return EffectiveTrafficQuota(s.limit) * 1024 * 1024
+}
+
+// usedLocked returns the bytes of the current month
+func (s *trafficSubject) usedLocked(now time.Time) int64 {
+	if month := TrafficMonth(now); month != s.month {
+		s.month = month
+		s.saved = 0
+	}
+	return s.saved + s.pending.Load()
+}
+
+func (s *trafficSubject) used() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.usedLocked(time.Now())
+}
+
+func (s *trafficSubject) exceededLocked(now time.Time) bool {
+	quota := s.quota()
+	return quota > 0 && s.usedLocked(now) >= quota
+}
+
+func (s *trafficSubject) exceeded() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.exceededLocked(time.Now())
+}
+
+// reserve takes n bytes from the bucket and returns the time to wait before writing them
+func (s *trafficSubject) reserve(n int) (time.Duration, error) {
+	now := time.Now()
+	s.active.Store(now.Unix())
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.exceededLocked(now) {
+		return 0, ErrTrafficQuotaExceeded
+	}
+	rate := s.bandwidth()
+	if rate <= 0 {
+		return 0, nil
+	}
+	burst := float64(rate) * trafficMaxBurst.Seconds()
+	s.tokens = min(burst, s.tokens+now.Sub(s.last).Seconds()*float64(rate))
+	s.last = now
+	s.tokens -= float64(n)
+	if s.tokens >= 0 {
+		return 0, nil
+	}
+	return time.Duration(-s.tokens / float64(rate) * float64(time.Second)), nil
+}
+
+func (s *trafficSubject) setLimit(limit *model.TrafficLimit) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.limit = limit
+}
+
+func (s *trafficSubject) flush() error {
+	s.lock.Lock()
+	s.usedLocked(time.Now())
+	n := s.pending.Swap(0)
+	month := s.month
+	s.saved += n
+	s.lock.Unlock()
+	if n == 0 {
+		return nil
+	}
+	err := db.AddTrafficUsage(&model.TrafficUsage{
+		SubjectType: s.subjectType,
+		SubjectID:   s.subjectID,
+		Month:       month,
+		Bytes:       n,
+	})
+	if err != nil {
+		s.lock.Lock()
+		if s.month == month {
+			s.saved -= n
+		}
+		s.pending.Add(n)
+		s.lock.Unlock()
+	}
+	return err
+}
+
+// EffectiveTrafficBandwidth returns the KB per second of the limit, 0 means unlimited
+func EffectiveTrafficBandwidth(limit *model.TrafficLimit) int64 {
+	b := limit.Bandwidth
+	if b == 0 {
+		switch limit.SubjectType {
+		case model.TrafficSubjectUser:
+			b = settings.UserProxyBandwidth.Get()
+		case model.TrafficSubjectRoom:
+			b = settings.RoomProxyBandwidth.Get()
+		}
+	}
+	return max(b, 0)
+}
+
+// EffectiveTrafficQuota returns the MB per month of the limit, 0 means unlimited
+func EffectiveTrafficQuota(limit *model.TrafficLimit) int64 {
+	q := limit.MonthlyQuota
+	if q == 0 {
+		switch limit.SubjectType {
+		case model.TrafficSubjectUser:
+			q = settings.UserMonthlyTrafficQuota.Get()
+		case model.TrafficSubjectRoom:
+			q = settings.RoomMonthlyTrafficQuota.Get()
+		}
+	}
+	return max(q, 0)
+}
+
+// TrafficMeter accounts the bytes proxied to a user in a room
+// and paces them to the bandwidth of both
+type TrafficMeter struct {
+	subjects []trafficSubjectRef
+}
+
+type trafficSubjectRef struct {
+	subjectType model.TrafficSubjectType
+	subjectID   string
+}
+
+// NewTrafficMeter returns ErrTrafficQuotaExceeded if the user or the room used up the quota,
+// userID is empty for the requests not bound to a user
+func NewTrafficMeter(userID, roomID string) (*TrafficMeter, error) {
+	m := &TrafficMeter{}
+	if userID != "" {
+		m.subjects = append(m.subjects, trafficSubjectRef{model.TrafficSubjectUser, userID})
+	}
+	if roomID != "" {
+		m.subjects = append(m.subjects, trafficSubjectRef{model.TrafficSubjectRoom, roomID})
+	}
+	for _, ref := range m.subjects {
+		s, err := loadTrafficSubject(ref.subjectType, ref.subjectID)
+		if err != nil {
+			return nil, err
+		}
+		if s.exceeded() {
+			return nil, ErrTrafficQuotaExceeded
+		}
+	}
+	return m, nil
+}
+
+// Wait blocks until n bytes can be written within the bandwidth of all the subjects
+func (m *TrafficMeter) Wait(ctx context.Context, n int) error {
+	var wait time.Duration
+	for _, ref := range m.subjects {
+		// the subject is loaded again in case it was dropped while idle
+		s, err := loadTrafficSubject(ref.subjectType, ref.subjectID)
+		if err != nil {
+			return err
+		}
+		w, err := s.reserve(n)
+		if err != nil {
+			return err
+		}
+		wait = max(wait, w)
+	}
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Written counts n bytes written to the client
+func (m *TrafficMeter) Written(n int) {
+	for _, ref := range m.subjects {
+		if s, ok := trafficSubjects.Load(trafficSubjectKey(ref.subjectType, ref.subjectID)); ok {
+			s.pending.Add(int64(n))
+		}
+	}
+}
+
+// FlushTrafficUsage saves the bytes counted since the last flush
+// and drops the subjects idle for a while
+func FlushTrafficUsage() {
+	deadline := time.Now().Add(-trafficSubjectTTL).Unix()
+	trafficSubjects.Range(func(key string, s *trafficSubject) bool {
+		if err := s.flush(); err != nil {
+			log.Errorf("save traffic usage of %s failed: %v", key, err)
+			return true
+		}
+		if s.active.Load() < deadline && s.pending.Load() == 0 {
+			trafficSubjects.CompareAndDelete(key, s)
+		}
+		return true
+	})
+}
+
+// GetTrafficUsedBytes returns the bytes proxied to the subject in the current month
+func GetTrafficUsedBytes(subjectType model.TrafficSubjectType, subjectID string) (int64, error) {
+	s, err := loadTrafficSubject(subjectType, subjectID)
+	if err != nil {
+		return 0, err
+	}
+	return s.used(), nil
+}
+
+// SetTrafficLimit saves the limit, a limit with neither bandwidth nor quota
+// resets the subject to the default settings
+func SetTrafficLimit(limit *model.TrafficLimit) error {
+	var err error
+	if limit.Bandwidth == 0 && limit.MonthlyQuota == 0 {
+		err = db.DeleteTrafficLimit(limit.SubjectType, limit.SubjectID)
+		var nfe db.NotFoundError
+		if errors.As(err, &nfe) {
+			err = nil
+		}
+	} else {
+		err = db.SaveTrafficLimit(limit)
+	}
+	if err != nil {
+		return err
+	}
+	if s, ok := trafficSubjects.Load(trafficSubjectKey(limit.SubjectType, limit.SubjectID)); ok {
+		s.setLimit(limit)
+	}
+	return nil
+}
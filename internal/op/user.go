@@ -2,6 +2,7 @@ package op
 
 import (
 	"errors"
+	"fmt"
 	"hash/crc32"
 	"sync/atomic"
 
@@ -9,9 +10,12 @@ import (
 	"github.com/synctv-org/synctv/internal/db"
 	"github.com/synctv-org/synctv/internal/email"
 	"github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/playlist"
 	"github.com/synctv-org/synctv/internal/provider"
 	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
 	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/utils"
 	"github.com/zijiren233/stream"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,6 +24,9 @@ type User struct {
 	alistCache    atomic.Pointer[cache.AlistUserCache]
 	bilibiliCache atomic.Pointer[cache.BilibiliUserCache]
 	embyCache     atomic.Pointer[cache.EmbyUserCache]
+	jellyfinCache atomic.Pointer[cache.JellyfinUserCache]
+	webdavCache   atomic.Pointer[cache.WebDAVUserCache]
+	s3Cache       atomic.Pointer[cache.S3UserCache]
 	model.User
 	version uint32
 }
@@ -57,6 +64,39 @@ func (u *User) EmbyCache() *cache.EmbyUserCache {
 	return c
 }
 
+func (u *User) JellyfinCache() *cache.JellyfinUserCache {
+	c := u.jellyfinCache.Load()
+	if c == nil {
+		c = cache.NewJellyfinUserCache(u.ID)
+		if !u.jellyfinCache.CompareAndSwap(nil, c) {
+			return u.JellyfinCache()
+		}
+	}
+	return c
+}
+
+func (u *User) WebDAVCache() *cache.WebDAVUserCache {
+	c := u.webdavCache.Load()
+	if c == nil {
+		c = cache.NewWebDAVUserCache(u.ID)
+		if !u.webdavCache.CompareAndSwap(nil, c) {
+			return u.WebDAVCache()
+		}
+	}
+	return c
+}
+
+func (u *User) S3Cache() *cache.S3UserCache {
+	c := u.s3Cache.Load()
+	if c == nil {
+		c = cache.NewS3UserCache(u.ID)
+		if !u.s3Cache.CompareAndSwap(nil, c) {
+			return u.S3Cache()
+		}
+	}
+	return c
+}
+
 func (u *User) Version() uint32 {
 	return atomic.LoadUint32(&u.version)
 }
@@ -119,6 +159,29 @@ func (u *User) NewMovie(movie *model.MovieBase) (*model.Movie, error) {
 		if movie.VendorInfo.Alist == nil {
 			return nil, errors.New("alist payload is nil")
 		}
+	case model.VendorJellyfin:
+		if movie.VendorInfo.Jellyfin == nil {
+			return nil, errors.New("jellyfin payload is nil")
+		}
+	case model.VendorWebDAV:
+		if movie.VendorInfo.WebDAV == nil {
+			return nil, errors.New("webdav payload is nil")
+		}
+	case model.VendorS3:
+		if movie.VendorInfo.S3 == nil {
+			return nil, errors.New("s3 payload is nil")
+		}
+	case model.VendorPlugin:
+		if movie.VendorInfo.Plugin == nil {
+			return nil, errors.New("plugin payload is nil")
+		}
+		if _, ok := vendorplugins.LoadVendor(movie.VendorInfo.Plugin.Name); !ok {
+			return nil, fmt.Errorf("vendor plugin %s not found", movie.VendorInfo.Plugin.Name)
+		}
+	case model.VendorLocal:
+		if err := u.checkLocalMovie(movie); err != nil {
+			return nil, err
+		}
 	}
 	return &model.Movie{
 		MovieBase: *movie,
@@ -180,6 +243,55 @@ func (u *User) AddRoomMovies(room *Room, movies []*model.MovieBase) ([]*model.Mo
 	})
 }
 
+// ImportRoomMovies adds the playlist items into the folder parentID
+func (u *User) ImportRoomMovies(room *Room, parentID string, items []*playlist.Item) ([]*model.Movie, error) {
+	if !u.HasRoomPermission(room, model.PermissionAddMovie) {
+		return nil, model.ErrNoPermission
+	}
+	var (
+		levels [][]*model.Movie
+		all    []*model.Movie
+	)
+	var walk func(items []*playlist.Item, parentID string, depth int) error
+	walk = func(items []*playlist.Item, parentID string, depth int) error {
+		for _, item := range items {
+			mb := item.Movie
+			mb.ParentID = model.EmptyNullString(parentID)
+			m, err := u.NewMovie(&mb)
+			if err != nil {
+				return err
+			}
+			if len(levels) <= depth {
+				levels = append(levels, nil)
+			}
+			levels[depth] = append(levels[depth], m)
+			all = append(all, m)
+			if len(item.Children) == 0 {
+				continue
+			}
+			// children need the id of the folder before it is created
+			m.ID = utils.SortUUID()
+			if err := walk(item.Children, m.ID, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(items, parentID, 0); err != nil {
+		return nil, err
+	}
+	if err := room.ImportMovies(levels); err != nil {
+		return nil, err
+	}
+	return all, room.Broadcast(&pb.Message{
+		Type: pb.MessageType_MOVIES,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+	})
+}
+
 func (u *User) IsRoot() bool {
 	return u.Role == model.RoleRoot
 }
@@ -311,6 +423,11 @@ func (u *User) UpdateRoomMovie(room *Room, movieID string, movie *model.MovieBas
 	if !u.HasRoomPermission(room, model.PermissionEditMovie) {
 		return model.ErrNoPermission
 	}
+	if movie.VendorInfo.Vendor == model.VendorLocal {
+		if err := u.checkLocalMovie(movie); err != nil {
+			return err
+		}
+	}
 	err := room.UpdateMovie(movieID, movie)
 	if err != nil {
 		return err
@@ -338,6 +455,27 @@ func (u *User) UpdateRoomSettings(room *Room, settings map[string]interface{}) e
 	return room.UpdateSettings(settings)
 }
 
+func (u *User) GetRoomVendorBinds(room *Room) ([]*RoomVendorBind, error) {
+	if !u.HasRoomAdminPermission(room, model.PermissionSetRoomVendor) {
+		return nil, model.ErrNoPermission
+	}
+	return room.VendorBinds()
+}
+
+func (u *User) BindRoomVendor(room *Room, vendor model.VendorName, serverID string) error {
+	if !u.HasRoomAdminPermission(room, model.PermissionSetRoomVendor) {
+		return model.ErrNoPermission
+	}
+	return room.BindVendor(u, vendor, serverID)
+}
+
+func (u *User) UnbindRoomVendor(room *Room, vendor model.VendorName, serverID string) error {
+	if !u.HasRoomAdminPermission(room, model.PermissionSetRoomVendor) {
+		return model.ErrNoPermission
+	}
+	return room.UnbindVendor(vendor, serverID)
+}
+
 func (u *User) DeleteRoomMovieByID(room *Room, movieID string) error {
 	m, err := room.GetMovieByID(movieID)
 	if err != nil {
@@ -346,7 +484,7 @@ func (u *User) DeleteRoomMovieByID(room *Room, movieID string) error {
 	if m.Movie.CreatorID != u.ID && !u.HasRoomPermission(room, model.PermissionDeleteMovie) {
 		return model.ErrNoPermission
 	}
-	return room.DeleteMovieByID(movieID)
+	return room.DeleteMovieByID(movieID, u.ID)
 }
 
 func (u *User) DeleteRoomMoviesByID(room *Room, movieIDs []string) error {
@@ -359,7 +497,7 @@ func (u *User) DeleteRoomMoviesByID(room *Room, movieIDs []string) error {
 			return model.ErrNoPermission
 		}
 	}
-	if err := room.DeleteMoviesByID(movieIDs); err != nil {
+	if err := room.DeleteMoviesByID(movieIDs, u.ID); err != nil {
 		return err
 	}
 	return room.Broadcast(&pb.Message{
@@ -375,7 +513,7 @@ func (u *User) ClearRoomMovies(room *Room) error {
 	if !u.HasRoomPermission(room, model.PermissionDeleteMovie) {
 		return model.ErrNoPermission
 	}
-	err := room.ClearMovies()
+	err := room.ClearMovies(u.ID)
 	if err != nil {
 		return err
 	}
@@ -392,7 +530,7 @@ func (u *User) ClearRoomMoviesByParentID(room *Room, parentID string) error {
 	if !u.HasRoomPermission(room, model.PermissionDeleteMovie) {
 		return model.ErrNoPermission
 	}
-	err := room.ClearMoviesByParentID(parentID)
+	err := room.ClearMoviesByParentID(parentID, u.ID)
 	if err != nil {
 		return err
 	}
@@ -405,6 +543,93 @@ func (u *User) ClearRoomMoviesByParentID(room *Room, parentID string) error {
 	})
 }
 
+func (u *User) GetRoomTrashedMoviesWithPage(room *Room, page, pageSize int) ([]*model.Movie, int64, error) {
+	if !u.HasRoomPermission(room, model.PermissionGetMovieList) {
+		return nil, 0, model.ErrNoPermission
+	}
+	return room.GetTrashedMoviesWithPage(page, pageSize)
+}
+
+// canRestoreMovie reports whether the user can restore or purge the trashed movie,
+// which is allowed to its creator and the room admins
+func (u *User) canRestoreMovie(room *Room, movieID string) (bool, error) {
+	if u.IsAdmin() || u.IsRoomAdmin(room) {
+		return true, nil
+	}
+	m, err := room.GetTrashedMovieByID(movieID)
+	if err != nil {
+		return false, err
+	}
+	return m.CreatorID == u.ID, nil
+}
+
+func (u *User) RestoreRoomMovies(room *Room, movieIDs []string) error {
+	for _, id := range movieIDs {
+		ok, err := u.canRestoreMovie(room, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return model.ErrNoPermission
+		}
+	}
+	if err := room.RestoreMovies(movieIDs); err != nil {
+		return err
+	}
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_MOVIES,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+	})
+}
+
+func (u *User) DeleteRoomTrashedMovies(room *Room, movieIDs []string) error {
+	for _, id := range movieIDs {
+		ok, err := u.canRestoreMovie(room, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return model.ErrNoPermission
+		}
+	}
+	return room.DeleteTrashedMovies(movieIDs)
+}
+
+func (u *User) MoveRoomMovies(room *Room, movieIDs []string, parentID string) error {
+	if !u.HasRoomPermission(room, model.PermissionEditMovie) {
+		return model.ErrNoPermission
+	}
+	if err := room.MoveMovies(movieIDs, parentID); err != nil {
+		return err
+	}
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_MOVIES,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+	})
+}
+
+func (u *User) ReorderRoomMovies(room *Room, parentID string, movieIDs []string) error {
+	if !u.HasRoomPermission(room, model.PermissionEditMovie) {
+		return model.ErrNoPermission
+	}
+	if err := room.ReorderMovies(parentID, movieIDs); err != nil {
+		return err
+	}
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_MOVIES,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+	})
+}
+
 func (u *User) SwapRoomMoviePositions(room *Room, id1, id2 string) error {
 	if !u.HasRoomPermission(room, model.PermissionEditMovie) {
 		return model.ErrNoPermission
@@ -512,6 +737,29 @@ func (u *User) SetRoomCurrentStatus(room *Room, playing bool, seek, rate, timeDi
 	return room.SetCurrentStatus(playing, seek, rate, timeDiff), nil
 }
 
+func (u *User) SetRoomCurrentSubtitle(room *Room, track string, delay int64) error {
+	if !u.HasRoomPermission(room, model.PermissionSetCurrentStatus) {
+		return model.ErrNoPermission
+	}
+	s, err := room.SetCurrentSubtitle(track, delay)
+	if err != nil {
+		return err
+	}
+	return room.Broadcast(&pb.Message{
+		Type: pb.MessageType_SUBTITLE,
+		Sender: &pb.Sender{
+			Username: u.Username,
+			UserId:   u.ID,
+		},
+		Payload: &pb.Message_SubtitleStatus{
+			SubtitleStatus: &pb.SubtitleStatus{
+				Track: s.Track,
+				Delay: s.Delay,
+			},
+		},
+	})
+}
+
 func (u *User) BanRoomMember(room *Room, userID string) error {
 	if !u.HasRoomAdminPermission(room, model.PermissionBanRoomMember) {
 		return model.ErrNoPermission
@@ -109,6 +109,7 @@ func CompareAndDeleteUser(user *UserEntry) error {
 	if err != nil {
 		return err
 	}
+	dropPendingWatchHistories(id)
 	return CompareAndCloseUser(user)
 }
 
@@ -120,6 +121,7 @@ func DeleteUserByID(id string) error {
 	if err != nil {
 		return err
 	}
+	dropPendingWatchHistories(id)
 	return CloseUserByID(id)
 }
 
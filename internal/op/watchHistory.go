@@ -0,0 +1,147 @@
+package op
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/model"
+	"github.com/zijiren233/gencontainer/rwmap"
+)
+
+const (
+	// a media is completed once watched to this ratio of its duration
+	watchHistoryCompletedRatio = 0.95
+	// positions near the start are not worth resuming from
+	MinResumePosition = 10
+)
+
+// pending watch histories keyed by user id and media key, saved by FlushWatchHistories
+var pendingWatchHistories rwmap.RWMap[string, *model.WatchHistory]
+
+// WatchHistoryMediaKey identifies the media of a movie, vendor media is identified
+// by its vendor path, so the same media pushed to different rooms shares the history
+func WatchHistoryMediaKey(m *Movie) string {
+	var identity string
+	switch vendorInfo := m.VendorInfo; vendorInfo.Vendor {
+	case model.VendorBilibili:
+		identity = fmt.Sprintf("bilibili:%s/%d/%d", vendorInfo.Bilibili.Bvid, vendorInfo.Bilibili.Cid, vendorInfo.Bilibili.Epid)
+	case model.VendorAlist:
+		identity = "alist:" + vendorInfo.Alist.Path
+	case model.VendorEmby:
+		identity = "emby:" + vendorInfo.Emby.Path
+	case model.VendorJellyfin:
+		identity = "jellyfin:" + vendorInfo.Jellyfin.Path
+	case model.VendorWebDAV:
+		identity = "webdav:" + vendorInfo.WebDAV.Path
+	case model.VendorS3:
+		identity = "s3:" + vendorInfo.S3.Path
+	case model.VendorPlugin:
+		identity = "plugin:" + vendorInfo.Plugin.Name + ":" + vendorInfo.Plugin.Path
+	case model.VendorLocal:
+		identity = "local:" + vendorInfo.Local.Path
+	default:
+		identity = "movie:" + m.ID
+	}
+	if m.subPath != "" {
+		identity += "#" + m.subPath
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:])
+}
+
+func watchHistoryPendingKey(userID, mediaKey string) string {
+	return userID + "/" + mediaKey
+}
+
+func (u *User) RecordWatchHistory(room *Room, m *Movie, position float64) {
+	if u.IsGuest() || m.Live || m.IsFolder && !m.IsDynamicFolder() {
+		return
+	}
+	mediaKey := WatchHistoryMediaKey(m)
+	duration := m.Meta.Duration
+	pendingWatchHistories.Store(watchHistoryPendingKey(u.ID, mediaKey), &model.WatchHistory{
+		UserID:    u.ID,
+		MediaKey:  mediaKey,
+		UpdatedAt: time.Now(),
+		RoomID:    room.ID,
+		MovieID:   m.ID,
+		SubPath:   m.subPath,
+		Name:      m.Name,
+		Vendor:    m.VendorInfo.Vendor,
+		Position:  position,
+		Duration:  duration,
+		Completed: duration > 0 && position >= duration*watchHistoryCompletedRatio,
+	})
+}
+
+// GetWatchHistory returns the latest watch history of the movie, including the unsaved one
+func (u *User) GetWatchHistory(m *Movie) (*model.WatchHistory, error) {
+	mediaKey := WatchHistoryMediaKey(m)
+	if h, ok := pendingWatchHistories.Load(watchHistoryPendingKey(u.ID, mediaKey)); ok {
+		return h, nil
+	}
+	return db.GetWatchHistory(u.ID, mediaKey)
+}
+
+func (u *User) DeleteWatchHistory(mediaKey string) error {
+	_, pending := pendingWatchHistories.LoadAndDelete(watchHistoryPendingKey(u.ID, mediaKey))
+	err := db.DeleteWatchHistory(u.ID, mediaKey)
+	if pending && errors.Is(err, db.NotFoundError(db.ErrWatchHistoryNotFound)) {
+		return nil
+	}
+	return err
+}
+
+func (u *User) ClearWatchHistories() error {
+	dropPendingWatchHistories(u.ID)
+	return db.DeleteWatchHistories(u.ID)
+}
+
+func dropPendingWatchHistories(userID string) {
+	pendingWatchHistories.Range(func(key string, value *model.WatchHistory) bool {
+		if value.UserID == userID {
+			pendingWatchHistories.Delete(key)
+		}
+		return true
+	})
+}
+
+// FlushWatchHistories saves the pending watch histories
+func FlushWatchHistories() {
+	if err := flushWatchHistories(""); err != nil {
+		logrus.Errorf("save watch histories failed: %v", err)
+	}
+}
+
+// FlushWatchHistories saves the pending watch histories of the user,
+// so that the saved histories are up to date
+func (u *User) FlushWatchHistories() error {
+	return flushWatchHistories(u.ID)
+}
+
+// flushWatchHistories removes the histories from the pending ones only after they
+// are saved, the histories updated while saving are kept for the next flush
+func flushWatchHistories(userID string) error {
+	keys := make([]string, 0)
+	histories := make([]*model.WatchHistory, 0)
+	pendingWatchHistories.Range(func(key string, value *model.WatchHistory) bool {
+		if userID != "" && value.UserID != userID {
+			return true
+		}
+		keys = append(keys, key)
+		histories = append(histories, value)
+		return true
+	})
+	if err := db.SaveWatchHistories(histories); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		pendingWatchHistories.CompareAndDelete(key, histories[i])
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	json "github.com/json-iterator/go"
+)
+
+const jsonVersion = 1
+
+type jsonPlaylist struct {
+	Version int     `json:"version"`
+	Items   []*Item `json:"items"`
+}
+
+func ParseJSON(r io.Reader) ([]*Item, error) {
+	var p jsonPlaylist
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+	if p.Version != jsonVersion {
+		return nil, fmt.Errorf("unsupported playlist version: %d", p.Version)
+	}
+	if err := checkJSONItems(p.Items); err != nil {
+		return nil, err
+	}
+	return p.Items, nil
+}
+
+func checkJSONItems(items []*Item) error {
+	for _, item := range items {
+		if item == nil {
+			return errors.New("playlist item is null")
+		}
+		// the parent is decided by the position in the tree
+		item.Movie.ParentID = ""
+		if len(item.Children) == 0 {
+			continue
+		}
+		if !item.Movie.IsFolder || item.Movie.IsDynamicFolder() {
+			return fmt.Errorf("%s is not a folder but has children", item.Movie.Name)
+		}
+		if err := checkJSONItems(item.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WriteJSON(w io.Writer, items []*Item) error {
+	if items == nil {
+		items = []*Item{}
+	}
+	return json.NewEncoder(w).Encode(&jsonPlaylist{
+		Version: jsonVersion,
+		Items:   items,
+	})
+}
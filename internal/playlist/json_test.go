@@ -0,0 +1,93 @@
+package playlist
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	items := []*Item{
+		{
+			Movie: model.MovieBase{
+				Name: "Emby Movie",
+				VendorInfo: model.VendorInfo{
+					Vendor:  model.VendorEmby,
+					Backend: "backend",
+					Emby: &model.EmbyStreamingInfo{
+						Path:      "server/item",
+						Transcode: true,
+					},
+				},
+			},
+		},
+		{
+			Movie: model.MovieBase{Name: "Folder", IsFolder: true},
+			Children: []*Item{
+				{
+					Movie: model.MovieBase{
+						Name:    "Movie",
+						URL:     "https://example.com/movie.m3u8",
+						Type:    "m3u8",
+						Headers: map[string]string{"Referer": "https://example.com/"},
+						Subtitles: map[string]*model.Subtitle{
+							"en": {URL: "https://example.com/en.vtt", Type: "vtt"},
+						},
+						Proxy: true,
+					},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, items); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("items = %s, want %s", dumpItems(got), dumpItems(items))
+	}
+
+	// the format is detected from the content
+	buf.Reset()
+	if err := Write(&buf, FormatJSON, items); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = Parse(&buf, ""); err != nil || !reflect.DeepEqual(got, items) {
+		t.Errorf("detected items = %s, %v", dumpItems(got), err)
+	}
+}
+
+func TestParseJSONError(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"invalid", `{"version":1,"items":[`},
+		{"version", `{"version":2,"items":[]}`},
+		{"null item", `{"version":1,"items":[null]}`},
+		{"children of movie", `{"version":1,"items":[{"base":{"name":"m","url":"u"},"children":[{"base":{"name":"c"}}]}]}`},
+		{"children of dynamic folder", `{"version":1,"items":[{"base":{"name":"d","isFolder":true,"vendorInfo":{"vendor":"emby"}},"children":[{"base":{"name":"c"}}]}]}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseJSON(strings.NewReader(tt.json)); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+}
+
+func TestParseJSONParentID(t *testing.T) {
+	items, err := ParseJSON(strings.NewReader(`{"version":1,"items":[{"base":{"name":"f","isFolder":true,"parentId":"x"},"children":[{"base":{"name":"c","parentId":"y"}}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].Movie.ParentID != "" || items[0].Children[0].Movie.ParentID != "" {
+		t.Error("the parent ids of the file are kept")
+	}
+}
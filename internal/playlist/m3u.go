@@ -0,0 +1,272 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+// vlcHeaderOptions maps #EXTVLCOPT options to http headers
+var vlcHeaderOptions = map[string]string{
+	"http-referrer":   "Referer",
+	"http-user-agent": "User-Agent",
+	"http-origin":     "Origin",
+	"http-cookie":     "Cookie",
+}
+
+type m3uEntry struct {
+	name    string
+	groups  []string
+	headers map[string]string
+}
+
+// ParseM3U parses a (extended) m3u playlist,
+// group-title is used as the folder of the item, nested folders are separated by /
+func ParseM3U(r io.Reader) ([]*Item, error) {
+	var (
+		root    []*Item
+		folders = make(map[string]*Item)
+		entry   m3uEntry
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			attrs, name := parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+			entry.name = name
+			if entry.name == "" {
+				entry.name = attrs["tvg-name"]
+			}
+			if group := attrs["group-title"]; group != "" {
+				entry.groups = splitGroup(group)
+			}
+		case strings.HasPrefix(line, "#EXTGRP:"):
+			if len(entry.groups) == 0 {
+				entry.groups = splitGroup(strings.TrimPrefix(line, "#EXTGRP:"))
+			}
+		case strings.HasPrefix(line, "#EXTVLCOPT:"):
+			k, v, ok := strings.Cut(strings.TrimPrefix(line, "#EXTVLCOPT:"), "=")
+			if header, known := vlcHeaderOptions[strings.TrimSpace(k)]; ok && known {
+				entry.setHeader(header, strings.TrimSpace(v))
+			}
+		case strings.HasPrefix(line, "#EXTHTTP:"):
+			var headers map[string]string
+			if err := json.UnmarshalFromString(strings.TrimPrefix(line, "#EXTHTTP:"), &headers); err != nil {
+				return nil, fmt.Errorf("parse #EXTHTTP error: %w", err)
+			}
+			for k, v := range headers {
+				entry.setHeader(k, v)
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			item := &Item{
+				Movie: model.MovieBase{
+					URL:     line,
+					Name:    entry.name,
+					Headers: entry.headers,
+				},
+			}
+			if item.Movie.Name == "" {
+				item.Movie.Name = nameFromURL(line)
+			}
+			if len(entry.groups) == 0 {
+				root = append(root, item)
+			} else {
+				folder := getFolder(&root, folders, entry.groups)
+				folder.Children = append(folder.Children, item)
+			}
+			entry = m3uEntry{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan m3u error: %w", err)
+	}
+	return root, nil
+}
+
+func (e *m3uEntry) setHeader(k, v string) {
+	if e.headers == nil {
+		e.headers = make(map[string]string)
+	}
+	e.headers[http.CanonicalHeaderKey(k)] = v
+}
+
+// parseExtInf parses `-1 key="value" key2="value2",name`
+func parseExtInf(s string) (attrs map[string]string, name string) {
+	attrs = make(map[string]string)
+	inQuote := false
+	i := 0
+	for ; i < len(s); i++ {
+		if s[i] == '"' {
+			inQuote = !inQuote
+		} else if s[i] == ',' && !inQuote {
+			break
+		}
+	}
+	if i < len(s) {
+		name = strings.TrimSpace(s[i+1:])
+	}
+	s = s[:i]
+	// skip duration
+	_, s, _ = strings.Cut(s, " ")
+	for {
+		s = strings.TrimSpace(s)
+		k, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		rest = strings.TrimSpace(rest)
+		var v string
+		if strings.HasPrefix(rest, `"`) {
+			v, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			v, rest, _ = strings.Cut(rest, " ")
+		}
+		attrs[strings.ToLower(strings.TrimSpace(k))] = v
+		s = rest
+	}
+	return attrs, name
+}
+
+func splitGroup(group string) []string {
+	// some playlists put multiple groups separated by ;, only the first one is used
+	group, _, _ = strings.Cut(group, ";")
+	groups := strings.Split(group, "/")
+	return slices.DeleteFunc(groups, func(g string) bool {
+		return strings.TrimSpace(g) == ""
+	})
+}
+
+func getFolder(root *[]*Item, folders map[string]*Item, groups []string) *Item {
+	var (
+		parent *Item
+		key    string
+	)
+	for _, group := range groups {
+		group = strings.TrimSpace(group)
+		key += "/" + group
+		folder, ok := folders[key]
+		if !ok {
+			folder = &Item{
+				Movie: model.MovieBase{
+					Name:     group,
+					IsFolder: true,
+				},
+			}
+			folders[key] = folder
+			if parent == nil {
+				*root = append(*root, folder)
+			} else {
+				parent.Children = append(parent.Children, folder)
+			}
+		}
+		parent = folder
+	}
+	return parent
+}
+
+func nameFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return u.Host
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// WriteM3U writes the items as an extended m3u playlist,
+// vendor movies and dynamic folders are skipped because they have no url
+func WriteM3U(w io.Writer, items []*Item) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("#EXTM3U\n"); err != nil {
+		return err
+	}
+	if err := writeM3UItems(bw, items, ""); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeM3UItems(w *bufio.Writer, items []*Item, group string) error {
+	for _, item := range items {
+		m := &item.Movie
+		if m.VendorInfo.Vendor != "" {
+			continue
+		}
+		if m.IsFolder {
+			name := strings.ReplaceAll(m.Name, "/", " ")
+			if group != "" {
+				name = group + "/" + name
+			}
+			if err := writeM3UItems(w, item.Children, name); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.URL == "" {
+			continue
+		}
+		if err := writeM3UItem(w, m, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeM3UItem(w *bufio.Writer, m *model.MovieBase, group string) error {
+	name := strings.NewReplacer("\r", " ", "\n", " ").Replace(m.Name)
+	if group != "" {
+		fmt.Fprintf(w, "#EXTINF:-1 group-title=\"%s\",%s\n", strings.ReplaceAll(group, `"`, "'"), name)
+	} else {
+		fmt.Fprintf(w, "#EXTINF:-1,%s\n", name)
+	}
+	other := make(map[string]string)
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		v := m.Headers[k]
+		if opt := vlcOption(k); opt != "" {
+			fmt.Fprintf(w, "#EXTVLCOPT:%s=%s\n", opt, v)
+		} else {
+			other[k] = v
+		}
+	}
+	if len(other) != 0 {
+		b, err := json.Marshal(other)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "#EXTHTTP:%s\n", b)
+	}
+	_, err := fmt.Fprintln(w, m.URL)
+	return err
+}
+
+func vlcOption(header string) string {
+	for opt, h := range vlcHeaderOptions {
+		if strings.EqualFold(h, header) {
+			return opt
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package playlist
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+func movieItem(name, url string, headers map[string]string) *Item {
+	return &Item{Movie: model.MovieBase{Name: name, URL: url, Headers: headers}}
+}
+
+func folderItem(name string, children ...*Item) *Item {
+	return &Item{Movie: model.MovieBase{Name: name, IsFolder: true}, Children: children}
+}
+
+// dumpItems makes the failures readable
+func dumpItems(items []*Item) string {
+	b, _ := json.MarshalIndent(items, "", "  ")
+	return string(b)
+}
+
+func TestParseExtInf(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantAttrs map[string]string
+		wantName  string
+	}{
+		{"-1,Channel", map[string]string{}, "Channel"},
+		{"123.4, Movie Name ", map[string]string{}, "Movie Name"},
+		{
+			`-1 tvg-id="cctv1" tvg-name="CCTV 1" group-title="News",CCTV-1`,
+			map[string]string{"tvg-id": "cctv1", "tvg-name": "CCTV 1", "group-title": "News"},
+			"CCTV-1",
+		},
+		{
+			`-1 tvg-id=cctv1 Group-Title=News,CCTV-1`,
+			map[string]string{"tvg-id": "cctv1", "group-title": "News"},
+			"CCTV-1",
+		},
+		{
+			`-1 group-title="Movies, Classic",Casablanca, 1942`,
+			map[string]string{"group-title": "Movies, Classic"},
+			"Casablanca, 1942",
+		},
+		{`-1 tvg-name="Only Attr"`, map[string]string{"tvg-name": "Only Attr"}, ""},
+		{`-1 tvg-logo="" tvg-name=x,`, map[string]string{"tvg-logo": "", "tvg-name": "x"}, ""},
+	}
+	for _, tt := range tests {
+		attrs, name := parseExtInf(tt.in)
+		if !reflect.DeepEqual(attrs, tt.wantAttrs) || name != tt.wantName {
+			t.Errorf("parseExtInf(%q) = %v, %q, want %v, %q", tt.in, attrs, name, tt.wantAttrs, tt.wantName)
+		}
+	}
+}
+
+func TestParseM3U(t *testing.T) {
+	tests := []struct {
+		name    string
+		m3u     string
+		want    []*Item
+		wantErr bool
+	}{
+		{
+			name: "plain",
+			m3u:  "https://example.com/a.mp4\r\n\r\nhttps://example.com/dir/b%20c.mkv?x=1\n",
+			want: []*Item{
+				movieItem("a.mp4", "https://example.com/a.mp4", nil),
+				movieItem("b c.mkv", "https://example.com/dir/b%20c.mkv?x=1", nil),
+			},
+		},
+		{
+			name: "extinf names",
+			m3u: "#EXTM3U\n" +
+				"#EXTINF:-1,First\nhttps://example.com/1.m3u8\n" +
+				"#EXTINF:-1 tvg-name=\"Second Name\",\nhttps://example.com/2.m3u8\n" +
+				"#EXTINF:-1,\nhttps://example.com/\n" +
+				"# a comment\nhttps://example.com/4.flv\n",
+			want: []*Item{
+				movieItem("First", "https://example.com/1.m3u8", nil),
+				movieItem("Second Name", "https://example.com/2.m3u8", nil),
+				movieItem("example.com", "https://example.com/", nil),
+				movieItem("4.flv", "https://example.com/4.flv", nil),
+			},
+		},
+		{
+			name: "header options",
+			m3u: "#EXTM3U\n" +
+				"#EXTINF:-1,With Headers\n" +
+				"#EXTVLCOPT:http-referrer=https://example.com/\n" +
+				"#EXTVLCOPT:http-user-agent=Mozilla/5.0 (X11)\n" +
+				"#EXTVLCOPT:network-caching=1000\n" +
+				"#EXTHTTP:{\"cookie\":\"a=b\",\"x-token\":\"t\"}\n" +
+				"https://example.com/h.m3u8\n" +
+				"#EXTINF:-1,No Headers\nhttps://example.com/n.m3u8\n",
+			want: []*Item{
+				movieItem("With Headers", "https://example.com/h.m3u8", map[string]string{
+					"Referer":    "https://example.com/",
+					"User-Agent": "Mozilla/5.0 (X11)",
+					"Cookie":     "a=b",
+					"X-Token":    "t",
+				}),
+				movieItem("No Headers", "https://example.com/n.m3u8", nil),
+			},
+		},
+		{
+			name: "groups",
+			m3u: "#EXTM3U\n" +
+				"#EXTINF:-1 group-title=\"TV/News\",CNN\nhttps://example.com/cnn.m3u8\n" +
+				"#EXTINF:-1,Loose\nhttps://example.com/loose.m3u8\n" +
+				"#EXTINF:-1 group-title=\"TV/Sports;Extra\",ESPN\nhttps://example.com/espn.m3u8\n" +
+				"#EXTINF:-1 group-title=\" TV / News \",BBC\nhttps://example.com/bbc.m3u8\n" +
+				"#EXTINF:-1,Radio One\n#EXTGRP:Radio\nhttps://example.com/r1.mp3\n" +
+				"#EXTINF:-1 group-title=\"Movies\",Film\n#EXTGRP:Ignored\nhttps://example.com/film.mp4\n",
+			want: []*Item{
+				folderItem("TV",
+					folderItem("News",
+						movieItem("CNN", "https://example.com/cnn.m3u8", nil),
+						movieItem("BBC", "https://example.com/bbc.m3u8", nil),
+					),
+					folderItem("Sports",
+						movieItem("ESPN", "https://example.com/espn.m3u8", nil),
+					),
+				),
+				movieItem("Loose", "https://example.com/loose.m3u8", nil),
+				folderItem("Radio",
+					movieItem("Radio One", "https://example.com/r1.mp3", nil),
+				),
+				folderItem("Movies",
+					movieItem("Film", "https://example.com/film.mp4", nil),
+				),
+			},
+		},
+		{
+			name:    "bad exthttp",
+			m3u:     "#EXTHTTP:{not json}\nhttps://example.com/a.mp4\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ParseM3U(strings.NewReader(tt.m3u))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(items, tt.want) {
+				t.Errorf("items = %s, want %s", dumpItems(items), dumpItems(tt.want))
+			}
+		})
+	}
+}
+
+func TestM3URoundTrip(t *testing.T) {
+	items := []*Item{
+		movieItem("Top", "https://example.com/top.mp4", map[string]string{
+			"Referer": "https://example.com/",
+			"X-Token": "t",
+		}),
+		folderItem("Shows",
+			folderItem(`Season "1"`,
+				movieItem("Episode 1", "https://example.com/s1e1.mkv", nil),
+			),
+		),
+		// vendor movies have no url and are skipped
+		{Movie: model.MovieBase{Name: "Emby", VendorInfo: model.VendorInfo{Vendor: model.VendorEmby}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteM3U(&buf, items); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseM3U(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Item{
+		items[0],
+		folderItem("Shows",
+			folderItem("Season '1'",
+				movieItem("Episode 1", "https://example.com/s1e1.mkv", nil),
+			),
+		),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %s, want %s", dumpItems(got), dumpItems(want))
+	}
+}
@@ -0,0 +1,81 @@
+package playlist
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/synctv-org/synctv/internal/model"
+)
+
+type Format string
+
+const (
+	FormatM3U  Format = "m3u"
+	FormatJSON Format = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown playlist format")
+
+// Item is a movie or a folder with its children
+type Item struct {
+	Movie    model.MovieBase `json:"base"`
+	Children []*Item         `json:"children,omitempty"`
+}
+
+// Count returns the number of items including all children
+func Count(items []*Item) int {
+	n := len(items)
+	for _, item := range items {
+		n += Count(item.Children)
+	}
+	return n
+}
+
+func ParseFormat(format string) (Format, error) {
+	switch format {
+	case "m3u", "m3u8":
+		return FormatM3U, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// Parse parses a playlist, if the format is empty it is detected from the content
+func Parse(r io.Reader, format Format) ([]*Item, error) {
+	if format == "" {
+		br := bufio.NewReader(r)
+		b, err := br.Peek(512)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+			format = FormatJSON
+		} else {
+			format = FormatM3U
+		}
+		r = br
+	}
+	switch format {
+	case FormatM3U:
+		return ParseM3U(r)
+	case FormatJSON:
+		return ParseJSON(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func Write(w io.Writer, format Format, items []*Item) error {
+	switch format {
+	case FormatM3U:
+		return WriteM3U(w, items)
+	case FormatJSON:
+		return WriteJSON(w, items)
+	default:
+		return ErrUnknownFormat
+	}
+}
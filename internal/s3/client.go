@@ -0,0 +1,263 @@
+// Package s3 is a minimal client of the s3 compatible object storages, only
+// listing, reading and presigning the objects are implemented.
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/go-uhc"
+)
+
+const defaultRegion = "us-east-1"
+
+type Client struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	// the bucket is in the path instead of the host, required by most of
+	// the self hosted storages like minio
+	pathStyle bool
+}
+
+type ClientConfig func(c *Client)
+
+func WithRegion(region string) ClientConfig {
+	return func(c *Client) {
+		if region != "" {
+			c.region = region
+		}
+	}
+}
+
+func WithCredentials(accessKey, secretKey string) ClientConfig {
+	return func(c *Client) {
+		c.accessKey = accessKey
+		c.secretKey = secretKey
+	}
+}
+
+func WithPathStyle(pathStyle bool) ClientConfig {
+	return func(c *Client) {
+		c.pathStyle = pathStyle
+	}
+}
+
+func NewClient(endpoint, bucket string, conf ...ClientConfig) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("endpoint is invalid")
+	}
+	if bucket == "" {
+		return nil, errors.New("bucket is empty")
+	}
+	c := &Client{
+		endpoint: &url.URL{
+			Scheme: u.Scheme,
+			Host:   u.Host,
+		},
+		region: defaultRegion,
+		bucket: bucket,
+	}
+	for _, f := range conf {
+		f(c)
+	}
+	return c, nil
+}
+
+// objectURL returns the url of the object, the key is escaped the same way as
+// it is signed
+func (c *Client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+	p := "/" + key
+	if c.pathStyle {
+		p = "/" + c.bucket + p
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	u.Path = p
+	u.RawPath = uriEncode(p, false)
+	return &u
+}
+
+type StatusError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("s3: bad status code: %d, %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("s3: bad status code: %d", e.StatusCode)
+}
+
+func newStatusError(resp *http.Response) error {
+	e := &StatusError{StatusCode: resp.StatusCode}
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body) == nil {
+		e.Code = body.Code
+		e.Message = body.Message
+	}
+	return e
+}
+
+func (c *Client) do(ctx context.Context, method string, u *url.URL, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", utils.UA)
+	if c.accessKey != "" {
+		c.signRequest(req, time.Now())
+	}
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp, nil
+}
+
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ContentType  string
+}
+
+type ListResp struct {
+	// the common prefixes, end with the delimiter
+	Prefixes []string
+	Objects  []*Object
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+// max objects of a listing, the rest are dropped
+const maxListObjects = 10000
+
+// List lists the objects and the common prefixes under the prefix with the
+// delimiter "/", all the pages are fetched
+func (c *Client) List(ctx context.Context, prefix string) (*ListResp, error) {
+	resp := &ListResp{}
+	token := ""
+	for {
+		u := c.objectURL("")
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("delimiter", "/")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(query)
+		r, err := c.do(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(r.Body).Decode(&result)
+		r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode list objects response error: %w", err)
+		}
+		for _, p := range result.CommonPrefixes {
+			resp.Prefixes = append(resp.Prefixes, p.Prefix)
+		}
+		for _, o := range result.Contents {
+			resp.Objects = append(resp.Objects, &Object{
+				Key:          o.Key,
+				Size:         o.Size,
+				LastModified: o.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" ||
+			len(resp.Prefixes)+len(resp.Objects) >= maxListObjects {
+			return resp, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Head returns the metadata of the object
+func (c *Client) Head(ctx context.Context, key string) (*Object, error) {
+	resp, err := c.do(ctx, http.MethodHead, c.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	o := &Object{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	o.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	o.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return o, nil
+}
+
+// Get reads the whole object, objects larger than maxSize are rejected
+func (c *Client) Get(ctx context.Context, key string, maxSize int64) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxSize {
+		return nil, errors.New("s3: object too large")
+	}
+	return b, nil
+}
+
+// PresignGet returns the url to download the object without the credentials,
+// the url expires after the duration, which is at most 7 days
+func (c *Client) PresignGet(key string, expires time.Duration) string {
+	u := c.objectURL(key)
+	if c.accessKey == "" {
+		return u.String()
+	}
+	return c.presign(http.MethodGet, u, time.Now(), expires)
+}
+
+// ObjectURL returns the unique url of the object, it can be used as the cache key
+func (c *Client) ObjectURL(key string) string {
+	return c.objectURL(key).String()
+}
@@ -0,0 +1,218 @@
+package s3_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/synctv-org/synctv/internal/s3"
+)
+
+// fakeBucket serves ListObjectsV2 and GetObject of the objects in the memory
+// with the path style urls, the listings are split into pages of pageSize
+type fakeBucket struct {
+	t        *testing.T
+	name     string
+	objects  map[string]string
+	pageSize int
+}
+
+type fakeListResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []fakeObject
+	CommonPrefixes        []fakePrefix
+}
+
+type fakeObject struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+type fakePrefix struct {
+	Prefix string
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=ak/") {
+		b.t.Errorf("unsigned request: %s %s", r.Method, r.URL)
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+b.name+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if key == "" && r.URL.Query().Get("list-type") == "2" {
+		b.list(w, r)
+		return
+	}
+	body, ok := b.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func (b *fakeBucket) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	var items []string
+	seen := map[string]bool{}
+	for key := range b.objects {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			key = prefix + rest[:i+len(delimiter)]
+		}
+		if !seen[key] {
+			seen[key] = true
+			items = append(items, key)
+		}
+	}
+	slices.Sort(items)
+
+	start, _ := strconv.Atoi(query.Get("continuation-token"))
+	end := min(start+b.pageSize, len(items))
+	result := fakeListResult{}
+	if end < len(items) {
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(end)
+	}
+	for _, key := range items[start:end] {
+		if strings.HasSuffix(key, delimiter) {
+			result.CommonPrefixes = append(result.CommonPrefixes, fakePrefix{Prefix: key})
+			continue
+		}
+		result.Contents = append(result.Contents, fakeObject{
+			Key:          key,
+			Size:         int64(len(b.objects[key])),
+			LastModified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func newFakeBucket(t *testing.T) *s3.Client {
+	t.Helper()
+	b := &fakeBucket{
+		t:    t,
+		name: "media",
+		objects: map[string]string{
+			"movies/a.mkv":          "aaaa",
+			"movies/a.en.srt":       "1\n00:00:01,000 --> 00:00:02,000\nhello\n",
+			"movies/b.mp4":          "bb",
+			"movies/series/e01.mkv": "e01",
+			"movies/series/e02.mkv": "e02",
+			"music/song.mp3":        "song",
+		},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(b)
+	t.Cleanup(srv.Close)
+	cli, err := s3.NewClient(srv.URL, b.name, s3.WithPathStyle(true), s3.WithCredentials("ak", "sk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestClientList(t *testing.T) {
+	cli := newFakeBucket(t)
+	tests := []struct {
+		name     string
+		prefix   string
+		prefixes []string
+		keys     []string
+	}{
+		{
+			name:     "root",
+			prefix:   "",
+			prefixes: []string{"movies/", "music/"},
+		},
+		{
+			name:     "pages",
+			prefix:   "movies/",
+			prefixes: []string{"movies/series/"},
+			keys:     []string{"movies/a.en.srt", "movies/a.mkv", "movies/b.mp4"},
+		},
+		{
+			name:   "stem",
+			prefix: "movies/a.",
+			keys:   []string{"movies/a.en.srt", "movies/a.mkv"},
+		},
+		{
+			name:   "missing",
+			prefix: "nothing/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := cli.List(context.Background(), tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(resp.Prefixes, tt.prefixes) {
+				t.Errorf("prefixes = %v, want %v", resp.Prefixes, tt.prefixes)
+			}
+			keys := make([]string, 0, len(resp.Objects))
+			for _, o := range resp.Objects {
+				keys = append(keys, o.Key)
+				if o.Size == 0 || o.LastModified.IsZero() {
+					t.Errorf("object %s has no size or modified time", o.Key)
+				}
+			}
+			if !slices.Equal(keys, tt.keys) && len(keys)+len(tt.keys) > 0 {
+				t.Errorf("keys = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	cli := newFakeBucket(t)
+	ctx := context.Background()
+
+	b, err := cli.Get(ctx, "movies/b.mp4", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "bb" {
+		t.Errorf("body = %q", b)
+	}
+
+	if _, err := cli.Get(ctx, "movies/a.mkv", 3); err == nil {
+		t.Error("object larger than max size is not rejected")
+	}
+
+	_, err = cli.Get(ctx, "movies/c.mkv", 16)
+	var statusErr *s3.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || statusErr.Code != "NoSuchKey" {
+		t.Errorf("err = %v, want NoSuchKey", err)
+	}
+
+	o, err := cli.Head(ctx, "music/song.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Size != 4 || o.ContentType != "application/octet-stream" {
+		t.Errorf("head = %+v", o)
+	}
+}
@@ -0,0 +1,158 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// sha256 of the empty body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+// uriEncode encodes the string as described by the aws signature version 4,
+// only the unreserved characters are kept
+func uriEncode(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexChars[c>>4])
+			b.WriteByte(hexChars[c&15])
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string(nil), query[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func (c *Client) scope(t time.Time) string {
+	return t.Format(shortDateFormat) + "/" + c.region + "/s3/aws4_request"
+}
+
+func (c *Client) signature(t time.Time, canonicalRequest string) string {
+	stringToSign := signAlgorithm + "\n" +
+		t.Format(amzDateFormat) + "\n" +
+		c.scope(t) + "\n" +
+		sha256Hex(canonicalRequest)
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), t.Format(shortDateFormat))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// signRequest signs the request with the authorization header, the host and
+// the x-amz-* headers are signed
+func (c *Client) signRequest(req *http.Request, t time.Time) {
+	t = t.UTC()
+	req.Header.Set("X-Amz-Date", t.Format(amzDateFormat))
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	}
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var ch strings.Builder
+	for _, k := range names {
+		ch.WriteString(k)
+		ch.WriteByte(':')
+		ch.WriteString(headers[k])
+		ch.WriteByte('\n')
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := req.Method + "\n" +
+		uriEncode(req.URL.Path, false) + "\n" +
+		canonicalQuery(req.URL.Query()) + "\n" +
+		ch.String() + "\n" +
+		signedHeaders + "\n" +
+		req.Header.Get("X-Amz-Content-Sha256")
+
+	req.Header.Set("Authorization", signAlgorithm+
+		" Credential="+c.accessKey+"/"+c.scope(t)+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+c.signature(t, canonicalRequest))
+}
+
+// presign returns the url with the query string authentication, only the
+// host header is signed so the url can be requested by anyone
+func (c *Client) presign(method string, u *url.URL, t time.Time, expires time.Duration) string {
+	t = t.UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", signAlgorithm)
+	query.Set("X-Amz-Credential", c.accessKey+"/"+c.scope(t))
+	query.Set("X-Amz-Date", t.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := method + "\n" +
+		uriEncode(u.Path, false) + "\n" +
+		canonicalQuery(query) + "\n" +
+		"host:" + u.Host + "\n\n" +
+		"host\n" +
+		unsignedPayload
+
+	query.Set("X-Amz-Signature", c.signature(t, canonicalRequest))
+	presigned := *u
+	presigned.RawQuery = canonicalQuery(query)
+	return presigned.String()
+}
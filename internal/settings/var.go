@@ -21,6 +21,43 @@ var (
 		}
 		return i, nil
 	}))
+	// days to keep room activity stats
+	RoomStatsRetention = NewInt64Setting("room_stats_retention", 30, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("room stats retention must be greater than 0")
+		}
+		return nil
+	}))
+	// days to keep deleted movies in the room trash before they are purged
+	MovieTrashRetention = NewInt64Setting("movie_trash_retention", 7, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("movie trash retention must be greater than 0")
+		}
+		return nil
+	}))
+	// days without anyone joining before a room is archived, 0 means never,
+	// rooms can override it with the archive_after_days setting, negative means never
+	RoomArchiveAfterDays = NewInt64Setting("room_archive_after_days", 0, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("room archive after days must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	// days after archival before a room is deleted, 0 means never
+	RoomArchiveDeleteAfterDays = NewInt64Setting("room_archive_delete_after_days", 30, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("room archive delete after days must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	// half life in hours of the room activity used by hot list ranking,
+	// 0 means rank by the current viewer count only
+	RoomHotDecayHalfLife = NewInt64Setting("room_hot_decay_half_life", 6, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("room hot decay half life must be greater than or equal to 0")
+		}
+		return nil
+	}))
 )
 
 func init() {
@@ -64,6 +101,123 @@ var (
 	ProxyCacheEnable  = NewBoolSetting("proxy_cache_enable", false, model.SettingGroupProxy)
 )
 
+var (
+	// slices read ahead after the one served from the proxy cache, 0 disables the prefetch
+	ProxyPrefetchSlices = NewInt64Setting("proxy_prefetch_slices", 2, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 || i > 16 {
+			return errors.New("proxy prefetch slices must be between 0 and 16")
+		}
+		return nil
+	}))
+	// hls segments read ahead after the one served, 0 disables the prefetch
+	ProxyPrefetchSegments = NewInt64Setting("proxy_prefetch_segments", 2, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 || i > 16 {
+			return errors.New("proxy prefetch segments must be between 0 and 16")
+		}
+		return nil
+	}))
+	ProxyPrefetchConcurrency = NewInt64Setting("proxy_prefetch_concurrency", 16, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("proxy prefetch concurrency must be greater than 0")
+		}
+		return nil
+	}))
+	ProxyPrefetchRoomConcurrency = NewInt64Setting("proxy_prefetch_room_concurrency", 2, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("proxy prefetch room concurrency must be greater than 0")
+		}
+		return nil
+	}))
+	// KB per second shared by all the prefetches, 0 means unlimited
+	ProxyPrefetchBandwidth = NewInt64Setting("proxy_prefetch_bandwidth", 0, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("proxy prefetch bandwidth must be greater than or equal to 0")
+		}
+		return nil
+	}))
+)
+
+var (
+	// minutes a signed media url stays valid, the clients refresh it through the current movie api
+	MediaTokenTTL = NewInt64Setting("media_token_ttl", 30, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 1 || i > 1440 {
+			return errors.New("media token ttl must be between 1 and 1440")
+		}
+		return nil
+	}))
+	// bind the signed media urls to the ip of the client requesting them
+	MediaTokenBindIP = NewBoolSetting("media_token_bind_ip", false, model.SettingGroupProxy)
+)
+
+var (
+	// KB per second proxied to a user or a room, 0 means unlimited,
+	// the admins can override them for a single user or room
+	UserProxyBandwidth = NewInt64Setting("user_proxy_bandwidth", 0, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("user proxy bandwidth must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	RoomProxyBandwidth = NewInt64Setting("room_proxy_bandwidth", 0, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("room proxy bandwidth must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	// MB proxied to a user or a room in a month, 0 means unlimited
+	UserMonthlyTrafficQuota = NewInt64Setting("user_monthly_traffic_quota", 0, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("user monthly traffic quota must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	RoomMonthlyTrafficQuota = NewInt64Setting("room_monthly_traffic_quota", 0, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("room monthly traffic quota must be greater than or equal to 0")
+		}
+		return nil
+	}))
+)
+
+var (
+	// hours between two link checks of a movie, 0 disables the background check
+	MovieHealthCheckInterval = NewInt64Setting("movie_health_check_interval", 24, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("movie health check interval must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	MovieHealthCheckConcurrency = NewInt64Setting("movie_health_check_concurrency", 4, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("movie health check concurrency must be greater than 0")
+		}
+		return nil
+	}))
+	// requests per second, 0 means unlimited
+	MovieHealthCheckRate = NewInt64Setting("movie_health_check_rate", 5, model.SettingGroupProxy, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("movie health check rate must be greater than or equal to 0")
+		}
+		return nil
+	}))
+)
+
+var (
+	// max size in KB of an uploaded subtitle
+	SubtitleMaxSize = NewInt64Setting("subtitle_max_size", 2048, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 1 {
+			return errors.New("subtitle max size must be greater than 0")
+		}
+		return nil
+	}))
+	MovieMaxSubtitles = NewInt64Setting("movie_max_subtitles", 10, model.SettingGroupRoom, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("movie max subtitles must be greater than or equal to 0")
+		}
+		return nil
+	}))
+)
+
 var (
 	// can watch live streams through the RTMP protocol (without authentication, insecure).
 	RtmpPlayer = NewBoolSetting("rtmp_player", false, model.SettingGroupRtmp)
@@ -73,6 +227,25 @@ var (
 	TSDisguisedAsPng = NewBoolSetting("ts_disguised_as_png", true, model.SettingGroupRtmp)
 )
 
+var (
+	// room admins can record the live movies to the disk
+	LiveRecord = NewBoolSetting("live_record", true, model.SettingGroupRtmp)
+	// max size in MB of a live recording, 0 means unlimited
+	LiveRecordMaxSize = NewInt64Setting("live_record_max_size", 4096, model.SettingGroupRtmp, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("live record max size must be greater than or equal to 0")
+		}
+		return nil
+	}))
+	// max minutes of a live recording, 0 means unlimited
+	LiveRecordMaxDuration = NewInt64Setting("live_record_max_duration", 360, model.SettingGroupRtmp, WithValidatorInt64(func(i int64) error {
+		if i < 0 {
+			return errors.New("live record max duration must be greater than or equal to 0")
+		}
+		return nil
+	}))
+)
+
 var DatabaseVersion = NewStringSetting("database_version", db.CurrentVersion, model.SettingGroupDatabase, WithBeforeSetString(func(ss StringSetting, s string) (string, error) {
 	return "", errors.New("not support change database version")
 }))
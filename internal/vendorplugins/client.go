@@ -0,0 +1,142 @@
+package vendorplugins
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	vendorpluginpb "github.com/synctv-org/synctv/proto/vendorplugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCClient struct {
+	client vendorpluginpb.VendorPluginClient
+}
+
+var (
+	_ Interface = (*GRPCClient)(nil)
+	_ Proxier   = (*GRPCClient)(nil)
+)
+
+func (c *GRPCClient) Name() string {
+	resp, err := c.client.Name(context.Background(), &vendorpluginpb.Empty{})
+	if err != nil {
+		return ""
+	}
+	return resp.Name
+}
+
+func (c *GRPCClient) ListDynamicMovie(ctx context.Context, req *ListReq) (*ListResp, error) {
+	resp, err := c.client.ListDynamicMovie(ctx, &vendorpluginpb.ListReq{
+		Path:    req.Path,
+		SubPath: req.SubPath,
+		Keyword: req.Keyword,
+		Page:    int64(req.Page),
+		Max:     int64(req.Max),
+		UserId:  req.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, len(resp.Items))
+	for i, v := range resp.Items {
+		items[i] = &Item{
+			Name:  v.Name,
+			Path:  v.Path,
+			IsDir: v.IsDir,
+		}
+	}
+	return &ListResp{
+		Total: resp.Total,
+		Items: items,
+	}, nil
+}
+
+func (c *GRPCClient) GenMovieInfo(ctx context.Context, req *MovieInfoReq) (*MovieInfo, error) {
+	resp, err := c.client.GenMovieInfo(ctx, &vendorpluginpb.MovieInfoReq{
+		Path:      req.Path,
+		SubPath:   req.SubPath,
+		UserId:    req.UserID,
+		UserAgent: req.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	subtitles := make([]*Subtitle, len(resp.Subtitles))
+	for i, v := range resp.Subtitles {
+		subtitles[i] = &Subtitle{
+			Name: v.Name,
+			URL:  v.Url,
+			Type: v.Type,
+		}
+	}
+	return &MovieInfo{
+		URL:       resp.Url,
+		Type:      resp.Type,
+		Headers:   resp.Headers,
+		Subtitles: subtitles,
+		Proxy:     resp.Proxy,
+	}, nil
+}
+
+// Proxy returns ErrProxyNotSupported when the plugin does not implement Proxier
+func (c *GRPCClient) Proxy(ctx context.Context, req *ProxyReq) (*ProxyResp, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.client.Proxy(ctx, &vendorpluginpb.ProxyReq{
+		Path:    req.Path,
+		SubPath: req.SubPath,
+		Type:    req.Type,
+		Id:      int64(req.ID),
+		Headers: req.Headers,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the first message carries the status and the headers
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		if status.Code(err) == codes.Unimplemented {
+			return nil, ErrProxyNotSupported
+		}
+		return nil, err
+	}
+	return &ProxyResp{
+		StatusCode: int(first.StatusCode),
+		Headers:    first.Headers,
+		Body: &proxyBody{
+			stream: stream,
+			buf:    first.Data,
+			cancel: cancel,
+		},
+	}, nil
+}
+
+type proxyBody struct {
+	stream vendorpluginpb.VendorPlugin_ProxyClient
+	buf    []byte
+	cancel context.CancelFunc
+}
+
+func (b *proxyBody) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		resp, err := b.stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		b.buf = resp.Data
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *proxyBody) Close() error {
+	b.cancel()
+	return nil
+}
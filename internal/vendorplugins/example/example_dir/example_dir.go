@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
+)
+
+// go build -o example_dir ./internal/vendorplugins/example/example_dir
+//
+// mv example_dir {data-dir}/plugins/vendor/example_dir
+//
+// config.yaml:
+//
+// vendor_plugins:
+//   - plugin_file: plugins/vendor/example_dir
+//     args: ["/path/to/videos"]
+//
+// ExampleDir serves the videos in the directory, the data is proxied by the
+// plugin itself
+type ExampleDir struct {
+	root string
+}
+
+var mediaExts = map[string]string{
+	".mp4": "mp4", ".mkv": "mkv", ".webm": "webm", ".flv": "flv", ".mp3": "mp3",
+}
+
+var subtitleExts = map[string]string{
+	".srt": "srt", ".vtt": "vtt", ".ass": "ass",
+}
+
+func (d *ExampleDir) Name() string {
+	return "example_dir"
+}
+
+// resolve returns the file of the path and the sub path, it is always in the root
+func (d *ExampleDir) resolve(p, subPath string) string {
+	rel := path.Clean("/" + path.Join(p, subPath))
+	return filepath.Join(d.root, filepath.FromSlash(rel))
+}
+
+func (d *ExampleDir) ListDynamicMovie(ctx context.Context, req *vendorplugins.ListReq) (*vendorplugins.ListResp, error) {
+	dir := d.resolve(req.Path, req.SubPath)
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	rel := path.Join("/", req.Path, req.SubPath)
+	var items []*vendorplugins.Item
+	for _, de := range des {
+		name := de.Name()
+		if _, ok := mediaExts[strings.ToLower(filepath.Ext(name))]; !ok && !de.IsDir() {
+			continue
+		}
+		if req.Keyword != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(req.Keyword)) {
+			continue
+		}
+		items = append(items, &vendorplugins.Item{
+			Name:  name,
+			Path:  path.Join(rel, name),
+			IsDir: de.IsDir(),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].IsDir != items[j].IsDir {
+			return items[i].IsDir
+		}
+		return items[i].Name < items[j].Name
+	})
+	total := int64(len(items))
+	if req.Max > 0 && req.Page > 0 {
+		start := min((req.Page-1)*req.Max, len(items))
+		items = items[start:min(start+req.Max, len(items))]
+	}
+	return &vendorplugins.ListResp{
+		Total: total,
+		Items: items,
+	}, nil
+}
+
+func (d *ExampleDir) subtitles(file string) []string {
+	stem := strings.TrimSuffix(file, filepath.Ext(file))
+	var subtitles []string
+	for ext := range subtitleExts {
+		if _, err := os.Stat(stem + ext); err == nil {
+			subtitles = append(subtitles, stem+ext)
+		}
+	}
+	sort.Strings(subtitles)
+	return subtitles
+}
+
+func (d *ExampleDir) GenMovieInfo(ctx context.Context, req *vendorplugins.MovieInfoReq) (*vendorplugins.MovieInfo, error) {
+	file := d.resolve(req.Path, req.SubPath)
+	t, ok := mediaExts[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		return nil, errors.New("not a media file")
+	}
+	info := &vendorplugins.MovieInfo{
+		Type: t,
+		// no url, the server proxies the movie through the plugin
+		Proxy: true,
+	}
+	for _, s := range d.subtitles(file) {
+		info.Subtitles = append(info.Subtitles, &vendorplugins.Subtitle{
+			Name: filepath.Base(s),
+			Type: subtitleExts[filepath.Ext(s)],
+		})
+	}
+	return info, nil
+}
+
+func (d *ExampleDir) Proxy(ctx context.Context, req *vendorplugins.ProxyReq) (*vendorplugins.ProxyResp, error) {
+	file := d.resolve(req.Path, req.SubPath)
+	if req.Type == "subtitle" {
+		subtitles := d.subtitles(file)
+		if req.ID < 0 || req.ID >= len(subtitles) {
+			return nil, errors.New("subtitle id out of range")
+		}
+		file = subtitles[req.ID]
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	size := fi.Size()
+	resp := &vendorplugins.ProxyResp{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Accept-Ranges":  "bytes",
+			"Content-Length": strconv.FormatInt(size, 10),
+		},
+		Body: f,
+	}
+	start, end, ok := parseRange(req.Headers["Range"], size)
+	if !ok {
+		return resp, nil
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	resp.StatusCode = http.StatusPartialContent
+	resp.Headers["Content-Length"] = strconv.FormatInt(end-start+1, 10)
+	resp.Headers["Content-Range"] = fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, end-start+1), f}
+	return resp, nil
+}
+
+// parseRange parses the single range like bytes=0-99 or bytes=100-
+func parseRange(r string, size int64) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(r, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	s, e, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end = size - 1
+	if e != "" {
+		if end, err = strconv.ParseInt(e, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: example_dir <dir>")
+		os.Exit(1)
+	}
+	pluginMap := map[string]plugin.Plugin{
+		"Vendor": &vendorplugins.VendorPlugin{Impl: &ExampleDir{root: os.Args[1]}},
+	}
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: vendorplugins.HandshakeConfig,
+		Plugins:         pluginMap,
+		GRPCServer:      plugin.DefaultGRPCServer,
+	})
+}
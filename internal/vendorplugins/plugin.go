@@ -0,0 +1,79 @@
+package vendorplugins
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/synctv-org/synctv/internal/sysnotify"
+	vendorpluginpb "github.com/synctv-org/synctv/proto/vendorplugin"
+	"google.golang.org/grpc"
+)
+
+// InitVendorPlugins starts the plugin binary and registers the vendor it serves
+func InitVendorPlugins(name string, arg []string, logger hclog.Logger) (Interface, error) {
+	client := NewVendorPlugin(name, arg, logger)
+	err := sysnotify.RegisterSysNotifyTask(0, sysnotify.NewSysNotifyTask("vendor plugin", sysnotify.NotifyTypeEXIT, func() error {
+		client.Kill()
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.Client()
+	if err != nil {
+		return nil, err
+	}
+	i, err := c.Dispense("Vendor")
+	if err != nil {
+		return nil, err
+	}
+	vendor, ok := i.(Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s not implement vendor plugin interface", name)
+	}
+	if vendor.Name() == "" {
+		return nil, fmt.Errorf("%s vendor name is empty", name)
+	}
+	RegisterVendor(vendor)
+	return vendor, nil
+}
+
+var HandshakeConfig = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "SYNCTV_VENDOR_PLUGIN",
+	MagicCookieValue: "vendor",
+}
+
+var pluginMap = map[string]plugin.Plugin{
+	"Vendor": &VendorPlugin{},
+}
+
+type VendorPlugin struct {
+	plugin.Plugin
+	Impl Interface
+}
+
+func (p *VendorPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	vendorpluginpb.RegisterVendorPluginServer(s, &GRPCServer{Impl: p.Impl})
+	return nil
+}
+
+func (p *VendorPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &GRPCClient{client: vendorpluginpb.NewVendorPluginClient(c)}, nil
+}
+
+func NewVendorPlugin(name string, arg []string, logger hclog.Logger) *plugin.Client {
+	return plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig: HandshakeConfig,
+		Plugins:         pluginMap,
+		Cmd:             exec.Command(name, arg...),
+		AllowedProtocols: []plugin.Protocol{
+			plugin.ProtocolGRPC,
+		},
+		Logger:  logger,
+		Managed: true,
+	})
+}
@@ -0,0 +1,87 @@
+package vendorplugins_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
+)
+
+func TestExamplePlugin(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is required to build the example plugin")
+	}
+	bin := filepath.Join(t.TempDir(), "example_dir")
+	out, err := exec.Command("go", "build", "-o", bin, "./example/example_dir").CombinedOutput()
+	if err != nil {
+		t.Fatalf("build example plugin error: %v\n%s", err, out)
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "season 1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "season 1", "episode 1.mp4"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "season 1", "episode 1.srt"), []byte("subtitle"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(plugin.CleanupClients)
+	vendor, err := vendorplugins.InitVendorPlugins(bin, []string{dir}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vendor.Name() != "example_dir" {
+		t.Fatalf("unexpected name: %s", vendor.Name())
+	}
+	if _, ok := vendorplugins.LoadVendor("example_dir"); !ok {
+		t.Fatal("vendor is not registered")
+	}
+
+	ctx := context.Background()
+	list, err := vendor.ListDynamicMovie(ctx, &vendorplugins.ListReq{
+		Path:    "/",
+		SubPath: "/season 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 1 || list.Items[0].Name != "episode 1.mp4" || list.Items[0].IsDir {
+		t.Fatalf("unexpected list: %+v", list.Items)
+	}
+
+	info, err := vendor.GenMovieInfo(ctx, &vendorplugins.MovieInfoReq{
+		Path: list.Items[0].Path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Proxy || info.Type != "mp4" || len(info.Subtitles) != 1 {
+		t.Fatalf("unexpected movie info: %+v", info)
+	}
+
+	resp, err := vendor.(vendorplugins.Proxier).Proxy(ctx, &vendorplugins.ProxyReq{
+		Path:    list.Items[0].Path,
+		Headers: map[string]string{"Range": "bytes=2-5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusPartialContent || string(b) != "2345" {
+		t.Fatalf("unexpected proxy response: %d %q", resp.StatusCode, b)
+	}
+}
@@ -0,0 +1,118 @@
+package vendorplugins
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	vendorpluginpb "github.com/synctv-org/synctv/proto/vendorplugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// size of the data of a proxy message
+const proxyChunkSize = 64 * 1024
+
+type GRPCServer struct {
+	vendorpluginpb.UnimplementedVendorPluginServer
+	Impl Interface
+}
+
+func (s *GRPCServer) Name(ctx context.Context, req *vendorpluginpb.Empty) (*vendorpluginpb.NameResp, error) {
+	return &vendorpluginpb.NameResp{Name: s.Impl.Name()}, nil
+}
+
+func (s *GRPCServer) ListDynamicMovie(ctx context.Context, req *vendorpluginpb.ListReq) (*vendorpluginpb.ListResp, error) {
+	resp, err := s.Impl.ListDynamicMovie(ctx, &ListReq{
+		Path:    req.Path,
+		SubPath: req.SubPath,
+		Keyword: req.Keyword,
+		Page:    int(req.Page),
+		Max:     int(req.Max),
+		UserID:  req.UserId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*vendorpluginpb.Item, len(resp.Items))
+	for i, v := range resp.Items {
+		items[i] = &vendorpluginpb.Item{
+			Name:  v.Name,
+			Path:  v.Path,
+			IsDir: v.IsDir,
+		}
+	}
+	return &vendorpluginpb.ListResp{
+		Total: resp.Total,
+		Items: items,
+	}, nil
+}
+
+func (s *GRPCServer) GenMovieInfo(ctx context.Context, req *vendorpluginpb.MovieInfoReq) (*vendorpluginpb.MovieInfoResp, error) {
+	info, err := s.Impl.GenMovieInfo(ctx, &MovieInfoReq{
+		Path:      req.Path,
+		SubPath:   req.SubPath,
+		UserID:    req.UserId,
+		UserAgent: req.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	subtitles := make([]*vendorpluginpb.Subtitle, len(info.Subtitles))
+	for i, v := range info.Subtitles {
+		subtitles[i] = &vendorpluginpb.Subtitle{
+			Name: v.Name,
+			Url:  v.URL,
+			Type: v.Type,
+		}
+	}
+	return &vendorpluginpb.MovieInfoResp{
+		Url:       info.URL,
+		Type:      info.Type,
+		Headers:   info.Headers,
+		Subtitles: subtitles,
+		Proxy:     info.Proxy,
+	}, nil
+}
+
+func (s *GRPCServer) Proxy(req *vendorpluginpb.ProxyReq, stream vendorpluginpb.VendorPlugin_ProxyServer) error {
+	p, ok := s.Impl.(Proxier)
+	if !ok {
+		return status.Error(codes.Unimplemented, ErrProxyNotSupported.Error())
+	}
+	resp, err := p.Proxy(stream.Context(), &ProxyReq{
+		Path:    req.Path,
+		SubPath: req.SubPath,
+		Type:    req.Type,
+		ID:      int(req.Id),
+		Headers: req.Headers,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
+	err = stream.Send(&vendorpluginpb.ProxyResp{
+		StatusCode: int32(resp.StatusCode),
+		Headers:    resp.Headers,
+	})
+	if err != nil || resp.Body == nil {
+		return err
+	}
+	buf := make([]byte, proxyChunkSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&vendorpluginpb.ProxyResp{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
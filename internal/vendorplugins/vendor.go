@@ -0,0 +1,111 @@
+package vendorplugins
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/zijiren233/gencontainer/rwmap"
+)
+
+type ListReq struct {
+	// the path of the folder movie
+	Path string
+	// the sub folder to list, the names of the listed items joined by slashes
+	SubPath string
+	Keyword string
+	Page    int
+	Max     int
+	// the id of the synctv user who lists the folder
+	UserID string
+}
+
+type Item struct {
+	Name string
+	// the path passed back to the plugin when the item is played or listed
+	Path  string
+	IsDir bool
+}
+
+type ListResp struct {
+	Total int64
+	Items []*Item
+}
+
+type MovieInfoReq struct {
+	Path string
+	// the sub path of the movie in the folder, the names of the listed items
+	// joined by slashes, like /season 1/episode 1.mkv
+	SubPath   string
+	UserID    string
+	UserAgent string
+}
+
+type Subtitle struct {
+	Name string
+	URL  string
+	Type string
+}
+
+type MovieInfo struct {
+	URL       string
+	Type      string
+	Headers   map[string]string
+	Subtitles []*Subtitle
+	// the url can only be requested by the server, like the headers carry
+	// the credentials, so the movie is always proxied
+	Proxy bool
+}
+
+type ProxyReq struct {
+	Path    string
+	SubPath string
+	// empty for the movie, subtitle for the subtitle with the id
+	Type string
+	ID   int
+	// the request headers, like Range
+	Headers map[string]string
+}
+
+type ProxyResp struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       io.ReadCloser
+}
+
+type Interface interface {
+	Name() string
+	ListDynamicMovie(ctx context.Context, req *ListReq) (*ListResp, error)
+	GenMovieInfo(ctx context.Context, req *MovieInfoReq) (*MovieInfo, error)
+}
+
+// Proxier is implemented by the plugins which serve the media data by themselves,
+// the urls of the movie info are proxied by the server otherwise
+type Proxier interface {
+	Proxy(ctx context.Context, req *ProxyReq) (*ProxyResp, error)
+}
+
+var ErrProxyNotSupported = errors.New("vendor plugin not support proxy")
+
+var allVendors rwmap.RWMap[string, Interface]
+
+func RegisterVendor(vendors ...Interface) {
+	for _, v := range vendors {
+		allVendors.Store(v.Name(), v)
+	}
+}
+
+func LoadVendor(name string) (Interface, bool) {
+	return allVendors.Load(name)
+}
+
+func AllVendors() []string {
+	names := make([]string, 0, allVendors.Len())
+	allVendors.Range(func(key string, value Interface) bool {
+		names = append(names, key)
+		return true
+	})
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,237 @@
+// Package webdav is a minimal WebDAV client, only PROPFIND listing and GET with
+// basic auth are implemented.
+package webdav
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/go-uhc"
+)
+
+type Client struct {
+	// the url of the root collection, the paths are relative to it
+	base     *url.URL
+	username string
+	password string
+}
+
+func NewClient(host, username, password string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("host is invalid")
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	return &Client{
+		base:     u,
+		username: username,
+		password: password,
+	}, nil
+}
+
+// Headers returns the auth headers of the requests to the server
+func (c *Client) Headers() map[string]string {
+	if c.username == "" && c.password == "" {
+		return nil
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(c.username + ":" + c.password))
+	return map[string]string{
+		"Authorization": "Basic " + auth,
+	}
+}
+
+// URL returns the url of the file, the path is cleaned so it can't leave the root
+func (c *Client) URL(p string) string {
+	u := *c.base
+	u.Path = c.base.Path + path.Clean("/"+p)
+	return u.String()
+}
+
+type File struct {
+	Name        string
+	Path        string
+	IsDir       bool
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webdav: bad status code: %d", e.StatusCode)
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+	<d:prop>
+		<d:resourcetype/>
+		<d:getcontentlength/>
+		<d:getlastmodified/>
+		<d:getcontenttype/>
+	</d:prop>
+</d:propfind>`
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+				ContentType   string `xml:"getcontenttype"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func (c *Client) propfind(ctx context.Context, p string, depth string) ([]*File, error) {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", c.URL(p), bytes.NewReader([]byte(propfindBody)))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Headers() {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Depth", depth)
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("User-Agent", utils.UA)
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("decode propfind response error: %w", err)
+	}
+
+	files := make([]*File, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		filePath, err := c.hrefToPath(r.Href)
+		if err != nil {
+			continue
+		}
+		f := &File{
+			Name: path.Base(filePath),
+			Path: filePath,
+		}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			f.IsDir = f.IsDir || ps.Prop.ResourceType.Collection != nil
+			if ps.Prop.ContentLength != "" {
+				f.Size, _ = strconv.ParseInt(ps.Prop.ContentLength, 10, 64)
+			}
+			if ps.Prop.LastModified != "" {
+				f.ModTime, _ = http.ParseTime(ps.Prop.LastModified)
+			}
+			if ps.Prop.ContentType != "" {
+				f.ContentType = ps.Prop.ContentType
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// hrefToPath converts the href of the response to the path relative to the root
+func (c *Client) hrefToPath(href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimRight(u.Path, "/")
+	if !strings.HasPrefix(p+"/", c.base.Path+"/") {
+		return "", errors.New("href is outside the root")
+	}
+	return path.Clean("/" + strings.TrimPrefix(p, c.base.Path)), nil
+}
+
+// Stat returns the file of the path
+func (c *Client) Stat(ctx context.Context, p string) (*File, error) {
+	files, err := c.propfind(ctx, p, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("webdav: file not found")
+	}
+	return files[0], nil
+}
+
+// ReadDir returns the children of the collection
+func (c *Client) ReadDir(ctx context.Context, p string) ([]*File, error) {
+	files, err := c.propfind(ctx, p, "1")
+	if err != nil {
+		return nil, err
+	}
+	self := path.Clean("/" + p)
+	children := files[:0]
+	for _, f := range files {
+		if f.Path == self {
+			if !f.IsDir {
+				return nil, fmt.Errorf("webdav: %s is not a directory", self)
+			}
+			continue
+		}
+		children = append(children, f)
+	}
+	return children, nil
+}
+
+// ReadFile reads the whole file, files larger than maxSize are rejected
+func (c *Client) ReadFile(ctx context.Context, p string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL(p), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Headers() {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("User-Agent", utils.UA)
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxSize {
+		return nil, errors.New("webdav: file too large")
+	}
+	return b, nil
+}
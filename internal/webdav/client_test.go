@@ -0,0 +1,153 @@
+package webdav_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/webdav"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+// newTestServer serves the files of a temp dir under /dav with basic auth
+func newTestServer(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &xwebdav.Handler{
+		Prefix:     "/dav",
+		FileSystem: xwebdav.Dir(root),
+		LockSystem: xwebdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/dav/"
+}
+
+func TestClient(t *testing.T) {
+	host := newTestServer(t, map[string]string{
+		"movies/a b.mkv":     "0123456789",
+		"movies/a b.en.srt":  "subtitle",
+		"movies/sub/c.mp4":   "c",
+		"movies/.hidden.mp4": "h",
+	})
+	cli, err := webdav.NewClient(host, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	files, err := cli.ReadDir(ctx, "/movies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+		switch f.Name {
+		case "a b.mkv":
+			if f.Path != "/movies/a b.mkv" || f.IsDir || f.Size != 10 || f.ModTime.IsZero() {
+				t.Errorf("file = %+v", f)
+			}
+		case "sub":
+			if f.Path != "/movies/sub" || !f.IsDir {
+				t.Errorf("dir = %+v", f)
+			}
+		}
+	}
+	slices.Sort(names)
+	if want := []string{".hidden.mp4", "a b.en.srt", "a b.mkv", "sub"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+
+	if _, err := cli.ReadDir(ctx, "/movies/a b.mkv"); err == nil {
+		t.Error("read dir of a file is not rejected")
+	}
+
+	f, err := cli.Stat(ctx, "/movies/sub/c.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "c.mp4" || f.Size != 1 {
+		t.Errorf("stat = %+v", f)
+	}
+
+	b, err := cli.ReadFile(ctx, "/movies/a b.en.srt", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "subtitle" {
+		t.Errorf("read file = %q", b)
+	}
+	if _, err := cli.ReadFile(ctx, "/movies/a b.mkv", 4); err == nil {
+		t.Error("file larger than max size is not rejected")
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	host := newTestServer(t, map[string]string{"a.mkv": "a"})
+	cli, err := webdav.NewClient(host, "alice", "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cli.ReadDir(context.Background(), "/")
+	var statusErr *webdav.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("err = %v, want 401", err)
+	}
+
+	// YWxpY2U6c2VjcmV0 is alice:secret
+	cli, err = webdav.NewClient(host, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cli.Headers()["Authorization"]; got != "Basic YWxpY2U6c2VjcmV0" {
+		t.Errorf("authorization = %q", got)
+	}
+	anonymous, err := webdav.NewClient(host, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anonymous.Headers() != nil {
+		t.Error("anonymous client sends the auth header")
+	}
+}
+
+func TestClientURL(t *testing.T) {
+	cli, err := webdav.NewClient("https://example.com/dav/?a=b", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/movies/a b.mkv", "https://example.com/dav/movies/a%20b.mkv"},
+		{"movies/../../../etc/passwd", "https://example.com/dav/etc/passwd"},
+		{"", "https://example.com/dav/"},
+	}
+	for _, tt := range tests {
+		if got := cli.URL(tt.path); got != tt.want {
+			t.Errorf("URL(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.2
+// source: proto/vendorplugin/plugin.proto
+
+package vendorpluginpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VendorPlugin_Name_FullMethodName             = "/vendorplugin.VendorPlugin/Name"
+	VendorPlugin_ListDynamicMovie_FullMethodName = "/vendorplugin.VendorPlugin/ListDynamicMovie"
+	VendorPlugin_GenMovieInfo_FullMethodName     = "/vendorplugin.VendorPlugin/GenMovieInfo"
+	VendorPlugin_Proxy_FullMethodName            = "/vendorplugin.VendorPlugin/Proxy"
+)
+
+// VendorPluginClient is the client API for VendorPlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VendorPluginClient interface {
+	Name(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NameResp, error)
+	ListDynamicMovie(ctx context.Context, in *ListReq, opts ...grpc.CallOption) (*ListResp, error)
+	GenMovieInfo(ctx context.Context, in *MovieInfoReq, opts ...grpc.CallOption) (*MovieInfoResp, error)
+	Proxy(ctx context.Context, in *ProxyReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProxyResp], error)
+}
+
+type vendorPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVendorPluginClient(cc grpc.ClientConnInterface) VendorPluginClient {
+	return &vendorPluginClient{cc}
+}
+
+func (c *vendorPluginClient) Name(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NameResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NameResp)
+	err := c.cc.Invoke(ctx, VendorPlugin_Name_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendorPluginClient) ListDynamicMovie(ctx context.Context, in *ListReq, opts ...grpc.CallOption) (*ListResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResp)
+	err := c.cc.Invoke(ctx, VendorPlugin_ListDynamicMovie_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendorPluginClient) GenMovieInfo(ctx context.Context, in *MovieInfoReq, opts ...grpc.CallOption) (*MovieInfoResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MovieInfoResp)
+	err := c.cc.Invoke(ctx, VendorPlugin_GenMovieInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vendorPluginClient) Proxy(ctx context.Context, in *ProxyReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProxyResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VendorPlugin_ServiceDesc.Streams[0], VendorPlugin_Proxy_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ProxyReq, ProxyResp]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VendorPlugin_ProxyClient = grpc.ServerStreamingClient[ProxyResp]
+
+// VendorPluginServer is the server API for VendorPlugin service.
+// All implementations must embed UnimplementedVendorPluginServer
+// for forward compatibility.
+type VendorPluginServer interface {
+	Name(context.Context, *Empty) (*NameResp, error)
+	ListDynamicMovie(context.Context, *ListReq) (*ListResp, error)
+	GenMovieInfo(context.Context, *MovieInfoReq) (*MovieInfoResp, error)
+	Proxy(*ProxyReq, grpc.ServerStreamingServer[ProxyResp]) error
+	mustEmbedUnimplementedVendorPluginServer()
+}
+
+// UnimplementedVendorPluginServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVendorPluginServer struct{}
+
+func (UnimplementedVendorPluginServer) Name(context.Context, *Empty) (*NameResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Name not implemented")
+}
+func (UnimplementedVendorPluginServer) ListDynamicMovie(context.Context, *ListReq) (*ListResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDynamicMovie not implemented")
+}
+func (UnimplementedVendorPluginServer) GenMovieInfo(context.Context, *MovieInfoReq) (*MovieInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenMovieInfo not implemented")
+}
+func (UnimplementedVendorPluginServer) Proxy(*ProxyReq, grpc.ServerStreamingServer[ProxyResp]) error {
+	return status.Errorf(codes.Unimplemented, "method Proxy not implemented")
+}
+func (UnimplementedVendorPluginServer) mustEmbedUnimplementedVendorPluginServer() {}
+func (UnimplementedVendorPluginServer) testEmbeddedByValue()                      {}
+
+// UnsafeVendorPluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VendorPluginServer will
+// result in compilation errors.
+type UnsafeVendorPluginServer interface {
+	mustEmbedUnimplementedVendorPluginServer()
+}
+
+func RegisterVendorPluginServer(s grpc.ServiceRegistrar, srv VendorPluginServer) {
+	// If the following call pancis, it indicates UnimplementedVendorPluginServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VendorPlugin_ServiceDesc, srv)
+}
+
+func _VendorPlugin_Name_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendorPluginServer).Name(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VendorPlugin_Name_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendorPluginServer).Name(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendorPlugin_ListDynamicMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendorPluginServer).ListDynamicMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VendorPlugin_ListDynamicMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendorPluginServer).ListDynamicMovie(ctx, req.(*ListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendorPlugin_GenMovieInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MovieInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VendorPluginServer).GenMovieInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VendorPlugin_GenMovieInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VendorPluginServer).GenMovieInfo(ctx, req.(*MovieInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VendorPlugin_Proxy_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProxyReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VendorPluginServer).Proxy(m, &grpc.GenericServerStream[ProxyReq, ProxyResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VendorPlugin_ProxyServer = grpc.ServerStreamingServer[ProxyResp]
+
+// VendorPlugin_ServiceDesc is the grpc.ServiceDesc for VendorPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VendorPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vendorplugin.VendorPlugin",
+	HandlerType: (*VendorPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Name",
+			Handler:    _VendorPlugin_Name_Handler,
+		},
+		{
+			MethodName: "ListDynamicMovie",
+			Handler:    _VendorPlugin_ListDynamicMovie_Handler,
+		},
+		{
+			MethodName: "GenMovieInfo",
+			Handler:    _VendorPlugin_GenMovieInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Proxy",
+			Handler:       _VendorPlugin_Proxy_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/vendorplugin/plugin.proto",
+}
@@ -1,3 +1,4 @@
 #!/bin/bash
 protoc --go_out=./proto/message ./proto/message/*.proto
 protoc --go_out=./proto/provider --go-grpc_out=./proto/provider ./proto/provider/*.proto
+protoc --go_out=./proto/vendorplugin --go-grpc_out=./proto/vendorplugin ./proto/vendorplugin/*.proto
@@ -460,6 +460,8 @@ func AdminGetRooms(ctx *gin.Context) {
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusPending))
 	case "banned":
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusBanned))
+	case "archived":
+		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusArchived))
 	}
 
 	if keyword := ctx.Query("keyword"); keyword != "" {
@@ -557,6 +559,8 @@ func AdminGetUserRooms(ctx *gin.Context) {
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusPending))
 	case "banned":
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusBanned))
+	case "archived":
+		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusArchived))
 	}
 
 	if keyword := ctx.Query("keyword"); keyword != "" {
@@ -647,6 +651,8 @@ func AdminGetUserJoinedRooms(ctx *gin.Context) {
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusPending))
 	case "banned":
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusBanned))
+	case "archived":
+		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusArchived))
 	}
 
 	if keyword := ctx.Query("keyword"); keyword != "" {
@@ -820,6 +826,25 @@ func AdminUnBanRoom(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+func AdminRestoreArchivedRoom(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	req := model.RoomIDReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	err := op.RestoreArchivedRoom(req.ID)
+	if err != nil {
+		log.Errorf("restore archived room error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 func AdminAddUser(ctx *gin.Context) {
 	user := ctx.MustGet("user").(*op.UserEntry).Value()
 	log := ctx.MustGet("log").(*logrus.Entry)
@@ -6,6 +6,11 @@ import (
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendoralist"
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendorbilibili"
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendoremby"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorjellyfin"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorlocal"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorplugin"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendors3"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorwebdav"
 	"github.com/synctv-org/synctv/server/middlewares"
 	"github.com/synctv-org/synctv/utils"
 )
@@ -41,8 +46,9 @@ func Init(e *gin.Engine) {
 		{
 			movie := room.Group("/movie")
 			needAuthMovie := needAuthRoom.Group("/movie")
+			needAuthMedia := room.Group("/movie", middlewares.AuthMediaMiddleware)
 
-			initMovie(movie, needAuthMovie)
+			initMovie(movie, needAuthMovie, needAuthMedia)
 		}
 	}
 
@@ -84,6 +90,32 @@ func initAdmin(admin *gin.RouterGroup, root *gin.RouterGroup) {
 
 		admin.POST("/vendors/disable", AdminDisableVendorBackends)
 
+		admin.GET("/vendors/local/roots", AdminGetLocalRoots)
+
+		admin.POST("/vendors/local/roots/add", AdminAddLocalRoot)
+
+		admin.POST("/vendors/local/roots/update", AdminUpdateLocalRoot)
+
+		admin.POST("/vendors/local/roots/delete", AdminDeleteLocalRoot)
+
+		admin.GET("/vendors/s3/binds", vendors3.AdminBinds)
+
+		admin.POST("/vendors/s3/bind", vendors3.AdminBind)
+
+		admin.POST("/vendors/s3/unbind", vendors3.AdminUnbind)
+
+		admin.GET("/proxy/cache", AdminProxyCacheStats)
+
+		admin.POST("/proxy/cache/purge", AdminPurgeProxyCache)
+
+		admin.GET("/proxy/prefetch", AdminProxyPrefetchStats)
+
+		admin.GET("/traffic/usage", AdminTrafficUsage)
+
+		admin.GET("/traffic/limits", AdminTrafficLimits)
+
+		admin.POST("/traffic/limit", AdminSetTrafficLimit)
+
 		{
 			user := admin.Group("/user")
 
@@ -122,6 +154,8 @@ func initAdmin(admin *gin.RouterGroup, root *gin.RouterGroup) {
 
 			room.POST("/unban", AdminUnBanRoom)
 
+			room.POST("/restore", AdminRestoreArchivedRoom)
+
 			room.POST("/delete", AdminDeleteRoom)
 
 			room.GET("/members", AdminGetRoomMembers)
@@ -142,6 +176,8 @@ func initRoom(room *gin.RouterGroup, needAuthUser *gin.RouterGroup, needAuthRoom
 
 	room.GET("/list", RoomList)
 
+	room.GET("/tags", RoomTags)
+
 	needAuthUser.POST("/create", CreateRoom)
 
 	needAuthUser.POST("/login", LoginRoom)
@@ -152,6 +188,8 @@ func initRoom(room *gin.RouterGroup, needAuthUser *gin.RouterGroup, needAuthRoom
 
 	needAuthRoom.GET("/info", RoomInfo)
 
+	needAuthRoom.GET("/stats", RoomStats)
+
 	needAuthRoom.GET("/ws", NewWebSocketHandler(utils.NewWebSocketServer()))
 
 	needAuthWithoutGuestRoom.GET("/settings", RoomPiblicSettings)
@@ -182,6 +220,18 @@ func initRoom(room *gin.RouterGroup, needAuthUser *gin.RouterGroup, needAuthRoom
 
 		needAuthRoomAdmin.POST("/members/unban", RoomAdminUnbanMember)
 
+		needAuthRoomAdmin.GET("/vendor/binds", RoomVendorBinds)
+
+		needAuthRoomAdmin.POST("/vendor/bind", RoomBindVendor)
+
+		needAuthRoomAdmin.POST("/vendor/unbind", RoomUnbindVendor)
+
+		needAuthRoomAdmin.GET("/live/records", RoomLiveRecords)
+
+		needAuthRoomAdmin.POST("/live/record/start", RoomStartLiveRecord)
+
+		needAuthRoomAdmin.POST("/live/record/stop", RoomStopLiveRecord)
+
 		needAuthRoomCreator.POST("/members/member", RoomSetMember)
 
 		needAuthRoomCreator.POST("/members/member/permissions", RoomSetMemberPermissions)
@@ -192,7 +242,7 @@ func initRoom(room *gin.RouterGroup, needAuthUser *gin.RouterGroup, needAuthRoom
 	}
 }
 
-func initMovie(movie *gin.RouterGroup, needAuthMovie *gin.RouterGroup) {
+func initMovie(movie *gin.RouterGroup, needAuthMovie *gin.RouterGroup, needAuthMedia *gin.RouterGroup) {
 	// needAuthMovie.GET("/list", MovieList)
 
 	needAuthMovie.GET("/current", CurrentMovie)
@@ -201,6 +251,8 @@ func initMovie(movie *gin.RouterGroup, needAuthMovie *gin.RouterGroup) {
 
 	needAuthMovie.POST("/current", ChangeCurrentMovie)
 
+	needAuthMovie.POST("/current/subtitle", ChangeCurrentSubtitle)
+
 	needAuthMovie.POST("/push", PushMovie)
 
 	needAuthMovie.POST("/pushs", PushMovies)
@@ -209,25 +261,54 @@ func initMovie(movie *gin.RouterGroup, needAuthMovie *gin.RouterGroup) {
 
 	needAuthMovie.POST("/swap", SwapMovie)
 
+	needAuthMovie.POST("/move", MoveMovies)
+
+	needAuthMovie.POST("/reorder", ReorderMovies)
+
 	needAuthMovie.POST("/delete", DelMovie)
 
 	needAuthMovie.POST("/clear", ClearMovies)
 
-	needAuthMovie.HEAD("/proxy/:movieId", ProxyMovie)
+	needAuthMovie.POST("/health/check", CheckMoviesHealth)
+
+	needAuthMovie.GET("/trash", TrashedMovies)
+
+	needAuthMovie.POST("/trash/restore", RestoreMovies)
+
+	needAuthMovie.POST("/trash/delete", DeleteTrashedMovies)
+
+	needAuthMovie.GET("/subtitles", MovieSubtitles)
+
+	needAuthMovie.POST("/subtitle/upload", UploadMovieSubtitle)
+
+	needAuthMovie.POST("/subtitle/delete", DeleteMovieSubtitle)
+
+	needAuthMedia.GET("/subtitle/:subtitleId", ServeMovieSubtitle)
+
+	needAuthMovie.POST("/import", ImportMovies)
+
+	needAuthMovie.POST("/import/vendor", ImportVendorMovies)
+
+	needAuthMovie.GET("/export", ExportMovies)
+
+	needAuthMedia.HEAD("/proxy/:movieId", ProxyMovie)
 
-	needAuthMovie.GET("/proxy/:movieId", ProxyMovie)
+	needAuthMedia.GET("/proxy/:movieId", ProxyMovie)
 
-	needAuthMovie.GET("/proxy/:movieId/m3u8/:targetToken", ServeM3u8)
+	needAuthMedia.GET("/proxy/:movieId/m3u8/:targetToken", ServeM3u8)
+
+	needAuthMedia.GET("/proxy/:movieId/mpd/:targetToken", ServeMpd)
 
 	{
 		live := movie.Group("/live")
 		needAuthLive := needAuthMovie.Group("/live")
+		needAuthMediaLive := needAuthMedia.Group("/live")
 
 		needAuthLive.POST("/publishKey", NewPublishKey)
 
-		needAuthLive.GET("/flv/:movieId", JoinFlvLive)
+		needAuthMediaLive.GET("/flv/:movieId", JoinFlvLive)
 
-		needAuthLive.GET("/hls/list/:movieId", JoinHlsLive)
+		needAuthMediaLive.GET("/hls/list/:movieId", JoinHlsLive)
 
 		live.GET("/hls/data/:roomId/:movieId/:dataId", ServeHlsLive)
 	}
@@ -258,6 +339,12 @@ func initUser(user *gin.RouterGroup, needAuthUser *gin.RouterGroup) {
 
 	needAuthUser.GET("/rooms/joined", UserJoinedRooms)
 
+	needAuthUser.GET("/history", UserWatchHistory)
+
+	needAuthUser.POST("/history/delete", DeleteUserWatchHistory)
+
+	needAuthUser.POST("/history/clear", ClearUserWatchHistory)
+
 	needAuthUser.POST("/username", SetUsername)
 
 	needAuthUser.POST("/password", SetUserPassword)
@@ -335,4 +422,58 @@ func initVendor(vendor *gin.RouterGroup) {
 
 		emby.GET("/binds", vendoremby.Binds)
 	}
+
+	{
+		jellyfin := vendor.Group("/jellyfin")
+
+		jellyfin.POST("/login", vendorjellyfin.Login)
+
+		jellyfin.POST("/logout", vendorjellyfin.Logout)
+
+		jellyfin.POST("/list", vendorjellyfin.List)
+
+		jellyfin.GET("/me", vendorjellyfin.Me)
+
+		jellyfin.GET("/binds", vendorjellyfin.Binds)
+	}
+
+	{
+		webdav := vendor.Group("/webdav")
+
+		webdav.POST("/login", vendorwebdav.Login)
+
+		webdav.POST("/logout", vendorwebdav.Logout)
+
+		webdav.POST("/list", vendorwebdav.List)
+
+		webdav.GET("/binds", vendorwebdav.Binds)
+	}
+
+	{
+		s3 := vendor.Group("/s3")
+
+		s3.POST("/login", vendors3.Login)
+
+		s3.POST("/logout", vendors3.Logout)
+
+		s3.POST("/list", vendors3.List)
+
+		s3.GET("/binds", vendors3.Binds)
+	}
+
+	{
+		plugin := vendor.Group("/plugin")
+
+		plugin.GET("/plugins", vendorplugin.Plugins)
+
+		plugin.POST("/list", vendorplugin.List)
+	}
+
+	{
+		local := vendor.Group("/local")
+
+		local.GET("/roots", vendorlocal.Roots)
+
+		local.POST("/list", vendorlocal.List)
+	}
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+func RoomLiveRecords(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	page, pageSize, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		log.Errorf("get page and max error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	scopes := []func(db *gorm.DB) *gorm.DB{}
+	if movieID := ctx.Query("movieId"); movieID != "" {
+		scopes = append(scopes, db.WhereLiveRecordSourceMovieID(movieID))
+	}
+	if status := ctx.Query("status"); status != "" {
+		scopes = append(scopes, db.WhereLiveRecordStatus(dbModel.LiveRecordStatus(status)))
+	}
+
+	total, err := db.GetLiveRecordsCount(room.ID, scopes...)
+	if err != nil {
+		log.Errorf("get live records count error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	records, err := db.GetLiveRecords(room.ID, append(scopes, db.OrderByDesc("created_at"), db.Paginate(page, pageSize))...)
+	if err != nil {
+		log.Errorf("get live records error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	// the size and the duration of the running recordings are only counted in the memory
+	for i, r := range records {
+		if r.Status != dbModel.LiveRecordStatusRecording {
+			continue
+		}
+		if running := room.LiveRecording(r.SourceMovieID); running != nil && running.ID == r.ID {
+			records[i] = running
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
+		"total": total,
+		"list":  records,
+	}))
+}
+
+func RoomStartLiveRecord(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	req := model.IDReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("start live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	record, err := room.StartLiveRecord(req.ID, user.ID)
+	if err != nil {
+		log.Errorf("start live record error: %v", err)
+		if errors.Is(err, op.ErrLiveRecording) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, model.NewAPIErrorResp(err))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(record))
+}
+
+// RoomStopLiveRecord returns the finished record, its movieId is the movie added for the recording
+func RoomStopLiveRecord(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	req := model.IDReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("stop live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	record, err := room.StopLiveRecord(req.ID)
+	if err != nil {
+		log.Errorf("stop live record error: %v", err)
+		if errors.Is(err, op.ErrLiveNotRecording) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(record))
+}
+
+// serveLiveRecord serves the recording file of the movie, range requests are handled by ServeContent
+func serveLiveRecord(ctx *gin.Context, log *logrus.Entry, room *op.Room, m *op.Movie, u *url.URL) {
+	id, err := dbModel.GetLiveRecordIDFromURL(u)
+	if err != nil {
+		log.Errorf("serve live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	record, err := room.GetLiveRecord(id)
+	if err != nil {
+		log.Errorf("serve live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	if record.MovieID.String() != m.ID || record.Status != dbModel.LiveRecordStatusFinished {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("live record is not available"))
+		return
+	}
+
+	f, err := os.Open(op.LiveRecordFilePath(room.ID, record.ID))
+	if err != nil {
+		log.Errorf("serve live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		log.Errorf("serve live record error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	ctx.Header("Content-Type", "video/x-flv")
+	http.ServeContent(proxy.NewMeteredResponseWriter(ctx.Request.Context(), ctx.Writer), ctx.Request, fi.Name(), fi.ModTime(), f)
+}
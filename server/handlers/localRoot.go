@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+func AdminGetLocalRoots(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	roots, err := op.GetLocalRoots()
+	if err != nil {
+		log.Errorf("get local roots error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(roots))
+}
+
+func AdminAddLocalRoot(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.AddLocalRootReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	root := &dbModel.LocalRoot{
+		Name:    req.Name,
+		Path:    req.Path,
+		Role:    req.Role,
+		UserIDs: req.UserIDs,
+	}
+	if err := op.CreateLocalRoot(root); err != nil {
+		log.Errorf("add local root error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(root))
+}
+
+func AdminUpdateLocalRoot(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.UpdateLocalRootReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	root, err := op.LoadLocalRoot(req.ID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrLocalRootNotFound)) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, model.NewAPIErrorResp(err))
+			return
+		}
+		log.Errorf("get local root error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	// the cached root is shared, update a copy
+	updated := *root
+	updated.Name = req.Name
+	updated.Path = req.Path
+	updated.Role = req.Role
+	updated.UserIDs = req.UserIDs
+	if err := op.UpdateLocalRoot(&updated); err != nil {
+		log.Errorf("update local root error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func AdminDeleteLocalRoot(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.IDReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := op.DeleteLocalRoot(req.ID); err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrLocalRootNotFound)) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, model.NewAPIErrorResp(err))
+			return
+		}
+		log.Errorf("delete local root error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/synctv-org/synctv/internal/settings"
 	"github.com/synctv-org/synctv/server/handlers/proxy"
 	"github.com/synctv-org/synctv/server/handlers/vendors"
+	"github.com/synctv-org/synctv/server/middlewares"
 	"github.com/synctv-org/synctv/server/model"
 	"github.com/synctv-org/synctv/utils"
 	"github.com/zijiren233/livelib/protocol/hls"
@@ -45,7 +46,7 @@ func genMovieInfo(
 	user *op.User,
 	opMovie *op.Movie,
 	userAgent,
-	userToken string,
+	mediaToken string,
 ) (*model.Movie, error) {
 	if opMovie == nil || opMovie.ID == "" {
 		return &model.Movie{}, nil
@@ -64,16 +65,16 @@ func genMovieInfo(
 		if err != nil {
 			return nil, err
 		}
-		movie, err = vendor.GenMovieInfo(ctx, user, userAgent, userToken)
+		movie, err = vendor.GenMovieInfo(ctx, user, userAgent, mediaToken)
 		if err != nil {
 			return nil, err
 		}
 	} else if movie.MovieBase.RtmpSource {
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/live/hls/list/%s.m3u8?token=%s&roomId=%s", movie.ID, userToken, opMovie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/live/hls/list/%s.m3u8?token=%s&roomId=%s", movie.ID, mediaToken, opMovie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 		movie.MoreSources = append(movie.MoreSources, &dbModel.MoreSource{
 			Name: "flv",
-			URL:  fmt.Sprintf("/api/room/movie/live/flv/%s.flv?token=%s&roomId=%s", movie.ID, userToken, opMovie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/live/flv/%s.flv?token=%s&roomId=%s", movie.ID, mediaToken, opMovie.RoomID),
 			Type: "flv",
 		})
 		movie.MovieBase.Headers = nil
@@ -81,15 +82,15 @@ func genMovieInfo(
 		if !utils.IsM3u8Url(movie.MovieBase.URL) {
 			movie.MoreSources = append(movie.MoreSources, &dbModel.MoreSource{
 				Name: "flv",
-				URL:  fmt.Sprintf("/api/room/movie/live/flv/%s.flv?token=%s&roomId=%s", movie.ID, userToken, opMovie.RoomID),
+				URL:  fmt.Sprintf("/api/room/movie/live/flv/%s.flv?token=%s&roomId=%s", movie.ID, mediaToken, opMovie.RoomID),
 				Type: "flv",
 			})
 		}
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/live/hls/list/%s.m3u8?token=%s&roomId=%s", movie.ID, userToken, opMovie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/live/hls/list/%s.m3u8?token=%s&roomId=%s", movie.ID, mediaToken, opMovie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 		movie.MovieBase.Headers = nil
 	} else if movie.MovieBase.Proxy {
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, opMovie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, opMovie.RoomID)
 		movie.MovieBase.Headers = nil
 	}
 	if movie.MovieBase.Type == "" && movie.MovieBase.URL != "" {
@@ -105,10 +106,29 @@ func genMovieInfo(
 			v.Type = utils.GetURLExtension(v.URL)
 		}
 	}
+	uploads, err := room.GetMovieSubtitles(movie.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range uploads {
+		if movie.MovieBase.Subtitles == nil {
+			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(uploads))
+		}
+		name := s.Name
+		if _, ok := movie.MovieBase.Subtitles[name]; ok {
+			name = fmt.Sprintf("%s (%s)", s.Name, s.ID)
+		}
+		movie.MovieBase.Subtitles[name] = &dbModel.Subtitle{
+			URL:  fmt.Sprintf("/api/room/movie/subtitle/%s?token=%s&roomId=%s", s.ID, mediaToken, opMovie.RoomID),
+			Type: s.Type,
+		}
+	}
 	resp := &model.Movie{
 		ID:        movie.ID,
 		CreatedAt: movie.CreatedAt.UnixMilli(),
 		Base:      movie.MovieBase,
+		Meta:      movie.Meta,
+		Health:    movie.Health,
 		Creator:   op.GetUserName(movie.CreatorID),
 		CreatorID: movie.CreatorID,
 		SubPath:   opMovie.SubPath(),
@@ -116,25 +136,42 @@ func genMovieInfo(
 	return resp, nil
 }
 
-func genCurrentRespWithCurrent(ctx context.Context, room *op.Room, user *op.User, userAgent string, userToken string) (*model.CurrentMovieResp, error) {
+func genCurrentRespWithCurrent(ctx context.Context, room *op.Room, user *op.User, userAgent, clientIP string) (*model.CurrentMovieResp, error) {
 	current := room.Current()
 	if current.Movie.ID == "" {
 		return &model.CurrentMovieResp{
-			Movie: &model.Movie{},
+			Movie:    &model.Movie{},
+			Subtitle: current.Subtitle,
 		}, nil
 	}
 	opMovie, err := room.GetMovieByID(current.Movie.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get current movie error: %w", err)
 	}
-	mr, err := genMovieInfo(ctx, room, user, opMovie, userAgent, userToken)
+	// the media urls carry a short-lived token of this movie instead of the user token
+	mediaToken, mediaTokenExpireAt, err := middlewares.NewMediaToken(user, room.ID, opMovie.ID, clientIP)
+	if err != nil {
+		return nil, fmt.Errorf("new media token error: %w", err)
+	}
+	mr, err := genMovieInfo(ctx, room, user, opMovie, userAgent, mediaToken)
 	if err != nil {
 		return nil, fmt.Errorf("gen current movie info error: %w", err)
 	}
 	resp := &model.CurrentMovieResp{
-		Status:   current.UpdateStatus(),
-		Movie:    mr,
-		ExpireID: opMovie.ExpireID(),
+		Status:             current.UpdateStatus(),
+		Movie:              mr,
+		ExpireID:           opMovie.ExpireID(),
+		Subtitle:           current.Subtitle,
+		MediaTokenExpireAt: mediaTokenExpireAt.UnixMilli(),
+	}
+	if !current.Movie.IsLive && !user.IsGuest() {
+		history, err := user.GetWatchHistory(opMovie)
+		if err == nil &&
+			!history.Completed &&
+			history.Position >= op.MinResumePosition &&
+			history.Position > resp.Status.CurrentTime+op.MinResumePosition {
+			resp.ResumeFrom = history.Position
+		}
 	}
 	return resp, nil
 }
@@ -144,7 +181,7 @@ func CurrentMovie(ctx *gin.Context) {
 	user := ctx.MustGet("user").(*op.UserEntry).Value()
 	log := ctx.MustGet("log").(*log.Entry)
 
-	currentResp, err := genCurrentRespWithCurrent(ctx, room, user, ctx.GetHeader("User-Agent"), ctx.GetString("token"))
+	currentResp, err := genCurrentRespWithCurrent(ctx, room, user, ctx.GetHeader("User-Agent"), ctx.ClientIP())
 	if err != nil {
 		log.Errorf("gen current resp error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
@@ -227,6 +264,8 @@ func Movies(ctx *gin.Context) {
 			ID:        v.ID,
 			CreatedAt: v.CreatedAt.UnixMilli(),
 			Base:      v.MovieBase,
+			Meta:      v.Meta,
+			Health:    v.Health,
 			Creator:   op.GetUserName(v.CreatorID),
 			CreatorID: v.CreatorID,
 		}
@@ -314,6 +353,8 @@ func PushMovie(ctx *gin.Context) {
 		return
 	}
 
+	probeMoviesMeta(room, m)
+
 	ctx.JSON(http.StatusOK, model.NewAPIDataResp(m))
 }
 
@@ -350,6 +391,8 @@ func PushMovies(ctx *gin.Context) {
 		return
 	}
 
+	probeMoviesMeta(room, m...)
+
 	ctx.JSON(http.StatusOK, model.NewAPIDataResp(m))
 }
 
@@ -450,6 +493,11 @@ func EditMovie(ctx *gin.Context) {
 		return
 	}
 
+	probeMoviesMeta(room, &dbModel.Movie{
+		ID:        req.ID,
+		MovieBase: dbModel.MovieBase(req.PushMovieReq),
+	})
+
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -529,6 +577,64 @@ func SwapMovie(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+func MoveMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.MoveMoviesReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.MoveRoomMovies(room, req.IDs, req.ParentID); err != nil {
+		log.Errorf("move movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("move movies error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func ReorderMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.ReorderMoviesReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.ReorderRoomMovies(room, req.ParentID, req.IDs); err != nil {
+		log.Errorf("reorder movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("reorder movies error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 func ChangeCurrentMovie(ctx *gin.Context) {
 	room := ctx.MustGet("room").(*op.RoomEntry).Value()
 	user := ctx.MustGet("user").(*op.UserEntry).Value()
@@ -560,6 +666,67 @@ func ChangeCurrentMovie(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+func ChangeCurrentSubtitle(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.SetRoomCurrentSubtitleReq{}
+	err := model.Decode(ctx, &req)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	err = user.SetRoomCurrentSubtitle(room, req.Track, req.Delay)
+	if err != nil {
+		log.Errorf("change current subtitle error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("change current subtitle error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func CheckMoviesHealth(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.CheckMoviesHealthReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("check movies health error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.CheckRoomMoviesHealth(room, req.IDs); err != nil {
+		log.Errorf("check movies health error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("check movies health error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
 func ProxyMovie(ctx *gin.Context) {
 	log := ctx.MustGet("log").(*log.Entry)
 
@@ -570,7 +737,12 @@ func ProxyMovie(ctx *gin.Context) {
 	}
 
 	room := ctx.MustGet("room").(*op.RoomEntry).Value()
-	// user := ctx.MustGet("user").(*op.UserEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	if err := meterTraffic(ctx, user.ID, room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
 
 	m, err := room.GetMovieByID(ctx.Param("movieId"))
 	if err != nil {
@@ -600,10 +772,26 @@ func ProxyMovie(ctx *gin.Context) {
 		return
 	}
 
-	switch m.Movie.MovieBase.Type {
-	case "mpd":
-		// TODO: cache mpd file
-		fallthrough
+	if u, err := url.Parse(m.Movie.MovieBase.URL); err == nil && u.Scheme == dbModel.LiveRecordScheme {
+		serveLiveRecord(ctx, log, room, m, u)
+		return
+	}
+
+	switch {
+	case m.Movie.MovieBase.Type == "mpd",
+		m.Movie.MovieBase.Type == "" && utils.IsMpdUrl(m.Movie.MovieBase.URL):
+		err = proxy.Mpd(ctx,
+			m.Movie.MovieBase.URL,
+			m.Movie.MovieBase.Headers,
+			ctx.GetString("token"),
+			room.ID,
+			m.ID,
+			proxy.WithProxyURLCache(true),
+		)
+		if err != nil {
+			log.Errorf("proxy mpd error: %v", err)
+			return
+		}
 	default:
 		err = proxy.AutoProxyURL(ctx,
 			m.Movie.MovieBase.URL,
@@ -631,6 +819,12 @@ func ServeM3u8(ctx *gin.Context) {
 	}
 
 	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	if err := meterTraffic(ctx, user.ID, room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
 
 	m, err := room.GetMovieByID(ctx.Param("movieId"))
 	if err != nil {
@@ -674,44 +868,59 @@ func ServeM3u8(ctx *gin.Context) {
 	}
 }
 
-// only cache mpd file
-// func initDashCache(ctx context.Context, movie *dbModel.Movie) func() (any, error) {
-// 	return func() (any, error) {
-// 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, movie.Base.Url, nil)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		for k, v := range movie.Base.Headers {
-// 			req.Header.Set(k, v)
-// 		}
-// 		req.Header.Set("User-Agent", utils.UA)
-// 		resp, err := uhc.Do(req)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		defer resp.Body.Close()
-// 		b, err := io.ReadAll(resp.Body)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		m, err := mpd.ReadFromString(string(b))
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		if len(m.BaseURL) != 0 && !path.IsAbs(m.BaseURL[0]) {
-// 			result, err := url.JoinPath(path.Dir(movie.Base.Url), m.BaseURL[0])
-// 			if err != nil {
-// 				return nil, err
-// 			}
-// 			m.BaseURL = []string{result}
-// 		}
-// 		s, err := m.WriteToString()
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		return s, nil
-// 	}
-// }
+func ServeMpd(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*log.Entry)
+
+	if !settings.MovieProxy.Get() {
+		log.Errorf("movie proxy is not enabled")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("movie proxy is not enabled"))
+		return
+	}
+
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	if err := meterTraffic(ctx, user.ID, room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
+
+	m, err := room.GetMovieByID(ctx.Param("movieId"))
+	if err != nil {
+		log.Errorf("get movie by id error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if m.Movie.MovieBase.Live || m.Movie.MovieBase.RtmpSource {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("this movie is live or rtmp source, not support use this method proxy"))
+		return
+	}
+
+	if !m.Movie.MovieBase.Proxy {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("proxy is not enabled"))
+		return
+	}
+
+	claims, err := proxy.GetMpdTarget(ctx.Param("targetToken"))
+	if err != nil {
+		log.Errorf("auth mpd error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	if claims.RoomID != room.ID || claims.MovieID != m.ID {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid token"))
+		return
+	}
+	err = proxy.MpdTarget(ctx,
+		claims,
+		m.Movie.MovieBase.Headers,
+		proxy.WithProxyURLCache(true),
+	)
+	if err != nil {
+		log.Errorf("proxy mpd error: %v", err)
+	}
+}
 
 type FormatNotSupportFileTypeError string
 
@@ -724,6 +933,11 @@ func JoinFlvLive(ctx *gin.Context) {
 
 	ctx.Header("Cache-Control", "no-store")
 	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	if err := meterTraffic(ctx, user.ID, room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
 	movieID := strings.TrimSuffix(strings.Trim(ctx.Param("movieId"), "/"), ".flv")
 	m, err := room.GetMovieByID(movieID)
 	if err != nil {
@@ -754,7 +968,7 @@ func JoinFlvLive(ctx *gin.Context) {
 		return
 	}
 
-	w := httpflv.NewHttpFLVWriter(ctx.Writer)
+	w := httpflv.NewHttpFLVWriter(proxy.NewMeteredWriter(ctx.Request.Context(), ctx.Writer))
 	defer w.Close()
 	err = channel.AddPlayer(w)
 	if err != nil {
@@ -774,6 +988,11 @@ func JoinHlsLive(ctx *gin.Context) {
 
 	ctx.Header("Cache-Control", "no-store")
 	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	if err := meterTraffic(ctx, user.ID, room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
 	movieID := strings.TrimSuffix(strings.Trim(ctx.Param("movieId"), "/"), ".m3u8")
 	m, err := room.GetMovieByID(movieID)
 	if err != nil {
@@ -846,6 +1065,13 @@ func ServeHlsLive(ctx *gin.Context) {
 	}
 	room := roomE.Value()
 
+	// the segments are requested without the user token, so only the room is metered
+	if err := meterTraffic(ctx, "", room.ID); err != nil {
+		log.Errorf("meter traffic error: %v", err)
+		return
+	}
+	ctx.Writer = proxy.NewMeteredResponseWriter(ctx.Request.Context(), ctx.Writer)
+
 	ctx.Header("Cache-Control", "public, max-age=30, s-maxage=90")
 
 	movieID := ctx.Param("movieId")
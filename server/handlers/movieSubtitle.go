@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/middlewares"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+func MovieSubtitles(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	movieID := ctx.Query("movieId")
+	if len(movieID) != 32 {
+		log.Errorf("get movie subtitles error: %v", model.ErrID)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(model.ErrID))
+		return
+	}
+
+	subtitles, err := user.GetRoomMovieSubtitles(room, movieID)
+	if err != nil {
+		log.Errorf("get movie subtitles error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(err))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(subtitles))
+}
+
+// UploadMovieSubtitle reads the multipart form with the movieId, the file and an optional name
+func UploadMovieSubtitle(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	movieID := ctx.PostForm("movieId")
+	if len(movieID) != 32 {
+		log.Errorf("upload movie subtitle error: %v", model.ErrID)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(model.ErrID))
+		return
+	}
+
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		log.Errorf("upload movie subtitle error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	maxSize := settings.SubtitleMaxSize.Get() * 1024
+	if fh.Size > maxSize {
+		log.Errorf("upload movie subtitle error: %v", "subtitle too large")
+		ctx.AbortWithStatusJSON(
+			http.StatusRequestEntityTooLarge,
+			model.NewAPIErrorStringResp(fmt.Sprintf("subtitle size exceeds the limit of %d KB", maxSize/1024)),
+		)
+		return
+	}
+	name := ctx.PostForm("name")
+	if name == "" {
+		name = fh.Filename
+	}
+	if len(name) > 256 {
+		log.Errorf("upload movie subtitle error: %v", "name too long")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("name too long"))
+		return
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		log.Errorf("upload movie subtitle error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		log.Errorf("upload movie subtitle error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	subtitle, err := user.AddRoomMovieSubtitle(room, movieID, name, data)
+	if err != nil {
+		log.Errorf("upload movie subtitle error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("upload movie subtitle error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewAPIDataResp(subtitle))
+}
+
+func DeleteMovieSubtitle(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.IDReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("delete movie subtitle error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.DeleteRoomMovieSubtitle(room, req.ID); err != nil {
+		log.Errorf("delete movie subtitle error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("delete movie subtitle error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ServeMovieSubtitle serves the uploaded subtitle, the format query converts it
+func ServeMovieSubtitle(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	if !user.HasRoomPermission(room, dbModel.PermissionGetMovieList) {
+		log.Errorf("serve movie subtitle error: %v", dbModel.ErrNoPermission)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(dbModel.ErrNoPermission))
+		return
+	}
+
+	subtitle, data, err := room.ReadMovieSubtitle(ctx.Param("subtitleId"))
+	if err != nil {
+		log.Errorf("serve movie subtitle error: %v", err)
+		if errors.Is(err, db.NotFoundError(db.ErrMovieSubtitleNotFound)) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, model.NewAPIErrorResp(err))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	// a media token only allows the subtitles of its movie
+	if movieID := ctx.GetString("mediaMovieId"); movieID != "" && movieID != subtitle.MovieID {
+		log.Errorf("serve movie subtitle error: %v", middlewares.ErrMediaTokenMismatch)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(middlewares.ErrMediaTokenMismatch))
+		return
+	}
+
+	if err := proxy.ServeSubtitle(ctx, subtitle.Name+"."+subtitle.Type, subtitle.Type, data); err != nil {
+		log.Errorf("serve movie subtitle error: %v", err)
+	}
+}
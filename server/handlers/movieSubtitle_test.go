@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/middlewares"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "synctv-handlers")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := 1
+	if err := initTestEnv(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	} else {
+		code = m.Run()
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// initTestEnv initializes the database, the settings and the op caches like the bootstrap
+func initTestEnv(dir string) error {
+	conf.Conf = conf.DefaultConfig()
+	conf.Conf.Server.SubtitlePath = filepath.Join(dir, "subtitle")
+	logrus.SetOutput(io.Discard)
+	gin.SetMode(gin.TestMode)
+	d, err := gorm.Open(sqlite.Open(filepath.Join(dir, "synctv.db")), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.Init(d, conf.DatabaseTypeSqlite3); err != nil {
+		return err
+	}
+	for {
+		b, ok := settings.PopNeedInit()
+		if !ok {
+			break
+		}
+		err := db.FirstOrCreateSettingItemValue(&dbModel.Setting{
+			Name:  b.Name(),
+			Value: b.DefaultString(),
+			Type:  b.Type(),
+			Group: b.Group(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := b.Init(b.DefaultString()); err != nil {
+			return err
+		}
+	}
+	return op.Init(4096)
+}
+
+const testSrt = "1\n00:00:01,000 --> 00:00:02,000\nhello\n"
+
+func TestServeMovieSubtitle(t *testing.T) {
+	userE, err := op.CreateUser("user-"+utils.RandString(8), "password", db.WithRole(dbModel.RoleUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := userE.Value()
+	roomE, err := op.CreateRoom("room-"+utils.RandString(8), "", 0,
+		db.WithCreator(&user.User),
+		db.WithStatus(dbModel.RoomStatusActive),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	room := roomE.Value()
+	movie, err := user.AddRoomMovie(room, &dbModel.MovieBase{Name: "movie", URL: "https://example.com/movie.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherMovie, err := user.AddRoomMovie(room, &dbModel.MovieBase{Name: "other", URL: "https://example.com/other.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtitle, err := user.AddRoomMovieSubtitle(room, movie.ID, "en.srt", []byte(testSrt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newMediaToken := func(movieID string) string {
+		token, _, err := middlewares.NewMediaToken(user, room.ID, movieID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	userToken, err := middlewares.NewAuthUserToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		token      string
+		wantStatus int
+	}{
+		{"media token of the movie", "", newMediaToken(movie.ID), http.StatusOK},
+		{"media token of another movie", "", newMediaToken(otherMovie.ID), http.StatusForbidden},
+		{"user token", "?roomId=" + room.ID, userToken, http.StatusOK},
+	}
+	e := gin.New()
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	e.Use(middlewares.NewLog(l))
+	e.GET("/api/room/movie/subtitle/:subtitleId", middlewares.AuthMediaMiddleware, ServeMovieSubtitle)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/room/movie/subtitle/"+subtitle.ID+tt.query, nil)
+			req.Header.Set("Authorization", tt.token)
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(w.Body.String(), "hello") {
+				t.Errorf("body = %q, want the subtitle", w.Body.String())
+			}
+			if tt.wantStatus == http.StatusForbidden && !strings.Contains(w.Body.String(), middlewares.ErrMediaTokenMismatch.Error()) {
+				t.Errorf("body = %s, want %v", w.Body.String(), middlewares.ErrMediaTokenMismatch)
+			}
+		})
+	}
+}
+
+func TestMovieSubtitlesInArchivedRoom(t *testing.T) {
+	userE, err := op.CreateUser("user-"+utils.RandString(8), "password", db.WithRole(dbModel.RoleUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := userE.Value()
+	roomE, err := op.CreateRoom("room-"+utils.RandString(8), "", 0,
+		db.WithCreator(&user.User),
+		db.WithStatus(dbModel.RoomStatusActive),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	room := roomE.Value()
+	movie, err := user.AddRoomMovie(room, &dbModel.MovieBase{Name: "movie", URL: "https://example.com/movie.mp4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtitle, err := user.AddRoomMovieSubtitle(room, movie.ID, "en.srt", []byte(testSrt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := op.ArchiveRoom(&room.Room); err != nil {
+		t.Fatal(err)
+	}
+	roomE, err = op.LoadOrInitRoomByID(room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	room = roomE.Value()
+	if !room.IsArchived() {
+		t.Fatal("room is not archived")
+	}
+
+	// the user created the room, the movie and the subtitle
+	if _, err := user.AddRoomMovieSubtitle(room, movie.ID, "fr.srt", []byte(testSrt)); !errors.Is(err, dbModel.ErrNoPermission) {
+		t.Errorf("add subtitle err = %v, want %v", err, dbModel.ErrNoPermission)
+	}
+	if err := user.DeleteRoomMovieSubtitle(room, subtitle.ID); !errors.Is(err, dbModel.ErrNoPermission) {
+		t.Errorf("delete subtitle err = %v, want %v", err, dbModel.ErrNoPermission)
+	}
+	if subtitles, err := room.GetMovieSubtitles(movie.ID); err != nil || len(subtitles) != 1 {
+		t.Errorf("subtitles = %v, %v, want the uploaded one", subtitles, err)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+)
+
+func TrashedMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	page, _max, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		log.Errorf("get page and max error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	m, total, err := user.GetRoomTrashedMoviesWithPage(room, page, _max)
+	if err != nil {
+		log.Errorf("get room trashed movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(err))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	retention := time.Duration(settings.MovieTrashRetention.Get()) * time.Hour * 24
+	list := make([]*model.TrashedMovie, len(m))
+	for i, v := range m {
+		list[i] = &model.TrashedMovie{
+			Movie: &model.Movie{
+				ID:        v.ID,
+				CreatedAt: v.CreatedAt.UnixMilli(),
+				Base:      v.MovieBase,
+				Meta:      v.Meta,
+				Health:    v.Health,
+				Creator:   op.GetUserName(v.CreatorID),
+				CreatorID: v.CreatorID,
+			},
+			DeletedBy:   op.GetUserName(v.DeletedBy.String()),
+			DeletedByID: v.DeletedBy.String(),
+			DeletedAt:   v.DeletedAt.Time.UnixMilli(),
+			PurgeAt:     v.DeletedAt.Time.Add(retention).UnixMilli(),
+		}
+		// hide url and headers when proxy
+		if user.ID != v.CreatorID && v.MovieBase.Proxy {
+			list[i].Base.URL = ""
+			list[i].Base.Headers = nil
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
+		"total": total,
+		"list":  list,
+	}))
+}
+
+func RestoreMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.IDsReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("restore movies error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.RestoreRoomMovies(room, req.IDs); err != nil {
+		log.Errorf("restore movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("restore movies error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func DeleteTrashedMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.IDsReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("delete trashed movies error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.DeleteRoomTrashedMovies(room, req.IDs); err != nil {
+		log.Errorf("delete trashed movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("delete trashed movies error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/playlist"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+const (
+	maxPlaylistSize  = 8 * 1024 * 1024
+	maxPlaylistItems = 10000
+)
+
+func validatePlaylistItems(items []*playlist.Item) error {
+	for _, item := range items {
+		if err := (*model.PushMovieReq)(&item.Movie).Validate(); err != nil {
+			return fmt.Errorf("invalid playlist item %s: %w", item.Movie.Name, err)
+		}
+		if err := validatePlaylistItems(item.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ImportMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	parentID := ctx.Query("parentId")
+	if len(parentID) != 0 && len(parentID) != 32 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("parent id length must be 0 or 32"))
+		return
+	}
+
+	var format playlist.Format
+	if f := ctx.Query("format"); f != "" {
+		var err error
+		format, err = playlist.ParseFormat(f)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return
+		}
+	}
+
+	items, err := playlist.Parse(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxPlaylistSize), format)
+	if err != nil {
+		log.Errorf("parse playlist error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	switch count := playlist.Count(items); {
+	case count == 0:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("playlist is empty"))
+		return
+	case count > maxPlaylistItems:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp(fmt.Sprintf("playlist has too many items, max %d", maxPlaylistItems)))
+		return
+	}
+	if err := validatePlaylistItems(items); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	m, err := user.ImportRoomMovies(room, parentID, items)
+	if err != nil {
+		log.Errorf("import movies error: %v", err)
+		if errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				model.NewAPIErrorResp(
+					fmt.Errorf("import movies error: %w", err),
+				),
+			)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	probeMoviesMeta(room, m...)
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(m))
+}
+
+func ExportMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	if !user.HasRoomPermission(room, dbModel.PermissionGetMovieList) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(dbModel.ErrNoPermission))
+		return
+	}
+
+	format, err := playlist.ParseFormat(ctx.DefaultQuery("format", string(playlist.FormatJSON)))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	id := ctx.Query("id")
+	name := room.Name
+	switch len(id) {
+	case 0:
+	case 32:
+		mv, err := room.GetMovieByID(id)
+		if err != nil {
+			log.Errorf("get room movie by id error: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return
+		}
+		if !mv.IsFolder || mv.IsDynamicFolder() {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id is not a static folder"))
+			return
+		}
+		name = mv.Name
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id length must be 0 or 32"))
+		return
+	}
+
+	items, err := room.GetMovieTree(id, user.ID)
+	if err != nil {
+		log.Errorf("get movie tree error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	switch format {
+	case playlist.FormatM3U:
+		ctx.Header("Content-Type", "audio/x-mpegurl; charset=utf-8")
+		name += ".m3u"
+	case playlist.FormatJSON:
+		ctx.Header("Content-Type", "application/json; charset=utf-8")
+		name += ".json"
+	}
+	ctx.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(name))
+	ctx.Status(http.StatusOK)
+	if err := playlist.Write(ctx.Writer, format, items); err != nil {
+		log.Errorf("write playlist error: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/handlers/vendors"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+)
+
+const (
+	movieProbeTimeout     = time.Minute
+	maxConcurrentProbes   = 4
+	maxProbeMoviesPerCall = 1000
+)
+
+var movieProbeSem = make(chan struct{}, maxConcurrentProbes)
+
+var errMovieNotProbeable = errors.New("movie can't be probed")
+
+func probeMovie(ctx context.Context, room *op.Room, movie *op.Movie) (*probe.Info, error) {
+	if movie.IsFolder || movie.Live || movie.RtmpSource {
+		return nil, errMovieNotProbeable
+	}
+	if movie.VendorInfo.Vendor != "" {
+		vendor, err := vendors.NewVendorService(room, movie)
+		if err != nil {
+			return nil, err
+		}
+		prober, ok := vendor.(vendors.MovieProber)
+		if !ok {
+			return nil, errMovieNotProbeable
+		}
+		return prober.ProbeMovie(ctx)
+	}
+	u, err := url.Parse(movie.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errMovieNotProbeable
+	}
+	if !settings.AllowProxyToLocal.Get() {
+		if l, err := utils.ParseURLIsLocalIP(movie.URL); err != nil || l {
+			return nil, errors.New("local ip is not allowed")
+		}
+	}
+	return proxy.ProbeURL(ctx, movie.URL, movie.Headers, movie.IsM3u8())
+}
+
+func probeAndUpdateMovieMeta(room *op.Room, movieID string) (bool, error) {
+	movie, err := room.GetMovieByID(movieID)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), movieProbeTimeout)
+	defer cancel()
+	info, err := probeMovie(ctx, room, movie)
+	if err != nil {
+		if errors.Is(err, errMovieNotProbeable) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, room.UpdateMovieMeta(movieID, &dbModel.MovieMeta{
+		VideoCodec: info.VideoCodec,
+		AudioCodec: info.AudioCodec,
+		Format:     info.Format,
+		Duration:   info.Duration,
+		Width:      info.Width,
+		Height:     info.Height,
+		ProbedAt:   time.Now().UnixMilli(),
+	})
+}
+
+// probeMoviesMeta probes the movies in background,
+// and notifies the room to reload the movie list once any of them is updated
+func probeMoviesMeta(room *op.Room, movies ...*dbModel.Movie) {
+	ids := make([]string, 0, len(movies))
+	for _, m := range movies {
+		if m.IsFolder || m.Live {
+			continue
+		}
+		ids = append(ids, m.ID)
+		if len(ids) >= maxProbeMoviesPerCall {
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	go func() {
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			updated bool
+		)
+		for _, id := range ids {
+			movieProbeSem <- struct{}{}
+			wg.Add(1)
+			go func(id string) {
+				defer func() {
+					<-movieProbeSem
+					wg.Done()
+				}()
+				ok, err := probeAndUpdateMovieMeta(room, id)
+				if err != nil {
+					log.WithFields(log.Fields{
+						"rid": room.ID,
+						"mid": id,
+					}).Warnf("probe movie meta error: %v", err)
+					return
+				}
+				if ok {
+					mu.Lock()
+					updated = true
+					mu.Unlock()
+				}
+			}(id)
+		}
+		wg.Wait()
+		if !updated {
+			return
+		}
+		if err := room.Broadcast(&pb.Message{
+			Type: pb.MessageType_MOVIES,
+		}); err != nil {
+			log.WithField("rid", room.ID).Errorf("broadcast movies error: %v", err)
+		}
+	}()
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,8 +22,54 @@ import (
 // Cache defines the interface for cache implementations
 type Cache interface {
 	Get(key string) (*CacheItem, bool, error)
+	// Has reports whether the key is cached without counting a lookup
+	Has(key string) (bool, error)
 	GetAnyWithPrefix(prefix string) (*CacheItem, bool, error)
 	Set(key string, data *CacheItem) error
+	Delete(key string) error
+	// PurgePrefix deletes all the items whose key starts with prefix and returns the number of deleted items
+	PurgePrefix(prefix string) (int64, error)
+	Stats() (*CacheStats, error)
+}
+
+// CacheStats is a snapshot of the cache usage
+type CacheStats struct {
+	Driver  string
+	Entries int64
+	Size    int64
+	MaxSize int64
+	Hits    uint64
+	Misses  uint64
+}
+
+func (s *CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// cacheCounter counts the lookups of a cache since startup
+type cacheCounter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *cacheCounter) count(found bool) {
+	if found {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *cacheCounter) stats(driver string) *CacheStats {
+	return &CacheStats{
+		Driver: driver,
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
 }
 
 // CacheMetadata stores metadata about a cached response
@@ -160,6 +207,7 @@ type MemoryCache struct {
 	maxSizeBytes int64
 	currentSize  int64
 	mu           sync.RWMutex
+	counter      cacheCounter
 }
 
 type MemoryCacheOption func(*MemoryCache)
@@ -198,6 +246,7 @@ func (c *MemoryCache) Get(key string) (*CacheItem, bool, error) {
 	element, exists := c.m[key]
 	if !exists {
 		c.mu.RUnlock()
+		c.counter.count(false)
 		return nil, false, nil
 	}
 
@@ -208,9 +257,22 @@ func (c *MemoryCache) Get(key string) (*CacheItem, bool, error) {
 	item := element.Value.item
 	c.mu.Unlock()
 
+	c.counter.count(true)
 	return item, true, nil
 }
 
+func (c *MemoryCache) Has(key string) (bool, error) {
+	if key == "" {
+		return false, errors.New("cache key cannot be empty")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.m[key]
+	return ok, nil
+}
+
 func (c *MemoryCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error) {
 	if prefix == "" {
 		return nil, false, errors.New("prefix cannot be empty")
@@ -225,6 +287,7 @@ func (c *MemoryCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error)
 		if next, ok := node.children[ch]; ok {
 			node = next
 		} else {
+			c.counter.count(false)
 			return nil, false, nil
 		}
 	}
@@ -245,10 +308,12 @@ func (c *MemoryCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error)
 
 	if key := findKey(node); key != "" {
 		if element, ok := c.m[key]; ok {
+			c.counter.count(true)
 			return element.Value.item, true, nil
 		}
 	}
 
+	c.counter.count(false)
 	return nil, false, nil
 }
 
@@ -287,18 +352,7 @@ func (c *MemoryCache) Set(key string, data *CacheItem) error {
 		((c.capacity > 0 && c.lruList.Len() >= c.capacity) ||
 			(c.maxSizeBytes > 0 && c.currentSize+newSize > c.maxSizeBytes)) {
 		if back := c.lruList.Back(); back != nil {
-			entry := back.Value
-			c.currentSize -= entry.size
-			delete(c.m, entry.key)
-			c.lruList.Remove(back)
-
-			// Remove from prefix tree
-			node := c.prefixTrie
-			for _, ch := range entry.key {
-				node = node.children[ch]
-			}
-			node.isEnd = false
-			node.key = ""
+			c.remove(back)
 		}
 	}
 
@@ -324,6 +378,97 @@ func (c *MemoryCache) Set(key string, data *CacheItem) error {
 	return nil
 }
 
+// remove must be called with the write lock held
+func (c *MemoryCache) remove(element *dllist.Element[*cacheEntry]) {
+	entry := element.Value
+	c.currentSize -= entry.size
+	delete(c.m, entry.key)
+	c.lruList.Remove(element)
+
+	// Remove from prefix tree and prune the empty branch
+	path := make([]*TrieNode, 0, len(entry.key)+1)
+	node := c.prefixTrie
+	path = append(path, node)
+	for _, ch := range entry.key {
+		node = node.children[ch]
+		path = append(path, node)
+	}
+	node.isEnd = false
+	node.key = ""
+	keyRunes := []rune(entry.key)
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].isEnd || len(path[i].children) > 0 {
+			break
+		}
+		delete(path[i-1].children, keyRunes[i-1])
+	}
+}
+
+func (c *MemoryCache) Delete(key string) error {
+	if key == "" {
+		return errors.New("cache key cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.m[key]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+func (c *MemoryCache) PurgePrefix(prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, errors.New("prefix cannot be empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node := c.prefixTrie
+	for _, ch := range prefix {
+		next, ok := node.children[ch]
+		if !ok {
+			return 0, nil
+		}
+		node = next
+	}
+
+	var keys []string
+	var collect func(*TrieNode)
+	collect = func(n *TrieNode) {
+		if n.isEnd {
+			keys = append(keys, n.key)
+		}
+		for _, child := range n.children {
+			collect(child)
+		}
+	}
+	collect(node)
+
+	var purged int64
+	for _, key := range keys {
+		if element, ok := c.m[key]; ok {
+			c.remove(element)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (c *MemoryCache) Stats() (*CacheStats, error) {
+	stats := c.counter.stats("memory")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats.Entries = int64(c.lruList.Len())
+	stats.Size = c.currentSize
+	stats.MaxSize = c.maxSizeBytes
+	return stats, nil
+}
+
 type FileCache struct {
 	mu           *ksync.Krwmutex
 	memCache     *MemoryCache
@@ -333,6 +478,7 @@ type FileCache struct {
 	lastCleanup  atomic.Int64
 	maxAge       time.Duration
 	cleanMu      sync.Mutex
+	counter      cacheCounter
 }
 
 type FileCacheOption func(*FileCache)
@@ -473,6 +619,14 @@ func (c *FileCache) cleanup() {
 }
 
 func (c *FileCache) Get(key string) (*CacheItem, bool, error) {
+	item, found, err := c.get(key)
+	if err == nil {
+		c.counter.count(found)
+	}
+	return item, found, err
+}
+
+func (c *FileCache) get(key string) (*CacheItem, bool, error) {
 	if key == "" {
 		return nil, false, errors.New("cache key cannot be empty")
 	}
@@ -519,7 +673,39 @@ func (c *FileCache) Get(key string) (*CacheItem, bool, error) {
 	return item, true, nil
 }
 
+func (c *FileCache) Has(key string) (bool, error) {
+	if key == "" {
+		return false, errors.New("cache key cannot be empty")
+	}
+
+	if ok, _ := c.memCache.Has(key); ok {
+		return true, nil
+	}
+
+	filePath := filepath.Join(c.filePath, string(key[0]), key)
+
+	c.mu.RLock(key)
+	defer c.mu.RUnlock(key)
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat cache file: %w", err)
+	}
+	return time.Since(info.ModTime()) <= c.maxAge, nil
+}
+
 func (c *FileCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error) {
+	item, found, err := c.getAnyWithPrefix(prefix)
+	if err == nil {
+		c.counter.count(found)
+	}
+	return item, found, err
+}
+
+func (c *FileCache) getAnyWithPrefix(prefix string) (*CacheItem, bool, error) {
 	if prefix == "" {
 		return nil, false, errors.New("prefix cannot be empty")
 	}
@@ -547,7 +733,7 @@ func (c *FileCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error) {
 
 		name := entry.Name()
 		if len(name) >= len(prefix) && name[:len(prefix)] == prefix {
-			item, found, err := c.Get(name)
+			item, found, err := c.get(name)
 			if err == nil && found {
 				return item, true, nil
 			}
@@ -611,3 +797,102 @@ func (c *FileCache) Set(key string, data *CacheItem) error {
 
 	return nil
 }
+
+func (c *FileCache) Delete(key string) error {
+	if key == "" {
+		return errors.New("cache key cannot be empty")
+	}
+
+	if err := c.memCache.Delete(key); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(c.filePath, string(key[0]), key)
+
+	c.mu.Lock(key)
+	defer c.mu.Unlock(key)
+
+	return c.removeFile(filePath)
+}
+
+func (c *FileCache) removeFile(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat cache file: %w", err)
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cache file: %w", err)
+	}
+	c.currentSize.Add(-info.Size())
+	return nil
+}
+
+func (c *FileCache) PurgePrefix(prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, errors.New("prefix cannot be empty")
+	}
+
+	if _, err := c.memCache.PurgePrefix(prefix); err != nil {
+		return 0, err
+	}
+
+	// every item in memory is also on disk, so only the files are counted
+	dirPath := filepath.Join(c.filePath, string(prefix[0]))
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var purged int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		c.mu.Lock(name)
+		err := c.removeFile(filepath.Join(dirPath, name))
+		c.mu.Unlock(name)
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (c *FileCache) Stats() (*CacheStats, error) {
+	stats := c.counter.stats("file")
+	stats.MaxSize = c.maxSizeBytes
+
+	entries, err := os.ReadDir(c.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		subEntries, err := os.ReadDir(filepath.Join(c.filePath, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, subEntry := range subEntries {
+			info, err := subEntry.Info()
+			if err != nil || info.IsDir() {
+				continue
+			}
+			stats.Entries++
+			stats.Size += info.Size()
+		}
+	}
+	return stats, nil
+}
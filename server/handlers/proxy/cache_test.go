@@ -0,0 +1,324 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis is an in-process redis compatible server implementing the commands used by RedisCache
+type fakeRedis struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeRedis{ln: ln, data: make(map[string][]byte)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *fakeRedis) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.exec(w, args)
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("invalid command")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(b), b)
+}
+
+// globMatch supports the * ? and \ of the redis glob pattern
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		s = s[1:]
+	}
+	return len(s) == 0
+}
+
+func (s *fakeRedis) exec(w *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "GET":
+		v, ok := s.data[args[1]]
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		writeBulk(w, v)
+	case "SET":
+		s.data[args[1]] = []byte(args[2])
+		w.WriteString("+OK\r\n")
+	case "EXISTS":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := s.data[k]; ok {
+				n++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", n)
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if _, ok := s.data[k]; ok {
+				delete(s.data, k)
+				n++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", n)
+	case "SCAN":
+		// a single iteration returns all the matching keys
+		match := "*"
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.EqualFold(args[i], "MATCH") {
+				match = args[i+1]
+			}
+		}
+		var keys []string
+		for k := range s.data {
+			if globMatch(match, k) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(w, "*2\r\n")
+		writeBulk(w, []byte("0"))
+		fmt.Fprintf(w, "*%d\r\n", len(keys))
+		for _, k := range keys {
+			writeBulk(w, []byte(k))
+		}
+	case "INFO":
+		size := 0
+		for _, v := range s.data {
+			size += len(v)
+		}
+		writeBulk(w, []byte(fmt.Sprintf("# Memory\r\nused_memory:%d\r\nmaxmemory:0\r\n", size)))
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func testCache(t *testing.T, c Cache) {
+	t.Helper()
+	room, otherRoom := strings.Repeat("a", 32), strings.Repeat("b", 32)
+	movie, otherMovie := strings.Repeat("c", 32), strings.Repeat("d", 32)
+	keys := []string{
+		cacheKey(cacheScope(room, movie), "u1", 0, sliceSize),
+		cacheKey(cacheScope(room, movie), "u1", sliceSize, sliceSize),
+		cacheKey(cacheScope(room, otherMovie), "u2", 0, sliceSize),
+		cacheKey(cacheScope(otherRoom, movie), "u1", 0, sliceSize),
+		cacheKey("", "u3", 0, sliceSize),
+	}
+	for _, key := range keys {
+		err := c.Set(key, &CacheItem{
+			Metadata: &CacheMetadata{ContentType: "video/mp4", ContentTotalLength: 10},
+			Data:     []byte(key),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item, ok, err := c.Get(keys[0])
+	if err != nil || !ok || string(item.Data) != keys[0] || item.Metadata.ContentType != "video/mp4" {
+		t.Fatalf("get %s: %v %v %v", keys[0], item, ok, err)
+	}
+	if _, ok, err := c.Get("missing"); err != nil || ok {
+		t.Fatalf("get missing: %v %v", ok, err)
+	}
+	if ok, err := c.Has(keys[2]); err != nil || !ok {
+		t.Fatalf("has %s: %v %v", keys[2], ok, err)
+	}
+	if ok, err := c.Has("missing"); err != nil || ok {
+		t.Fatalf("has missing: %v %v", ok, err)
+	}
+	if _, ok, err := c.GetAnyWithPrefix(cachePrefix(cacheScope(room, otherMovie), "u2", sliceSize)); err != nil || !ok {
+		t.Fatalf("get any with prefix: %v %v", ok, err)
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != int64(len(keys)) || stats.Hits != 2 || stats.Misses != 1 || stats.Size <= 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if n, err := c.PurgePrefix(cacheScope(room, movie)); err != nil || n != 2 {
+		t.Fatalf("purge movie: %d %v", n, err)
+	}
+	if n, err := c.PurgePrefix(cacheScope(room, "")); err != nil || n != 1 {
+		t.Fatalf("purge room: %d %v", n, err)
+	}
+	if err := c.Delete(keys[4]); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		_, ok, err := c.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i == 3; ok != want {
+			t.Fatalf("key %d exists: %v, want %v", i, ok, want)
+		}
+	}
+
+	stats, err = c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 1 {
+		t.Fatalf("unexpected entries after purge: %d", stats.Entries)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	testCache(t, NewMemoryCache(0))
+}
+
+func TestFileCache(t *testing.T) {
+	testCache(t, NewFileCache(t.TempDir()))
+}
+
+func TestRedisCache(t *testing.T) {
+	s := newFakeRedis(t)
+	opts, err := redis.ParseURL("redis://" + s.ln.Addr().String() + "/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(opts)
+	defer client.Close()
+	testCache(t, NewRedisCache(client))
+
+	// instances sharing the server see the items of each other
+	c1, c2 := NewRedisCache(client), NewRedisCache(client)
+	key := cacheKey("", "shared", 0, sliceSize)
+	if err := c1.Set(key, &CacheItem{Metadata: &CacheMetadata{}, Data: []byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	if item, ok, err := c2.Get(key); err != nil || !ok || string(item.Data) != "x" {
+		t.Fatalf("shared get: %v %v %v", item, ok, err)
+	}
+
+	// a slice is much larger than the buffer of the connection
+	large := bytes.Repeat([]byte("0123456789abcdef"), sliceSize/16)
+	key = cacheKey("", "large", 0, sliceSize)
+	if err := c1.Set(key, &CacheItem{Metadata: &CacheMetadata{}, Data: large}); err != nil {
+		t.Fatal(err)
+	}
+	if item, ok, err := c2.Get(key); err != nil || !ok || !bytes.Equal(item.Data, large) {
+		t.Fatalf("large get: %v %v", ok, err)
+	}
+}
+
+func TestEscapeRedisGlob(t *testing.T) {
+	tests := map[string]string{
+		"synctv:proxy:": "synctv:proxy:",
+		"a*b?c":         `a\*b\?c`,
+		"[room]":        `\[room\]`,
+		`back\slash`:    `back\\slash`,
+		"url?q=1&p=[*]": `url\?q=1&p=\[\*\]`,
+	}
+	for in, want := range tests {
+		if got := escapeRedisGlob(in); got != want {
+			t.Errorf("escapeRedisGlob(%q) = %q, want %q", in, got, want)
+		}
+		if !globMatch(escapeRedisGlob(in)+"*", in+"suffix") {
+			t.Errorf("escaped %q does not match itself", in)
+		}
+	}
+}
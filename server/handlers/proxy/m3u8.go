@@ -11,6 +11,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/synctv-org/synctv/cmd/flags"
 	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/settings"
 	"github.com/synctv-org/synctv/server/model"
 	"github.com/synctv-org/synctv/utils"
 	"github.com/synctv-org/synctv/utils/m3u8"
@@ -58,11 +59,13 @@ const maxM3u8FileSize = 3 * 1024 * 1024 //
 
 func M3u8Data(ctx *gin.Context, data []byte, baseURL string, token, roomID, movieID string) error {
 	hasM3u8File := false
+	var segments []string
 	err := m3u8.RangeM3u8SegmentsWithBaseURL(stream.BytesToString(data), baseURL, func(segmentUrl string) (bool, error) {
 		if utils.IsM3u8Url(segmentUrl) {
 			hasM3u8File = true
 			return false, nil
 		}
+		segments = append(segments, segmentUrl)
 		return true, nil
 	})
 	if err != nil {
@@ -88,6 +91,9 @@ func M3u8Data(ctx *gin.Context, data []byte, baseURL string, token, roomID, movi
 		)
 		return fmt.Errorf("replace m3u8 segments with base url error: %w", err)
 	}
+	if !hasM3u8File && settings.ProxyCacheEnable.Get() && settings.ProxyPrefetchSegments.Get() > 0 {
+		defaultPrefetcher.indexSegments(cacheScope(roomID, movieID), segments)
+	}
 	ctx.Data(http.StatusOK, hls.M3U8ContentType, stream.StringToBytes(m3u8Str))
 	return nil
 }
@@ -95,6 +101,10 @@ func M3u8Data(ctx *gin.Context, data []byte, baseURL string, token, roomID, movi
 // only cache non-m3u8 files
 func M3u8(ctx *gin.Context, u string, headers map[string]string, isM3u8File bool, token, roomID, movieID string, opts ...Option) error {
 	if !isM3u8File {
+		opts = append([]Option{WithProxyURLCacheScope(roomID, movieID)}, opts...)
+		if o := NewProxyURLOptions(opts...); o.Cache && o.CacheKey == "" && settings.ProxyCacheEnable.Get() {
+			defaultPrefetcher.prefetchSegments(roomID, o.CacheScope, u, headers)
+		}
 		return URL(ctx, u, headers, opts...)
 	}
 	if flags.Global.Dev {
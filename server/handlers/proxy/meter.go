@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Meter accounts and paces the bytes proxied to a client
+type Meter interface {
+	// Wait blocks until n bytes can be written, an error stops the response
+	Wait(ctx context.Context, n int) error
+	Written(n int)
+}
+
+type meterContextKey struct{}
+
+// WithMeter attaches the meter to the request, the proxies write the response through it
+func WithMeter(r *http.Request, m Meter) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), meterContextKey{}, m))
+}
+
+func meterFromContext(ctx context.Context) Meter {
+	m, _ := ctx.Value(meterContextKey{}).(Meter)
+	return m
+}
+
+// NewMeteredWriter writes to w through the meter of the request context,
+// w is returned as is if the request has no meter
+func NewMeteredWriter(ctx context.Context, w io.Writer) io.Writer {
+	m := meterFromContext(ctx)
+	if m == nil {
+		return w
+	}
+	return &meteredWriter{ctx: ctx, w: w, m: m}
+}
+
+// NewMeteredResponseWriter is NewMeteredWriter for the handlers writing through a gin.ResponseWriter
+func NewMeteredResponseWriter(ctx context.Context, w gin.ResponseWriter) gin.ResponseWriter {
+	m := meterFromContext(ctx)
+	if m == nil {
+		return w
+	}
+	return &meteredResponseWriter{ResponseWriter: w, w: &meteredWriter{ctx: ctx, w: w, m: m}}
+}
+
+type meteredResponseWriter struct {
+	gin.ResponseWriter
+	w io.Writer
+}
+
+func (w *meteredResponseWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (w *meteredResponseWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
+
+type meteredWriter struct {
+	ctx context.Context
+	w   io.Writer
+	m   Meter
+}
+
+// Write splits p into buffer sized chunks so the large slices are paced smoothly
+func (w *meteredWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		chunk := p[:min(len(p), DefaultBufferSize)]
+		if err := w.m.Wait(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.w.Write(chunk)
+		w.m.Written(n)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
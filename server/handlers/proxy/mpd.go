@@ -0,0 +1,494 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/synctv-org/synctv/cmd/flags"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zencoder/go-dash/v3/mpd"
+	"github.com/zijiren233/go-uhc"
+	"github.com/zijiren233/stream"
+)
+
+const DashContentType = "application/dash+xml"
+
+type MpdTargetClaims struct {
+	jwt.RegisteredClaims
+	RoomID  string `json:"r"`
+	MovieID string `json:"m"`
+	// the absolute target url, or the base url of the template
+	TargetURL string `json:"t"`
+	// SegmentTemplate with $identifiers$ filled by the player through the query
+	Template string `json:"p,omitempty"`
+}
+
+func GetMpdTarget(token string) (*MpdTargetClaims, error) {
+	t, err := jwt.ParseWithClaims(token, &MpdTargetClaims{}, func(token *jwt.Token) (any, error) {
+		return stream.StringToBytes(conf.Conf.Jwt.Secret), nil
+	})
+	if err != nil || !t.Valid {
+		return nil, errors.New("auth failed")
+	}
+	claims, ok := t.Claims.(*MpdTargetClaims)
+	if !ok {
+		return nil, errors.New("auth failed")
+	}
+	return claims, nil
+}
+
+func NewMpdTargetToken(targetURL, template, roomID, movieID string) (string, error) {
+	claims := &MpdTargetClaims{
+		RoomID:    roomID,
+		MovieID:   movieID,
+		TargetURL: targetURL,
+		Template:  template,
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(stream.StringToBytes(conf.Conf.Jwt.Secret))
+}
+
+const (
+	maxMpdFileSize = 3 * 1024 * 1024
+	mpdCacheMaxAge = time.Minute * 10
+)
+
+// ISO 23009-1 5.3.9.4.4, $$ is an escaped $, it is matched in the same pass
+// so that a $ escaped right before an identifier is not taken as its delimiter
+var mpdTemplateIdentifierReg = regexp.MustCompile(`\$\$|\$(RepresentationID|Number|Bandwidth|Time|SubNumber)(%0\d+d)?\$`)
+
+func mpdTemplateIdentifiers(template string) []string {
+	var ids []string
+	for _, m := range mpdTemplateIdentifierReg.FindAllStringSubmatch(template, -1) {
+		if m[1] != "" {
+			ids = append(ids, m[0])
+		}
+	}
+	return ids
+}
+
+func fillMpdTemplate(template string, values url.Values) (string, error) {
+	var err error
+	s := mpdTemplateIdentifierReg.ReplaceAllStringFunc(template, func(s string) string {
+		if s == "$$" {
+			return "$"
+		}
+		name := mpdTemplateIdentifierReg.FindStringSubmatch(s)[1]
+		v := values.Get(name)
+		if v == "" {
+			err = fmt.Errorf("template identifier %s is empty", name)
+		}
+		return url.PathEscape(v)
+	})
+	if err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func resolveMpdURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+type mpdRewriter struct {
+	token, roomID, movieID string
+}
+
+func (w *mpdRewriter) proxyURL(targetURL, template string) (string, error) {
+	targetToken, err := NewMpdTargetToken(targetURL, template, w.roomID, w.movieID)
+	if err != nil {
+		return "", err
+	}
+	u := fmt.Sprintf("/api/room/movie/proxy/%s/mpd/%s?token=%s&roomId=%s", w.movieID, targetToken, w.token, w.roomID)
+	if template == "" {
+		return u, nil
+	}
+	var sb strings.Builder
+	sb.WriteString(u)
+	for _, id := range mpdTemplateIdentifiers(template) {
+		name := mpdTemplateIdentifierReg.FindStringSubmatch(id)[1]
+		sb.WriteString("&")
+		sb.WriteString(name)
+		sb.WriteString("=")
+		sb.WriteString(id)
+	}
+	return sb.String(), nil
+}
+
+// rewriteURL rewrites a plain url relative to base
+func (w *mpdRewriter) rewriteURL(base, ref string) (string, error) {
+	target, err := resolveMpdURL(base, ref)
+	if err != nil {
+		return "", err
+	}
+	return w.proxyURL(target, "")
+}
+
+// rewriteTemplate rewrites a SegmentTemplate url relative to base,
+// the template is resolved after the player fills the identifiers
+func (w *mpdRewriter) rewriteTemplate(base, template string) (string, error) {
+	if len(mpdTemplateIdentifiers(template)) == 0 {
+		return w.rewriteURL(base, strings.ReplaceAll(template, "$$", "$"))
+	}
+	return w.proxyURL(base, template)
+}
+
+func (w *mpdRewriter) rewriteStringPtr(base string, s *string, template bool) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var (
+		u   string
+		err error
+	)
+	if template {
+		u, err = w.rewriteTemplate(base, *s)
+	} else {
+		u, err = w.rewriteURL(base, *s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (w *mpdRewriter) rewriteSourceURL(base string, u *mpd.URL) (*mpd.URL, error) {
+	if u == nil {
+		return nil, nil
+	}
+	n := *u
+	if n.SourceURL == nil {
+		// the range is in the resource of the base url
+		n.SourceURL = &base
+	}
+	var err error
+	n.SourceURL, err = w.rewriteStringPtr(base, n.SourceURL, false)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// mergeSegmentTemplate applies the attributes of child over parent
+func mergeSegmentTemplate(parent, child *mpd.SegmentTemplate) *mpd.SegmentTemplate {
+	if parent == nil {
+		if child == nil {
+			return nil
+		}
+		n := *child
+		return &n
+	}
+	n := *parent
+	if child == nil {
+		return &n
+	}
+	if child.SegmentTimeline != nil {
+		n.SegmentTimeline = child.SegmentTimeline
+	}
+	if child.PresentationTimeOffset != nil {
+		n.PresentationTimeOffset = child.PresentationTimeOffset
+	}
+	if child.Duration != nil {
+		n.Duration = child.Duration
+	}
+	if child.Initialization != nil {
+		n.Initialization = child.Initialization
+	}
+	if child.Media != nil {
+		n.Media = child.Media
+	}
+	if child.StartNumber != nil {
+		n.StartNumber = child.StartNumber
+	}
+	if child.Timescale != nil {
+		n.Timescale = child.Timescale
+	}
+	return &n
+}
+
+func firstNonNil[T any](v ...*T) *T {
+	for _, p := range v {
+		if p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+func (w *mpdRewriter) rewriteRepresentation(base string, p *mpd.Period, as *mpd.AdaptationSet, r *mpd.Representation) error {
+	var err error
+	if len(r.BaseURL) != 0 {
+		base, err = resolveMpdURL(base, r.BaseURL[0])
+		if err != nil {
+			return err
+		}
+	}
+	// every representation gets its own rewritten segment information,
+	// the inherited ones are resolved against the representation base url
+	if st := mergeSegmentTemplate(mergeSegmentTemplate(p.SegmentTemplate, as.SegmentTemplate), r.SegmentTemplate); st != nil {
+		st.AdaptationSet = nil
+		if st.Initialization, err = w.rewriteStringPtr(base, st.Initialization, true); err != nil {
+			return err
+		}
+		if st.Media, err = w.rewriteStringPtr(base, st.Media, true); err != nil {
+			return err
+		}
+		r.SegmentTemplate = st
+		r.BaseURL = nil
+		return nil
+	}
+	if sl := firstNonNil(r.SegmentList, as.SegmentList, p.SegmentList); sl != nil {
+		n := *sl
+		if n.Initialization, err = w.rewriteSourceURL(base, n.Initialization); err != nil {
+			return err
+		}
+		if n.RepresentationIndex, err = w.rewriteSourceURL(base, n.RepresentationIndex); err != nil {
+			return err
+		}
+		n.SegmentURLs = make([]*mpd.SegmentURL, len(sl.SegmentURLs))
+		for i, su := range sl.SegmentURLs {
+			s := *su
+			if s.Media == nil {
+				// the media range is in the resource of the base url
+				s.Media = &base
+			}
+			if s.Media, err = w.rewriteStringPtr(base, s.Media, false); err != nil {
+				return err
+			}
+			if s.Index, err = w.rewriteStringPtr(base, s.Index, false); err != nil {
+				return err
+			}
+			n.SegmentURLs[i] = &s
+		}
+		r.SegmentList = &n
+		r.BaseURL = nil
+		return nil
+	}
+	if sb := firstNonNil(r.SegmentBase, as.SegmentBase, p.SegmentBase); sb != nil {
+		n := *sb
+		if n.Initialization != nil && n.Initialization.SourceURL != nil {
+			if n.Initialization, err = w.rewriteSourceURL(base, n.Initialization); err != nil {
+				return err
+			}
+		}
+		if n.RepresentationIndex != nil && n.RepresentationIndex.SourceURL != nil {
+			if n.RepresentationIndex, err = w.rewriteSourceURL(base, n.RepresentationIndex); err != nil {
+				return err
+			}
+		}
+		r.SegmentBase = &n
+	}
+	u, err := w.proxyURL(base, "")
+	if err != nil {
+		return err
+	}
+	r.BaseURL = []string{u}
+	return nil
+}
+
+func (w *mpdRewriter) rewrite(m *mpd.MPD, baseURL string) error {
+	var err error
+	if len(m.BaseURL) != 0 {
+		baseURL, err = resolveMpdURL(baseURL, m.BaseURL[0])
+		if err != nil {
+			return err
+		}
+		m.BaseURL = nil
+	}
+	// the manifest is served by the proxy, it must not be reloaded from the origin
+	m.Location = ""
+	for _, p := range m.Periods {
+		pBase := baseURL
+		if len(p.BaseURL) != 0 {
+			pBase, err = resolveMpdURL(baseURL, p.BaseURL[0])
+			if err != nil {
+				return err
+			}
+			p.BaseURL = nil
+		}
+		for _, as := range p.AdaptationSets {
+			for _, r := range as.Representations {
+				if err := w.rewriteRepresentation(pBase, p, as, r); err != nil {
+					return err
+				}
+			}
+			as.SegmentTemplate = nil
+			as.SegmentList = nil
+			as.SegmentBase = nil
+		}
+		p.SegmentTemplate = nil
+		p.SegmentList = nil
+		p.SegmentBase = nil
+	}
+	return nil
+}
+
+// MpdData rewrites the BaseURL, SegmentTemplate and SegmentURL of the dash manifest
+// to signed proxy urls and writes it to the response
+func MpdData(ctx *gin.Context, data []byte, baseURL string, token, roomID, movieID string) error {
+	m, err := mpd.ReadFromString(stream.BytesToString(data))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest,
+			model.NewAPIErrorStringResp(
+				fmt.Sprintf("parse mpd error: %v", err),
+			),
+		)
+		return fmt.Errorf("parse mpd error: %w", err)
+	}
+	w := &mpdRewriter{
+		token:   token,
+		roomID:  roomID,
+		movieID: movieID,
+	}
+	if err := w.rewrite(m, baseURL); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest,
+			model.NewAPIErrorStringResp(
+				fmt.Sprintf("rewrite mpd error: %v", err),
+			),
+		)
+		return fmt.Errorf("rewrite mpd error: %w", err)
+	}
+	s, err := m.WriteToString()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError,
+			model.NewAPIErrorStringResp(
+				fmt.Sprintf("write mpd error: %v", err),
+			),
+		)
+		return fmt.Errorf("write mpd error: %w", err)
+	}
+	ctx.Data(http.StatusOK, DashContentType, stream.StringToBytes(s))
+	return nil
+}
+
+// the key changes every mpdCacheMaxAge, so the manifest is refetched after a while
+func mpdCacheKey(scope, u string) string {
+	hash := sha256.Sum256(stream.StringToBytes(u))
+	return fmt.Sprintf("%smpd-%s-%d", scope, hex.EncodeToString(hash[:]), time.Now().Unix()/int64(mpdCacheMaxAge/time.Second))
+}
+
+func fetchMpd(ctx context.Context, u string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", utils.UA)
+	}
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxMpdFileSize {
+		return nil, fmt.Errorf("mpd file is too large: %d, max: %d (3MB)", resp.ContentLength, maxMpdFileSize)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxMpdFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response body error: %w", err)
+	}
+	return b, nil
+}
+
+// loadMpd returns the raw manifest, it is rewritten on every request
+// because the rewritten manifest contains the user token
+func loadMpd(ctx context.Context, u string, headers map[string]string, useCache bool, scope string) ([]byte, error) {
+	if !useCache {
+		return fetchMpd(ctx, u, headers)
+	}
+	key := mpdCacheKey(scope, u)
+	mu.Lock(key)
+	defer mu.Unlock(key)
+	c := getCache()
+	item, ok, err := c.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return item.Data, nil
+	}
+	b, err := fetchMpd(ctx, u, headers)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Set(key, &CacheItem{
+		Metadata: &CacheMetadata{
+			ContentType:        DashContentType,
+			ContentTotalLength: int64(len(b)),
+		},
+		Data: b,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Mpd proxies the dash manifest, all the urls in it are rewritten to go through MpdTarget
+func Mpd(ctx *gin.Context, u string, headers map[string]string, token, roomID, movieID string, opts ...Option) error {
+	if flags.Global.Dev {
+		ctx.Header(proxyURLHeader, u)
+	}
+	if err := checkProxyToLocal(ctx, u); err != nil {
+		return err
+	}
+	o := NewProxyURLOptions(opts...)
+	b, err := loadMpd(ctx, u, headers, o.Cache && settings.ProxyCacheEnable.Get(), cacheScope(roomID, movieID))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest,
+			model.NewAPIErrorStringResp(
+				fmt.Sprintf("load mpd error: %v", err),
+			),
+		)
+		return fmt.Errorf("load mpd error: %w", err)
+	}
+	return MpdData(ctx, b, u, token, roomID, movieID)
+}
+
+// MpdTarget proxies a segment referenced by a manifest rewritten by Mpd
+func MpdTarget(ctx *gin.Context, claims *MpdTargetClaims, headers map[string]string, opts ...Option) error {
+	u := claims.TargetURL
+	if claims.Template != "" {
+		ref, err := fillMpdTemplate(claims.Template, ctx.Request.URL.Query())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return err
+		}
+		u, err = resolveMpdURL(u, ref)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return err
+		}
+	}
+	return URL(ctx, u, headers, append([]Option{WithProxyURLCacheScope(claims.RoomID, claims.MovieID)}, opts...)...)
+}
@@ -0,0 +1,279 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/zencoder/go-dash/v3/mpd"
+)
+
+const testMpd = `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="static" mediaPresentationDuration="PT30S" minBufferTime="PT2S">
+  <Location>https://origin.example.com/live/manifest.mpd</Location>
+  <BaseURL>https://cdn.example.com/vod/</BaseURL>
+  <Period id="0">
+    <BaseURL>p1/</BaseURL>
+    <AdaptationSet id="1" mimeType="video/mp4" segmentAlignment="true">
+      <SegmentTemplate initialization="$RepresentationID$/init.mp4" media="$RepresentationID$/seg-$Number%05d$.m4s" startNumber="1" duration="2" timescale="1"></SegmentTemplate>
+      <Representation id="v720" bandwidth="2000000" width="1280" height="720" codecs="avc1.64001f"></Representation>
+      <Representation id="v1080" bandwidth="4000000" width="1920" height="1080" codecs="avc1.640028">
+        <BaseURL>https://cdn2.example.com/hd/</BaseURL>
+      </Representation>
+      <Representation id="v360" bandwidth="800000" width="640" height="360" codecs="avc1.64001e">
+        <SegmentTemplate media="low/$$$Number$.m4s"></SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" mimeType="audio/mp4" lang="en">
+      <SegmentList duration="2" timescale="1">
+        <Initialization sourceURL="init.mp4"></Initialization>
+        <SegmentURL media="1.m4s"></SegmentURL>
+        <SegmentURL mediaRange="0-999"></SegmentURL>
+      </SegmentList>
+      <Representation id="a1" bandwidth="128000" codecs="mp4a.40.2">
+        <BaseURL>audio/</BaseURL>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="3" mimeType="text/vtt" lang="en">
+      <Representation id="en" bandwidth="256">
+        <BaseURL>subs/en.vtt</BaseURL>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+// resolveProxiedMpdURL does what the player and MpdTarget do with a rewritten url,
+// it fills the template identifiers and returns the origin url
+func resolveProxiedMpdURL(t *testing.T, proxied string, identifiers map[string]string) string {
+	t.Helper()
+	for k, v := range identifiers {
+		proxied = strings.ReplaceAll(proxied, k, v)
+	}
+	u, err := url.Parse(proxied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := "/api/room/movie/proxy/movie/mpd/"
+	if !strings.HasPrefix(u.Path, prefix) {
+		t.Fatalf("unexpected proxy url: %s", proxied)
+	}
+	query := u.Query()
+	if query.Get("token") != "user-token" || query.Get("roomId") != "room" {
+		t.Fatalf("unexpected proxy query: %s", u.RawQuery)
+	}
+	claims, err := GetMpdTarget(strings.TrimPrefix(u.Path, prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.RoomID != "room" || claims.MovieID != "movie" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if claims.Template == "" {
+		return claims.TargetURL
+	}
+	ref, err := fillMpdTemplate(claims.Template, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := resolveMpdURL(claims.TargetURL, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+func TestMpdData(t *testing.T) {
+	conf.Conf = conf.DefaultConfig()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	err := MpdData(ctx, []byte(testMpd), "https://origin.example.com/live/manifest.mpd", "user-token", "room", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, DashContentType) {
+		t.Errorf("content type = %q, want %q", ct, DashContentType)
+	}
+
+	m, err := mpd.ReadFromString(w.Body.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Location != "" || len(m.BaseURL) != 0 {
+		t.Errorf("location = %q, base url = %v, want them removed", m.Location, m.BaseURL)
+	}
+	if len(m.Periods) != 1 || len(m.Periods[0].AdaptationSets) != 3 {
+		t.Fatalf("unexpected manifest structure: %s", w.Body.String())
+	}
+	p := m.Periods[0]
+	if len(p.BaseURL) != 0 {
+		t.Errorf("period base url = %v, want it removed", p.BaseURL)
+	}
+	number := map[string]string{"$Number%05d$": "00003", "$Number$": "3"}
+
+	video := p.AdaptationSets[0]
+	if video.SegmentTemplate != nil {
+		t.Error("adaptation set segment template is not moved to the representations")
+	}
+	templateTests := []struct {
+		id        string
+		wantInit  string
+		wantMedia string
+	}{
+		{"v720", "https://cdn.example.com/vod/p1/v720/init.mp4", "https://cdn.example.com/vod/p1/v720/seg-00003.m4s"},
+		{"v1080", "https://cdn2.example.com/hd/v1080/init.mp4", "https://cdn2.example.com/hd/v1080/seg-00003.m4s"},
+		{"v360", "https://cdn.example.com/vod/p1/v360/init.mp4", "https://cdn.example.com/vod/p1/low/$3.m4s"},
+	}
+	if len(video.Representations) != len(templateTests) {
+		t.Fatalf("video representations = %d, want %d", len(video.Representations), len(templateTests))
+	}
+	for i, tt := range templateTests {
+		r := video.Representations[i]
+		if r.ID == nil || *r.ID != tt.id {
+			t.Fatalf("representation %d id = %v, want %s", i, r.ID, tt.id)
+		}
+		if len(r.BaseURL) != 0 {
+			t.Errorf("%s: base url = %v, want it removed", tt.id, r.BaseURL)
+		}
+		st := r.SegmentTemplate
+		if st == nil || st.Initialization == nil || st.Media == nil {
+			t.Fatalf("%s: segment template is not rewritten", tt.id)
+		}
+		if st.StartNumber == nil || *st.StartNumber != 1 || st.Duration == nil || *st.Duration != 2 {
+			t.Errorf("%s: inherited segment template attributes are lost", tt.id)
+		}
+		identifiers := map[string]string{"$RepresentationID$": tt.id}
+		if got := resolveProxiedMpdURL(t, *st.Initialization, identifiers); got != tt.wantInit {
+			t.Errorf("%s: init = %s, want %s", tt.id, got, tt.wantInit)
+		}
+		for k, v := range number {
+			identifiers[k] = v
+		}
+		if got := resolveProxiedMpdURL(t, *st.Media, identifiers); got != tt.wantMedia {
+			t.Errorf("%s: media = %s, want %s", tt.id, got, tt.wantMedia)
+		}
+	}
+
+	audio := p.AdaptationSets[1]
+	if audio.SegmentList != nil || len(audio.Representations) != 1 {
+		t.Fatal("adaptation set segment list is not moved to the representation")
+	}
+	sl := audio.Representations[0].SegmentList
+	if sl == nil || sl.Initialization == nil || sl.Initialization.SourceURL == nil || len(sl.SegmentURLs) != 2 {
+		t.Fatal("segment list is not rewritten")
+	}
+	if got, want := resolveProxiedMpdURL(t, *sl.Initialization.SourceURL, nil), "https://cdn.example.com/vod/p1/audio/init.mp4"; got != want {
+		t.Errorf("segment list init = %s, want %s", got, want)
+	}
+	segmentTests := []struct {
+		wantMedia string
+		wantRange string
+	}{
+		{"https://cdn.example.com/vod/p1/audio/1.m4s", ""},
+		{"https://cdn.example.com/vod/p1/audio/", "0-999"},
+	}
+	for i, tt := range segmentTests {
+		su := sl.SegmentURLs[i]
+		if su.Media == nil {
+			t.Fatalf("segment %d media is not rewritten", i)
+		}
+		if got := resolveProxiedMpdURL(t, *su.Media, nil); got != tt.wantMedia {
+			t.Errorf("segment %d media = %s, want %s", i, got, tt.wantMedia)
+		}
+		var gotRange string
+		if su.MediaRange != nil {
+			gotRange = *su.MediaRange
+		}
+		if gotRange != tt.wantRange {
+			t.Errorf("segment %d media range = %q, want %q", i, gotRange, tt.wantRange)
+		}
+	}
+
+	text := p.AdaptationSets[2]
+	if len(text.Representations) != 1 || len(text.Representations[0].BaseURL) != 1 {
+		t.Fatal("base url is not rewritten")
+	}
+	if got, want := resolveProxiedMpdURL(t, text.Representations[0].BaseURL[0], nil), "https://cdn.example.com/vod/p1/subs/en.vtt"; got != want {
+		t.Errorf("base url = %s, want %s", got, want)
+	}
+}
+
+func TestMpdDataError(t *testing.T) {
+	conf.Conf = conf.DefaultConfig()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	if err := MpdData(ctx, []byte("not a manifest"), "https://origin.example.com/manifest.mpd", "user-token", "room", "movie"); err == nil {
+		t.Fatal("want error")
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestFillMpdTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   url.Values
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "identifiers",
+			template: "$RepresentationID$/seg-$Number%05d$-$Bandwidth$.m4s",
+			values:   url.Values{"RepresentationID": {"v720"}, "Number": {"00003"}, "Bandwidth": {"2000000"}},
+			want:     "v720/seg-00003-2000000.m4s",
+		},
+		{
+			name:     "time",
+			template: "chunk-$Time$.m4s",
+			values:   url.Values{"Time": {"180000"}},
+			want:     "chunk-180000.m4s",
+		},
+		{
+			name:     "escaped dollar",
+			template: "price$$$Number$$$.m4s",
+			values:   url.Values{"Number": {"7"}},
+			want:     "price$7$.m4s",
+		},
+		{
+			name:     "escaped identifier",
+			template: "$$Number$$/$Number$.m4s",
+			values:   url.Values{"Number": {"7"}},
+			want:     "$Number$/7.m4s",
+		},
+		{
+			name:     "path escape",
+			template: "$RepresentationID$/init.mp4",
+			values:   url.Values{"RepresentationID": {"../a b"}},
+			want:     "..%2Fa%20b/init.mp4",
+		},
+		{
+			name:     "missing identifier",
+			template: "$RepresentationID$/$Number$.m4s",
+			values:   url.Values{"RepresentationID": {"v720"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fillMpdTemplate(tt.template, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
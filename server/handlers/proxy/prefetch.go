@@ -0,0 +1,370 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/zijiren233/gencontainer/rwmap"
+)
+
+const (
+	prefetchTimeout = 2 * time.Minute
+	// a prefetched item not requested within this time is counted as unused
+	prefetchTrackTTL = 30 * time.Minute
+	// the bandwidth budget can be used ahead up to this time
+	prefetchMaxBurst = time.Second
+	// the largest hls segment read ahead, in slices
+	maxPrefetchSegmentSlices = 8
+)
+
+// PrefetchProxy is the source read by a prefetch, a new one is opened for every prefetch
+// since the source of the request is closed with the request
+type PrefetchProxy interface {
+	Proxy
+	io.Closer
+}
+
+type PrefetchSource func(ctx context.Context) PrefetchProxy
+
+// PrefetchStats counts the prefetches since startup
+type PrefetchStats struct {
+	Running int64
+	// items read ahead and their bytes
+	Prefetched uint64
+	Bytes      uint64
+	// prefetched items requested by a client later
+	Used uint64
+	// prefetched items not requested in time
+	Unused uint64
+	// items skipped because they were cached or being fetched
+	Deduplicated uint64
+	// prefetches skipped or stopped by the concurrency limits or the bandwidth budget
+	Throttled uint64
+	Failed    uint64
+}
+
+func (s *PrefetchStats) UsedRatio() float64 {
+	if s.Prefetched == 0 {
+		return 0
+	}
+	return float64(s.Used) / float64(s.Prefetched)
+}
+
+// prefetcher limits the concurrency and the bandwidth of all the prefetches,
+// a prefetch is skipped instead of waiting when a limit is reached
+type prefetcher struct {
+	lock    sync.Mutex
+	running int64
+	rooms   map[string]int64
+	next    time.Time
+
+	// prefetched keys and the unix time they were prefetched
+	tracked   rwmap.RWMap[string, int64]
+	sweepOnce sync.Once
+
+	// hls segments and the segments after them, see indexSegments
+	segments rwmap.RWMap[string, *segmentRef]
+
+	prefetched   atomic.Uint64
+	bytes        atomic.Uint64
+	used         atomic.Uint64
+	unused       atomic.Uint64
+	deduplicated atomic.Uint64
+	throttled    atomic.Uint64
+	failed       atomic.Uint64
+}
+
+var defaultPrefetcher = newPrefetcher()
+
+func newPrefetcher() *prefetcher {
+	return &prefetcher{
+		rooms: make(map[string]int64),
+	}
+}
+
+func GetPrefetchStats() *PrefetchStats {
+	return defaultPrefetcher.stats()
+}
+
+func (p *prefetcher) stats() *PrefetchStats {
+	p.lock.Lock()
+	running := p.running
+	p.lock.Unlock()
+	return &PrefetchStats{
+		Running:      running,
+		Prefetched:   p.prefetched.Load(),
+		Bytes:        p.bytes.Load(),
+		Used:         p.used.Load(),
+		Unused:       p.unused.Load(),
+		Deduplicated: p.deduplicated.Load(),
+		Throttled:    p.throttled.Load(),
+		Failed:       p.failed.Load(),
+	}
+}
+
+func (p *prefetcher) tryAcquire(roomID string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.running >= settings.ProxyPrefetchConcurrency.Get() ||
+		p.rooms[roomID] >= settings.ProxyPrefetchRoomConcurrency.Get() {
+		return false
+	}
+	p.running++
+	p.rooms[roomID]++
+	return true
+}
+
+func (p *prefetcher) release(roomID string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.running--
+	if p.rooms[roomID] <= 1 {
+		delete(p.rooms, roomID)
+	} else {
+		p.rooms[roomID]--
+	}
+}
+
+// takeBudget reserves the bandwidth for size bytes
+func (p *prefetcher) takeBudget(size int64) bool {
+	rate := settings.ProxyPrefetchBandwidth.Get()
+	if rate <= 0 {
+		return true
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	if p.next.Sub(now) > prefetchMaxBurst {
+		return false
+	}
+	p.next = p.next.Add(time.Duration(size * int64(time.Second) / (rate * 1024)))
+	return true
+}
+
+func (p *prefetcher) track(key string) {
+	p.sweepOnce.Do(func() {
+		go p.sweep()
+	})
+	p.tracked.Store(key, time.Now().Unix())
+}
+
+// markUsed is called when a key is served from the cache
+func (p *prefetcher) markUsed(key string) {
+	if _, ok := p.tracked.LoadAndDelete(key); ok {
+		p.used.Add(1)
+	}
+}
+
+func (p *prefetcher) isTracked(key string) bool {
+	_, ok := p.tracked.Load(key)
+	return ok
+}
+
+func (p *prefetcher) sweep() {
+	ticker := time.NewTicker(prefetchTrackTTL / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deadline := time.Now().Add(-prefetchTrackTTL).Unix()
+		p.tracked.Range(func(key string, at int64) bool {
+			if at < deadline && p.tracked.CompareAndDelete(key, at) {
+				p.unused.Add(1)
+			}
+			return true
+		})
+		p.segments.Range(func(key string, ref *segmentRef) bool {
+			if ref.playlist.updated < deadline {
+				p.segments.CompareAndDelete(key, ref)
+			}
+			return true
+		})
+	}
+}
+
+// prefetchTarget is a range of slices of a source to read ahead
+type prefetchTarget struct {
+	proxy *SliceCacheProxy
+	open  PrefetchSource
+	start int64
+	count int64
+	// -1 if unknown, the target stops at the first cached slice then
+	total int64
+}
+
+// prefetch reads the targets in order in the background
+func (p *prefetcher) prefetch(roomID string, targets ...*prefetchTarget) {
+	if len(targets) == 0 {
+		return
+	}
+	if !p.tryAcquire(roomID) {
+		p.throttled.Add(1)
+		return
+	}
+	go func() {
+		defer p.release(roomID)
+		for _, t := range targets {
+			if !p.prefetchTarget(t) {
+				return
+			}
+		}
+	}()
+}
+
+// prefetchTarget returns false if the bandwidth budget is used up
+func (p *prefetcher) prefetchTarget(t *prefetchTarget) bool {
+	var src PrefetchProxy
+	defer func() {
+		if src != nil {
+			src.Close()
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	total := t.total
+	for i := int64(0); i < t.count; i++ {
+		offset := t.start + i*t.proxy.sliceSize
+		if total >= 0 && offset >= total {
+			return true
+		}
+		key := cacheKey(t.proxy.scope, t.proxy.key, offset, t.proxy.sliceSize)
+		if !p.tryPrefetch(t.proxy.cache, key, func() (bool, error) {
+			if !p.takeBudget(t.proxy.sliceSize) {
+				return false, nil
+			}
+			if src == nil {
+				src = t.open(ctx)
+			}
+			item, err := t.proxy.withSource(src).fetchFromSource(offset)
+			if err != nil {
+				return true, err
+			}
+			if err := t.proxy.cache.Set(key, item); err != nil {
+				return true, err
+			}
+			total = item.Metadata.ContentTotalLength
+			p.prefetched.Add(1)
+			p.bytes.Add(uint64(len(item.Data)))
+			p.track(key)
+			return true, nil
+		}) {
+			return false
+		}
+		if total < 0 {
+			// the length is unknown without reading the slice
+			return true
+		}
+	}
+	return true
+}
+
+// tryPrefetch runs fetch if the key is neither cached nor being fetched,
+// it returns false if fetch is skipped for the bandwidth budget
+func (p *prefetcher) tryPrefetch(cache Cache, key string, fetch func() (bool, error)) bool {
+	if p.isTracked(key) || !mu.TryLock(key) {
+		p.deduplicated.Add(1)
+		return true
+	}
+	defer mu.Unlock(key)
+	if ok, err := cache.Has(key); err != nil || ok {
+		p.deduplicated.Add(1)
+		return true
+	}
+	fetched, err := fetch()
+	if !fetched {
+		p.throttled.Add(1)
+		return false
+	}
+	if err != nil {
+		p.failed.Add(1)
+		log.Debugf("prefetch %s error: %v", key, err)
+	}
+	return true
+}
+
+// segmentPlaylist is the segments of a media playlist in order
+type segmentPlaylist struct {
+	urls    []string
+	updated int64
+}
+
+type segmentRef struct {
+	playlist *segmentPlaylist
+	index    int
+}
+
+// indexSegments remembers the order of the segments, so the segments after the
+// one served can be found, a live playlist is indexed again on every refresh
+func (p *prefetcher) indexSegments(scope string, urls []string) {
+	if len(urls) < 2 {
+		return
+	}
+	p.sweepOnce.Do(func() {
+		go p.sweep()
+	})
+	pl := &segmentPlaylist{
+		urls:    urls,
+		updated: time.Now().Unix(),
+	}
+	for i, u := range urls[:len(urls)-1] {
+		p.segments.Store(scope+u, &segmentRef{playlist: pl, index: i})
+	}
+}
+
+func (p *prefetcher) nextSegments(scope, u string, n int64) []string {
+	ref, ok := p.segments.Load(scope + u)
+	if !ok {
+		return nil
+	}
+	urls := ref.playlist.urls[ref.index+1:]
+	if int64(len(urls)) > n {
+		urls = urls[:n]
+	}
+	return urls
+}
+
+// prefetchSegments reads the segments after u ahead
+func (p *prefetcher) prefetchSegments(roomID, scope, u string, headers map[string]string) {
+	n := settings.ProxyPrefetchSegments.Get()
+	if n <= 0 {
+		return
+	}
+	next := p.nextSegments(scope, u, n)
+	allowLocal := settings.AllowProxyToLocal.Get()
+	targets := make([]*prefetchTarget, 0, len(next))
+	for _, segment := range next {
+		// the segments are checked like the urls served by URL
+		if !allowLocal {
+			if l, err := utils.ParseURLIsLocalIP(segment); err != nil || l {
+				continue
+			}
+		}
+		targets = append(targets, &prefetchTarget{
+			proxy: NewSliceCacheProxy(segment, sliceSize, nil, getCache(), WithSliceCacheScope(scope)),
+			open:  httpPrefetchSource(segment, headers),
+			start: 0,
+			count: maxPrefetchSegmentSlices,
+			total: -1,
+		})
+	}
+	p.prefetch(roomID, targets...)
+}
+
+func httpPrefetchSource(u string, headers map[string]string) PrefetchSource {
+	return func(ctx context.Context) PrefetchProxy {
+		return NewHTTPReadSeekCloser(u,
+			WithContext(ctx),
+			WithHeadersMap(headers),
+			WithPerLength(sliceSize*3),
+		)
+	}
+}
@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/m3u8"
+	"github.com/synctv-org/synctv/utils/probe"
+	"github.com/zijiren233/go-uhc"
+)
+
+// container headers are small, so fetch them in small ranges
+const probePerLength = 256 * 1024
+
+// ProbeURL reads the media info of the url, m3u8 playlists are summed by #EXTINF,
+// other files are parsed from their container headers with range requests
+func ProbeURL(ctx context.Context, u string, headers map[string]string, isM3u8 bool) (*probe.Info, error) {
+	if isM3u8 {
+		return ProbeM3u8(ctx, u, headers)
+	}
+	rsc := NewHTTPReadSeekCloser(u,
+		WithContext(ctx),
+		WithHeadersMap(headers),
+		WithPerLength(probePerLength),
+		WithNotSupportSeekWhenNotSupportRange(true),
+	)
+	defer rsc.Close()
+	return probe.Probe(rsc)
+}
+
+func fetchM3u8(ctx context.Context, u string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("new request error: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", utils.UA)
+	}
+	resp, err := uhc.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("do request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxM3u8FileSize {
+		return "", fmt.Errorf("m3u8 file is too large: %d, max: %d (3MB)", resp.ContentLength, maxM3u8FileSize)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxM3u8FileSize))
+	if err != nil {
+		return "", fmt.Errorf("read response body error: %w", err)
+	}
+	return string(b), nil
+}
+
+// ProbeM3u8 sums the segment durations, a master playlist is resolved to its first variant
+func ProbeM3u8(ctx context.Context, u string, headers map[string]string) (*probe.Info, error) {
+	data, err := fetchM3u8(ctx, u, headers)
+	if err != nil {
+		return nil, err
+	}
+	duration, isMaster, err := m3u8.GetM3u8Duration(data)
+	if err != nil {
+		return nil, err
+	}
+	if isMaster {
+		var variant string
+		err = m3u8.RangeM3u8SegmentsWithBaseURL(data, u, func(segmentURL string) (bool, error) {
+			variant = segmentURL
+			return false, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if variant == "" {
+			return nil, errors.New("no variant stream in master playlist")
+		}
+		// the variant comes from the playlist, it is not checked with the movie url
+		if !settings.AllowProxyToLocal.Get() {
+			if l, err := utils.ParseURLIsLocalIP(variant); err != nil || l {
+				return nil, errors.New("local ip is not allowed")
+			}
+		}
+		if data, err = fetchM3u8(ctx, variant, headers); err != nil {
+			return nil, err
+		}
+		if duration, _, err = m3u8.GetM3u8Duration(data); err != nil {
+			return nil, err
+		}
+	}
+	return &probe.Info{
+		Format:   "m3u8",
+		Duration: duration,
+	}, nil
+}
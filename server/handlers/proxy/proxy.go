@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"github.com/synctv-org/synctv/cmd/flags"
 	"github.com/synctv-org/synctv/internal/conf"
@@ -21,9 +22,8 @@ import (
 )
 
 var (
-	defaultCache  *MemoryCache
-	fileCacheOnce sync.Once
-	fileCache     Cache
+	proxyCacheOnce sync.Once
+	proxyCache     Cache
 )
 
 const (
@@ -60,7 +60,16 @@ func parseProxyCacheSize(sizeStr string) (int64, error) {
 }
 
 func getCache() Cache {
-	fileCacheOnce.Do(func() {
+	proxyCacheOnce.Do(func() {
+		if conf.Conf.Server.ProxyCacheRedis != "" {
+			opts, err := redis.ParseURL(conf.Conf.Server.ProxyCacheRedis)
+			if err != nil {
+				log.Fatalf("parse proxy cache redis url error: %v", err)
+			}
+			log.Info("proxy cache use redis, the size is limited by the redis server")
+			proxyCache = NewRedisCache(redis.NewClient(opts))
+			return
+		}
 		size, err := parseProxyCacheSize(conf.Conf.Server.ProxyCacheSize)
 		if err != nil {
 			log.Fatalf("parse proxy cache size error: %v", err)
@@ -70,21 +79,44 @@ func getCache() Cache {
 		}
 		if conf.Conf.Server.ProxyCachePath == "" {
 			log.Infof("proxy cache path is empty, use memory cache, size: %d", size)
-			defaultCache = NewMemoryCache(0, WithMaxSizeBytes(size))
+			proxyCache = NewMemoryCache(0, WithMaxSizeBytes(size))
 			return
 		}
 		log.Infof("proxy cache path: %s, size: %d", conf.Conf.Server.ProxyCachePath, size)
-		fileCache = NewFileCache(conf.Conf.Server.ProxyCachePath, WithFileCacheMaxSizeBytes(size))
+		proxyCache = NewFileCache(conf.Conf.Server.ProxyCachePath, WithFileCacheMaxSizeBytes(size))
 	})
-	if fileCache != nil {
-		return fileCache
+	return proxyCache
+}
+
+// cacheScope prefixes the cache keys of a movie, so they can be purged by room or movie,
+// the ids have a fixed length so a scope is never a prefix of another one
+func cacheScope(roomID, movieID string) string {
+	if roomID == "" {
+		return ""
+	}
+	if movieID == "" {
+		return roomID + "-"
 	}
-	return defaultCache
+	return roomID + "-" + movieID + "-"
+}
+
+func GetCacheStats() (*CacheStats, error) {
+	return getCache().Stats()
+}
+
+// PurgeCache deletes the cached items of the movie, or of the whole room if movieID is empty
+func PurgeCache(roomID, movieID string) (int64, error) {
+	if roomID == "" {
+		return 0, errors.New("room id is empty")
+	}
+	return getCache().PurgePrefix(cacheScope(roomID, movieID))
 }
 
 type Options struct {
-	CacheKey string
-	Cache    bool
+	CacheKey   string
+	CacheScope string
+	RoomID     string
+	Cache      bool
 }
 
 type Option func(o *Options)
@@ -101,6 +133,14 @@ func WithProxyURLCacheKey(key string) Option {
 	}
 }
 
+// WithProxyURLCacheScope attaches the cached slices to the movie
+func WithProxyURLCacheScope(roomID, movieID string) Option {
+	return func(o *Options) {
+		o.CacheScope = cacheScope(roomID, movieID)
+		o.RoomID = roomID
+	}
+}
+
 func NewProxyURLOptions(opts ...Option) *Options {
 	o := &Options{}
 	for _, opt := range opts {
@@ -114,27 +154,35 @@ const (
 	proxyURLHeader = "X-Proxy-URL"
 )
 
+func checkProxyToLocal(ctx *gin.Context, u string) error {
+	if settings.AllowProxyToLocal.Get() {
+		return nil
+	}
+	if l, err := utils.ParseURLIsLocalIP(u); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest,
+			model.NewAPIErrorStringResp(
+				fmt.Sprintf("check url is local ip error: %v", err),
+			),
+		)
+		return fmt.Errorf("check url is local ip error: %w", err)
+	} else if l {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest,
+			model.NewAPIErrorStringResp(
+				"not allow proxy to local",
+			),
+		)
+		return errors.New("not allow proxy to local")
+	}
+	return nil
+}
+
 func URL(ctx *gin.Context, u string, headers map[string]string, opts ...Option) error {
 	if flags.Global.Dev {
 		ctx.Header(proxyURLHeader, u)
 	}
 	o := NewProxyURLOptions(opts...)
-	if !settings.AllowProxyToLocal.Get() {
-		if l, err := utils.ParseURLIsLocalIP(u); err != nil {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest,
-				model.NewAPIErrorStringResp(
-					fmt.Sprintf("check url is local ip error: %v", err),
-				),
-			)
-			return fmt.Errorf("check url is local ip error: %w", err)
-		} else if l {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest,
-				model.NewAPIErrorStringResp(
-					"not allow proxy to local",
-				),
-			)
-			return errors.New("not allow proxy to local")
-		}
+	if err := checkProxyToLocal(ctx, u); err != nil {
+		return err
 	}
 
 	if o.Cache && settings.ProxyCacheEnable.Get() {
@@ -149,8 +197,10 @@ func URL(ctx *gin.Context, u string, headers map[string]string, opts ...Option)
 		if o.CacheKey == "" {
 			o.CacheKey = u
 		}
-		return NewSliceCacheProxy(o.CacheKey, sliceSize, rsc, getCache()).
-			Proxy(ctx.Writer, ctx.Request)
+		return NewSliceCacheProxy(o.CacheKey, sliceSize, rsc, getCache(),
+			WithSliceCacheScope(o.CacheScope),
+			WithSlicePrefetch(o.RoomID, httpPrefetchSource(u, headers)),
+		).Proxy(ctx.Writer, ctx.Request)
 	}
 
 	ctx2, cf := context.WithCancel(ctx)
@@ -204,7 +254,7 @@ func URL(ctx *gin.Context, u string, headers map[string]string, opts ...Option)
 	ctx.Header("Content-Length", resp.Header.Get("Content-Length"))
 	ctx.Header("Content-Range", resp.Header.Get("Content-Range"))
 	ctx.Header("Content-Type", resp.Header.Get("Content-Type"))
-	_, err = copyBuffer(ctx.Writer, resp.Body)
+	_, err = copyBuffer(NewMeteredWriter(ctx.Request.Context(), ctx.Writer), resp.Body)
 	if err != nil && !errors.Is(err, io.EOF) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest,
 			model.NewAPIErrorStringResp(
@@ -220,5 +270,5 @@ func AutoProxyURL(ctx *gin.Context, u, t string, headers map[string]string, toke
 	if strings.HasPrefix(t, "m3u") || utils.IsM3u8Url(u) {
 		return M3u8(ctx, u, headers, true, token, roomID, movieID, opts...)
 	}
-	return URL(ctx, u, headers, opts...)
+	return URL(ctx, u, headers, append([]Option{WithProxyURLCacheScope(roomID, movieID)}, opts...)...)
 }
@@ -0,0 +1,247 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultRedisCacheKeyPrefix = "synctv:proxy:"
+	redisScanCount             = 1000
+)
+
+// RedisCache implements a Cache shared by multiple instances through a redis compatible server,
+// the memory limit and the eviction are left to the server
+type RedisCache struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	maxAge    time.Duration
+	counter   cacheCounter
+}
+
+type RedisCacheOption func(*RedisCache)
+
+func WithRedisCacheKeyPrefix(prefix string) RedisCacheOption {
+	return func(c *RedisCache) {
+		c.keyPrefix = prefix
+	}
+}
+
+func WithRedisCacheMaxAge(age time.Duration) RedisCacheOption {
+	return func(c *RedisCache) {
+		if age > 0 {
+			c.maxAge = age
+		}
+	}
+}
+
+func NewRedisCache(client redis.UniversalClient, opts ...RedisCacheOption) *RedisCache {
+	rc := &RedisCache{
+		client:    client,
+		keyPrefix: defaultRedisCacheKeyPrefix,
+		maxAge:    24 * time.Hour, // Default 1 day
+	}
+	for _, opt := range opts {
+		opt(rc)
+	}
+	return rc
+}
+
+func (c *RedisCache) Get(key string) (*CacheItem, bool, error) {
+	item, found, err := c.get(key)
+	if err == nil {
+		c.counter.count(found)
+	}
+	return item, found, err
+}
+
+func (c *RedisCache) get(key string) (*CacheItem, bool, error) {
+	if key == "" {
+		return nil, false, errors.New("cache key cannot be empty")
+	}
+
+	b, err := c.client.Get(context.Background(), c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get cache item: %w", err)
+	}
+
+	item := &CacheItem{}
+	if _, err := item.ReadFrom(bytes.NewReader(b)); err != nil {
+		return nil, false, fmt.Errorf("failed to read cache item: %w", err)
+	}
+
+	return item, true, nil
+}
+
+func (c *RedisCache) Has(key string) (bool, error) {
+	if key == "" {
+		return false, errors.New("cache key cannot be empty")
+	}
+
+	n, err := c.client.Exists(context.Background(), c.keyPrefix+key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check cache item: %w", err)
+	}
+	return n > 0, nil
+}
+
+// scan calls fn with the keys starting with prefix until fn returns false
+func (c *RedisCache) scan(ctx context.Context, prefix string, fn func(keys []string) (bool, error)) error {
+	match := escapeRedisGlob(c.keyPrefix+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan cache keys: %w", err)
+		}
+		if len(keys) > 0 {
+			goOn, err := fn(keys)
+			if err != nil || !goOn {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (c *RedisCache) GetAnyWithPrefix(prefix string) (*CacheItem, bool, error) {
+	if prefix == "" {
+		return nil, false, errors.New("prefix cannot be empty")
+	}
+
+	var (
+		item  *CacheItem
+		found bool
+	)
+	err := c.scan(context.Background(), prefix, func(keys []string) (bool, error) {
+		for _, key := range keys {
+			var err error
+			// the key may be expired or evicted after scanning
+			item, found, err = c.get(key[len(c.keyPrefix):])
+			if err != nil {
+				return false, err
+			}
+			if found {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	c.counter.count(found)
+	return item, found, nil
+}
+
+func (c *RedisCache) Set(key string, data *CacheItem) error {
+	if key == "" {
+		return errors.New("cache key cannot be empty")
+	}
+	if data == nil {
+		return errors.New("cannot cache nil CacheItem")
+	}
+
+	var buf bytes.Buffer
+	if _, err := data.WriteTo(&buf); err != nil {
+		return fmt.Errorf("failed to write cache item: %w", err)
+	}
+
+	if err := c.client.Set(context.Background(), c.keyPrefix+key, buf.Bytes(), c.maxAge).Err(); err != nil {
+		return fmt.Errorf("failed to set cache item: %w", err)
+	}
+	return nil
+}
+
+func (c *RedisCache) Delete(key string) error {
+	if key == "" {
+		return errors.New("cache key cannot be empty")
+	}
+
+	if err := c.client.Del(context.Background(), c.keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache item: %w", err)
+	}
+	return nil
+}
+
+func (c *RedisCache) PurgePrefix(prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, errors.New("prefix cannot be empty")
+	}
+
+	ctx := context.Background()
+	var purged int64
+	err := c.scan(ctx, prefix, func(keys []string) (bool, error) {
+		n, err := c.client.Del(ctx, keys...).Result()
+		if err != nil {
+			return false, fmt.Errorf("failed to delete cache items: %w", err)
+		}
+		purged += n
+		return true, nil
+	})
+	return purged, err
+}
+
+// Stats counts the entries of this cache, but the size is the memory used by the whole server
+func (c *RedisCache) Stats() (*CacheStats, error) {
+	stats := c.counter.stats("redis")
+
+	ctx := context.Background()
+	err := c.scan(ctx, "", func(keys []string) (bool, error) {
+		stats.Entries += int64(len(keys))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.client.Info(ctx, "memory").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server info: %w", err)
+	}
+	fields := parseRedisInfo(info)
+	stats.Size, _ = strconv.ParseInt(fields["used_memory"], 10, 64)
+	stats.MaxSize, _ = strconv.ParseInt(fields["maxmemory"], 10, 64)
+	return stats, nil
+}
+
+// parseRedisInfo returns the fields of the INFO reply
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\r\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// escapeRedisGlob escapes the special characters of the SCAN MATCH pattern
+func escapeRedisGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
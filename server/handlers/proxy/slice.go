@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/synctv-org/synctv/internal/settings"
 	"github.com/zijiren233/ksync"
 	"github.com/zijiren233/stream"
 )
@@ -33,27 +34,53 @@ type SliceCacheProxy struct {
 	r         Proxy
 	cache     Cache
 	key       string
+	scope     string
 	sliceSize int64
+
+	roomID         string
+	prefetchSource PrefetchSource
+}
+
+type SliceCacheProxyOption func(*SliceCacheProxy)
+
+// WithSliceCacheScope prefixes the cache keys, see cacheScope
+func WithSliceCacheScope(scope string) SliceCacheProxyOption {
+	return func(c *SliceCacheProxy) {
+		c.scope = scope
+	}
+}
+
+// WithSlicePrefetch reads the slices after the served one ahead from the source opened by open,
+// the prefetches of a room share the room concurrency limit
+func WithSlicePrefetch(roomID string, open PrefetchSource) SliceCacheProxyOption {
+	return func(c *SliceCacheProxy) {
+		c.roomID = roomID
+		c.prefetchSource = open
+	}
 }
 
 // NewSliceCacheProxy creates a new SliceCacheProxy instance
-func NewSliceCacheProxy(key string, sliceSize int64, r Proxy, cache Cache) *SliceCacheProxy {
-	return &SliceCacheProxy{
+func NewSliceCacheProxy(key string, sliceSize int64, r Proxy, cache Cache, opts ...SliceCacheProxyOption) *SliceCacheProxy {
+	c := &SliceCacheProxy{
 		key:       key,
 		sliceSize: sliceSize,
 		r:         r,
 		cache:     cache,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func cacheKey(key string, offset int64, sliceSize int64) string {
+func cacheKey(scope, key string, offset int64, sliceSize int64) string {
 	hash := sha256.Sum256(stream.StringToBytes(key))
-	return fmt.Sprintf("%s-%d-%d", hex.EncodeToString(hash[:]), sliceSize, offset)
+	return fmt.Sprintf("%s%s-%d-%d", scope, hex.EncodeToString(hash[:]), sliceSize, offset)
 }
 
-func cachePrefix(key string, sliceSize int64) string {
+func cachePrefix(scope, key string, sliceSize int64) string {
 	hash := sha256.Sum256(stream.StringToBytes(key))
-	return fmt.Sprintf("%s-%d", hex.EncodeToString(hash[:]), sliceSize)
+	return fmt.Sprintf("%s%s-%d", scope, hex.EncodeToString(hash[:]), sliceSize)
 }
 
 func alignedOffset(offset, sliceSize int64) int64 {
@@ -117,8 +144,10 @@ func (c *SliceCacheProxy) Proxy(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("failed to get cache item: %w", err)
 	}
 
+	c.prefetchNext(alignedOffset, cacheItem.Metadata.ContentTotalLength)
+
 	c.setResponseHeaders(w, byteRange, cacheItem, cached, r.Header.Get("Range") != "")
-	if err := c.writeResponse(w, byteRange, alignedOffset, cacheItem); err != nil {
+	if err := c.writeResponse(NewMeteredWriter(r.Context(), w), byteRange, alignedOffset, cacheItem); err != nil {
 		return fmt.Errorf("failed to write response: %w", err)
 	}
 	return nil
@@ -153,7 +182,7 @@ func (c *SliceCacheProxy) setResponseHeaders(w http.ResponseWriter, byteRange *B
 	}
 }
 
-func (c *SliceCacheProxy) writeResponse(w http.ResponseWriter, byteRange *ByteRange, alignedOffset int64, cacheItem *CacheItem) error {
+func (c *SliceCacheProxy) writeResponse(w io.Writer, byteRange *ByteRange, alignedOffset int64, cacheItem *CacheItem) error {
 	sliceOffset := byteRange.Start - alignedOffset
 	if sliceOffset < 0 {
 		return fmt.Errorf("slice offset cannot be negative, got: %d", sliceOffset)
@@ -207,7 +236,7 @@ func (c *SliceCacheProxy) getCacheItem(alignedOffset int64) (*CacheItem, bool, e
 		return nil, false, fmt.Errorf("cache item offset cannot be negative, got: %d", alignedOffset)
 	}
 
-	cacheKey := cacheKey(c.key, alignedOffset, c.sliceSize)
+	cacheKey := cacheKey(c.scope, c.key, alignedOffset, c.sliceSize)
 	mu.Lock(cacheKey)
 	defer mu.Unlock(cacheKey)
 
@@ -217,6 +246,7 @@ func (c *SliceCacheProxy) getCacheItem(alignedOffset int64) (*CacheItem, bool, e
 		return nil, false, fmt.Errorf("failed to get item from cache: %w", err)
 	}
 	if ok {
+		defaultPrefetcher.markUsed(cacheKey)
 		return slice, true, nil
 	}
 
@@ -234,6 +264,31 @@ func (c *SliceCacheProxy) getCacheItem(alignedOffset int64) (*CacheItem, bool, e
 	return slice, false, nil
 }
 
+// prefetchNext reads the slices after the one at offset ahead
+func (c *SliceCacheProxy) prefetchNext(offset, total int64) {
+	if c.prefetchSource == nil {
+		return
+	}
+	n := settings.ProxyPrefetchSlices.Get()
+	if n <= 0 || offset+c.sliceSize >= total {
+		return
+	}
+	defaultPrefetcher.prefetch(c.roomID, &prefetchTarget{
+		proxy: c,
+		open:  c.prefetchSource,
+		start: offset + c.sliceSize,
+		count: n,
+		total: total,
+	})
+}
+
+// withSource returns a copy reading from r
+func (c *SliceCacheProxy) withSource(r Proxy) *SliceCacheProxy {
+	n := *c
+	n.r = r
+	return &n
+}
+
 func (c *SliceCacheProxy) contentTotalLength() (int64, error) {
 	total, err := c.r.ContentTotalLength()
 	if err != nil {
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils/subtitle"
+)
+
+// ServeSubtitle serves the subtitle, converted to the format given by the format query
+// when present, so the player can request srt, ass or vtt for any subtitle.
+// The delay query in milliseconds shifts the cues, e.g. by the room subtitle delay
+func ServeSubtitle(ctx *gin.Context, name, format string, data []byte) error {
+	format = subtitle.NormalizeFormat(format)
+	if format == "" {
+		format = subtitle.NormalizeFormat(filepath.Ext(name))
+	}
+	if format == "" {
+		format = subtitle.Detect(data)
+	}
+	to := format
+	if target := ctx.Query("format"); target != "" {
+		to = subtitle.NormalizeFormat(target)
+		if to == "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest,
+				model.NewAPIErrorStringResp(fmt.Sprintf("unsupported subtitle format: %s", target)),
+			)
+			return fmt.Errorf("unsupported subtitle format: %s", target)
+		}
+	}
+	var delay int64
+	if d := ctx.Query("delay"); d != "" {
+		var err error
+		delay, err = strconv.ParseInt(d, 10, 64)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return fmt.Errorf("parse subtitle delay error: %w", err)
+		}
+	}
+	if to != format || delay != 0 {
+		converted, err := convertSubtitle(data, format, to, time.Duration(delay)*time.Millisecond)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, subtitle.ErrUnknownFormat) {
+				status = http.StatusBadRequest
+			}
+			ctx.AbortWithStatusJSON(status, model.NewAPIErrorResp(err))
+			return fmt.Errorf("convert subtitle error: %w", err)
+		}
+		data = converted
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + to
+		format = to
+	}
+	ctx.Header("Content-Type", subtitle.ContentType(format))
+	http.ServeContent(ctx.Writer, ctx.Request, name, time.Now(), bytes.NewReader(data))
+	return nil
+}
+
+func convertSubtitle(data []byte, from, to string, delay time.Duration) ([]byte, error) {
+	if delay == 0 {
+		return subtitle.Convert(data, from, to)
+	}
+	cues, err := subtitle.Parse(data, from)
+	if err != nil {
+		return nil, err
+	}
+	return subtitle.Encode(subtitle.Shift(cues, delay), to)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+func AdminProxyCacheStats(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	stats, err := proxy.GetCacheStats()
+	if err != nil {
+		log.Errorf("get proxy cache stats error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&model.ProxyCacheStatsResp{
+		Driver:   stats.Driver,
+		Entries:  stats.Entries,
+		Size:     stats.Size,
+		MaxSize:  stats.MaxSize,
+		Hits:     stats.Hits,
+		Misses:   stats.Misses,
+		HitRatio: stats.HitRatio(),
+	}))
+}
+
+// AdminPurgeProxyCache deletes the cached slices of a movie, or of a whole room if the movie id is empty
+func AdminPurgeProxyCache(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.PurgeProxyCacheReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	purged, err := proxy.PurgeCache(req.RoomID, req.MovieID)
+	if err != nil {
+		log.Errorf("purge proxy cache error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&model.PurgeProxyCacheResp{
+		Purged: purged,
+	}))
+}
+
+func AdminProxyPrefetchStats(ctx *gin.Context) {
+	stats := proxy.GetPrefetchStats()
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&model.ProxyPrefetchStatsResp{
+		Running:      stats.Running,
+		Prefetched:   stats.Prefetched,
+		Bytes:        stats.Bytes,
+		Used:         stats.Used,
+		Unused:       stats.Unused,
+		Deduplicated: stats.Deduplicated,
+		Throttled:    stats.Throttled,
+		Failed:       stats.Failed,
+		UsedRatio:    stats.UsedRatio(),
+	}))
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -64,6 +67,14 @@ func RoomInfo(ctx *gin.Context) {
 		return
 	}
 
+	var announcement gin.H
+	if room.Settings.HasAnnouncement(time.Now()) {
+		announcement = gin.H{
+			"content":  room.Settings.Announcement,
+			"expireAt": room.Settings.AnnouncementExpireAt,
+		}
+	}
+
 	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
 		"id":           room.ID,
 		"name":         room.Name,
@@ -73,6 +84,7 @@ func RoomInfo(ctx *gin.Context) {
 		"createdAt":    room.CreatedAt.UnixMilli(),
 		"status":       room.Status,
 		"enabledGuest": room.EnabledGuest(),
+		"announcement": announcement,
 
 		"member": gin.H{
 			"id":               user.ID,
@@ -121,11 +133,44 @@ func CreateRoom(ctx *gin.Context) {
 	}))
 }
 
-var roomHotCache = refreshcache0.NewRefreshCache[[]*model.RoomListResp](func(context.Context) ([]*model.RoomListResp, error) {
+// roomHotScoreCache holds the time-decayed viewer minutes of rooms,
+// stats are sampled every minute so there is no need to refresh it more often
+var roomHotScoreCache = refreshcache0.NewRefreshCache[map[string]float64](func(context.Context) (map[string]float64, error) {
+	halfLife := time.Duration(settings.RoomHotDecayHalfLife.Get()) * time.Hour
+	if halfLife <= 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	// older stats contribute less than 1/16 of their viewer minutes
+	stats, err := db.GetAllRoomStats(now.Add(-halfLife * 4))
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64)
+	for _, stat := range stats {
+		age := max(now.Sub(stat.StartAt.Add(time.Hour/2)), 0)
+		scores[stat.RoomID] += float64(stat.ViewerMinutes) * math.Exp2(-age.Hours()/halfLife.Hours())
+	}
+	return scores, nil
+}, time.Minute)
+
+// roomHotCache ranks the rooms with a hot score and the live rooms in the cache,
+// the scored rooms not in the cache are loaded from the database
+var roomHotCache = refreshcache0.NewRefreshCache[[]*model.RoomListResp](func(ctx context.Context) ([]*model.RoomListResp, error) {
+	scores, err := roomHotScoreCache.Get(ctx)
+	if err != nil {
+		logrus.Errorf("get room hot score failed: %v", err)
+	}
 	rooms := make([]*model.RoomListResp, 0)
+	cached := make(map[string]struct{})
 	op.RangeRoomCache(func(key string, value *synccache.Entry[*op.Room]) bool {
 		v := value.Value()
-		if !v.Settings.Hidden && v.IsActive() && !v.HubIsNotInited() {
+		cached[v.ID] = struct{}{}
+		if v.Settings.Hidden || !v.IsActive() {
+			return true
+		}
+		score := scores[v.ID]
+		if !v.HubIsNotInited() || score > 0 {
 			rooms = append(rooms, &model.RoomListResp{
 				RoomID:       v.ID,
 				RoomName:     v.Name,
@@ -134,29 +179,65 @@ var roomHotCache = refreshcache0.NewRefreshCache[[]*model.RoomListResp](func(con
 				Creator:      op.GetUserName(v.CreatorID),
 				CreatorID:    v.CreatorID,
 				CreatedAt:    v.CreatedAt.UnixMilli(),
+				Category:     v.Settings.Category,
+				Description:  v.Settings.Description,
+				Tags:         v.Settings.Tags,
+				HotScore:     score,
 			})
 		}
 		return true
 	})
 
-	slices.SortStableFunc(rooms, func(a, b *model.RoomListResp) int {
-		if a.ViewerCount == b.ViewerCount {
-			if a.RoomName == b.RoomName {
-				return 0
-			}
-			if natural.Less(a.RoomName, b.RoomName) {
-				return -1
-			}
-			return 1
-		} else if a.ViewerCount > b.ViewerCount {
-			return -1
+	missing := make([]string, 0)
+	for id, score := range scores {
+		if _, ok := cached[id]; !ok && score > 0 {
+			missing = append(missing, id)
 		}
-		return 1
-	})
+	}
+	if len(missing) != 0 {
+		loaded, err := genRoomListResp(
+			func(db *gorm.DB) *gorm.DB {
+				return db.InnerJoins("JOIN room_settings ON rooms.id = room_settings.id").
+					Where("rooms.id IN ?", missing)
+			},
+			db.WhereRoomSettingWithoutHidden(),
+			db.WhereStatus(dbModel.RoomStatusActive),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range loaded {
+			r.HotScore = scores[r.RoomID]
+			rooms = append(rooms, r)
+		}
+	}
+
+	slices.SortStableFunc(rooms, compareRoomHot)
 
 	return rooms, nil
 }, time.Second*3)
 
+func compareRoomHot(a, b *model.RoomListResp) int {
+	if a.HotScore != b.HotScore {
+		if a.HotScore > b.HotScore {
+			return -1
+		}
+		return 1
+	}
+	if a.ViewerCount == b.ViewerCount {
+		if a.RoomName == b.RoomName {
+			return 0
+		}
+		if natural.Less(a.RoomName, b.RoomName) {
+			return -1
+		}
+		return 1
+	} else if a.ViewerCount > b.ViewerCount {
+		return -1
+	}
+	return 1
+}
+
 func RoomHotList(ctx *gin.Context) {
 	log := ctx.MustGet("log").(*logrus.Entry)
 
@@ -174,12 +255,91 @@ func RoomHotList(ctx *gin.Context) {
 		return
 	}
 
+	tags := getRoomTagsQuery(ctx)
+	category := ctx.Query("category")
+	if len(tags) != 0 || category != "" {
+		r = slices.DeleteFunc(slices.Clone(r), func(rl *model.RoomListResp) bool {
+			if category != "" && rl.Category != category {
+				return true
+			}
+			for _, tag := range tags {
+				if !dbModel.RoomTags(rl.Tags).Has(tag) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	// rooms with the sort tag are ranked first, then by hot score and viewer count
+	if sortTag := dbModel.NormalizeRoomTag(ctx.Query("sortTag")); sortTag != "" {
+		r = slices.Clone(r)
+		slices.SortStableFunc(r, func(a, b *model.RoomListResp) int {
+			aHas, bHas := dbModel.RoomTags(a.Tags).Has(sortTag), dbModel.RoomTags(b.Tags).Has(sortTag)
+			switch {
+			case aHas && !bHas:
+				return -1
+			case !aHas && bHas:
+				return 1
+			default:
+				return compareRoomHot(a, b)
+			}
+		})
+	}
+
 	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
 		"total": len(r),
 		"list":  utils.GetPageItems(r, page, pageSize),
 	}))
 }
 
+// getRoomTagsQuery supports both `tag=a&tag=b` and `tag=a,b`
+func getRoomTagsQuery(ctx *gin.Context) []string {
+	var tags []string
+	for _, v := range ctx.QueryArray("tag") {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = dbModel.NormalizeRoomTag(tag); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func RoomTags(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	tags, err := db.GetAllRoomTags()
+	if err != nil {
+		log.Errorf("get room tags failed: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(tags))
+}
+
+func RoomStats(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	hours, err := strconv.ParseInt(ctx.DefaultQuery("hours", "24"), 10, 64)
+	if err != nil || hours < 1 || hours > settings.RoomStatsRetention.Get()*24 {
+		log.Errorf("get room stats failed: invalid hours: %s", ctx.Query("hours"))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid hours"))
+		return
+	}
+
+	stats, err := db.GetRoomStats(room.ID, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		log.Errorf("get room stats failed: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(stats))
+}
+
 func RoomList(ctx *gin.Context) {
 	log := ctx.MustGet("log").(*logrus.Entry)
 
@@ -198,8 +358,16 @@ func RoomList(ctx *gin.Context) {
 		db.WhereStatus(dbModel.RoomStatusActive),
 	}
 
+	if tags := getRoomTagsQuery(ctx); len(tags) != 0 {
+		scopes = append(scopes, db.WhereRoomTagsIn(tags))
+	}
+
+	if category := ctx.Query("category"); category != "" {
+		scopes = append(scopes, db.WhereRoomCategory(category))
+	}
+
 	if keyword := ctx.Query("keyword"); keyword != "" {
-		// search mode, all, name, creator
+		// search mode, all, name, creator, id, description
 		switch ctx.DefaultQuery("search", "all") {
 		case "all":
 			ids, err := db.GerUsersIDByUsernameLike(keyword)
@@ -208,7 +376,9 @@ func RoomList(ctx *gin.Context) {
 				ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
 				return
 			}
-			scopes = append(scopes, db.WhereRoomNameLikeOrCreatorInOrRoomsIDLike(keyword, ids, keyword))
+			scopes = append(scopes, db.WhereRoomNameLikeOrCreatorInOrRoomsIDLikeOrDescriptionMatch(keyword, ids, keyword, keyword))
+		case "description":
+			scopes = append(scopes, db.WhereRoomDescriptionMatch(keyword))
 		case "name":
 			scopes = append(scopes, db.WhereRoomNameLike(keyword))
 		case "creator":
@@ -245,6 +415,12 @@ func RoomList(ctx *gin.Context) {
 		} else {
 			scopes = append(scopes, db.OrderByAsc("name"))
 		}
+	case "category":
+		if desc {
+			scopes = append(scopes, db.OrderByDesc("room_settings.category"))
+		} else {
+			scopes = append(scopes, db.OrderByAsc("room_settings.category"))
+		}
 	default:
 		log.Errorf("get room list failed: not support sort")
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("not support sort"))
@@ -265,7 +441,7 @@ func RoomList(ctx *gin.Context) {
 }
 
 func genRoomListResp(scopes ...func(db *gorm.DB) *gorm.DB) ([]*model.RoomListResp, error) {
-	rs, err := db.GetAllRooms(scopes...)
+	rs, err := db.GetAllRooms(append(scopes, db.PreloadRoomSettings)...)
 	if err != nil {
 		return nil, err
 	}
@@ -281,6 +457,11 @@ func genRoomListResp(scopes ...func(db *gorm.DB) *gorm.DB) ([]*model.RoomListRes
 			CreatedAt:    r.CreatedAt.UnixMilli(),
 			Status:       r.Status,
 		}
+		if r.Settings != nil {
+			resp[i].Category = r.Settings.Category
+			resp[i].Description = r.Settings.Description
+			resp[i].Tags = r.Settings.Tags
+		}
 	}
 	return resp, nil
 }
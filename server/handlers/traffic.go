@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+// meterTraffic attaches the traffic meter of the user and the room to the request,
+// the request is aborted if either of them used up the monthly quota
+func meterTraffic(ctx *gin.Context, userID, roomID string) error {
+	m, err := op.NewTrafficMeter(userID, roomID)
+	if err != nil {
+		if errors.Is(err, op.ErrTrafficQuotaExceeded) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(err))
+		} else {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		}
+		return err
+	}
+	ctx.Request = proxy.WithMeter(ctx.Request, m)
+	return nil
+}
+
+func AdminTrafficUsage(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	page, pageSize, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		log.Errorf("get page and max error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	currentMonth := op.TrafficMonth(time.Now())
+	month := ctx.DefaultQuery("month", currentMonth)
+	if _, err := time.Parse("2006-01", month); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("month must be formatted as 2006-01"))
+		return
+	}
+
+	scopes := []func(db *gorm.DB) *gorm.DB{}
+	if subjectType := ctx.Query("subjectType"); subjectType != "" {
+		scopes = append(scopes, db.WhereTrafficSubjectType(dbModel.TrafficSubjectType(subjectType)))
+	}
+	if subjectID := ctx.Query("subjectId"); subjectID != "" {
+		scopes = append(scopes, db.WhereTrafficSubjectID(subjectID))
+	}
+
+	// save the bytes counted in the memory, so the usage is up to date
+	if month == currentMonth {
+		op.FlushTrafficUsage()
+	}
+
+	total, err := db.GetTrafficUsagesCount(month, scopes...)
+	if err != nil {
+		log.Errorf("get traffic usages count error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	usages, err := db.GetTrafficUsages(month, append(scopes, db.OrderByDesc("bytes"), db.Paginate(page, pageSize))...)
+	if err != nil {
+		log.Errorf("get traffic usages error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ids := make([]string, len(usages))
+	for i, u := range usages {
+		ids[i] = u.SubjectID
+	}
+	limits, err := db.GetTrafficLimits(db.WhereTrafficSubjectIDIn(ids))
+	if err != nil {
+		log.Errorf("get traffic limits error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	limitMap := make(map[string]*dbModel.TrafficLimit, len(limits))
+	for _, l := range limits {
+		limitMap[string(l.SubjectType)+l.SubjectID] = l
+	}
+
+	list := make([]*model.TrafficUsageResp, len(usages))
+	for i, u := range usages {
+		limit, ok := limitMap[string(u.SubjectType)+u.SubjectID]
+		if !ok {
+			limit = &dbModel.TrafficLimit{SubjectType: u.SubjectType, SubjectID: u.SubjectID}
+		}
+		list[i] = &model.TrafficUsageResp{
+			SubjectType:  u.SubjectType,
+			SubjectID:    u.SubjectID,
+			Month:        u.Month,
+			Bytes:        u.Bytes,
+			Bandwidth:    op.EffectiveTrafficBandwidth(limit),
+			MonthlyQuota: op.EffectiveTrafficQuota(limit),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
+		"total": total,
+		"list":  list,
+	}))
+}
+
+// AdminTrafficLimits lists the users and rooms not using the default limits
+func AdminTrafficLimits(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	limits, err := db.GetTrafficLimits(db.OrderByDesc("updated_at"))
+	if err != nil {
+		log.Errorf("get traffic limits error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(limits))
+}
+
+func AdminSetTrafficLimit(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.SetTrafficLimitReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	var err error
+	switch req.SubjectType {
+	case dbModel.TrafficSubjectUser:
+		_, err = op.LoadOrInitUserByID(req.SubjectID)
+	case dbModel.TrafficSubjectRoom:
+		_, err = op.LoadOrInitRoomByID(req.SubjectID)
+	}
+	if err != nil {
+		log.Errorf("load traffic subject error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	err = op.SetTrafficLimit(&dbModel.TrafficLimit{
+		SubjectType:  req.SubjectType,
+		SubjectID:    req.SubjectID,
+		Bandwidth:    req.Bandwidth,
+		MonthlyQuota: req.MonthlyQuota,
+	})
+	if err != nil {
+		log.Errorf("set traffic limit error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -142,6 +142,8 @@ func UserRooms(ctx *gin.Context) {
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusPending))
 	case "banned":
 		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusBanned))
+	case "archived":
+		scopes = append(scopes, db.WhereStatus(dbModel.RoomStatusArchived))
 	}
 
 	if keyword := ctx.Query("keyword"); keyword != "" {
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	pb "github.com/synctv-org/synctv/proto/message"
+	"github.com/synctv-org/synctv/server/handlers/vendors"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+)
+
+const (
+	vendorImportTimeout          = time.Hour
+	vendorImportProgressInterval = time.Second
+)
+
+// roomID -> jobID, only one vendor import job can run in a room at the same time
+var vendorImportJobs sync.Map
+
+// the running jobs are canceled at shutdown
+var (
+	vendorImportCtx, cancelVendorImports = context.WithCancel(context.Background())
+	vendorImportLock                     sync.Mutex
+	vendorImportWg                       sync.WaitGroup
+)
+
+// StopVendorImports cancels the running import jobs and waits for them
+func StopVendorImports() {
+	vendorImportLock.Lock()
+	cancelVendorImports()
+	vendorImportLock.Unlock()
+	vendorImportWg.Wait()
+}
+
+func startVendorImport(job *vendorImportJob, walk vendors.WalkFunc) error {
+	vendorImportLock.Lock()
+	defer vendorImportLock.Unlock()
+	if err := vendorImportCtx.Err(); err != nil {
+		return errors.New("server is shutting down")
+	}
+	vendorImportWg.Add(1)
+	go func() {
+		defer vendorImportWg.Done()
+		job.run(walk)
+	}()
+	return nil
+}
+
+type vendorImportJob struct {
+	lastReport time.Time
+	room       *op.Room
+	user       *op.User
+	req        *model.ImportVendorMoviesReq
+	log        *log.Entry
+	id         string
+	name       string
+}
+
+func (j *vendorImportJob) report(progress *pb.ImportProgress) {
+	progress.JobId = j.id
+	progress.Name = j.name
+	err := j.room.Broadcast(&pb.Message{
+		Type:      pb.MessageType_IMPORT_PROGRESS,
+		Timestamp: time.Now().UnixMilli(),
+		Sender: &pb.Sender{
+			Username: j.user.Username,
+			UserId:   j.user.ID,
+		},
+		Payload: &pb.Message_ImportProgress{
+			ImportProgress: progress,
+		},
+	})
+	if err != nil {
+		j.log.Errorf("broadcast import progress error: %v", err)
+	}
+}
+
+func (j *vendorImportJob) progress(scanned, matched int) error {
+	if matched > maxPlaylistItems {
+		return fmt.Errorf("too many items, max %d", maxPlaylistItems)
+	}
+	if time.Since(j.lastReport) < vendorImportProgressInterval {
+		return nil
+	}
+	j.lastReport = time.Now()
+	j.report(&pb.ImportProgress{
+		Scanned: int64(scanned),
+		Matched: int64(matched),
+	})
+	return nil
+}
+
+func (j *vendorImportJob) run(walk vendors.WalkFunc) {
+	defer vendorImportJobs.Delete(j.room.ID)
+
+	ctx, cancel := context.WithTimeout(vendorImportCtx, vendorImportTimeout)
+	defer cancel()
+
+	items, err := walk(ctx, j.user, j.req, j.progress)
+	if err == nil && len(items) == 0 {
+		err = errors.New("no matched files")
+	}
+	if err == nil {
+		err = validatePlaylistItems(items)
+	}
+	var movies []*dbModel.Movie
+	if err == nil {
+		movies, err = j.user.ImportRoomMovies(j.room, j.req.ParentID, items)
+	}
+	if err != nil {
+		j.log.Errorf("import vendor movies error: %v", err)
+		j.report(&pb.ImportProgress{
+			Done:  true,
+			Error: err.Error(),
+		})
+		return
+	}
+	j.report(&pb.ImportProgress{
+		Done: true,
+	})
+	probeMoviesMeta(j.room, movies...)
+}
+
+// ImportVendorMovies imports an alist folder or an emby item recursively into a static folder,
+// the import runs in background and reports progress with IMPORT_PROGRESS messages
+func ImportVendorMovies(ctx *gin.Context) {
+	room := ctx.MustGet("room").(*op.RoomEntry).Value()
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*log.Entry)
+
+	req := model.ImportVendorMoviesReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if !user.HasRoomPermission(room, dbModel.PermissionAddMovie) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(dbModel.ErrNoPermission))
+		return
+	}
+
+	walk, err := vendors.NewVendorWalker(req.Vendor)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if req.ParentID != "" {
+		mv, err := room.GetMovieByID(req.ParentID)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return
+		}
+		if !mv.IsFolder || mv.IsDynamicFolder() {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("parent is not a static folder"))
+			return
+		}
+	}
+
+	job := &vendorImportJob{
+		room: room,
+		user: user,
+		req:  &req,
+		id:   utils.SortUUID(),
+		name: path.Base(req.Path),
+	}
+	job.log = log.WithField("importJob", job.id)
+	if _, loaded := vendorImportJobs.LoadOrStore(room.ID, job.id); loaded {
+		ctx.AbortWithStatusJSON(http.StatusConflict, model.NewAPIErrorResp(errors.New("an import job is already running in this room")))
+		return
+	}
+	if err := startVendorImport(job, walk); err != nil {
+		vendorImportJobs.Delete(room.ID)
+		ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
+		"jobId": job.id,
+	}))
+}
@@ -1,7 +1,6 @@
 package vendoralist
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,7 +8,6 @@ import (
 	"path"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -21,6 +19,7 @@ import (
 	"github.com/synctv-org/synctv/server/handlers/proxy"
 	"github.com/synctv-org/synctv/server/model"
 	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
 	"github.com/synctv-org/vendors/api/alist"
 )
 
@@ -177,13 +176,13 @@ func (s *AlistVendorService) ProxyMovie(ctx *gin.Context) {
 }
 
 func (s *AlistVendorService) getCacheData(ctx *gin.Context) (*cache.AlistMovieCacheData, error) {
-	u, err := op.LoadOrInitUserByID(s.movie.Movie.CreatorID)
+	userCache, err := s.movie.AlistUserCache(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	data, err := s.movie.AlistCache().Get(ctx, &cache.AlistMovieCacheFuncArgs{
-		UserCache: u.Value().AlistCache(),
+		UserCache: userCache,
 		UserAgent: utils.UA,
 	})
 	if err != nil {
@@ -261,7 +260,9 @@ func (s *AlistVendorService) handleDefaultProvider(ctx *gin.Context, log *logrus
 			return
 		}
 
-		http.ServeContent(ctx.Writer, ctx.Request, subtitle.Name, time.Now(), bytes.NewReader(b))
+		if err := proxy.ServeSubtitle(ctx, subtitle.Name, subtitle.Type, b); err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+		}
 	default:
 		if !s.movie.Movie.MovieBase.Proxy {
 			log.Errorf("proxy vendor movie error: %v", "proxy is not enabled")
@@ -327,24 +328,52 @@ func (s *AlistVendorService) handleAliSubtitle(ctx *gin.Context, log *logrus.Ent
 		return
 	}
 
-	http.ServeContent(ctx.Writer, ctx.Request, subtitle.Name, time.Now(), bytes.NewReader(b))
+	if err := proxy.ServeSubtitle(ctx, subtitle.Name, subtitle.Type, b); err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+	}
+}
+
+// ProbeMovie reads the media info of the raw file behind the alist path
+func (s *AlistVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder {
+		return nil, errors.New("alist folder can't be probed")
+	}
+	userCache, err := s.movie.AlistUserCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.movie.AlistCache().Get(ctx, &cache.AlistMovieCacheFuncArgs{
+		UserCache: userCache,
+		UserAgent: utils.UA,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data.Provider == cache.AlistProviderAli {
+		ali, err := data.Ali.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return proxy.ProbeURL(ctx, ali.URL, nil, false)
+	}
+	return proxy.ProbeURL(ctx, data.URL, nil, utils.IsM3u8Url(data.URL))
 }
 
-func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	if s.movie.Proxy {
-		return s.GenProxyMovieInfo(ctx, user, userAgent, userToken)
+		return s.GenProxyMovieInfo(ctx, user, userAgent, mediaToken)
 	}
 
 	movie := s.movie.Clone()
 	var err error
 
-	creator, err := op.LoadOrInitUserByID(movie.CreatorID)
+	userCache, err := s.movie.AlistUserCache(ctx)
 	if err != nil {
 		return nil, err
 	}
 	alistCache := s.movie.AlistCache()
 	data, err := alistCache.Get(ctx, &cache.AlistMovieCacheFuncArgs{
-		UserCache: creator.Value().AlistCache(),
+		UserCache: userCache,
 		UserAgent: utils.UA,
 	})
 	if err != nil {
@@ -356,7 +385,7 @@ func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, us
 			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(data.Subtitles))
 		}
 		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
-			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, userToken, movie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID),
 			Type: subt.Type,
 		}
 	}
@@ -367,7 +396,7 @@ func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, us
 		if err != nil {
 			return nil, err
 		}
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 
 		rawStreamURL := data.URL
@@ -384,14 +413,14 @@ func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, us
 				movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(data.Subtitles))
 			}
 			movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
-				URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, len(data.Subtitles)+i, userToken, movie.RoomID),
+				URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, len(data.Subtitles)+i, mediaToken, movie.RoomID),
 				Type: subt.Type,
 			}
 		}
 
 	case cache.AlistProvider115:
 		data, err = alistCache.GetRefreshFunc()(ctx, &cache.AlistMovieCacheFuncArgs{
-			UserCache: creator.Value().AlistCache(),
+			UserCache: userCache,
 			UserAgent: userAgent,
 		})
 		if err != nil {
@@ -414,17 +443,17 @@ func (s *AlistVendorService) GenMovieInfo(ctx context.Context, user *op.User, us
 	return movie, nil
 }
 
-func (s *AlistVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+func (s *AlistVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	movie := s.movie.Clone()
 	var err error
 
-	creator, err := op.LoadOrInitUserByID(movie.CreatorID)
+	userCache, err := s.movie.AlistUserCache(ctx)
 	if err != nil {
 		return nil, err
 	}
 	alistCache := s.movie.AlistCache()
 	data, err := alistCache.Get(ctx, &cache.AlistMovieCacheFuncArgs{
-		UserCache: creator.Value().AlistCache(),
+		UserCache: userCache,
 		UserAgent: utils.UA,
 	})
 	if err != nil {
@@ -436,7 +465,7 @@ func (s *AlistVendorService) GenProxyMovieInfo(ctx context.Context, user *op.Use
 			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(data.Subtitles))
 		}
 		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
-			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, userToken, movie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID),
 			Type: subt.Type,
 		}
 	}
@@ -447,10 +476,10 @@ func (s *AlistVendorService) GenProxyMovieInfo(ctx context.Context, user *op.Use
 		if err != nil {
 			return nil, err
 		}
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 
-		rawStreamURL := fmt.Sprintf("/api/room/movie/proxy/%s?t=raw&token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		rawStreamURL := fmt.Sprintf("/api/room/movie/proxy/%s?t=raw&token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.MoreSources = []*dbModel.MoreSource{
 			{
 				Name: "raw",
@@ -464,19 +493,19 @@ func (s *AlistVendorService) GenProxyMovieInfo(ctx context.Context, user *op.Use
 				movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(data.Subtitles))
 			}
 			movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
-				URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, len(data.Subtitles)+i, userToken, movie.RoomID),
+				URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, len(data.Subtitles)+i, mediaToken, movie.RoomID),
 				Type: subt.Type,
 			}
 		}
 
 	case cache.AlistProvider115:
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = utils.GetURLExtension(data.URL)
 
 		// TODO: proxy subtitle
 
 	default:
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = utils.GetURLExtension(data.URL)
 	}
 
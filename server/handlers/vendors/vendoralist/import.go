@@ -0,0 +1,141 @@
+package vendoralist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/maruel/natural"
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/playlist"
+	"github.com/synctv-org/synctv/internal/vendor"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/vendors/api/alist"
+)
+
+const walkPageSize = 100
+
+type walker struct {
+	cli      alist.AlistHTTPServer
+	aucd     *cache.AlistUserCacheData
+	req      *model.ImportVendorMoviesReq
+	progress func(scanned, matched int) error
+	serverID string
+	scanned  int
+	matched  int
+}
+
+// Walk lists the alist path recursively and returns the matched files as a static folder tree,
+// progress is called after every listed page and stops the walk when it returns an error
+func Walk(ctx context.Context, user *op.User, req *model.ImportVendorMoviesReq, progress func(scanned, matched int) error) ([]*playlist.Item, error) {
+	serverID, filePath, err := dbModel.GetAlistServerIDFromPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	aucd, err := user.AlistCache().LoadOrStore(ctx, serverID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+			return nil, errors.New("alist server not found")
+		}
+		return nil, err
+	}
+	w := &walker{
+		cli:      vendor.LoadAlistClient(req.Backend),
+		aucd:     aucd,
+		req:      req,
+		progress: progress,
+		serverID: serverID,
+	}
+	return w.walk(ctx, "/"+strings.Trim(filePath, "/"), req.Depth)
+}
+
+func (w *walker) list(ctx context.Context, dir string) ([]*alist.FsListResp_FsListContent, error) {
+	var content []*alist.FsListResp_FsListContent
+	for page := uint64(1); ; page++ {
+		data, err := w.cli.FsList(ctx, &alist.FsListReq{
+			Token:    w.aucd.Token,
+			Password: w.req.Password,
+			Path:     dir,
+			Host:     w.aucd.Host,
+			Page:     page,
+			PerPage:  walkPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list %s error: %w", dir, err)
+		}
+		content = append(content, data.Content...)
+		w.scanned += len(data.Content)
+		if err := w.progress(w.scanned, w.matched); err != nil {
+			return nil, err
+		}
+		if len(data.Content) < walkPageSize || uint64(len(content)) >= data.Total {
+			return content, nil
+		}
+	}
+}
+
+func (w *walker) walk(ctx context.Context, dir string, depth int) ([]*playlist.Item, error) {
+	content, err := w.list(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(content, func(a, b *alist.FsListResp_FsListContent) int {
+		switch {
+		case a.Name == b.Name:
+			return 0
+		case natural.Less(a.Name, b.Name):
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	items := make([]*playlist.Item, 0, len(content))
+	for _, flr := range content {
+		if flr.IsDir {
+			if depth <= 1 {
+				continue
+			}
+			children, err := w.walk(ctx, path.Join(dir, flr.Name), depth-1)
+			if err != nil {
+				return nil, err
+			}
+			// skip folders without any matched file
+			if len(children) == 0 {
+				continue
+			}
+			items = append(items, &playlist.Item{
+				Movie: dbModel.MovieBase{
+					Name:     flr.Name,
+					IsFolder: true,
+				},
+				Children: children,
+			})
+			continue
+		}
+		if !w.req.MatchExt(flr.Name) {
+			continue
+		}
+		w.matched++
+		items = append(items, &playlist.Item{
+			Movie: dbModel.MovieBase{
+				Name: flr.Name,
+				VendorInfo: dbModel.VendorInfo{
+					Vendor:  dbModel.VendorAlist,
+					Backend: w.req.Backend,
+					Alist: &dbModel.AlistStreamingInfo{
+						Path:     dbModel.FormatAlistPath(w.serverID, path.Join(dir, flr.Name)),
+						Password: w.req.Password,
+					},
+				},
+			},
+		})
+	}
+	return items, nil
+}
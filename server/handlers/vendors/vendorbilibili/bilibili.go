@@ -1,13 +1,12 @@
 package vendorbilibili
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -18,7 +17,10 @@ import (
 	"github.com/synctv-org/synctv/server/handlers/proxy"
 	"github.com/synctv-org/synctv/server/model"
 	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+	"github.com/synctv-org/synctv/utils/subtitle"
 	"github.com/synctv-org/vendors/api/bilibili"
+	"github.com/zencoder/go-dash/v3/mpd"
 	"github.com/zijiren233/stream"
 	"golang.org/x/exp/maps"
 )
@@ -85,14 +87,14 @@ func (s *BilibiliVendorService) handleVideoProxy(ctx *gin.Context, log *logrus.E
 		return
 	}
 
-	u, err := op.LoadOrInitUserByID(s.movie.Movie.CreatorID)
+	_, userCache, err := s.movie.BilibiliSharedUserCache(ctx)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
 		return
 	}
 
-	mpdC, err := s.movie.BilibiliCache().SharedMpd.Get(ctx, u.Value().BilibiliCache())
+	mpdC, err := s.movie.BilibiliCache().SharedMpd.Get(ctx, userCache)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
@@ -142,6 +144,7 @@ func (s *BilibiliVendorService) handleStreamProxy(ctx *gin.Context, log *logrus.
 		mpdC.URLs[streamID],
 		headers,
 		proxy.WithProxyURLCache(true),
+		proxy.WithProxyURLCacheScope(s.movie.RoomID, s.movie.ID),
 	)
 	if err != nil {
 		log.Errorf("proxy vendor movie [%s] error: %v", mpdC.URLs[streamID], err)
@@ -170,14 +173,14 @@ func (s *BilibiliVendorService) handleSubtitleProxy(ctx *gin.Context, log *logru
 		return
 	}
 
-	u, err := op.LoadOrInitUserByID(s.movie.Movie.CreatorID)
+	_, userCache, err := s.movie.BilibiliSharedUserCache(ctx)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
 		return
 	}
 
-	srtI, err := s.movie.BilibiliCache().Subtitle.Get(ctx, u.Value().BilibiliCache())
+	srtI, err := s.movie.BilibiliCache().Subtitle.Get(ctx, userCache)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
@@ -191,7 +194,9 @@ func (s *BilibiliVendorService) handleSubtitleProxy(ctx *gin.Context, log *logru
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
 			return
 		}
-		http.ServeContent(ctx.Writer, ctx.Request, id, time.Now(), bytes.NewReader(srtData))
+		if err := proxy.ServeSubtitle(ctx, id, subtitle.FormatSRT, srtData); err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+		}
 		return
 	}
 
@@ -199,9 +204,57 @@ func (s *BilibiliVendorService) handleSubtitleProxy(ctx *gin.Context, log *logru
 	ctx.AbortWithStatusJSON(http.StatusNotFound, model.NewAPIErrorStringResp("subtitle not found"))
 }
 
-func (s *BilibiliVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+// ProbeMovie reads the duration and the best video representation from the dash mpd
+func (s *BilibiliVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder || s.movie.Live {
+		return nil, errors.New("only bilibili video can be probed")
+	}
+	_, userCache, err := s.movie.BilibiliSharedUserCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mpdC, err := s.movie.BilibiliCache().SharedMpd.Get(ctx, userCache)
+	if err != nil {
+		return nil, err
+	}
+	info := &probe.Info{
+		Format: "dash",
+	}
+	if d := mpdC.Mpd.MediaPresentationDuration; d != nil {
+		duration, err := mpd.ParseDuration(*d)
+		if err != nil {
+			return nil, fmt.Errorf("parse mpd duration error: %w", err)
+		}
+		info.Duration = duration.Seconds()
+	}
+	for _, p := range mpdC.Mpd.Periods {
+		for _, as := range p.AdaptationSets {
+			for _, r := range as.Representations {
+				if r.Codecs == nil {
+					continue
+				}
+				// e.g. avc1.640032
+				codec, _, _ := strings.Cut(*r.Codecs, ".")
+				if r.Width == nil || r.Height == nil {
+					if info.AudioCodec == "" {
+						info.AudioCodec = probe.NormalizeCodec(codec)
+					}
+					continue
+				}
+				if int(*r.Width) > info.Width {
+					info.VideoCodec = probe.NormalizeCodec(codec)
+					info.Width = int(*r.Width)
+					info.Height = int(*r.Height)
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+func (s *BilibiliVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	if s.movie.Proxy {
-		return s.GenProxyMovieInfo(ctx, user, userAgent, userToken)
+		return s.GenProxyMovieInfo(ctx, user, userAgent, mediaToken)
 	}
 
 	movie := s.movie.Clone()
@@ -212,22 +265,17 @@ func (s *BilibiliVendorService) GenMovieInfo(ctx context.Context, user *op.User,
 
 	bmc := s.movie.BilibiliCache()
 	if movie.MovieBase.Live {
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 		return movie, nil
 	}
 
 	var str string
-	if movie.MovieBase.VendorInfo.Bilibili.Shared {
-		var u *op.UserEntry
-		u, err = op.LoadOrInitUserByID(movie.CreatorID)
-		if err != nil {
-			return nil, err
-		}
-		str, err = s.movie.BilibiliCache().NoSharedMovie.LoadOrStore(ctx, movie.CreatorID, u.Value().BilibiliCache())
-	} else {
-		str, err = s.movie.BilibiliCache().NoSharedMovie.LoadOrStore(ctx, user.ID, user.BilibiliCache())
+	key, userCache, err := s.movie.BilibiliUserCache(ctx, user)
+	if err != nil {
+		return nil, err
 	}
+	str, err = s.movie.BilibiliCache().NoSharedMovie.LoadOrStore(ctx, key, userCache)
 	if err != nil {
 		return nil, err
 	}
@@ -242,14 +290,14 @@ func (s *BilibiliVendorService) GenMovieInfo(ctx context.Context, user *op.User,
 			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(srt))
 		}
 		movie.MovieBase.Subtitles[k] = &dbModel.Subtitle{
-			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&n=%s&token=%s&roomId=%s", movie.ID, k, userToken, movie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&n=%s&token=%s&roomId=%s", movie.ID, k, mediaToken, movie.RoomID),
 			Type: "srt",
 		}
 	}
 	return movie, nil
 }
 
-func (s *BilibiliVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+func (s *BilibiliVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	movie := s.movie.Clone()
 	var err error
 	if movie.IsFolder {
@@ -258,18 +306,18 @@ func (s *BilibiliVendorService) GenProxyMovieInfo(ctx context.Context, user *op.
 
 	bmc := s.movie.BilibiliCache()
 	if movie.MovieBase.Live {
-		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 		movie.MovieBase.Type = "m3u8"
 		return movie, nil
 	}
 
-	movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, userToken, movie.RoomID)
+	movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
 	movie.MovieBase.Type = "mpd"
 	movie.MovieBase.MoreSources = []*dbModel.MoreSource{
 		{
 			Name: "hevc",
 			Type: "mpd",
-			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&t=hevc&roomId=%s", movie.ID, userToken, movie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&t=hevc&roomId=%s", movie.ID, mediaToken, movie.RoomID),
 		},
 	}
 	srt, err := bmc.Subtitle.Get(ctx, user.BilibiliCache())
@@ -281,7 +329,7 @@ func (s *BilibiliVendorService) GenProxyMovieInfo(ctx context.Context, user *op.
 			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(srt))
 		}
 		movie.MovieBase.Subtitles[k] = &dbModel.Subtitle{
-			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&n=%s&token=%s&roomId=%s", movie.ID, k, userToken, movie.RoomID),
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&n=%s&token=%s&roomId=%s", movie.ID, k, mediaToken, movie.RoomID),
 			Type: "srt",
 		}
 	}
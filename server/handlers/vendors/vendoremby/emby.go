@@ -1,14 +1,12 @@
 package vendoremby
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -19,6 +17,7 @@ import (
 	"github.com/synctv-org/synctv/server/handlers/proxy"
 	"github.com/synctv-org/synctv/server/model"
 	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
 	"github.com/synctv-org/vendors/api/emby"
 )
 
@@ -112,14 +111,14 @@ func (s *EmbyVendorService) handleProxyMovie(ctx *gin.Context) {
 		return
 	}
 
-	u, err := op.LoadOrInitUserByID(s.movie.Movie.CreatorID)
+	userCache, err := s.movie.EmbyUserCache(ctx)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp(err.Error()))
 		return
 	}
 
-	embyC, err := s.movie.EmbyCache().Get(ctx, u.Value().EmbyCache())
+	embyC, err := s.movie.EmbyCache().Get(ctx, userCache)
 	if err != nil {
 		log.Errorf("proxy vendor movie error: %v", err)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp(err.Error()))
@@ -179,12 +178,12 @@ func (s *EmbyVendorService) handleProxyMovie(ctx *gin.Context) {
 }
 
 func (s *EmbyVendorService) handleSubtitle(ctx *gin.Context) error {
-	u, err := op.LoadOrInitUserByID(s.movie.Movie.CreatorID)
+	userCache, err := s.movie.EmbyUserCache(ctx)
 	if err != nil {
 		return err
 	}
 
-	embyC, err := s.movie.EmbyCache().Get(ctx, u.Value().EmbyCache())
+	embyC, err := s.movie.EmbyCache().Get(ctx, userCache)
 	if err != nil {
 		return err
 	}
@@ -212,8 +211,8 @@ func (s *EmbyVendorService) handleSubtitle(ctx *gin.Context) error {
 		return err
 	}
 
-	http.ServeContent(ctx.Writer, ctx.Request, embyC.Sources[source].Subtitles[id].Name, time.Now(), bytes.NewReader(data))
-	return nil
+	subtitle := embyC.Sources[source].Subtitles[id]
+	return proxy.ServeSubtitle(ctx, subtitle.Name, subtitle.Type, data)
 }
 
 func (s *EmbyVendorService) ProxyMovie(ctx *gin.Context) {
@@ -221,25 +220,47 @@ func (s *EmbyVendorService) ProxyMovie(ctx *gin.Context) {
 	case "":
 		s.handleProxyMovie(ctx)
 	case "subtitle":
-		s.handleSubtitle(ctx)
+		if err := s.handleSubtitle(ctx); err != nil && !ctx.IsAborted() {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		}
 	default:
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp(fmt.Sprintf("unknown proxy type: %s", t)))
 	}
 }
 
-func (s *EmbyVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+// ProbeMovie reads the media info of the first source, transcoded sources are summed from the m3u8
+func (s *EmbyVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder {
+		return nil, errors.New("emby folder can't be probed")
+	}
+	userCache, err := s.movie.EmbyUserCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	embyC, err := s.movie.EmbyCache().Get(ctx, userCache)
+	if err != nil {
+		return nil, err
+	}
+	if len(embyC.Sources) == 0 {
+		return nil, errors.New("no source")
+	}
+	source := embyC.Sources[0]
+	return proxy.ProbeURL(ctx, source.URL, nil, source.IsTranscode)
+}
+
+func (s *EmbyVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	if s.movie.Proxy {
-		return s.GenProxyMovieInfo(ctx, user, userAgent, userToken)
+		return s.GenProxyMovieInfo(ctx, user, userAgent, mediaToken)
 	}
 
 	movie := s.movie.Clone()
 	var err error
 
-	u, err := op.LoadOrInitUserByID(movie.CreatorID)
+	userCache, err := s.movie.EmbyUserCache(ctx)
 	if err != nil {
 		return nil, err
 	}
-	data, err := s.movie.EmbyCache().Get(ctx, u.Value().EmbyCache())
+	data, err := s.movie.EmbyCache().Get(ctx, userCache)
 	if err != nil {
 		return nil, err
 	}
@@ -279,15 +300,15 @@ func (s *EmbyVendorService) GenMovieInfo(ctx context.Context, user *op.User, use
 	return movie, nil
 }
 
-func (s *EmbyVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, userToken string) (*dbModel.Movie, error) {
+func (s *EmbyVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
 	movie := s.movie.Clone()
 	var err error
 
-	u, err := op.LoadOrInitUserByID(movie.CreatorID)
+	userCache, err := s.movie.EmbyUserCache(ctx)
 	if err != nil {
 		return nil, err
 	}
-	data, err := s.movie.EmbyCache().Get(ctx, u.Value().EmbyCache())
+	data, err := s.movie.EmbyCache().Get(ctx, userCache)
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +329,7 @@ func (s *EmbyVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User
 		}
 		rawQuery := url.Values{}
 		rawQuery.Set("source", strconv.Itoa(si))
-		rawQuery.Set("token", userToken)
+		rawQuery.Set("token", mediaToken)
 		rawQuery.Set("roomId", movie.RoomID)
 		u := url.URL{
 			Path:     rawPath,
@@ -339,7 +360,7 @@ func (s *EmbyVendorService) GenProxyMovieInfo(ctx context.Context, user *op.User
 			rawQuery.Set("t", "subtitle")
 			rawQuery.Set("source", strconv.Itoa(si))
 			rawQuery.Set("id", strconv.Itoa(sbi))
-			rawQuery.Set("token", userToken)
+			rawQuery.Set("token", mediaToken)
 			rawQuery.Set("roomId", movie.RoomID)
 			u := url.URL{
 				Path:     rawPath,
@@ -0,0 +1,159 @@
+package vendoremby
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/maruel/natural"
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/playlist"
+	"github.com/synctv-org/synctv/internal/vendor"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/vendors/api/emby"
+)
+
+const walkPageSize = 100
+
+type walker struct {
+	cli      emby.EmbyHTTPServer
+	aucd     *cache.EmbyUserCacheData
+	req      *model.ImportVendorMoviesReq
+	progress func(scanned, matched int) error
+	serverID string
+	scanned  int
+	matched  int
+}
+
+// Walk lists the emby item recursively and returns the matched items as a static folder tree,
+// progress is called after every listed page and stops the walk when it returns an error
+func Walk(ctx context.Context, user *op.User, req *model.ImportVendorMoviesReq, progress func(scanned, matched int) error) ([]*playlist.Item, error) {
+	serverID, itemID, err := dbModel.GetEmbyServerIDFromPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	aucd, err := user.EmbyCache().LoadOrStore(ctx, serverID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+			return nil, errors.New("emby server not found")
+		}
+		return nil, err
+	}
+	w := &walker{
+		cli:      vendor.LoadEmbyClient(req.Backend),
+		aucd:     aucd,
+		req:      req,
+		progress: progress,
+		serverID: serverID,
+	}
+	return w.walk(ctx, itemID, req.Depth)
+}
+
+func (w *walker) list(ctx context.Context, itemID string) ([]*emby.Item, error) {
+	var items []*emby.Item
+	for {
+		data, err := w.cli.FsList(ctx, &emby.FsListReq{
+			Host:       w.aucd.Host,
+			Path:       itemID,
+			Token:      w.aucd.APIKey,
+			UserId:     w.aucd.UserID,
+			Limit:      walkPageSize,
+			StartIndex: uint64(len(items)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("emby fs list %s error: %w", itemID, err)
+		}
+		items = append(items, data.Items...)
+		w.scanned += len(data.Items)
+		if err := w.progress(w.scanned, w.matched); err != nil {
+			return nil, err
+		}
+		if len(data.Items) < walkPageSize || uint64(len(items)) >= data.Total {
+			return items, nil
+		}
+	}
+}
+
+// matchExt matches the ext filters with the container or the file path of the media sources
+func (w *walker) matchExt(item *emby.Item) bool {
+	if len(w.req.Exts) == 0 || len(item.MediaSourceInfo) == 0 {
+		return true
+	}
+	for _, source := range item.MediaSourceInfo {
+		if source.Path != "" && w.req.MatchExt(path.Base(source.Path)) {
+			return true
+		}
+		// container may be a comma separated list, e.g. mov,mp4,m4a
+		for _, container := range strings.Split(source.Container, ",") {
+			if w.req.MatchExt("." + container) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w *walker) walk(ctx context.Context, itemID string, depth int) ([]*playlist.Item, error) {
+	list, err := w.list(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(list, func(a, b *emby.Item) int {
+		switch {
+		case a.Name == b.Name:
+			return 0
+		case natural.Less(a.Name, b.Name):
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	items := make([]*playlist.Item, 0, len(list))
+	for _, i := range list {
+		if i.IsFolder {
+			if depth <= 1 {
+				continue
+			}
+			children, err := w.walk(ctx, i.Id, depth-1)
+			if err != nil {
+				return nil, err
+			}
+			// skip folders without any matched item
+			if len(children) == 0 {
+				continue
+			}
+			items = append(items, &playlist.Item{
+				Movie: dbModel.MovieBase{
+					Name:     i.Name,
+					IsFolder: true,
+				},
+				Children: children,
+			})
+			continue
+		}
+		if !w.matchExt(i) {
+			continue
+		}
+		w.matched++
+		items = append(items, &playlist.Item{
+			Movie: dbModel.MovieBase{
+				Name: i.Name,
+				VendorInfo: dbModel.VendorInfo{
+					Vendor:  dbModel.VendorEmby,
+					Backend: w.req.Backend,
+					Emby: &dbModel.EmbyStreamingInfo{
+						Path: dbModel.FormatEmbyPath(w.serverID, i.Id),
+					},
+				},
+			},
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,66 @@
+// Package vendorfs holds the helpers shared by the vendors browsing a file system,
+// like the local roots, the webdav and the s3 servers
+package vendorfs
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/maruel/natural"
+	"github.com/synctv-org/synctv/utils/subtitle"
+)
+
+// MaxSubtitleFileSize is the max size of a sidecar subtitle
+const MaxSubtitleFileSize = 16 * 1024 * 1024
+
+var ErrSubPathOutsideFolder = errors.New("sub path is not in parent path")
+
+// CompareEntries orders the dirs before the files and the names in natural order
+func CompareEntries(aName string, aIsDir bool, bName string, bIsDir bool) int {
+	if aIsDir != bIsDir {
+		if aIsDir {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case natural.Less(aName, bName):
+		return -1
+	case natural.Less(bName, aName):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SidecarSubtitle reports whether name is a subtitle named after the media file,
+// like movie.srt and movie.en.ass for movie.mkv, the label is the part between
+// the two names or the name itself
+func SidecarSubtitle(media, name string) (label, format string, ok bool) {
+	stem := strings.TrimSuffix(media, path.Ext(media))
+	if !strings.HasPrefix(name, stem+".") {
+		return "", "", false
+	}
+	ext := path.Ext(name)
+	format = subtitle.NormalizeFormat(ext)
+	if format == "" {
+		return "", "", false
+	}
+	label = strings.Trim(strings.TrimSuffix(strings.TrimPrefix(name, stem), ext), ".")
+	if label == "" {
+		label = name
+	}
+	return label, format, true
+}
+
+// JoinSubPath joins the sub path to the folder path, the result must be in the folder,
+// both are slash separated and the result starts with a slash
+func JoinSubPath(folderPath, subPath string) (string, error) {
+	folderPath = path.Clean("/" + folderPath)
+	p := path.Join(folderPath, subPath)
+	if p != folderPath && !strings.HasPrefix(p, strings.TrimRight(folderPath, "/")+"/") {
+		return "", ErrSubPathOutsideFolder
+	}
+	return p, nil
+}
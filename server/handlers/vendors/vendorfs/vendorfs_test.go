@@ -0,0 +1,115 @@
+package vendorfs_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+)
+
+func TestCompareEntries(t *testing.T) {
+	tests := []struct {
+		aName  string
+		aIsDir bool
+		bName  string
+		bIsDir bool
+		want   int
+	}{
+		{"b", true, "a", false, -1},
+		{"a", false, "b", true, 1},
+		{"ep2.mkv", false, "ep10.mkv", false, -1},
+		{"ep10.mkv", false, "ep2.mkv", false, 1},
+		{"Season 1", true, "Season 1", true, 0},
+		{"movie.mkv", false, "movie.mkv", false, 0},
+	}
+	for _, tt := range tests {
+		if got := vendorfs.CompareEntries(tt.aName, tt.aIsDir, tt.bName, tt.bIsDir); got != tt.want {
+			t.Errorf("CompareEntries(%q, %v, %q, %v) = %d, want %d", tt.aName, tt.aIsDir, tt.bName, tt.bIsDir, got, tt.want)
+		}
+	}
+
+	type entry struct {
+		name  string
+		isDir bool
+	}
+	entries := []entry{
+		{"ep10.mkv", false},
+		{"Season 2", true},
+		{"ep2.mkv", false},
+		{"Season 10", true},
+		{"ep1.mkv", false},
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return vendorfs.CompareEntries(a.name, a.isDir, b.name, b.isDir)
+	})
+	want := []entry{
+		{"Season 2", true},
+		{"Season 10", true},
+		{"ep1.mkv", false},
+		{"ep2.mkv", false},
+		{"ep10.mkv", false},
+	}
+	if !slices.Equal(entries, want) {
+		t.Errorf("sorted = %v, want %v", entries, want)
+	}
+}
+
+func TestSidecarSubtitle(t *testing.T) {
+	tests := []struct {
+		media      string
+		name       string
+		wantLabel  string
+		wantFormat string
+		wantOk     bool
+	}{
+		{"movie.mkv", "movie.srt", "movie.srt", "srt", true},
+		{"movie.mkv", "movie.en.ass", "en", "ass", true},
+		{"movie.mkv", "movie.zh-CN.forced.VTT", "zh-CN.forced", "vtt", true},
+		{"movie.2024.mkv", "movie.2024.en.srt", "en", "srt", true},
+		{"movie", "movie.srt", "movie.srt", "srt", true},
+		{"movie.mkv", "movie.txt", "", "", false},
+		{"movie.mkv", "movie.mkv", "", "", false},
+		{"movie.mkv", "movie2.srt", "", "", false},
+		{"movie.mkv", "other.srt", "", "", false},
+	}
+	for _, tt := range tests {
+		label, format, ok := vendorfs.SidecarSubtitle(tt.media, tt.name)
+		if label != tt.wantLabel || format != tt.wantFormat || ok != tt.wantOk {
+			t.Errorf("SidecarSubtitle(%q, %q) = %q, %q, %v, want %q, %q, %v",
+				tt.media, tt.name, label, format, ok, tt.wantLabel, tt.wantFormat, tt.wantOk)
+		}
+	}
+}
+
+func TestJoinSubPath(t *testing.T) {
+	tests := []struct {
+		folder  string
+		sub     string
+		want    string
+		wantErr bool
+	}{
+		{"/show", "Season 1", "/show/Season 1", false},
+		{"show/", "Season 1/ep1.mkv", "/show/Season 1/ep1.mkv", false},
+		{"/show", "", "/show", false},
+		{"/show", "Season 1/../Season 2", "/show/Season 2", false},
+		{"", "movie.mkv", "/movie.mkv", false},
+		{"/", "../movie.mkv", "/movie.mkv", false},
+		{"/show", "..", "", true},
+		{"/show", "../other", "", true},
+		{"/show", "Season 1/../../other", "", true},
+		{"/show", "../show2/ep1.mkv", "", true},
+	}
+	for _, tt := range tests {
+		got, err := vendorfs.JoinSubPath(tt.folder, tt.sub)
+		if tt.wantErr {
+			if !errors.Is(err, vendorfs.ErrSubPathOutsideFolder) {
+				t.Errorf("JoinSubPath(%q, %q) = %q, %v, want error", tt.folder, tt.sub, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("JoinSubPath(%q, %q) = %q, %v, want %q", tt.folder, tt.sub, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package vendorjellyfin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/jellyfin"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+type ListReq struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+}
+
+func (r *ListReq) Validate() (err error) {
+	return nil
+}
+
+func (r *ListReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type JellyfinFileItem struct {
+	*model.Item
+	Type string `json:"type"`
+}
+
+type JellyfinFSListResp = model.VendorFSListResp[*JellyfinFileItem]
+
+func List(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := ListReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	page, size, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if req.Path == "" {
+		if req.Keyword != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("keywords is not supported when not choose server (server id is empty)"))
+			return
+		}
+		socpes := [](func(*gorm.DB) *gorm.DB){
+			db.OrderByCreatedAtAsc,
+		}
+
+		total, err := db.GetJellyfinVendorsCount(user.ID, socpes...)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+		if total == 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("jellyfin server not found"))
+			return
+		}
+
+		jv, err := db.GetJellyfinVendors(user.ID, append(socpes, db.Paginate(page, size))...)
+		if err != nil {
+			if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+				ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("jellyfin server not found"))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+
+		if total == 1 {
+			req.Path = jv[0].ServerID + "/"
+			goto JellyfinFSListResp
+		}
+
+		resp := JellyfinFSListResp{
+			Paths: []*model.Path{
+				{
+					Name: "",
+					Path: "",
+				},
+			},
+			Total: uint64(total),
+		}
+
+		for _, jvi := range jv {
+			resp.Items = append(resp.Items, &JellyfinFileItem{
+				Item: &model.Item{
+					Name:  jvi.Host,
+					Path:  jvi.ServerID + `/`,
+					IsDir: true,
+				},
+				Type: "server",
+			})
+		}
+
+		ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+
+		return
+	}
+
+JellyfinFSListResp:
+
+	var serverID string
+	serverID, req.Path, err = dbModel.GetJellyfinServerIDFromPath(req.Path)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	jucd, err := user.JellyfinCache().LoadOrStore(ctx, serverID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("jellyfin server not found"))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	data, err := jucd.Client().List(ctx, &jellyfin.ListReq{
+		Path:       req.Path,
+		SearchTerm: req.Keyword,
+		Limit:      uint64(size),
+		StartIndex: uint64((page - 1) * size),
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(fmt.Errorf("jellyfin fs list error: %w", err)))
+		return
+	}
+
+	var resp JellyfinFSListResp = JellyfinFSListResp{
+		Paths: []*model.Path{
+			{},
+		},
+	}
+	for _, p := range data.Paths {
+		n := p.Name
+		if p.ID == "" {
+			n = jucd.Host
+		}
+		resp.Paths = append(resp.Paths, &model.Path{
+			Name: n,
+			Path: fmt.Sprintf("%s/%s", jucd.ServerID, p.ID),
+		})
+	}
+	for _, i := range data.Items {
+		resp.Items = append(resp.Items, &JellyfinFileItem{
+			Item: &model.Item{
+				Name:  i.Name,
+				Path:  fmt.Sprintf("%s/%s", jucd.ServerID, i.ID),
+				IsDir: i.IsFolder,
+			},
+			Type: i.Type,
+		})
+	}
+
+	resp.Total = data.Total
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+}
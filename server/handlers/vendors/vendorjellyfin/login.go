@@ -0,0 +1,189 @@
+package vendorjellyfin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/jellyfin"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+type LoginReq struct {
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// login with the api key instead of the password, the username picks the
+	// jellyfin user to browse as, the first administrator is used when empty
+	APIKey string `json:"apiKey"`
+}
+
+func (r *LoginReq) Validate() error {
+	if r.Host == "" {
+		return errors.New("host is required")
+	}
+	url, err := url.Parse(r.Host)
+	if err != nil {
+		return err
+	}
+	if url.Scheme != "http" && url.Scheme != "https" {
+		return errors.New("host is invalid")
+	}
+	r.Host = strings.TrimRight(url.String(), "/")
+	if r.Username == "" && r.APIKey == "" {
+		return errors.New("username is required")
+	}
+	return nil
+}
+
+func (r *LoginReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type loginResult struct {
+	Token    string
+	UserID   string
+	ServerID string
+}
+
+func loginByPassword(ctx context.Context, req *LoginReq) (*loginResult, error) {
+	data, err := jellyfin.NewClient(req.Host).AuthenticateByName(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	if data.User == nil {
+		return nil, errors.New("user is empty")
+	}
+	return &loginResult{
+		Token:    data.AccessToken,
+		UserID:   data.User.ID,
+		ServerID: data.ServerID,
+	}, nil
+}
+
+func loginByAPIKey(ctx context.Context, req *LoginReq) (*loginResult, error) {
+	cli := jellyfin.NewClient(req.Host, jellyfin.WithToken(req.APIKey))
+	info, err := cli.SystemInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users, err := cli.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.Policy.IsDisabled {
+			continue
+		}
+		if req.Username != "" && strings.EqualFold(u.Name, req.Username) ||
+			req.Username == "" && u.Policy.IsAdministrator {
+			return &loginResult{
+				Token:    req.APIKey,
+				UserID:   u.ID,
+				ServerID: info.ID,
+			}, nil
+		}
+	}
+	if req.Username != "" {
+		return nil, fmt.Errorf("jellyfin user %s not found", req.Username)
+	}
+	return nil, errors.New("jellyfin administrator not found")
+}
+
+func Login(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := LoginReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	var (
+		data *loginResult
+		err  error
+	)
+	if req.APIKey != "" {
+		data, err = loginByAPIKey(ctx, &req)
+	} else {
+		data, err = loginByPassword(ctx, &req)
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if data.ServerID == "" {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorStringResp("serverID is empty"))
+		return
+	}
+
+	_, err = db.CreateOrSaveJellyfinVendor(&dbModel.JellyfinVendor{
+		UserID:         user.ID,
+		ServerID:       data.ServerID,
+		Host:           req.Host,
+		APIKey:         data.Token,
+		JellyfinUserID: data.UserID,
+		UseAPIKey:      req.APIKey != "",
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	_, err = user.JellyfinCache().StoreOrRefreshWithDynamicFunc(ctx, data.ServerID, func(ctx context.Context, key string) (*cache.JellyfinUserCacheData, error) {
+		return &cache.JellyfinUserCacheData{
+			Host:      req.Host,
+			ServerID:  key,
+			APIKey:    data.Token,
+			UserID:    data.UserID,
+			UseAPIKey: req.APIKey != "",
+		}, nil
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func Logout(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	var req model.ServerIDReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	err := db.DeleteJellyfinVendor(user.ID, req.ServerID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	jucd, ok := user.JellyfinCache().LoadCache(req.ServerID)
+	if ok {
+		jucdr, _ := jucd.Raw()
+		go logoutJellyfin(jucdr)
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func logoutJellyfin(jucd *cache.JellyfinUserCacheData) {
+	if jucd == nil || jucd.APIKey == "" || jucd.UseAPIKey {
+		return
+	}
+	_ = jucd.Client().Logout(context.Background())
+}
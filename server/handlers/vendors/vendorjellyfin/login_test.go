@@ -0,0 +1,118 @@
+package vendorjellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newLoginStub serves the login endpoints, the password of alice is "password"
+// and the api key is "apikey"
+func newLoginStub(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /Users/AuthenticateByName", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Username string
+			Pw       string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Username != "alice" || body.Pw != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"User":{"Id":"u1","Name":"alice"},"AccessToken":"token","ServerId":"srv"}`)
+	})
+	apiKey := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.Header.Get("Authorization"), `Token="apikey"`) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /System/Info", apiKey(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"Id":"srv"}`)
+	}))
+	mux.HandleFunc("GET /Users", apiKey(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `[`+
+			`{"Id":"u0","Name":"root","Policy":{"IsAdministrator":true,"IsDisabled":true}},`+
+			`{"Id":"u1","Name":"alice","Policy":{"IsAdministrator":false}},`+
+			`{"Id":"u2","Name":"admin","Policy":{"IsAdministrator":true}}]`)
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestLoginByPassword(t *testing.T) {
+	host := newLoginStub(t)
+	res, err := loginByPassword(context.Background(), &LoginReq{Host: host, Username: "alice", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res != (loginResult{Token: "token", UserID: "u1", ServerID: "srv"}) {
+		t.Errorf("login = %+v", *res)
+	}
+	if _, err := loginByPassword(context.Background(), &LoginReq{Host: host, Username: "alice", Password: "wrong"}); err == nil {
+		t.Error("wrong password is not rejected")
+	}
+}
+
+func TestLoginByAPIKey(t *testing.T) {
+	host := newLoginStub(t)
+	tests := []struct {
+		name     string
+		apiKey   string
+		username string
+		userID   string
+		wantErr  bool
+	}{
+		{name: "user", apiKey: "apikey", username: "ALICE", userID: "u1"},
+		{name: "first enabled administrator", apiKey: "apikey", userID: "u2"},
+		{name: "disabled user", apiKey: "apikey", username: "root", wantErr: true},
+		{name: "unknown user", apiKey: "apikey", username: "bob", wantErr: true},
+		{name: "wrong api key", apiKey: "wrong", username: "alice", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := loginByAPIKey(context.Background(), &LoginReq{Host: host, Username: tt.username, APIKey: tt.apiKey})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("login = %+v, want error", *res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *res != (loginResult{Token: tt.apiKey, UserID: tt.userID, ServerID: "srv"}) {
+				t.Errorf("login = %+v", *res)
+			}
+		})
+	}
+}
+
+func TestLoginReqValidate(t *testing.T) {
+	tests := []struct {
+		req     LoginReq
+		host    string
+		wantErr bool
+	}{
+		{req: LoginReq{Host: "http://jellyfin.local:8096/", Username: "alice"}, host: "http://jellyfin.local:8096"},
+		{req: LoginReq{Host: "https://example.com/jellyfin", APIKey: "apikey"}, host: "https://example.com/jellyfin"},
+		{req: LoginReq{Host: "ftp://example.com", Username: "alice"}, wantErr: true},
+		{req: LoginReq{Host: "http://example.com"}, wantErr: true},
+		{req: LoginReq{Username: "alice"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		if (err != nil) != tt.wantErr || !tt.wantErr && tt.req.Host != tt.host {
+			t.Errorf("validate %+v = %v", tt.req, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package vendorlocal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/utils"
+)
+
+type entry struct {
+	Name  string
+	IsDir bool
+	Size  int64
+}
+
+// readDir lists the sub directories and the media files, directories first,
+// hidden files are skipped
+func readDir(dir, keyword string) ([]*entry, error) {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	keyword = strings.ToLower(keyword)
+	entries := make([]*entry, 0, len(des))
+	for _, de := range des {
+		name := de.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(name), keyword) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		// follow the symlinks, they are checked again when resolved
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(filepath.Join(dir, name)); err != nil {
+				continue
+			}
+		}
+		switch {
+		case info.IsDir():
+		case info.Mode().IsRegular() && utils.IsMediaFile(name):
+		default:
+			continue
+		}
+		entries = append(entries, &entry{
+			Name:  name,
+			IsDir: info.IsDir(),
+			Size:  info.Size(),
+		})
+	}
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return vendorfs.CompareEntries(a.Name, a.IsDir, b.Name, b.IsDir)
+	})
+	return entries, nil
+}
+
+type sidecarSubtitle struct {
+	Name   string
+	Path   string
+	Format string
+}
+
+// findSidecarSubtitles finds the subtitles named after the media file,
+// like movie.srt and movie.en.ass for movie.mkv, the file is the real path
+// resolved in the root and the subtitles linked out of the root are dropped
+func findSidecarSubtitles(root *dbModel.LocalRoot, file string) ([]*sidecarSubtitle, error) {
+	rootPath, err := filepath.EvalSymlinks(root.Path)
+	if err != nil {
+		return nil, err
+	}
+	dir, base := filepath.Split(file)
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var subtitles []*sidecarSubtitle
+	for _, de := range des {
+		name := de.Name()
+		if de.IsDir() {
+			continue
+		}
+		label, format, ok := vendorfs.SidecarSubtitle(base, name)
+		if !ok {
+			continue
+		}
+		rel, err := filepath.Rel(rootPath, filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		p, err := op.ResolveLocalPath(root, filepath.ToSlash(rel))
+		if err != nil {
+			continue
+		}
+		subtitles = append(subtitles, &sidecarSubtitle{
+			Name:   label,
+			Path:   p,
+			Format: format,
+		})
+	}
+	return subtitles, nil
+}
+
+// resolveMovieFile returns the root and the real path of the movie file, the
+// creator of the movie must still be able to access the root
+func resolveMovieFile(movie *op.Movie) (*dbModel.LocalRoot, string, error) {
+	rootID, filePath, err := movie.VendorInfo.Local.RootIDAndFilePath()
+	if err != nil {
+		return nil, "", err
+	}
+	if movie.IsFolder {
+		if movie.SubPath() == "" {
+			return nil, "", errors.New("sub path is empty")
+		}
+		if filePath, err = vendorfs.JoinSubPath(filePath, movie.SubPath()); err != nil {
+			return nil, "", err
+		}
+	}
+	u, err := op.LoadOrInitUserByID(movie.CreatorID)
+	if err != nil {
+		return nil, "", err
+	}
+	root, err := u.Value().LoadLocalRoot(rootID)
+	if err != nil {
+		return nil, "", err
+	}
+	p, err := op.ResolveLocalPath(root, filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, "", errors.New("local path is not a file")
+	}
+	return root, p, nil
+}
+
+func newLocalVendorInfo(rootID, filePath string) dbModel.VendorInfo {
+	return dbModel.VendorInfo{
+		Vendor: dbModel.VendorLocal,
+		Local: &dbModel.LocalStreamingInfo{
+			Path: dbModel.FormatLocalPath(rootID, filePath),
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package vendorlocal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbModel "github.com/synctv-org/synctv/internal/model"
+)
+
+func TestFindSidecarSubtitles(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootPath := filepath.Join(dir, "root")
+	for _, p := range []string{
+		filepath.Join(rootPath, "movie.mkv"),
+		filepath.Join(rootPath, "movie.srt"),
+		filepath.Join(rootPath, "movie.en.ass"),
+		filepath.Join(rootPath, "movie.txt"),
+		filepath.Join(rootPath, "movie2.srt"),
+		filepath.Join(rootPath, "other.srt"),
+		filepath.Join(rootPath, "subs", "fr.vtt"),
+		filepath.Join(dir, "secret.srt"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(rootPath, "movie.dir.srt"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		// linked in the root, kept with the real path
+		filepath.Join(rootPath, "movie.fr.vtt"): filepath.Join("subs", "fr.vtt"),
+		// linked out of the root, dropped
+		filepath.Join(rootPath, "movie.zh.srt"): filepath.Join(dir, "secret.srt"),
+		filepath.Join(rootPath, "movie.ja.srt"): filepath.Join("..", "secret.srt"),
+		// dangling, dropped
+		filepath.Join(rootPath, "movie.ko.srt"): filepath.Join(rootPath, "missing.srt"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlink is not supported: %v", err)
+		}
+	}
+
+	want := []sidecarSubtitle{
+		{Name: "en", Path: filepath.Join(rootPath, "movie.en.ass"), Format: "ass"},
+		{Name: "fr", Path: filepath.Join(rootPath, "subs", "fr.vtt"), Format: "vtt"},
+		{Name: "movie.srt", Path: filepath.Join(rootPath, "movie.srt"), Format: "srt"},
+	}
+	// the root may be a link itself
+	if err := os.Symlink(rootPath, filepath.Join(dir, "link-to-root")); err != nil {
+		t.Skipf("symlink is not supported: %v", err)
+	}
+	for _, root := range []string{rootPath, filepath.Join(dir, "link-to-root")} {
+		got, err := findSidecarSubtitles(&dbModel.LocalRoot{Path: root}, filepath.Join(rootPath, "movie.mkv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("root %s: got %d subtitles, want %d: %+v", root, len(got), len(want), got)
+		}
+		for i, s := range got {
+			if *s != want[i] {
+				t.Errorf("root %s: subtitle %d = %+v, want %+v", root, i, *s, want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package vendorlocal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+)
+
+type ListReq struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+}
+
+func (r *ListReq) Validate() (err error) {
+	return nil
+}
+
+func (r *ListReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type LocalFileItem struct {
+	*model.Item
+	Size int64 `json:"size"`
+}
+
+type LocalFSListResp = model.VendorFSListResp[*LocalFileItem]
+
+type RootResp struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Roots returns the local roots the user can access, the server paths are hidden
+func Roots(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	roots, err := user.GetLocalRoots()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	resp := make([]*RootResp, len(roots))
+	for i, root := range roots {
+		resp[i] = &RootResp{
+			ID:   root.ID,
+			Name: root.Name,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+}
+
+func List(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := ListReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	page, size, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if req.Path == "" {
+		roots, err := user.GetLocalRoots()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+		if len(roots) == 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("local root not found"))
+			return
+		}
+
+		if len(roots) == 1 {
+			req.Path = roots[0].ID + "/"
+			goto LocalFSListResp
+		}
+
+		resp := LocalFSListResp{
+			Paths: []*model.Path{
+				{
+					Name: "",
+					Path: "",
+				},
+			},
+			Total: uint64(len(roots)),
+		}
+
+		for _, root := range utils.GetPageItems(roots, page, size) {
+			resp.Items = append(resp.Items, &LocalFileItem{
+				Item: &model.Item{
+					Name:  root.Name,
+					Path:  root.ID + `/`,
+					IsDir: true,
+				},
+			})
+		}
+
+		ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+
+		return
+	}
+
+LocalFSListResp:
+
+	var rootID string
+	rootID, req.Path, err = dbModel.GetLocalRootIDFromPath(req.Path)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	root, err := user.LoadLocalRoot(rootID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrLocalRootNotFound)) ||
+			errors.Is(err, dbModel.ErrNoPermission) {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("local root not found"))
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	dir, err := op.ResolveLocalPath(root, req.Path)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	entries, err := readDir(dir, req.Keyword)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	req.Path = strings.Trim(req.Path, "/")
+	resp := LocalFSListResp{
+		Total: uint64(len(entries)),
+		Paths: model.GenDefaultPaths(req.Path, true,
+			&model.Path{
+				Name: "",
+				Path: "",
+			},
+			&model.Path{
+				Name: root.Name,
+				Path: root.ID + "/",
+			}),
+	}
+	for _, e := range utils.GetPageItems(entries, page, size) {
+		resp.Items = append(resp.Items, &LocalFileItem{
+			Item: &model.Item{
+				Name:  e.Name,
+				Path:  fmt.Sprintf("%s/%s", root.ID, strings.Trim(fmt.Sprintf("%s/%s", req.Path, e.Name), "/")),
+				IsDir: e.IsDir,
+			},
+			Size: e.Size,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&resp))
+}
@@ -0,0 +1,222 @@
+package vendorlocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+)
+
+type LocalVendorService struct {
+	room  *op.Room
+	movie *op.Movie
+}
+
+func NewLocalVendorService(room *op.Room, movie *op.Movie) (*LocalVendorService, error) {
+	if movie.VendorInfo.Vendor != dbModel.VendorLocal {
+		return nil, fmt.Errorf("local vendor not support vendor %s", movie.MovieBase.VendorInfo.Vendor)
+	}
+	if movie.VendorInfo.Local == nil {
+		return nil, errors.New("local payload is nil")
+	}
+	return &LocalVendorService{
+		room:  room,
+		movie: movie,
+	}, nil
+}
+
+func (s *LocalVendorService) ListDynamicMovie(ctx context.Context, reqUser *op.User, subPath string, keyword string, page, _max int) (*model.MovieList, error) {
+	if reqUser.ID != s.movie.CreatorID {
+		return nil, fmt.Errorf("list vendor dynamic folder error: %w", dbModel.ErrNoPermission)
+	}
+
+	rootID, truePath, err := s.movie.VendorInfo.Local.RootIDAndFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("load local root id error: %w", err)
+	}
+	newPath, err := vendorfs.JoinSubPath(truePath, subPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := reqUser.LoadLocalRoot(rootID)
+	if err != nil {
+		return nil, fmt.Errorf("load local root error: %w", err)
+	}
+	dir, err := op.ResolveLocalPath(root, newPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readDir(dir, keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.MovieList{
+		Total: int64(len(entries)),
+		Paths: model.GenDefaultSubPaths(s.movie.ID, subPath, true),
+	}
+	entries = utils.GetPageItems(entries, page, _max)
+	resp.Movies = make([]*model.Movie, len(entries))
+	for i, e := range entries {
+		resp.Movies[i] = &model.Movie{
+			ID:        s.movie.ID,
+			CreatedAt: s.movie.CreatedAt.UnixMilli(),
+			Creator:   op.GetUserName(s.movie.CreatorID),
+			CreatorID: s.movie.CreatorID,
+			SubPath:   "/" + strings.Trim(fmt.Sprintf("%s/%s", subPath, e.Name), "/"),
+			Base: dbModel.MovieBase{
+				Name:       e.Name,
+				IsFolder:   e.IsDir,
+				ParentID:   dbModel.EmptyNullString(s.movie.ID),
+				VendorInfo: newLocalVendorInfo(rootID, path.Join(newPath, e.Name)),
+			},
+		}
+	}
+	return resp, nil
+}
+
+func (s *LocalVendorService) ProxyMovie(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	root, file, err := resolveMovieFile(s.movie)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	switch ctx.Query("t") {
+	case "":
+		s.serveFile(ctx, log, file)
+	case "subtitle":
+		s.serveSubtitle(ctx, log, root, file)
+	default:
+		log.Errorf("proxy vendor movie error: %v", "unknown type")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("unknown type"))
+	}
+}
+
+func (s *LocalVendorService) serveFile(ctx *gin.Context, log *logrus.Entry, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	// range requests are handled by ServeContent
+	http.ServeContent(proxy.NewMeteredResponseWriter(ctx.Request.Context(), ctx.Writer), ctx.Request, fi.Name(), fi.ModTime(), f)
+}
+
+func (s *LocalVendorService) serveSubtitle(ctx *gin.Context, log *logrus.Entry, root *dbModel.LocalRoot, file string) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid subtitle id"))
+		return
+	}
+	subtitles, err := findSidecarSubtitles(root, file)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if id < 0 || id >= len(subtitles) {
+		log.Errorf("proxy vendor movie error: %v", "id out of range")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id out of range"))
+		return
+	}
+	subt := subtitles[id]
+	fi, err := os.Stat(subt.Path)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if fi.Size() > vendorfs.MaxSubtitleFileSize {
+		log.Errorf("proxy vendor movie error: %v", "subtitle file too large")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("subtitle file too large"))
+		return
+	}
+	b, err := os.ReadFile(subt.Path)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if err := proxy.ServeSubtitle(ctx, filepath.Base(subt.Path), subt.Format, b); err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+	}
+}
+
+// ProbeMovie reads the media info of the local file
+func (s *LocalVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder {
+		return nil, errors.New("local folder can't be probed")
+	}
+	_, file, err := resolveMovieFile(s.movie)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return probe.Probe(f)
+}
+
+func (s *LocalVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("movie is dynamic folder, can't get movie info")
+	}
+
+	movie := s.movie.Clone()
+	root, file, err := resolveMovieFile(s.movie)
+	if err != nil {
+		return nil, err
+	}
+
+	movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
+	movie.MovieBase.Type = utils.GetFileExtension(file)
+	movie.MovieBase.Headers = nil
+
+	subtitles, err := findSidecarSubtitles(root, file)
+	if err != nil {
+		return nil, err
+	}
+	for i, subt := range subtitles {
+		if movie.MovieBase.Subtitles == nil {
+			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(subtitles))
+		}
+		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID),
+			Type: subt.Format,
+		}
+	}
+
+	// the path on the server is not exposed to the clients
+	movie.MovieBase.VendorInfo.Local = nil
+	return movie, nil
+}
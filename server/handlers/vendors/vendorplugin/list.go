@@ -0,0 +1,98 @@
+package vendorplugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+)
+
+// Plugins lists the names of the loaded vendor plugins
+func Plugins(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(vendorplugins.AllVendors()))
+}
+
+type ListReq struct {
+	Plugin  string `json:"plugin"`
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+}
+
+func (r *ListReq) Validate() (err error) {
+	if r.Plugin == "" {
+		return errors.New("plugin is required")
+	}
+	return nil
+}
+
+func (r *ListReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type PluginFSListResp = model.VendorFSListResp[*model.Item]
+
+func List(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := ListReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	page, size, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	vendor, ok := vendorplugins.LoadVendor(req.Plugin)
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("vendor plugin not found"))
+		return
+	}
+
+	data, err := vendor.ListDynamicMovie(ctx, &vendorplugins.ListReq{
+		Path:    req.Path,
+		Keyword: req.Keyword,
+		Page:    page,
+		Max:     size,
+		UserID:  user.ID,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(fmt.Errorf("vendor plugin list error: %w", err)))
+		return
+	}
+
+	// the paths are opaque to the server, so only the root and the current folder are known
+	resp := PluginFSListResp{
+		Total: uint64(data.Total),
+		Paths: []*model.Path{
+			{
+				Name: req.Plugin,
+				Path: "",
+			},
+		},
+	}
+	if req.Path != "" {
+		resp.Paths = append(resp.Paths, &model.Path{
+			Name: req.Path,
+			Path: req.Path,
+		})
+	}
+	for _, item := range data.Items {
+		resp.Items = append(resp.Items, &model.Item{
+			Name:  item.Name,
+			Path:  item.Path,
+			IsDir: item.IsDir,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&resp))
+}
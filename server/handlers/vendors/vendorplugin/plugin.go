@@ -0,0 +1,253 @@
+package vendorplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/vendorplugins"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+)
+
+type PluginVendorService struct {
+	room   *op.Room
+	movie  *op.Movie
+	vendor vendorplugins.Interface
+}
+
+func NewPluginVendorService(room *op.Room, movie *op.Movie) (*PluginVendorService, error) {
+	if movie.VendorInfo.Vendor != dbModel.VendorPlugin {
+		return nil, fmt.Errorf("plugin vendor not support vendor %s", movie.MovieBase.VendorInfo.Vendor)
+	}
+	if movie.VendorInfo.Plugin == nil {
+		return nil, errors.New("plugin payload is nil")
+	}
+	vendor, ok := vendorplugins.LoadVendor(movie.VendorInfo.Plugin.Name)
+	if !ok {
+		return nil, fmt.Errorf("vendor plugin %s not found", movie.VendorInfo.Plugin.Name)
+	}
+	return &PluginVendorService{
+		room:   room,
+		movie:  movie,
+		vendor: vendor,
+	}, nil
+}
+
+func (s *PluginVendorService) ListDynamicMovie(ctx context.Context, reqUser *op.User, subPath string, keyword string, page, _max int) (*model.MovieList, error) {
+	if reqUser.ID != s.movie.CreatorID {
+		return nil, fmt.Errorf("list vendor dynamic folder error: %w", dbModel.ErrNoPermission)
+	}
+
+	data, err := s.vendor.ListDynamicMovie(ctx, &vendorplugins.ListReq{
+		Path:    s.movie.VendorInfo.Plugin.Path,
+		SubPath: subPath,
+		Keyword: keyword,
+		Page:    page,
+		Max:     _max,
+		UserID:  reqUser.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("vendor plugin list error: %w", err)
+	}
+
+	resp := &model.MovieList{
+		Total:  data.Total,
+		Paths:  model.GenDefaultSubPaths(s.movie.ID, subPath, true),
+		Movies: make([]*model.Movie, len(data.Items)),
+	}
+	for i, item := range data.Items {
+		resp.Movies[i] = &model.Movie{
+			ID:        s.movie.ID,
+			CreatedAt: s.movie.CreatedAt.UnixMilli(),
+			Creator:   op.GetUserName(s.movie.CreatorID),
+			CreatorID: s.movie.CreatorID,
+			SubPath:   "/" + strings.Trim(fmt.Sprintf("%s/%s", subPath, item.Name), "/"),
+			Base: dbModel.MovieBase{
+				Name:     item.Name,
+				IsFolder: item.IsDir,
+				ParentID: dbModel.EmptyNullString(s.movie.ID),
+				VendorInfo: dbModel.VendorInfo{
+					Vendor: dbModel.VendorPlugin,
+					Plugin: &dbModel.PluginStreamingInfo{
+						Name: s.movie.VendorInfo.Plugin.Name,
+						Path: item.Path,
+					},
+				},
+			},
+		}
+	}
+	return resp, nil
+}
+
+func (s *PluginVendorService) genMovieInfo(ctx context.Context, userID, userAgent string) (*vendorplugins.MovieInfo, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("movie is dynamic folder, can't get movie info")
+	}
+	return s.vendor.GenMovieInfo(ctx, &vendorplugins.MovieInfoReq{
+		Path:      s.movie.VendorInfo.Plugin.Path,
+		SubPath:   s.movie.SubPath(),
+		UserID:    userID,
+		UserAgent: userAgent,
+	})
+}
+
+// proxied reports whether the movie is served through the server
+func (s *PluginVendorService) proxied(info *vendorplugins.MovieInfo) bool {
+	return s.movie.Proxy || info.Proxy || info.URL == ""
+}
+
+func (s *PluginVendorService) ProxyMovie(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	t := ctx.Query("t")
+	var id int
+	switch t {
+	case "":
+	case "subtitle":
+		var err error
+		id, err = strconv.Atoi(ctx.Query("id"))
+		if err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid subtitle id"))
+			return
+		}
+	default:
+		log.Errorf("proxy vendor movie error: %v", "unknown type")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("unknown type"))
+		return
+	}
+
+	if p, ok := s.vendor.(vendorplugins.Proxier); ok {
+		err := s.proxyByPlugin(ctx, p, t, id)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, vendorplugins.ErrProxyNotSupported) {
+			log.Errorf("proxy vendor movie error: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+	}
+
+	info, err := s.genMovieInfo(ctx, s.movie.CreatorID, utils.UA)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	switch t {
+	case "":
+		if !s.proxied(info) {
+			log.Errorf("proxy vendor movie error: %v", "proxy is not enabled")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("proxy is not enabled"))
+			return
+		}
+		err = proxy.AutoProxyURL(ctx,
+			info.URL,
+			info.Type,
+			info.Headers,
+			ctx.GetString("token"),
+			s.movie.RoomID,
+			s.movie.ID,
+			proxy.WithProxyURLCache(true),
+		)
+	case "subtitle":
+		if id < 0 || id >= len(info.Subtitles) {
+			log.Errorf("proxy vendor movie error: %v", "id out of range")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id out of range"))
+			return
+		}
+		err = proxy.URL(ctx, info.Subtitles[id].URL, info.Headers)
+	}
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+	}
+}
+
+// proxyByPlugin writes the data served by the plugin itself
+func (s *PluginVendorService) proxyByPlugin(ctx *gin.Context, p vendorplugins.Proxier, t string, id int) error {
+	headers := make(map[string]string)
+	for _, k := range []string{"Range", "If-Range", "If-Modified-Since", "If-None-Match"} {
+		if v := ctx.GetHeader(k); v != "" {
+			headers[k] = v
+		}
+	}
+	resp, err := p.Proxy(ctx, &vendorplugins.ProxyReq{
+		Path:    s.movie.VendorInfo.Plugin.Path,
+		SubPath: s.movie.SubPath(),
+		Type:    t,
+		ID:      id,
+		Headers: headers,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	for k, v := range resp.Headers {
+		ctx.Header(k, v)
+	}
+	ctx.Status(resp.StatusCode)
+	_, _ = io.Copy(proxy.NewMeteredWriter(ctx.Request.Context(), ctx.Writer), resp.Body)
+	return nil
+}
+
+// ProbeMovie reads the media info of the url returned by the plugin
+func (s *PluginVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	info, err := s.genMovieInfo(ctx, s.movie.CreatorID, utils.UA)
+	if err != nil {
+		return nil, err
+	}
+	if info.URL == "" {
+		return nil, errors.New("vendor plugin movie has no url to probe")
+	}
+	return proxy.ProbeURL(ctx, info.URL, info.Headers, info.Type == "m3u8" || utils.IsM3u8Url(info.URL))
+}
+
+func (s *PluginVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
+	info, err := s.genMovieInfo(ctx, user.ID, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	movie := s.movie.Clone()
+	proxied := s.proxied(info)
+	if proxied {
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
+		movie.MovieBase.Headers = nil
+	} else {
+		movie.MovieBase.URL = info.URL
+		movie.MovieBase.Headers = info.Headers
+	}
+	movie.MovieBase.Type = info.Type
+	if movie.MovieBase.Type == "" {
+		movie.MovieBase.Type = utils.GetURLExtension(info.URL)
+	}
+
+	for i, subt := range info.Subtitles {
+		if movie.MovieBase.Subtitles == nil {
+			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(info.Subtitles))
+		}
+		u := subt.URL
+		if proxied || u == "" {
+			u = fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID)
+		}
+		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
+			URL:  u,
+			Type: subt.Type,
+		}
+	}
+
+	return movie, nil
+}
@@ -8,11 +8,18 @@ import (
 	"github.com/gin-gonic/gin"
 	dbModel "github.com/synctv-org/synctv/internal/model"
 	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/playlist"
 	"github.com/synctv-org/synctv/internal/vendor"
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendoralist"
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendorbilibili"
 	"github.com/synctv-org/synctv/server/handlers/vendors/vendoremby"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorjellyfin"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorlocal"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorplugin"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendors3"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorwebdav"
 	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils/probe"
 	"golang.org/x/exp/maps"
 )
 
@@ -35,7 +42,12 @@ func Backends(ctx *gin.Context) {
 type VendorService interface {
 	ListDynamicMovie(ctx context.Context, reqUser *op.User, subPath string, keyword string, page, _max int) (*model.MovieList, error)
 	ProxyMovie(ctx *gin.Context)
-	GenMovieInfo(ctx context.Context, reqUser *op.User, userAgent, userToken string) (*dbModel.Movie, error)
+	GenMovieInfo(ctx context.Context, reqUser *op.User, userAgent, mediaToken string) (*dbModel.Movie, error)
+}
+
+// MovieProber is implemented by the vendors which can read the media info of their movies
+type MovieProber interface {
+	ProbeMovie(ctx context.Context) (*probe.Info, error)
 }
 
 func NewVendorService(room *op.Room, movie *op.Movie) (VendorService, error) {
@@ -46,7 +58,31 @@ func NewVendorService(room *op.Room, movie *op.Movie) (VendorService, error) {
 		return vendoralist.NewAlistVendorService(room, movie)
 	case dbModel.VendorEmby:
 		return vendoremby.NewEmbyVendorService(room, movie)
+	case dbModel.VendorJellyfin:
+		return vendorjellyfin.NewJellyfinVendorService(room, movie)
+	case dbModel.VendorLocal:
+		return vendorlocal.NewLocalVendorService(room, movie)
+	case dbModel.VendorWebDAV:
+		return vendorwebdav.NewWebDAVVendorService(room, movie)
+	case dbModel.VendorS3:
+		return vendors3.NewS3VendorService(room, movie)
+	case dbModel.VendorPlugin:
+		return vendorplugin.NewPluginVendorService(room, movie)
 	default:
 		return nil, fmt.Errorf("vendor %s not support", movie.VendorInfo.Vendor)
 	}
 }
+
+type WalkFunc func(ctx context.Context, user *op.User, req *model.ImportVendorMoviesReq, progress func(scanned, matched int) error) ([]*playlist.Item, error)
+
+// NewVendorWalker returns the func to recursively list a vendor path for import
+func NewVendorWalker(vendorName dbModel.VendorName) (WalkFunc, error) {
+	switch vendorName {
+	case dbModel.VendorAlist:
+		return vendoralist.Walk, nil
+	case dbModel.VendorEmby:
+		return vendoremby.Walk, nil
+	default:
+		return nil, fmt.Errorf("vendor %s not support import", vendorName)
+	}
+}
@@ -0,0 +1,106 @@
+package vendors3
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+type AdminBindReq struct {
+	UserID string `json:"userId"`
+	LoginReq
+}
+
+func (r *AdminBindReq) Validate() error {
+	if len(r.UserID) != 32 {
+		return errors.New("userId is required")
+	}
+	return r.LoginReq.Validate()
+}
+
+func (r *AdminBindReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type AdminUnbindReq struct {
+	UserID string `json:"userId"`
+	model.ServerIDReq
+}
+
+func (r *AdminUnbindReq) Validate() error {
+	if len(r.UserID) != 32 {
+		return errors.New("userId is required")
+	}
+	return r.ServerIDReq.Validate()
+}
+
+func (r *AdminUnbindReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+// AdminBinds lists the buckets bound to the user
+func AdminBinds(ctx *gin.Context) {
+	userID := ctx.Query("id")
+	if len(userID) != 32 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("user id error"))
+		return
+	}
+	writeBinds(ctx, userID)
+}
+
+// AdminBind binds the bucket to the user, like the user binds it by himself
+func AdminBind(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	req := AdminBindReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	u, err := op.LoadOrInitUserByID(req.UserID)
+	if err != nil {
+		log.Errorf("load or init user by id error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("user not found"))
+		return
+	}
+
+	if err := bind(ctx, u.Value(), &req.LoginReq); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func AdminUnbind(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	req := AdminUnbindReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	u, err := op.LoadOrInitUserByID(req.UserID)
+	if err != nil {
+		log.Errorf("load or init user by id error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("user not found"))
+		return
+	}
+
+	if err := db.DeleteS3Vendor(req.UserID, req.ServerID); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	u.Value().S3Cache().Delete(req.ServerID)
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,181 @@
+package vendors3
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/s3"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/utils"
+)
+
+const (
+	// the presigned urls sent to the clients
+	presignExpires = 12 * time.Hour
+	// the presigned urls requested by the proxy and the prober
+	proxyPresignExpires = time.Hour
+)
+
+type entry struct {
+	Name string
+	// the key relative to the prefix of the server, without the trailing slash
+	Key      string
+	IsDir    bool
+	Size     int64
+	Modified time.Time
+}
+
+// normalizePrefix trims the slashes and ends the prefix with a slash
+func normalizePrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// listDir lists the sub prefixes and the media objects of the dir, dirs first
+func listDir(ctx context.Context, cli *s3.Client, prefix, dir, keyword string) ([]*entry, error) {
+	dirPrefix := prefix + normalizePrefix(dir)
+	data, err := cli.List(ctx, dirPrefix)
+	if err != nil {
+		return nil, err
+	}
+	keyword = strings.ToLower(keyword)
+	entries := make([]*entry, 0, len(data.Prefixes)+len(data.Objects))
+	add := func(key string, isDir bool, size int64, modified time.Time) {
+		name := path.Base(strings.TrimSuffix(key, "/"))
+		if name == "" || name == "." || strings.HasPrefix(name, ".") {
+			return
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(name), keyword) {
+			return
+		}
+		if !isDir && !utils.IsMediaFile(name) {
+			return
+		}
+		entries = append(entries, &entry{
+			Name:     name,
+			Key:      strings.TrimSuffix(strings.TrimPrefix(key, prefix), "/"),
+			IsDir:    isDir,
+			Size:     size,
+			Modified: modified,
+		})
+	}
+	for _, p := range data.Prefixes {
+		add(p, true, 0, time.Time{})
+	}
+	for _, o := range data.Objects {
+		// the placeholder objects of the folders
+		if strings.HasSuffix(o.Key, "/") {
+			continue
+		}
+		add(o.Key, false, o.Size, o.LastModified)
+	}
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return vendorfs.CompareEntries(a.Name, a.IsDir, b.Name, b.IsDir)
+	})
+	return entries, nil
+}
+
+type sidecarSubtitle struct {
+	Name   string
+	Key    string
+	Format string
+	Size   int64
+}
+
+// findSidecarSubtitles finds the subtitles named after the media object,
+// like movie.srt and movie.en.ass for movie.mkv, the key is the full key
+func findSidecarSubtitles(ctx context.Context, cli *s3.Client, key string) ([]*sidecarSubtitle, error) {
+	dir, base := path.Split(key)
+	data, err := cli.List(ctx, dir+strings.TrimSuffix(base, path.Ext(base))+".")
+	if err != nil {
+		return nil, err
+	}
+	var subtitles []*sidecarSubtitle
+	for _, o := range data.Objects {
+		label, format, ok := vendorfs.SidecarSubtitle(base, path.Base(o.Key))
+		if !ok {
+			continue
+		}
+		subtitles = append(subtitles, &sidecarSubtitle{
+			Name:   label,
+			Key:    o.Key,
+			Format: format,
+			Size:   o.Size,
+		})
+	}
+	return subtitles, nil
+}
+
+// joinSubPath joins the sub path to the folder key, the keys have no leading slash
+func joinSubPath(folderKey, subPath string) (string, error) {
+	p, err := vendorfs.JoinSubPath(folderKey, subPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(p, "/"), nil
+}
+
+// loadServer loads the server bound by the user
+func loadServer(ctx context.Context, user *op.User, serverID string) (*cache.S3UserCacheData, error) {
+	sucd, err := user.S3Cache().LoadOrStore(ctx, serverID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+			return nil, errors.New("s3 server not found")
+		}
+		return nil, err
+	}
+	return sucd, nil
+}
+
+// resolveMovieObject returns the client of the creator's server and the full
+// key of the movie object
+func resolveMovieObject(ctx context.Context, movie *op.Movie) (*s3.Client, string, error) {
+	serverID, key, err := movie.VendorInfo.S3.ServerIDAndKey()
+	if err != nil {
+		return nil, "", err
+	}
+	if movie.IsFolder {
+		if movie.SubPath() == "" {
+			return nil, "", errors.New("sub path is empty")
+		}
+		if key, err = joinSubPath(key, movie.SubPath()); err != nil {
+			return nil, "", err
+		}
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil, "", errors.New("s3 key is not an object")
+	}
+	u, err := op.LoadOrInitUserByID(movie.CreatorID)
+	if err != nil {
+		return nil, "", err
+	}
+	sucd, err := loadServer(ctx, u.Value(), serverID)
+	if err != nil {
+		return nil, "", err
+	}
+	cli, err := sucd.Client()
+	if err != nil {
+		return nil, "", err
+	}
+	return cli, sucd.Prefix + key, nil
+}
+
+func newS3VendorInfo(serverID, key string) dbModel.VendorInfo {
+	return dbModel.VendorInfo{
+		Vendor: dbModel.VendorS3,
+		S3: &dbModel.S3StreamingInfo{
+			Path: dbModel.FormatS3Path(serverID, key),
+		},
+	}
+}
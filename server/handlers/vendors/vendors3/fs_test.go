@@ -0,0 +1,166 @@
+package vendors3
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/synctv-org/synctv/internal/s3"
+)
+
+// newFakeBucket serves ListObjectsV2 of the keys with the delimiter "/" in one page
+func newFakeBucket(t *testing.T, keys ...string) *s3.Client {
+	t.Helper()
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/media/" || r.URL.Query().Get("list-type") != "2" {
+			http.NotFound(w, r)
+			return
+		}
+		type object struct {
+			Key  string
+			Size int64
+		}
+		type prefix struct {
+			Prefix string
+		}
+		result := struct {
+			XMLName        xml.Name `xml:"ListBucketResult"`
+			Contents       []object
+			CommonPrefixes []prefix
+		}{}
+		p := r.URL.Query().Get("prefix")
+		for _, key := range keys {
+			rest, ok := strings.CutPrefix(key, p)
+			if !ok {
+				continue
+			}
+			if i := strings.Index(rest, "/"); i >= 0 {
+				dir := p + rest[:i+1]
+				if !slices.Contains(result.CommonPrefixes, prefix{dir}) {
+					result.CommonPrefixes = append(result.CommonPrefixes, prefix{dir})
+				}
+				continue
+			}
+			result.Contents = append(result.Contents, object{Key: key, Size: int64(len(key))})
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(srv.Close)
+	cli, err := s3.NewClient(srv.URL, "media", s3.WithPathStyle(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestListDir(t *testing.T) {
+	cli := newFakeBucket(t,
+		"lib/show/",
+		"lib/show/e10.mkv",
+		"lib/show/e2.mkv",
+		"lib/show/e2.srt",
+		"lib/show/notes.txt",
+		"lib/show/.hidden.mp4",
+		"lib/show/extras/trailer.mp4",
+		"lib/show/Bonus/b.mp4",
+	)
+	tests := []struct {
+		name    string
+		dir     string
+		keyword string
+		want    []string
+	}{
+		{
+			name: "dirs first in natural order",
+			dir:  "/show/",
+			want: []string{"show/Bonus/", "show/extras/", "show/e2.mkv", "show/e10.mkv"},
+		},
+		{
+			name:    "keyword",
+			dir:     "show",
+			keyword: "E1",
+			want:    []string{"show/e10.mkv"},
+		},
+		{
+			name: "root",
+			dir:  "",
+			want: []string{"show/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := listDir(context.Background(), cli, "lib/", tt.dir, tt.keyword)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(entries))
+			for _, e := range entries {
+				if e.IsDir {
+					got = append(got, e.Key+"/")
+				} else {
+					got = append(got, e.Key)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSidecarSubtitles(t *testing.T) {
+	cli := newFakeBucket(t,
+		"lib/movie.mkv",
+		"lib/movie.srt",
+		"lib/movie.en.ass",
+		"lib/movie.zh.vtt",
+		"lib/movie.nfo",
+		"lib/movie 2.srt",
+		"lib/movie.extras/a.srt",
+		"lib/other.srt",
+	)
+	subtitles, err := findSidecarSubtitles(context.Background(), cli, "lib/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sidecarSubtitle{
+		{Name: "en", Key: "lib/movie.en.ass", Format: "ass"},
+		{Name: "movie.srt", Key: "lib/movie.srt", Format: "srt"},
+		{Name: "zh", Key: "lib/movie.zh.vtt", Format: "vtt"},
+	}
+	if len(subtitles) != len(want) {
+		t.Fatalf("subtitles = %d, want %d", len(subtitles), len(want))
+	}
+	for i, s := range subtitles {
+		if s.Name != want[i].Name || s.Key != want[i].Key || s.Format != want[i].Format || s.Size == 0 {
+			t.Errorf("subtitle %d = %+v, want %+v", i, *s, want[i])
+		}
+	}
+}
+
+func TestJoinSubPath(t *testing.T) {
+	tests := []struct {
+		folder  string
+		sub     string
+		want    string
+		wantErr bool
+	}{
+		{"lib/show", "e01.mkv", "lib/show/e01.mkv", false},
+		{"lib/show", "s1/e01.mkv", "lib/show/s1/e01.mkv", false},
+		{"lib/show", "../movie.mkv", "", true},
+		{"lib/show", "../show2/e01.mkv", "", true},
+		{"", "a/b.mkv", "a/b.mkv", false},
+	}
+	for _, tt := range tests {
+		got, err := joinSubPath(tt.folder, tt.sub)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("joinSubPath(%q, %q) = %q, %v", tt.folder, tt.sub, got, err)
+		}
+	}
+}
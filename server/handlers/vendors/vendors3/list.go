@@ -0,0 +1,161 @@
+package vendors3
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+type ListReq struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+}
+
+func (r *ListReq) Validate() (err error) {
+	return nil
+}
+
+func (r *ListReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type S3FileItem struct {
+	*model.Item
+	Size     int64 `json:"size"`
+	Modified int64 `json:"modified"`
+}
+
+type S3FSListResp = model.VendorFSListResp[*S3FileItem]
+
+func List(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := ListReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	page, size, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if req.Path == "" {
+		if req.Keyword != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("keywords is not supported when not choose server (server id is empty)"))
+			return
+		}
+		socpes := [](func(*gorm.DB) *gorm.DB){
+			db.OrderByCreatedAtAsc,
+		}
+
+		total, err := db.GetS3VendorsCount(user.ID, socpes...)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+		if total == 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("s3 server not found"))
+			return
+		}
+
+		sv, err := db.GetS3Vendors(user.ID, append(socpes, db.Paginate(page, size))...)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+
+		if total == 1 {
+			req.Path = sv[0].ServerID + "/"
+			goto S3FSListResp
+		}
+
+		resp := S3FSListResp{
+			Paths: []*model.Path{
+				{
+					Name: "",
+					Path: "",
+				},
+			},
+			Total: uint64(total),
+		}
+
+		for _, svi := range sv {
+			resp.Items = append(resp.Items, &S3FileItem{
+				Item: &model.Item{
+					Name:  svi.Bucket + "/" + svi.Prefix,
+					Path:  svi.ServerID + `/`,
+					IsDir: true,
+				},
+			})
+		}
+
+		ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+
+		return
+	}
+
+S3FSListResp:
+
+	var serverID string
+	serverID, req.Path, err = dbModel.GetS3ServerIDFromPath(req.Path)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	sucd, err := loadServer(ctx, user, serverID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	cli, err := sucd.Client()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	files, err := listDir(ctx, cli, sucd.Prefix, req.Path, req.Keyword)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(fmt.Errorf("s3 list objects error: %w", err)))
+		return
+	}
+
+	req.Path = strings.Trim(req.Path, "/")
+	resp := S3FSListResp{
+		Total: uint64(len(files)),
+		Paths: model.GenDefaultPaths(req.Path, true,
+			&model.Path{
+				Name: "",
+				Path: "",
+			},
+			&model.Path{
+				Name: sucd.Name(),
+				Path: sucd.ServerID + "/",
+			}),
+	}
+	for _, f := range utils.GetPageItems(files, page, size) {
+		resp.Items = append(resp.Items, &S3FileItem{
+			Item: &model.Item{
+				Name:  f.Name,
+				Path:  dbModel.FormatS3Path(sucd.ServerID, f.Key),
+				IsDir: f.IsDir,
+			},
+			Size:     f.Size,
+			Modified: f.Modified.UnixMilli(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&resp))
+}
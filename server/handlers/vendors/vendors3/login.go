@@ -0,0 +1,157 @@
+package vendors3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/s3"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+)
+
+type LoginReq struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	PathStyle bool   `json:"pathStyle"`
+}
+
+func (r *LoginReq) Validate() error {
+	if r.Endpoint == "" {
+		return errors.New("endpoint is required")
+	}
+	url, err := url.Parse(r.Endpoint)
+	if err != nil {
+		return err
+	}
+	if url.Scheme != "http" && url.Scheme != "https" {
+		return errors.New("endpoint is invalid")
+	}
+	r.Endpoint = url.Scheme + "://" + url.Host
+	if r.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+	if len(r.Region) > 64 {
+		return errors.New("region is too long")
+	}
+	r.Prefix = normalizePrefix(r.Prefix)
+	for _, v := range strings.Split(r.Prefix, "/") {
+		if v == ".." || v == "." {
+			return errors.New("prefix is invalid")
+		}
+	}
+	if (r.AccessKey == "") != (r.SecretKey == "") {
+		return errors.New("access key and secret key must be set together")
+	}
+	return nil
+}
+
+func (r *LoginReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+// bind checks the bucket can be listed and saves the server for the user
+func bind(ctx context.Context, user *op.User, req *LoginReq) error {
+	if !settings.AllowProxyToLocal.Get() {
+		if l, err := utils.ParseURLIsLocalIP(req.Endpoint); err != nil {
+			return fmt.Errorf("check url is local ip error: %w", err)
+		} else if l {
+			return errors.New("not allow proxy to local")
+		}
+	}
+
+	cli, err := s3.NewClient(req.Endpoint, req.Bucket,
+		s3.WithRegion(req.Region),
+		s3.WithCredentials(req.AccessKey, req.SecretKey),
+		s3.WithPathStyle(req.PathStyle),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := cli.List(ctx, req.Prefix); err != nil {
+		return err
+	}
+
+	v := &dbModel.S3Vendor{
+		UserID:    user.ID,
+		Endpoint:  req.Endpoint,
+		Region:    req.Region,
+		Bucket:    req.Bucket,
+		Prefix:    req.Prefix,
+		AccessKey: req.AccessKey,
+		SecretKey: req.SecretKey,
+		PathStyle: req.PathStyle,
+	}
+	dbModel.GenS3ServerID(v)
+	serverID := v.ServerID
+
+	_, err = db.CreateOrSaveS3Vendor(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = user.S3Cache().StoreOrRefreshWithDynamicFunc(ctx, serverID, func(ctx context.Context, key string) (*cache.S3UserCacheData, error) {
+		return &cache.S3UserCacheData{
+			ServerID:  key,
+			Endpoint:  req.Endpoint,
+			Region:    req.Region,
+			Bucket:    req.Bucket,
+			Prefix:    req.Prefix,
+			AccessKey: req.AccessKey,
+			SecretKey: req.SecretKey,
+			PathStyle: req.PathStyle,
+		}, nil
+	})
+	return err
+}
+
+func Login(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := LoginReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := bind(ctx, user, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func Logout(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	var req model.ServerIDReq
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	err := db.DeleteS3Vendor(user.ID, req.ServerID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	user.S3Cache().Delete(req.ServerID)
+
+	ctx.Status(http.StatusNoContent)
+}
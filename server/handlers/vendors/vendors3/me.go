@@ -0,0 +1,49 @@
+package vendors3
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+type S3Bind struct {
+	ServerID  string `json:"serverId"`
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix"`
+	PathStyle bool   `json:"pathStyle"`
+}
+
+type S3BindsResp []*S3Bind
+
+func Binds(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	writeBinds(ctx, user.ID)
+}
+
+// writeBinds responds the buckets bound to the user, the keys are never sent back
+func writeBinds(ctx *gin.Context, userID string) {
+	sv, err := db.GetS3Vendors(userID, db.OrderByCreatedAtAsc)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	resp := make(S3BindsResp, len(sv))
+	for i, v := range sv {
+		resp[i] = &S3Bind{
+			ServerID:  v.ServerID,
+			Endpoint:  v.Endpoint,
+			Region:    v.Region,
+			Bucket:    v.Bucket,
+			Prefix:    v.Prefix,
+			PathStyle: v.PathStyle,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+}
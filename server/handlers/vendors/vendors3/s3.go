@@ -0,0 +1,209 @@
+package vendors3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/s3"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+)
+
+type S3VendorService struct {
+	room  *op.Room
+	movie *op.Movie
+}
+
+func NewS3VendorService(room *op.Room, movie *op.Movie) (*S3VendorService, error) {
+	if movie.VendorInfo.Vendor != dbModel.VendorS3 {
+		return nil, fmt.Errorf("s3 vendor not support vendor %s", movie.MovieBase.VendorInfo.Vendor)
+	}
+	if movie.VendorInfo.S3 == nil {
+		return nil, errors.New("s3 payload is nil")
+	}
+	return &S3VendorService{
+		room:  room,
+		movie: movie,
+	}, nil
+}
+
+func (s *S3VendorService) ListDynamicMovie(ctx context.Context, reqUser *op.User, subPath string, keyword string, page, _max int) (*model.MovieList, error) {
+	if reqUser.ID != s.movie.CreatorID {
+		return nil, fmt.Errorf("list vendor dynamic folder error: %w", dbModel.ErrNoPermission)
+	}
+
+	serverID, key, err := s.movie.VendorInfo.S3.ServerIDAndKey()
+	if err != nil {
+		return nil, fmt.Errorf("load s3 server id error: %w", err)
+	}
+	dir, err := joinSubPath(key, subPath)
+	if err != nil {
+		return nil, err
+	}
+	sucd, err := loadServer(ctx, reqUser, serverID)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := sucd.Client()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := listDir(ctx, cli, sucd.Prefix, dir, keyword)
+	if err != nil {
+		return nil, fmt.Errorf("s3 list objects error: %w", err)
+	}
+
+	resp := &model.MovieList{
+		Total: int64(len(entries)),
+		Paths: model.GenDefaultSubPaths(s.movie.ID, subPath, true),
+	}
+	entries = utils.GetPageItems(entries, page, _max)
+	resp.Movies = make([]*model.Movie, len(entries))
+	for i, e := range entries {
+		resp.Movies[i] = &model.Movie{
+			ID:        s.movie.ID,
+			CreatedAt: s.movie.CreatedAt.UnixMilli(),
+			Creator:   op.GetUserName(s.movie.CreatorID),
+			CreatorID: s.movie.CreatorID,
+			SubPath:   "/" + strings.Trim(fmt.Sprintf("%s/%s", subPath, e.Name), "/"),
+			Base: dbModel.MovieBase{
+				Name:       e.Name,
+				IsFolder:   e.IsDir,
+				ParentID:   dbModel.EmptyNullString(s.movie.ID),
+				VendorInfo: newS3VendorInfo(serverID, e.Key),
+			},
+		}
+	}
+	return resp, nil
+}
+
+func (s *S3VendorService) ProxyMovie(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	cli, key, err := resolveMovieObject(ctx, s.movie)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	switch ctx.Query("t") {
+	case "":
+		if !s.movie.Proxy {
+			log.Errorf("proxy vendor movie error: %v", "proxy is not enabled")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("proxy is not enabled"))
+			return
+		}
+		// the presigned url changes every time, so the object url is the cache key
+		err := proxy.URL(ctx,
+			cli.PresignGet(key, proxyPresignExpires),
+			nil,
+			proxy.WithProxyURLCache(true),
+			proxy.WithProxyURLCacheKey(cli.ObjectURL(key)),
+			proxy.WithProxyURLCacheScope(s.movie.RoomID, s.movie.ID),
+		)
+		if err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+		}
+	case "subtitle":
+		s.serveSubtitle(ctx, log, cli, key)
+	default:
+		log.Errorf("proxy vendor movie error: %v", "unknown type")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("unknown type"))
+	}
+}
+
+func (s *S3VendorService) serveSubtitle(ctx *gin.Context, log *logrus.Entry, cli *s3.Client, key string) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid subtitle id"))
+		return
+	}
+	subtitles, err := findSidecarSubtitles(ctx, cli, key)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if id < 0 || id >= len(subtitles) {
+		log.Errorf("proxy vendor movie error: %v", "id out of range")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id out of range"))
+		return
+	}
+	subt := subtitles[id]
+	if subt.Size > vendorfs.MaxSubtitleFileSize {
+		log.Errorf("proxy vendor movie error: %v", "subtitle file too large")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("subtitle file too large"))
+		return
+	}
+	b, err := cli.Get(ctx, subt.Key, vendorfs.MaxSubtitleFileSize)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if err := proxy.ServeSubtitle(ctx, path.Base(subt.Key), subt.Format, b); err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+	}
+}
+
+// ProbeMovie reads the media info of the object
+func (s *S3VendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("s3 folder can't be probed")
+	}
+	cli, key, err := resolveMovieObject(ctx, s.movie)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.ProbeURL(ctx, cli.PresignGet(key, proxyPresignExpires), nil, false)
+}
+
+func (s *S3VendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("movie is dynamic folder, can't get movie info")
+	}
+
+	movie := s.movie.Clone()
+	cli, key, err := resolveMovieObject(ctx, s.movie)
+	if err != nil {
+		return nil, err
+	}
+
+	if movie.Proxy {
+		movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
+	} else {
+		movie.MovieBase.URL = cli.PresignGet(key, presignExpires)
+	}
+	movie.MovieBase.Type = utils.GetFileExtension(key)
+	movie.MovieBase.Headers = nil
+
+	subtitles, err := findSidecarSubtitles(ctx, cli, key)
+	if err != nil {
+		return nil, err
+	}
+	for i, subt := range subtitles {
+		if movie.MovieBase.Subtitles == nil {
+			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(subtitles))
+		}
+		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID),
+			Type: subt.Format,
+		}
+	}
+
+	return movie, nil
+}
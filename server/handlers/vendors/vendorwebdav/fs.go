@@ -0,0 +1,132 @@
+package vendorwebdav
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/synctv-org/synctv/internal/cache"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/webdav"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/utils"
+)
+
+// readDir lists the sub directories and the media files, directories first,
+// hidden files are skipped
+func readDir(ctx context.Context, cli *webdav.Client, dir, keyword string) ([]*webdav.File, error) {
+	files, err := cli.ReadDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	keyword = strings.ToLower(keyword)
+	entries := files[:0]
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, ".") {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(f.Name), keyword) {
+			continue
+		}
+		if !f.IsDir && !utils.IsMediaFile(f.Name) {
+			continue
+		}
+		entries = append(entries, f)
+	}
+	slices.SortFunc(entries, func(a, b *webdav.File) int {
+		return vendorfs.CompareEntries(a.Name, a.IsDir, b.Name, b.IsDir)
+	})
+	return entries, nil
+}
+
+type sidecarSubtitle struct {
+	Name   string
+	Path   string
+	Format string
+	Size   int64
+}
+
+// findSidecarSubtitles finds the subtitles named after the media file,
+// like movie.srt and movie.en.ass for movie.mkv
+func findSidecarSubtitles(ctx context.Context, cli *webdav.Client, file string) ([]*sidecarSubtitle, error) {
+	dir, base := path.Split(file)
+	files, err := cli.ReadDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var subtitles []*sidecarSubtitle
+	for _, f := range files {
+		if f.IsDir {
+			continue
+		}
+		label, format, ok := vendorfs.SidecarSubtitle(base, f.Name)
+		if !ok {
+			continue
+		}
+		subtitles = append(subtitles, &sidecarSubtitle{
+			Name:   label,
+			Path:   f.Path,
+			Format: format,
+			Size:   f.Size,
+		})
+	}
+	slices.SortFunc(subtitles, func(a, b *sidecarSubtitle) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return subtitles, nil
+}
+
+// loadServer loads the server bound by the user
+func loadServer(ctx context.Context, user *op.User, serverID string) (*cache.WebDAVUserCacheData, error) {
+	wucd, err := user.WebDAVCache().LoadOrStore(ctx, serverID)
+	if err != nil {
+		if errors.Is(err, db.NotFoundError(db.ErrVendorNotFound)) {
+			return nil, errors.New("webdav server not found")
+		}
+		return nil, err
+	}
+	return wucd, nil
+}
+
+// resolveMovieFile returns the client of the creator's server and the path of
+// the movie file on it
+func resolveMovieFile(ctx context.Context, movie *op.Movie) (*webdav.Client, string, error) {
+	serverID, filePath, err := movie.VendorInfo.WebDAV.ServerIDAndFilePath()
+	if err != nil {
+		return nil, "", err
+	}
+	if movie.IsFolder {
+		if movie.SubPath() == "" {
+			return nil, "", errors.New("sub path is empty")
+		}
+		if filePath, err = vendorfs.JoinSubPath(filePath, movie.SubPath()); err != nil {
+			return nil, "", err
+		}
+	}
+	u, err := op.LoadOrInitUserByID(movie.CreatorID)
+	if err != nil {
+		return nil, "", err
+	}
+	wucd, err := loadServer(ctx, u.Value(), serverID)
+	if err != nil {
+		return nil, "", err
+	}
+	cli, err := wucd.Client()
+	if err != nil {
+		return nil, "", err
+	}
+	return cli, path.Clean("/" + filePath), nil
+}
+
+func newWebDAVVendorInfo(serverID, filePath string) dbModel.VendorInfo {
+	return dbModel.VendorInfo{
+		Vendor: dbModel.VendorWebDAV,
+		WebDAV: &dbModel.WebDAVStreamingInfo{
+			Path: dbModel.FormatWebDAVPath(serverID, filePath),
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package vendorwebdav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/synctv-org/synctv/internal/webdav"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	xwebdav "golang.org/x/net/webdav"
+)
+
+// newTestClient serves the files of a temp dir with basic auth
+func newTestClient(t *testing.T, files map[string]string) *webdav.Client {
+	t.Helper()
+	root := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &xwebdav.Handler{
+		FileSystem: xwebdav.Dir(root),
+		LockSystem: xwebdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	cli, err := webdav.NewClient(srv.URL, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestReadDir(t *testing.T) {
+	cli := newTestClient(t, map[string]string{
+		"show/e10.mkv":        "e10",
+		"show/e2.mkv":         "e2",
+		"show/e2.srt":         "e2",
+		"show/notes.txt":      "notes",
+		"show/.hidden.mp4":    "hidden",
+		"show/Extras/a.mp4":   "a",
+		"show/bonus/b.mp4":    "b",
+		"show/.trash/old.mkv": "old",
+	})
+	tests := []struct {
+		name    string
+		keyword string
+		want    []string
+	}{
+		{
+			name: "dirs first in natural order",
+			want: []string{"/show/Extras", "/show/bonus", "/show/e2.mkv", "/show/e10.mkv"},
+		},
+		{
+			name:    "keyword",
+			keyword: "E1",
+			want:    []string{"/show/e10.mkv"},
+		},
+		{
+			name:    "keyword matches dirs",
+			keyword: "xtra",
+			want:    []string{"/show/Extras"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := readDir(context.Background(), cli, "/show", tt.keyword)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(files))
+			for _, f := range files {
+				got = append(got, f.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSidecarSubtitles(t *testing.T) {
+	cli := newTestClient(t, map[string]string{
+		"movie.mkv":          "movie",
+		"movie.srt":          "srt",
+		"movie.en.ass":       "ass",
+		"movie.zh.vtt":       "vtt",
+		"movie.nfo":          "nfo",
+		"movie 2.srt":        "srt",
+		"movie.extras/a.srt": "srt",
+	})
+	subtitles, err := findSidecarSubtitles(context.Background(), cli, "/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sidecarSubtitle{
+		{Name: "en", Path: "/movie.en.ass", Format: "ass", Size: 3},
+		{Name: "movie.srt", Path: "/movie.srt", Format: "srt", Size: 3},
+		{Name: "zh", Path: "/movie.zh.vtt", Format: "vtt", Size: 3},
+	}
+	if len(subtitles) != len(want) {
+		t.Fatalf("subtitles = %d, want %d", len(subtitles), len(want))
+	}
+	for i, s := range subtitles {
+		if *s != want[i] {
+			t.Errorf("subtitle %d = %+v, want %+v", i, *s, want[i])
+		}
+	}
+}
+
+// TestRangedPlayback serves the remote file like serveFile does
+func TestRangedPlayback(t *testing.T) {
+	cli := newTestClient(t, map[string]string{
+		"movie.mkv": "0123456789abcdef",
+	})
+	ctx := context.Background()
+	f, err := cli.Stat(ctx, "/movie.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc := proxy.NewHTTPReadSeekCloser(cli.URL("/movie.mkv"),
+		proxy.WithContext(ctx),
+		proxy.WithHeadersMap(cli.Headers()),
+		proxy.WithContentTotalLength(f.Size),
+	)
+	defer rsc.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=4-9")
+	w := httptest.NewRecorder()
+	http.ServeContent(w, req, f.Name, time.Time{}, rsc)
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if string(body) != "456789" {
+		t.Errorf("body = %q", body)
+	}
+	if got := resp.Header.Get("Content-Range"); got != "bytes 4-9/16" {
+		t.Errorf("content range = %q", got)
+	}
+}
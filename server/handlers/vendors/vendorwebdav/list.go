@@ -0,0 +1,161 @@
+package vendorwebdav
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+type ListReq struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+}
+
+func (r *ListReq) Validate() (err error) {
+	return nil
+}
+
+func (r *ListReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type WebDAVFileItem struct {
+	*model.Item
+	Size     int64 `json:"size"`
+	Modified int64 `json:"modified"`
+}
+
+type WebDAVFSListResp = model.VendorFSListResp[*WebDAVFileItem]
+
+func List(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	req := ListReq{}
+	if err := model.Decode(ctx, &req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	page, size, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if req.Path == "" {
+		if req.Keyword != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("keywords is not supported when not choose server (server id is empty)"))
+			return
+		}
+		socpes := [](func(*gorm.DB) *gorm.DB){
+			db.OrderByCreatedAtAsc,
+		}
+
+		total, err := db.GetWebDAVVendorsCount(user.ID, socpes...)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+		if total == 0 {
+			ctx.JSON(http.StatusBadRequest, model.NewAPIErrorStringResp("webdav server not found"))
+			return
+		}
+
+		wv, err := db.GetWebDAVVendors(user.ID, append(socpes, db.Paginate(page, size))...)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+			return
+		}
+
+		if total == 1 {
+			req.Path = wv[0].ServerID + "/"
+			goto WebDAVFSListResp
+		}
+
+		resp := WebDAVFSListResp{
+			Paths: []*model.Path{
+				{
+					Name: "",
+					Path: "",
+				},
+			},
+			Total: uint64(total),
+		}
+
+		for _, wvi := range wv {
+			resp.Items = append(resp.Items, &WebDAVFileItem{
+				Item: &model.Item{
+					Name:  wvi.Host,
+					Path:  wvi.ServerID + `/`,
+					IsDir: true,
+				},
+			})
+		}
+
+		ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+
+		return
+	}
+
+WebDAVFSListResp:
+
+	var serverID string
+	serverID, req.Path, err = dbModel.GetWebDAVServerIDFromPath(req.Path)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	wucd, err := loadServer(ctx, user, serverID)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	cli, err := wucd.Client()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	files, err := readDir(ctx, cli, req.Path, req.Keyword)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(fmt.Errorf("webdav fs list error: %w", err)))
+		return
+	}
+
+	req.Path = strings.Trim(req.Path, "/")
+	resp := WebDAVFSListResp{
+		Total: uint64(len(files)),
+		Paths: model.GenDefaultPaths(req.Path, true,
+			&model.Path{
+				Name: "",
+				Path: "",
+			},
+			&model.Path{
+				Name: wucd.Host,
+				Path: wucd.ServerID + "/",
+			}),
+	}
+	for _, f := range utils.GetPageItems(files, page, size) {
+		resp.Items = append(resp.Items, &WebDAVFileItem{
+			Item: &model.Item{
+				Name:  f.Name,
+				Path:  dbModel.FormatWebDAVPath(wucd.ServerID, f.Path),
+				IsDir: f.IsDir,
+			},
+			Size:     f.Size,
+			Modified: f.ModTime.UnixMilli(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(&resp))
+}
@@ -0,0 +1,41 @@
+package vendorwebdav
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+)
+
+type WebDAVBindsResp []*struct {
+	ServerID string `json:"serverId"`
+	Host     string `json:"host"`
+	Username string `json:"username"`
+}
+
+func Binds(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+
+	wv, err := db.GetWebDAVVendors(user.ID, db.OrderByCreatedAtAsc)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	resp := make(WebDAVBindsResp, len(wv))
+	for i, v := range wv {
+		resp[i] = &struct {
+			ServerID string `json:"serverId"`
+			Host     string `json:"host"`
+			Username string `json:"username"`
+		}{
+			ServerID: v.ServerID,
+			Host:     v.Host,
+			Username: v.Username,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(resp))
+}
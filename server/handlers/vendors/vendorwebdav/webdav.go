@@ -0,0 +1,244 @@
+package vendorwebdav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/internal/webdav"
+	"github.com/synctv-org/synctv/server/handlers/proxy"
+	"github.com/synctv-org/synctv/server/handlers/vendors/vendorfs"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"github.com/synctv-org/synctv/utils/probe"
+)
+
+type WebDAVVendorService struct {
+	room  *op.Room
+	movie *op.Movie
+}
+
+func NewWebDAVVendorService(room *op.Room, movie *op.Movie) (*WebDAVVendorService, error) {
+	if movie.VendorInfo.Vendor != dbModel.VendorWebDAV {
+		return nil, fmt.Errorf("webdav vendor not support vendor %s", movie.MovieBase.VendorInfo.Vendor)
+	}
+	if movie.VendorInfo.WebDAV == nil {
+		return nil, errors.New("webdav payload is nil")
+	}
+	return &WebDAVVendorService{
+		room:  room,
+		movie: movie,
+	}, nil
+}
+
+func (s *WebDAVVendorService) ListDynamicMovie(ctx context.Context, reqUser *op.User, subPath string, keyword string, page, _max int) (*model.MovieList, error) {
+	if reqUser.ID != s.movie.CreatorID {
+		return nil, fmt.Errorf("list vendor dynamic folder error: %w", dbModel.ErrNoPermission)
+	}
+
+	serverID, truePath, err := s.movie.VendorInfo.WebDAV.ServerIDAndFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("load webdav server id error: %w", err)
+	}
+	newPath, err := vendorfs.JoinSubPath(truePath, subPath)
+	if err != nil {
+		return nil, err
+	}
+	wucd, err := loadServer(ctx, reqUser, serverID)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := wucd.Client()
+	if err != nil {
+		return nil, err
+	}
+	files, err := readDir(ctx, cli, newPath, keyword)
+	if err != nil {
+		return nil, fmt.Errorf("webdav fs list error: %w", err)
+	}
+
+	resp := &model.MovieList{
+		Total: int64(len(files)),
+		Paths: model.GenDefaultSubPaths(s.movie.ID, subPath, true),
+	}
+	files = utils.GetPageItems(files, page, _max)
+	resp.Movies = make([]*model.Movie, len(files))
+	for i, f := range files {
+		resp.Movies[i] = &model.Movie{
+			ID:        s.movie.ID,
+			CreatedAt: s.movie.CreatedAt.UnixMilli(),
+			Creator:   op.GetUserName(s.movie.CreatorID),
+			CreatorID: s.movie.CreatorID,
+			SubPath:   "/" + strings.Trim(fmt.Sprintf("%s/%s", subPath, f.Name), "/"),
+			Base: dbModel.MovieBase{
+				Name:       f.Name,
+				IsFolder:   f.IsDir,
+				ParentID:   dbModel.EmptyNullString(s.movie.ID),
+				VendorInfo: newWebDAVVendorInfo(serverID, f.Path),
+			},
+		}
+	}
+	return resp, nil
+}
+
+func (s *WebDAVVendorService) ProxyMovie(ctx *gin.Context) {
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	cli, file, err := resolveMovieFile(ctx, s.movie)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	switch ctx.Query("t") {
+	case "":
+		s.serveFile(ctx, log, cli, file)
+	case "subtitle":
+		s.serveSubtitle(ctx, log, cli, file)
+	default:
+		log.Errorf("proxy vendor movie error: %v", "unknown type")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("unknown type"))
+	}
+}
+
+func (s *WebDAVVendorService) serveFile(ctx *gin.Context, log *logrus.Entry, cli *webdav.Client, file string) {
+	u := cli.URL(file)
+	if settings.ProxyCacheEnable.Get() {
+		err := proxy.URL(ctx, u, cli.Headers(),
+			proxy.WithProxyURLCache(true),
+			proxy.WithProxyURLCacheScope(s.movie.RoomID, s.movie.ID),
+		)
+		if err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+		}
+		return
+	}
+
+	if !settings.AllowProxyToLocal.Get() {
+		if l, err := utils.ParseURLIsLocalIP(u); err != nil {
+			log.Errorf("proxy vendor movie error: %v", err)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+			return
+		} else if l {
+			log.Errorf("proxy vendor movie error: %v", "not allow proxy to local")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("not allow proxy to local"))
+			return
+		}
+	}
+
+	f, err := cli.Stat(ctx, file)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+	if f.IsDir {
+		log.Errorf("proxy vendor movie error: %v", "webdav path is not a file")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("webdav path is not a file"))
+		return
+	}
+
+	c, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rsc := proxy.NewHTTPReadSeekCloser(u,
+		proxy.WithContext(c),
+		proxy.WithHeadersMap(cli.Headers()),
+		proxy.WithContentTotalLength(f.Size),
+	)
+	defer rsc.Close()
+	if f.ContentType != "" {
+		ctx.Header("Content-Type", f.ContentType)
+	}
+	// the range requests are served from the remote file by the read seeker
+	http.ServeContent(proxy.NewMeteredResponseWriter(ctx.Request.Context(), ctx.Writer), ctx.Request, f.Name, f.ModTime, rsc)
+}
+
+func (s *WebDAVVendorService) serveSubtitle(ctx *gin.Context, log *logrus.Entry, cli *webdav.Client, file string) {
+	id, err := strconv.Atoi(ctx.Query("id"))
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("invalid subtitle id"))
+		return
+	}
+	subtitles, err := findSidecarSubtitles(ctx, cli, file)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if id < 0 || id >= len(subtitles) {
+		log.Errorf("proxy vendor movie error: %v", "id out of range")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("id out of range"))
+		return
+	}
+	subt := subtitles[id]
+	if subt.Size > vendorfs.MaxSubtitleFileSize {
+		log.Errorf("proxy vendor movie error: %v", "subtitle file too large")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorStringResp("subtitle file too large"))
+		return
+	}
+	b, err := cli.ReadFile(ctx, subt.Path, vendorfs.MaxSubtitleFileSize)
+	if err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+	if err := proxy.ServeSubtitle(ctx, path.Base(subt.Path), subt.Format, b); err != nil {
+		log.Errorf("proxy vendor movie error: %v", err)
+	}
+}
+
+// ProbeMovie reads the media info of the remote file
+func (s *WebDAVVendorService) ProbeMovie(ctx context.Context) (*probe.Info, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("webdav folder can't be probed")
+	}
+	cli, file, err := resolveMovieFile(ctx, s.movie)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.ProbeURL(ctx, cli.URL(file), cli.Headers(), false)
+}
+
+func (s *WebDAVVendorService) GenMovieInfo(ctx context.Context, user *op.User, userAgent, mediaToken string) (*dbModel.Movie, error) {
+	if s.movie.IsFolder && s.movie.SubPath() == "" {
+		return nil, errors.New("movie is dynamic folder, can't get movie info")
+	}
+
+	movie := s.movie.Clone()
+	cli, file, err := resolveMovieFile(ctx, s.movie)
+	if err != nil {
+		return nil, err
+	}
+
+	// the credentials can't be exposed, so the movie is always proxied
+	movie.MovieBase.URL = fmt.Sprintf("/api/room/movie/proxy/%s?token=%s&roomId=%s", movie.ID, mediaToken, movie.RoomID)
+	movie.MovieBase.Type = utils.GetFileExtension(file)
+	movie.MovieBase.Headers = nil
+
+	subtitles, err := findSidecarSubtitles(ctx, cli, file)
+	if err != nil {
+		return nil, err
+	}
+	for i, subt := range subtitles {
+		if movie.MovieBase.Subtitles == nil {
+			movie.MovieBase.Subtitles = make(map[string]*dbModel.Subtitle, len(subtitles))
+		}
+		movie.MovieBase.Subtitles[subt.Name] = &dbModel.Subtitle{
+			URL:  fmt.Sprintf("/api/room/movie/proxy/%s?t=subtitle&id=%d&token=%s&roomId=%s", movie.ID, i, mediaToken, movie.RoomID),
+			Type: subt.Format,
+		}
+	}
+
+	return movie, nil
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/db"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+)
+
+func UserWatchHistory(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	page, pageSize, err := utils.GetPageAndMax(ctx)
+	if err != nil {
+		log.Errorf("failed to get page and max: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.FlushWatchHistories(); err != nil {
+		log.Errorf("failed to save watch histories: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	scopes := []func(db *gorm.DB) *gorm.DB{}
+	switch ctx.DefaultQuery("status", "all") {
+	case "watching":
+		scopes = append(scopes, db.WhereWatchHistoryCompleted(false))
+	case "completed":
+		scopes = append(scopes, db.WhereWatchHistoryCompleted(true))
+	}
+
+	total, err := db.GetWatchHistoriesCount(user.ID, scopes...)
+	if err != nil {
+		log.Errorf("failed to get watch histories count: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	histories, err := db.GetWatchHistories(user.ID, append(scopes, db.OrderByDesc("updated_at"), db.Paginate(page, pageSize))...)
+	if err != nil {
+		log.Errorf("failed to get watch histories: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	list := make([]*model.WatchHistoryResp, len(histories))
+	for i, h := range histories {
+		list[i] = &model.WatchHistoryResp{
+			MediaKey:  h.MediaKey,
+			RoomID:    h.RoomID,
+			MovieID:   h.MovieID,
+			SubPath:   h.SubPath,
+			Name:      h.Name,
+			Vendor:    h.Vendor,
+			Position:  h.Position,
+			Duration:  h.Duration,
+			Completed: h.Completed,
+			UpdatedAt: h.UpdatedAt.UnixMilli(),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIDataResp(gin.H{
+		"total": total,
+		"list":  list,
+	}))
+}
+
+func DeleteUserWatchHistory(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	var req model.DeleteWatchHistoryReq
+	if err := model.Decode(ctx, &req); err != nil {
+		log.Errorf("failed to decode request: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := user.DeleteWatchHistory(req.MediaKey); err != nil {
+		log.Errorf("failed to delete watch history: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func ClearUserWatchHistory(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*op.UserEntry).Value()
+	log := ctx.MustGet("log").(*logrus.Entry)
+
+	if err := user.ClearWatchHistories(); err != nil {
+		log.Errorf("failed to clear watch histories: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, model.NewAPIErrorResp(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -64,6 +64,13 @@ func NewWSMessageHandler(u *op.User, r *op.Room, l *log.Entry) func(c *websocket
 			return err
 		}
 
+		if r.Settings.HasAnnouncement(time.Now()) {
+			if err := client.Send(r.AnnouncementMessage()); err != nil {
+				l.Errorf("ws: send announcement error: %v", err)
+				return err
+			}
+		}
+
 		go func() {
 			if err := handleReaderMessage(client, l); err != nil {
 				l.Errorf("ws: handle reader message error: %v", err)
@@ -174,6 +181,8 @@ func handleElementMsg(cli *op.Client, msg *pb.Message) error {
 		return handleExpiredMessage(cli, msg.GetExpirationId())
 	case pb.MessageType_CHECK_STATUS:
 		return handleCheckStatusMessage(cli, msg, timeDiff)
+	case pb.MessageType_SUBTITLE:
+		return handleSubtitleMessage(cli, msg)
 	default:
 		return sendErrorMessage(cli, fmt.Sprintf("unknown message type: %v", msg.Type))
 	}
@@ -221,6 +230,19 @@ func handleStatusMessage(cli *op.Client, msg *pb.Message, timeDiff float64) erro
 	if err != nil {
 		return sendErrorMessage(cli, fmt.Sprintf("set status error: %v", err))
 	}
+	recordWatchHistory(cli, playbackStatus, timeDiff)
+	return nil
+}
+
+func handleSubtitleMessage(cli *op.Client, msg *pb.Message) error {
+	subtitleStatus := msg.GetSubtitleStatus()
+	if subtitleStatus == nil {
+		return sendErrorMessage(cli, "subtitle status is nil")
+	}
+	err := cli.User().SetRoomCurrentSubtitle(cli.Room(), subtitleStatus.GetTrack(), subtitleStatus.GetDelay())
+	if err != nil {
+		return sendErrorMessage(cli, fmt.Sprintf("set subtitle error: %v", err))
+	}
 	return nil
 }
 
@@ -262,12 +284,30 @@ func handleCheckStatusMessage(cli *op.Client, msg *pb.Message, timeDiff float64)
 	if cliStatus == nil {
 		return sendErrorMessage(cli, "playback status is nil")
 	}
+	recordWatchHistory(cli, cliStatus, timeDiff)
 	if needsSync(cliStatus, status, timeDiff) {
 		return sendSyncStatus(cli, &status)
 	}
 	return nil
 }
 
+// recordWatchHistory records the position reported by the client,
+// paused positions are skipped so that switching movies does not reset the history
+func recordWatchHistory(cli *op.Client, status *pb.Status, timeDiff float64) {
+	if !status.GetIsPlaying() || cli.User().IsGuest() {
+		return
+	}
+	current := cli.Room().Current()
+	if current.Movie.ID == "" || current.Movie.IsLive {
+		return
+	}
+	movie, err := cli.Room().GetMovieByID(current.Movie.ID)
+	if err != nil {
+		return
+	}
+	cli.User().RecordWatchHistory(cli.Room(), movie, status.GetCurrentTime()+timeDiff*status.GetPlaybackRate())
+}
+
 func needsSync(clientStatus *pb.Status, serverStatus op.Status, timeDiff float64) bool {
 	if clientStatus.IsPlaying != serverStatus.IsPlaying ||
 		clientStatus.PlaybackRate != serverStatus.PlaybackRate ||
@@ -0,0 +1,171 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/server/model"
+	"github.com/zijiren233/stream"
+)
+
+var (
+	ErrMediaTokenMismatch = errors.New("media token does not match the request")
+	errNotMediaToken      = errors.New("not a media token")
+)
+
+// MediaClaims allow a user to play a movie of a room for a short time,
+// they are embedded in the media urls instead of the user token
+type MediaClaims struct {
+	jwt.RegisteredClaims
+	UserID      string `json:"u"`
+	UserVersion uint32 `json:"uv"`
+	RoomID      string `json:"r"`
+	MovieID     string `json:"m"`
+	// empty if the token is not bound to the client ip
+	IP string `json:"ip,omitempty"`
+}
+
+// mediaTokenKey is derived from the jwt secret, so a media token is never accepted
+// as a user token and a user token is never accepted as a media token
+func mediaTokenKey() []byte {
+	h := hmac.New(sha256.New, stream.StringToBytes(conf.Conf.Jwt.Secret))
+	h.Write([]byte("media"))
+	return h.Sum(nil)
+}
+
+func NewMediaToken(user *op.User, roomID, movieID, clientIP string) (string, time.Time, error) {
+	if user.IsBanned() {
+		return "", time.Time{}, ErrUserBanned
+	}
+	if user.IsPending() {
+		return "", time.Time{}, ErrUserPending
+	}
+
+	now := time.Now()
+	expireAt := now.Add(time.Duration(settings.MediaTokenTTL.Get()) * time.Minute)
+	claims := &MediaClaims{
+		UserID:      user.ID,
+		UserVersion: user.Version(),
+		RoomID:      roomID,
+		MovieID:     movieID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expireAt),
+		},
+	}
+	if settings.MediaTokenBindIP.Get() {
+		claims.IP = clientIP
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(mediaTokenKey())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expireAt, nil
+}
+
+// authMedia returns errNotMediaToken if the token is not signed as a media token
+func authMedia(token string) (*MediaClaims, error) {
+	t, err := jwt.ParseWithClaims(strings.TrimPrefix(token, `Bearer `), &MediaClaims{}, func(token *jwt.Token) (any, error) {
+		return mediaTokenKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		// the claims are validated after the signature
+		if errors.Is(err, jwt.ErrTokenExpired) || errors.Is(err, jwt.ErrTokenNotValidYet) {
+			return nil, ErrAuthExpired
+		}
+		return nil, errNotMediaToken
+	}
+	claims, ok := t.Claims.(*MediaClaims)
+	if !ok || !t.Valid {
+		return nil, ErrAuthFailed
+	}
+	return claims, nil
+}
+
+// AuthMedia checks the user and the room of the media token again,
+// so banning the user, changing the password or removing the user from the room
+// revokes the outstanding media tokens
+func AuthMedia(claims *MediaClaims) (*op.UserEntry, *op.RoomEntry, error) {
+	if len(claims.UserID) != 32 || len(claims.RoomID) != 32 || len(claims.MovieID) != 32 {
+		return nil, nil, ErrAuthFailed
+	}
+
+	userE, err := op.LoadOrInitUserByID(claims.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user := userE.Value()
+
+	if user.IsGuest() {
+		if !settings.EnableGuest.Get() {
+			return nil, nil, ErrUserGuest
+		}
+	} else if err := validateUser(user, claims.UserVersion); err != nil {
+		return nil, nil, err
+	}
+
+	roomE, err := authenticateRoomAccess(claims.RoomID, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return userE, roomE, nil
+}
+
+// AuthMediaMiddleware authenticates the media routes, a media token only allows the movie
+// it is signed for, the user tokens are authenticated like AuthRoomMiddleware
+func AuthMediaMiddleware(ctx *gin.Context) {
+	claims, err := authMedia(GetAuthorizationTokenFromContext(ctx))
+	if errors.Is(err, errNotMediaToken) {
+		AuthRoomMiddleware(ctx)
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, model.NewAPIErrorResp(err))
+		return
+	}
+
+	if err := checkMediaRequest(ctx, claims); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, model.NewAPIErrorResp(err))
+		return
+	}
+
+	userE, roomE, err := AuthMedia(claims)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, model.NewAPIErrorResp(err))
+		return
+	}
+	user := userE.Value()
+	room := roomE.Value()
+
+	ctx.Set("user", userE)
+	ctx.Set("room", roomE)
+	ctx.Set("mediaMovieId", claims.MovieID)
+	setLogFields(ctx, user, room)
+}
+
+func checkMediaRequest(ctx *gin.Context, claims *MediaClaims) error {
+	if claims.IP != "" && claims.IP != ctx.ClientIP() {
+		return ErrMediaTokenMismatch
+	}
+	if roomID := ctx.Query("roomId"); roomID != "" && roomID != claims.RoomID {
+		return ErrMediaTokenMismatch
+	}
+	ctx.Set("roomId", claims.RoomID)
+	// the live routes end with the extension of the stream
+	if movieID := strings.Trim(ctx.Param("movieId"), "/"); movieID != "" &&
+		strings.TrimSuffix(movieID, path.Ext(movieID)) != claims.MovieID {
+		return ErrMediaTokenMismatch
+	}
+	return nil
+}
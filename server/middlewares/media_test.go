@@ -0,0 +1,334 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/synctv-org/synctv/internal/conf"
+	"github.com/synctv-org/synctv/internal/db"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+	"github.com/synctv-org/synctv/internal/op"
+	"github.com/synctv-org/synctv/internal/settings"
+	"github.com/synctv-org/synctv/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "synctv-middlewares")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code := 1
+	if err := initTestEnv(dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	} else {
+		code = m.Run()
+	}
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// initTestEnv initializes the database, the settings and the op caches like the bootstrap
+func initTestEnv(dir string) error {
+	conf.Conf = conf.DefaultConfig()
+	logrus.SetOutput(io.Discard)
+	gin.SetMode(gin.TestMode)
+	d, err := gorm.Open(sqlite.Open(filepath.Join(dir, "synctv.db")), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.Init(d, conf.DatabaseTypeSqlite3); err != nil {
+		return err
+	}
+	for {
+		b, ok := settings.PopNeedInit()
+		if !ok {
+			break
+		}
+		err := db.FirstOrCreateSettingItemValue(&dbModel.Setting{
+			Name:  b.Name(),
+			Value: b.DefaultString(),
+			Type:  b.Type(),
+			Group: b.Group(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := b.Init(b.DefaultString()); err != nil {
+			return err
+		}
+	}
+	return op.Init(4096)
+}
+
+func newTestUser(t *testing.T) *op.User {
+	t.Helper()
+	userE, err := op.CreateUser("user-"+utils.RandString(8), "password", db.WithRole(dbModel.RoleUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userE.Value()
+}
+
+func newTestRoom(t *testing.T, creator *op.User) *op.Room {
+	t.Helper()
+	roomE, err := op.CreateRoom("room-"+utils.RandString(8), "", 0,
+		db.WithCreator(&creator.User),
+		db.WithStatus(dbModel.RoomStatusActive),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return roomE.Value()
+}
+
+func newTestMediaToken(t *testing.T, user *op.User, roomID, movieID, clientIP string) string {
+	t.Helper()
+	token, _, err := NewMediaToken(user, roomID, movieID, clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// newTestEngine mounts the media routes and a room route like the api
+func newTestEngine() *gin.Engine {
+	e := gin.New()
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	e.Use(NewLog(l))
+	ok := func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("mediaMovieId"))
+	}
+	e.GET("/api/room/movie/proxy/:movieId", AuthMediaMiddleware, ok)
+	e.GET("/api/room/movie/current", AuthRoomMiddleware, ok)
+	return e
+}
+
+func doTestRequest(e *gin.Engine, target, token, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMediaMiddleware(t *testing.T) {
+	user := newTestUser(t)
+	room := newTestRoom(t, user)
+	otherRoom := newTestRoom(t, newTestUser(t))
+	movieID := utils.SortUUID()
+	mediaToken := newTestMediaToken(t, user, room.ID, movieID, "")
+	userToken, err := NewAuthUserToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		token      string
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:       "media token",
+			target:     "/api/room/movie/proxy/" + movieID,
+			token:      mediaToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "media token in query",
+			target:     "/api/room/movie/proxy/" + movieID + "?token=" + mediaToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "live stream extension",
+			target:     "/api/room/movie/proxy/" + movieID + ".flv",
+			token:      mediaToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "same room",
+			target:     "/api/room/movie/proxy/" + movieID + "?roomId=" + room.ID,
+			token:      mediaToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other movie",
+			target:     "/api/room/movie/proxy/" + utils.SortUUID(),
+			token:      mediaToken,
+			wantStatus: http.StatusForbidden,
+			wantErr:    ErrMediaTokenMismatch,
+		},
+		{
+			name:       "other room",
+			target:     "/api/room/movie/proxy/" + movieID + "?roomId=" + otherRoom.ID,
+			token:      mediaToken,
+			wantStatus: http.StatusForbidden,
+			wantErr:    ErrMediaTokenMismatch,
+		},
+		{
+			name:       "user token",
+			target:     "/api/room/movie/proxy/" + utils.SortUUID() + "?roomId=" + room.ID,
+			token:      userToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			target:     "/api/room/movie/proxy/" + movieID + "?roomId=" + room.ID,
+			token:      "invalid",
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    ErrAuthFailed,
+		},
+		{
+			name:       "media token on room route",
+			target:     "/api/room/movie/current?roomId=" + room.ID,
+			token:      mediaToken,
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    ErrAuthFailed,
+		},
+		{
+			name:       "user token on room route",
+			target:     "/api/room/movie/current?roomId=" + room.ID,
+			token:      userToken,
+			wantStatus: http.StatusOK,
+		},
+	}
+	e := newTestEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doTestRequest(e, tt.target, tt.token, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantErr != nil && !strings.Contains(w.Body.String(), tt.wantErr.Error()) {
+				t.Errorf("body = %s, want error %q", w.Body.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthMediaExpired(t *testing.T) {
+	user := newTestUser(t)
+	room := newTestRoom(t, user)
+	movieID := utils.SortUUID()
+	claims := &MediaClaims{
+		UserID:      user.ID,
+		UserVersion: user.Version(),
+		RoomID:      room.ID,
+		MovieID:     movieID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(mediaTokenKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authMedia(token); err != ErrAuthExpired {
+		t.Errorf("err = %v, want %v", err, ErrAuthExpired)
+	}
+	w := doTestRequest(newTestEngine(), "/api/room/movie/proxy/"+movieID, token, "")
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), ErrAuthExpired.Error()) {
+		t.Errorf("status = %d, body = %s, want %v", w.Code, w.Body.String(), ErrAuthExpired)
+	}
+}
+
+func TestAuthMediaBindIP(t *testing.T) {
+	if err := settings.MediaTokenBindIP.Set(true); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = settings.MediaTokenBindIP.Set(false)
+	})
+	user := newTestUser(t)
+	room := newTestRoom(t, user)
+	movieID := utils.SortUUID()
+	token := newTestMediaToken(t, user, room.ID, movieID, "192.0.2.1")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantStatus int
+	}{
+		{"same ip", "192.0.2.1:1234", http.StatusOK},
+		{"other ip", "192.0.2.2:1234", http.StatusForbidden},
+	}
+	e := newTestEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doTestRequest(e, "/api/room/movie/proxy/"+movieID, token, tt.remoteAddr)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthMediaRevoked(t *testing.T) {
+	tests := []struct {
+		name    string
+		revoke  func(user *op.User) error
+		wantErr error
+	}{
+		{
+			name: "password changed",
+			revoke: func(user *op.User) error {
+				return user.SetPassword("new password")
+			},
+			wantErr: ErrAuthExpired,
+		},
+		{
+			name: "banned",
+			revoke: func(user *op.User) error {
+				return user.Ban()
+			},
+			wantErr: ErrUserBanned,
+		},
+	}
+	e := newTestEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(t)
+			room := newTestRoom(t, user)
+			movieID := utils.SortUUID()
+			token := newTestMediaToken(t, user, room.ID, movieID, "")
+			if w := doTestRequest(e, "/api/room/movie/proxy/"+movieID, token, ""); w.Code != http.StatusOK {
+				t.Fatalf("status = %d before revoking: %s", w.Code, w.Body.String())
+			}
+			if err := tt.revoke(user); err != nil {
+				t.Fatal(err)
+			}
+			w := doTestRequest(e, "/api/room/movie/proxy/"+movieID, token, "")
+			if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), tt.wantErr.Error()) {
+				t.Errorf("status = %d, body = %s, want %v", w.Code, w.Body.String(), tt.wantErr)
+			}
+			if _, _, err := NewMediaToken(user, room.ID, movieID, ""); tt.wantErr == ErrUserBanned && err != ErrUserBanned {
+				t.Errorf("new media token err = %v, want %v", err, ErrUserBanned)
+			}
+		})
+	}
+}
@@ -187,3 +187,83 @@ func (ster *SendTestEmailReq) Validate() error {
 func (ster *SendTestEmailReq) Decode(ctx *gin.Context) error {
 	return json.NewDecoder(ctx.Request.Body).Decode(ster)
 }
+
+type ProxyCacheStatsResp struct {
+	Driver   string  `json:"driver"`
+	Entries  int64   `json:"entries"`
+	Size     int64   `json:"size"`
+	MaxSize  int64   `json:"maxSize"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hitRatio"`
+}
+
+type PurgeProxyCacheReq struct {
+	RoomID  string `json:"roomId"`
+	MovieID string `json:"movieId"`
+}
+
+func (r *PurgeProxyCacheReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+func (r *PurgeProxyCacheReq) Validate() error {
+	if len(r.RoomID) != 32 {
+		return ErrInvalidID
+	}
+	if r.MovieID != "" && len(r.MovieID) != 32 {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+type PurgeProxyCacheResp struct {
+	Purged int64 `json:"purged"`
+}
+
+type ProxyPrefetchStatsResp struct {
+	Running      int64   `json:"running"`
+	Prefetched   uint64  `json:"prefetched"`
+	Bytes        uint64  `json:"bytes"`
+	Used         uint64  `json:"used"`
+	Unused       uint64  `json:"unused"`
+	Deduplicated uint64  `json:"deduplicated"`
+	Throttled    uint64  `json:"throttled"`
+	Failed       uint64  `json:"failed"`
+	UsedRatio    float64 `json:"usedRatio"`
+}
+
+type SetTrafficLimitReq struct {
+	SubjectType dbModel.TrafficSubjectType `json:"subjectType"`
+	SubjectID   string                     `json:"subjectId"`
+	// KB per second, 0 means the default setting and negative means unlimited
+	Bandwidth int64 `json:"bandwidth"`
+	// MB per month, 0 means the default setting and negative means unlimited
+	MonthlyQuota int64 `json:"monthlyQuota"`
+}
+
+func (r *SetTrafficLimitReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+func (r *SetTrafficLimitReq) Validate() error {
+	switch r.SubjectType {
+	case dbModel.TrafficSubjectUser, dbModel.TrafficSubjectRoom:
+	default:
+		return errors.New("subject type must be user or room")
+	}
+	if len(r.SubjectID) != 32 {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+type TrafficUsageResp struct {
+	SubjectType dbModel.TrafficSubjectType `json:"subjectType"`
+	SubjectID   string                     `json:"subjectId"`
+	Month       string                     `json:"month"`
+	Bytes       int64                      `json:"bytes"`
+	// the limits in effect, 0 means unlimited
+	Bandwidth    int64 `json:"bandwidth"`
+	MonthlyQuota int64 `json:"monthlyQuota"`
+}
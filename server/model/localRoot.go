@@ -0,0 +1,50 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	dbModel "github.com/synctv-org/synctv/internal/model"
+)
+
+type AddLocalRootReq struct {
+	Name    string       `json:"name"`
+	Path    string       `json:"path"`
+	Role    dbModel.Role `json:"role"`
+	UserIDs []string     `json:"userIds"`
+}
+
+func (r *AddLocalRootReq) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is empty")
+	} else if len(r.Name) > 64 {
+		return errors.New("name is too long")
+	} else if !alnumPrintHanReg.MatchString(r.Name) {
+		return errors.New("name has invalid char")
+	}
+	if r.Path == "" {
+		return errors.New("path is empty")
+	}
+	return nil
+}
+
+func (r *AddLocalRootReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+type UpdateLocalRootReq struct {
+	IDReq
+	AddLocalRootReq
+}
+
+func (r *UpdateLocalRootReq) Validate() error {
+	if err := r.IDReq.Validate(); err != nil {
+		return err
+	}
+	return r.AddLocalRootReq.Validate()
+}
+
+func (r *UpdateLocalRootReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
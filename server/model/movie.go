@@ -101,6 +101,21 @@ func (s *SetRoomCurrentMovieReq) Decode(ctx *gin.Context) error {
 	return json.NewDecoder(ctx.Request.Body).Decode(s)
 }
 
+type SetRoomCurrentSubtitleReq struct {
+	Track string `json:"track"`
+	Delay int64  `json:"delay"`
+}
+
+func (s *SetRoomCurrentSubtitleReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(s)
+}
+
+// the track and delay are checked by Room.SetCurrentSubtitle,
+// which is shared with the websocket messages
+func (s *SetRoomCurrentSubtitleReq) Validate() error {
+	return nil
+}
+
 type EditMovieReq struct {
 	IDReq
 	PushMovieReq
@@ -156,6 +171,72 @@ func (s *SwapMovieReq) Validate() error {
 	return nil
 }
 
+type MoveMoviesReq struct {
+	IDs      []string `json:"ids"`
+	ParentID string   `json:"parentId"`
+}
+
+func (m *MoveMoviesReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(m)
+}
+
+func (m *MoveMoviesReq) Validate() error {
+	if len(m.ParentID) != 0 && len(m.ParentID) != 32 {
+		return ErrID
+	}
+	return validateUniqueIDs(m.IDs)
+}
+
+type ReorderMoviesReq struct {
+	ParentID string `json:"parentId"`
+	// the new order of the movies in the folder
+	IDs []string `json:"ids"`
+}
+
+func (r *ReorderMoviesReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+func (r *ReorderMoviesReq) Validate() error {
+	if len(r.ParentID) != 0 && len(r.ParentID) != 32 {
+		return ErrID
+	}
+	return validateUniqueIDs(r.IDs)
+}
+
+// CheckMoviesHealthReq checks all the url movies of the room when the ids is empty
+type CheckMoviesHealthReq struct {
+	IDs []string `json:"ids"`
+}
+
+func (c *CheckMoviesHealthReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(c)
+}
+
+func (c *CheckMoviesHealthReq) Validate() error {
+	if len(c.IDs) == 0 {
+		return nil
+	}
+	return validateUniqueIDs(c.IDs)
+}
+
+func validateUniqueIDs(ids []string) error {
+	if len(ids) == 0 {
+		return ErrEmptyIDs
+	}
+	seen := make(map[string]struct{}, len(ids))
+	for _, v := range ids {
+		if len(v) != 32 {
+			return ErrID
+		}
+		if _, ok := seen[v]; ok {
+			return errors.New("duplicate id")
+		}
+		seen[v] = struct{}{}
+	}
+	return nil
+}
+
 func GenDefaultSubPaths(id, path string, skipEmpty bool, paths ...*MoviePath) []*MoviePath {
 	path = strings.TrimRight(path, "/")
 	for _, v := range strings.Split(path, `/`) {
@@ -197,18 +278,34 @@ type MoviesResp struct {
 }
 
 type Movie struct {
-	ID        string          `json:"id"`
-	Creator   string          `json:"creator"`
-	CreatorID string          `json:"creatorId"`
-	SubPath   string          `json:"subPath"`
-	Base      model.MovieBase `json:"base"`
-	CreatedAt int64           `json:"createAt"`
+	ID        string            `json:"id"`
+	Creator   string            `json:"creator"`
+	CreatorID string            `json:"creatorId"`
+	SubPath   string            `json:"subPath"`
+	Base      model.MovieBase   `json:"base"`
+	Meta      model.MovieMeta   `json:"meta"`
+	Health    model.MovieHealth `json:"health"`
+	CreatedAt int64             `json:"createAt"`
+}
+
+type TrashedMovie struct {
+	*Movie
+	DeletedBy   string `json:"deletedBy"`
+	DeletedByID string `json:"deletedById"`
+	DeletedAt   int64  `json:"deletedAt"`
+	// unix milli, when the movie will be purged
+	PurgeAt int64 `json:"purgeAt"`
 }
 
 type CurrentMovieResp struct {
-	Movie    *Movie    `json:"movie"`
-	Status   op.Status `json:"status"`
-	ExpireID uint64    `json:"expireId"`
+	Movie    *Movie            `json:"movie"`
+	Status   op.Status         `json:"status"`
+	ExpireID uint64            `json:"expireId"`
+	Subtitle op.SubtitleStatus `json:"subtitle"`
+	// the position the user left off, when the movie was partially watched
+	ResumeFrom float64 `json:"resumeFrom,omitempty"`
+	// unix milliseconds the media urls expire at, the clients get this api again before it
+	MediaTokenExpireAt int64 `json:"mediaTokenExpireAt,omitempty"`
 }
 
 type ClearMoviesReq struct {
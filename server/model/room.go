@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"strings"
 
 	json "github.com/json-iterator/go"
 
@@ -61,8 +62,12 @@ type RoomListResp struct {
 	RoomName     string             `json:"roomName"`
 	CreatorID    string             `json:"creatorId"`
 	Creator      string             `json:"creator"`
+	Category     string             `json:"category"`
+	Description  string             `json:"description"`
+	Tags         []string           `json:"tags"`
 	ViewerCount  int64              `json:"viewerCount"`
 	CreatedAt    int64              `json:"createdAt"`
+	HotScore     float64            `json:"hotScore,omitempty"`
 	NeedPassword bool               `json:"needPassword"`
 	Status       dbModel.RoomStatus `json:"status"`
 }
@@ -134,6 +139,68 @@ func (s *SetRoomSettingReq) Decode(ctx *gin.Context) error {
 }
 
 func (s *SetRoomSettingReq) Validate() error {
+	if v, ok := (*s)["tags"]; ok {
+		raw, ok := v.([]any)
+		if !ok && v != nil {
+			return errors.New("tags must be a string array")
+		}
+		tags := make([]string, len(raw))
+		for i, t := range raw {
+			tag, ok := t.(string)
+			if !ok {
+				return errors.New("tags must be a string array")
+			}
+			tags[i] = tag
+		}
+		rt, err := dbModel.NewRoomTags(tags)
+		if err != nil {
+			return err
+		}
+		(*s)["tags"] = rt
+	}
+	if v, ok := (*s)["category"]; ok {
+		category, ok := v.(string)
+		if !ok {
+			return errors.New("category must be a string")
+		}
+		category = strings.TrimSpace(category)
+		if len(category) > dbModel.MaxRoomCategoryLength {
+			return errors.New("category too long")
+		}
+		(*s)["category"] = category
+	}
+	if v, ok := (*s)["description"]; ok {
+		description, ok := v.(string)
+		if !ok {
+			return errors.New("description must be a string")
+		}
+		if len(description) > dbModel.MaxRoomDescriptionLength {
+			return errors.New("description too long")
+		}
+	}
+	if v, ok := (*s)["announcement"]; ok {
+		announcement, ok := v.(string)
+		if !ok {
+			return errors.New("announcement must be a string")
+		}
+		if len(announcement) > dbModel.MaxRoomAnnouncementLength {
+			return errors.New("announcement too long")
+		}
+	}
+	if v, ok := (*s)["announcement_expire_at"]; ok {
+		expireAt, ok := v.(float64)
+		if !ok || expireAt < 0 || expireAt != float64(int64(expireAt)) {
+			return errors.New("announcement_expire_at must be a unix milli timestamp")
+		}
+		(*s)["announcement_expire_at"] = int64(expireAt)
+	}
+	if v, ok := (*s)["archive_after_days"]; ok {
+		days, ok := v.(float64)
+		if !ok || days != float64(int64(days)) {
+			return errors.New("archive_after_days must be an integer")
+		}
+		(*s)["archive_after_days"] = int64(days)
+	}
 	return nil
 }
 
@@ -158,3 +225,33 @@ type CheckRoomResp struct {
 	NeedPassword bool               `json:"needPassword"`
 	EnabledGuest bool               `json:"enabledGuest"`
 }
+
+type RoomVendorBindReq struct {
+	Vendor   dbModel.VendorName `json:"vendor"`
+	ServerID string             `json:"serverId"`
+}
+
+func (r *RoomVendorBindReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+func (r *RoomVendorBindReq) Validate() error {
+	switch r.Vendor {
+	case dbModel.VendorAlist, dbModel.VendorEmby:
+		if r.ServerID == "" {
+			return errors.New("serverId is required")
+		}
+	case dbModel.VendorBilibili:
+	default:
+		return errors.New("vendor can not be bound to room")
+	}
+	return nil
+}
+
+type RoomVendorBindResp struct {
+	Vendor    dbModel.VendorName `json:"vendor"`
+	ServerID  string             `json:"serverId"`
+	Host      string             `json:"host"`
+	BoundByID string             `json:"boundById"`
+	BoundBy   string             `json:"boundBy"`
+}
@@ -260,3 +260,31 @@ func (u *UserRetrievePasswordEmailReq) Validate() error {
 	}
 	return nil
 }
+
+type WatchHistoryResp struct {
+	MediaKey  string             `json:"mediaKey"`
+	RoomID    string             `json:"roomId"`
+	MovieID   string             `json:"movieId"`
+	SubPath   string             `json:"subPath"`
+	Name      string             `json:"name"`
+	Vendor    dbModel.VendorName `json:"vendor"`
+	Position  float64            `json:"position"`
+	Duration  float64            `json:"duration"`
+	Completed bool               `json:"completed"`
+	UpdatedAt int64              `json:"updatedAt"`
+}
+
+type DeleteWatchHistoryReq struct {
+	MediaKey string `json:"mediaKey"`
+}
+
+func (d *DeleteWatchHistoryReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(d)
+}
+
+func (d *DeleteWatchHistoryReq) Validate() error {
+	if len(d.MediaKey) != 64 {
+		return errors.New("invalid media key")
+	}
+	return nil
+}
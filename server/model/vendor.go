@@ -66,3 +66,70 @@ func (r *ServerIDReq) Validate() error {
 func (r *ServerIDReq) Decode(ctx *gin.Context) error {
 	return json.NewDecoder(ctx.Request.Body).Decode(r)
 }
+
+const (
+	MaxVendorImportDepth = 16
+	MaxVendorImportExts  = 32
+)
+
+type ImportVendorMoviesReq struct {
+	Vendor   string   `json:"vendor"`
+	Backend  string   `json:"backend"`
+	Path     string   `json:"path"`
+	Password string   `json:"password"`
+	ParentID string   `json:"parentId"`
+	Exts     []string `json:"exts"`
+	// 0 means MaxVendorImportDepth, 1 only imports the files directly under path
+	Depth int `json:"depth"`
+}
+
+func (r *ImportVendorMoviesReq) Decode(ctx *gin.Context) error {
+	return json.NewDecoder(ctx.Request.Body).Decode(r)
+}
+
+func (r *ImportVendorMoviesReq) Validate() error {
+	if r.Path == "" {
+		return errors.New("path is required")
+	}
+	if len(r.ParentID) != 0 && len(r.ParentID) != 32 {
+		return errors.New("parent id length must be 0 or 32")
+	}
+	switch {
+	case r.Depth < 0:
+		return errors.New("depth must not be negative")
+	case r.Depth == 0:
+		r.Depth = MaxVendorImportDepth
+	case r.Depth > MaxVendorImportDepth:
+		return fmt.Errorf("depth must not be greater than %d", MaxVendorImportDepth)
+	}
+	if len(r.Exts) > MaxVendorImportExts {
+		return errors.New("too many exts")
+	}
+	exts := make([]string, 0, len(r.Exts))
+	for _, ext := range r.Exts {
+		ext = strings.ToLower(strings.TrimLeft(strings.TrimSpace(ext), "."))
+		if ext != "" {
+			exts = append(exts, ext)
+		}
+	}
+	r.Exts = exts
+	return nil
+}
+
+// MatchExt reports whether the file name matches the ext filters, empty filters match all files
+func (r *ImportVendorMoviesReq) MatchExt(name string) bool {
+	if len(r.Exts) == 0 {
+		return true
+	}
+	i := strings.LastIndexByte(name, '.')
+	if i == -1 {
+		return false
+	}
+	ext := strings.ToLower(name[i+1:])
+	for _, v := range r.Exts {
+		if v == ext {
+			return true
+		}
+	}
+	return false
+}
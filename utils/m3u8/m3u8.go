@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -92,3 +93,27 @@ func ReplaceM3u8SegmentsWithBaseURL(m3u8Str string, baseURL string, callback fun
 		return callback(segmentURL)
 	})
 }
+
+// GetM3u8Duration sums the #EXTINF durations in seconds,
+// isMaster is true when the playlist lists variant streams instead of segments
+func GetM3u8Duration(m3u8Str string) (duration float64, isMaster bool, err error) {
+	scanner := bufio.NewScanner(strings.NewReader(m3u8Str))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF"):
+			isMaster = true
+		case strings.HasPrefix(line, "#EXTINF:"):
+			v, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			d, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return 0, false, fmt.Errorf("parse extinf error: %w", err)
+			}
+			duration += d
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, false, fmt.Errorf("scan m3u8 error: %w", err)
+	}
+	return duration, isMaster, nil
+}
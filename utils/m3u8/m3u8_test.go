@@ -0,0 +1,56 @@
+package m3u8_test
+
+import (
+	"testing"
+
+	"github.com/synctv-org/synctv/utils/m3u8"
+)
+
+func TestGetM3u8Duration(t *testing.T) {
+	tests := []struct {
+		name         string
+		m3u8         string
+		wantDuration float64
+		wantMaster   bool
+		wantErr      bool
+	}{
+		{
+			name: "media",
+			m3u8: "#EXTM3U\n#EXT-X-TARGETDURATION:10\n" +
+				"#EXTINF:10.0,\nseg0.ts\n" +
+				"#EXTINF:9.5,title\nseg1.ts\n" +
+				"#EXTINF: 2 ,\r\nseg2.ts\r\n#EXT-X-ENDLIST\n",
+			wantDuration: 21.5,
+		},
+		{
+			name: "master",
+			m3u8: "#EXTM3U\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=1280000,RESOLUTION=1280x720\nlow.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=2560000,RESOLUTION=1920x1080\nhigh.m3u8\n",
+			wantMaster: true,
+		},
+		{
+			name: "empty",
+			m3u8: "#EXTM3U\n",
+		},
+		{
+			name:    "bad extinf",
+			m3u8:    "#EXTM3U\n#EXTINF:abc,\nseg0.ts\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration, isMaster, err := m3u8.GetM3u8Duration(tt.m3u8)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if duration != tt.wantDuration {
+				t.Errorf("duration = %v, want %v", duration, tt.wantDuration)
+			}
+			if isMaster != tt.wantMaster {
+				t.Errorf("isMaster = %v, want %v", isMaster, tt.wantMaster)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	flvTagTypeScript = 18
+	maxFLVScriptSize = 1024 * 1024
+
+	amf0Number      = 0x00
+	amf0Boolean     = 0x01
+	amf0String      = 0x02
+	amf0Object      = 0x03
+	amf0Null        = 0x05
+	amf0Undefined   = 0x06
+	amf0ECMAArray   = 0x08
+	amf0ObjectEnd   = 0x09
+	amf0StrictArray = 0x0a
+	amf0Date        = 0x0b
+	amf0LongString  = 0x0c
+)
+
+var (
+	flvVideoCodecs = map[float64]string{
+		2:  "h263",
+		4:  "vp6",
+		7:  "h264",
+		12: "hevc",
+		13: "av1",
+	}
+	flvAudioCodecs = map[float64]string{
+		2:  "mp3",
+		10: "aac",
+		11: "speex",
+		13: "opus",
+	}
+)
+
+// probeFLV reads the onMetaData script tag, which is usually the first tag
+func probeFLV(r io.ReadSeeker) (*Info, error) {
+	var hdr [9]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	// skip the rest of header and the first previous tag size
+	if _, err := r.Seek(int64(binary.BigEndian.Uint32(hdr[5:9]))+4, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var tagHdr [11]byte
+	if _, err := io.ReadFull(r, tagHdr[:]); err != nil {
+		return nil, err
+	}
+	if tagHdr[0]&0x1f != flvTagTypeScript {
+		return nil, errors.New("flv metadata not found")
+	}
+	size := int(tagHdr[1])<<16 | int(tagHdr[2])<<8 | int(tagHdr[3])
+	if size > maxFLVScriptSize {
+		return nil, fmt.Errorf("invalid flv script tag size: %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	br := bytes.NewReader(data)
+	name, err := readAMF0Value(br)
+	if err != nil {
+		return nil, err
+	}
+	if name != "onMetaData" {
+		return nil, errors.New("flv metadata not found")
+	}
+	v, err := readAMF0Value(br)
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid flv metadata")
+	}
+
+	info := &Info{
+		Format: "flv",
+	}
+	if d, ok := meta["duration"].(float64); ok {
+		info.Duration = d
+	}
+	if w, ok := meta["width"].(float64); ok {
+		info.Width = int(w)
+	}
+	if h, ok := meta["height"].(float64); ok {
+		info.Height = int(h)
+	}
+	switch c := meta["videocodecid"].(type) {
+	case float64:
+		info.VideoCodec = flvVideoCodecs[c]
+	case string:
+		info.VideoCodec = NormalizeCodec(c)
+	}
+	switch c := meta["audiocodecid"].(type) {
+	case float64:
+		info.AudioCodec = flvAudioCodecs[c]
+	case string:
+		info.AudioCodec = NormalizeCodec(c)
+	}
+	return info, nil
+}
+
+func readAMF0String(r *bytes.Reader, long bool) (string, error) {
+	var l uint32
+	if long {
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return "", err
+		}
+	} else {
+		var s uint16
+		if err := binary.Read(r, binary.BigEndian, &s); err != nil {
+			return "", err
+		}
+		l = uint32(s)
+	}
+	if int64(l) > int64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	b := make([]byte, l)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+func readAMF0Object(r *bytes.Reader) (map[string]any, error) {
+	obj := make(map[string]any)
+	for {
+		key, err := readAMF0String(r, false)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			marker, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if marker == amf0ObjectEnd {
+				return obj, nil
+			}
+			if err := r.UnreadByte(); err != nil {
+				return nil, err
+			}
+		}
+		v, err := readAMF0Value(r)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+	}
+}
+
+func readAMF0Value(r *bytes.Reader) (any, error) {
+	marker, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch marker {
+	case amf0Number:
+		var v uint64
+		if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(v), nil
+	case amf0Boolean:
+		b, err := r.ReadByte()
+		return b != 0, err
+	case amf0String:
+		return readAMF0String(r, false)
+	case amf0LongString:
+		return readAMF0String(r, true)
+	case amf0Object:
+		return readAMF0Object(r)
+	case amf0ECMAArray:
+		// the count is only a hint, the array ends with an object end marker
+		if _, err := r.Seek(4, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		return readAMF0Object(r)
+	case amf0StrictArray:
+		var l uint32
+		if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+			return nil, err
+		}
+		if int64(l) > int64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		arr := make([]any, l)
+		for i := range arr {
+			if arr[i], err = readAMF0Value(r); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case amf0Date:
+		var date [10]byte
+		_, err := io.ReadFull(r, date[:])
+		return nil, err
+	case amf0Null, amf0Undefined:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported amf0 type: %d", marker)
+	}
+}
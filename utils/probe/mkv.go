@@ -0,0 +1,260 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+const (
+	ebmlIDHeader        = 0x1a45dfa3
+	ebmlIDDocType       = 0x4282
+	mkvIDSegment        = 0x18538067
+	mkvIDInfo           = 0x1549a966
+	mkvIDTimecodeScale  = 0x2ad7b1
+	mkvIDDuration       = 0x4489
+	mkvIDTracks         = 0x1654ae6b
+	mkvIDTrackEntry     = 0xae
+	mkvIDTrackType      = 0x83
+	mkvIDCodecID        = 0x86
+	mkvIDVideo          = 0xe0
+	mkvIDPixelWidth     = 0xb0
+	mkvIDPixelHeight    = 0xba
+	mkvIDCluster        = 0x1f43b675
+	mkvTrackTypeVideo   = 1
+	mkvTrackTypeAudio   = 2
+	maxMKVElementSize   = 4 * 1024 * 1024
+	mkvDefaultTimescale = 1000000
+)
+
+// readEBMLVint reads a variable size integer, the length marker is kept for element ids,
+// unknown is true when all value bits are set
+func readEBMLVint(r io.Reader, keepMarker bool) (v uint64, unknown bool, err error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:1]); err != nil {
+		return 0, false, err
+	}
+	l := bits.LeadingZeros8(b[0]) + 1
+	if l > 8 {
+		return 0, false, errors.New("invalid ebml vint")
+	}
+	if _, err := io.ReadFull(r, b[1:l]); err != nil {
+		return 0, false, err
+	}
+	return decodeEBMLVint(b[:l], keepMarker)
+}
+
+func decodeEBMLVint(b []byte, keepMarker bool) (v uint64, unknown bool, err error) {
+	l := len(b)
+	first := uint64(b[0])
+	if !keepMarker {
+		first &= 0xff >> l
+	}
+	v = first
+	for _, c := range b[1:] {
+		v = v<<8 | uint64(c)
+	}
+	return v, !keepMarker && v == 1<<(7*l)-1, nil
+}
+
+func readEBMLElementHeader(r io.Reader) (id uint64, size int64, err error) {
+	id, _, err = readEBMLVint(r, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	s, unknown, err := readEBMLVint(r, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if unknown {
+		return id, -1, nil
+	}
+	return id, int64(s), nil
+}
+
+// rangeEBMLElements calls fn for every child element in data
+func rangeEBMLElements(data []byte, fn func(id uint64, payload []byte)) {
+	for len(data) > 0 {
+		idLen := bits.LeadingZeros8(data[0]) + 1
+		if idLen > 4 || len(data) < idLen+1 {
+			return
+		}
+		id, _, _ := decodeEBMLVint(data[:idLen], true)
+		data = data[idLen:]
+		sizeLen := bits.LeadingZeros8(data[0]) + 1
+		if sizeLen > 8 || len(data) < sizeLen {
+			return
+		}
+		size, unknown, _ := decodeEBMLVint(data[:sizeLen], false)
+		data = data[sizeLen:]
+		if unknown || size > uint64(len(data)) {
+			size = uint64(len(data))
+		}
+		fn(id, data[:size])
+		data = data[size:]
+	}
+}
+
+func ebmlUint(data []byte) uint64 {
+	var v uint64
+	for _, c := range data {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	default:
+		return 0
+	}
+}
+
+func readEBMLPayload(r io.Reader, size int64) ([]byte, error) {
+	if size < 0 || size > maxMKVElementSize {
+		return nil, fmt.Errorf("invalid element size: %d", size)
+	}
+	data := make([]byte, size)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+func probeMKV(r io.ReadSeeker) (*Info, error) {
+	id, size, err := readEBMLElementHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if id != ebmlIDHeader {
+		return nil, errors.New("invalid ebml header")
+	}
+	header, err := readEBMLPayload(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("read ebml header error: %w", err)
+	}
+	info := &Info{
+		Format: "mkv",
+	}
+	rangeEBMLElements(header, func(id uint64, payload []byte) {
+		if id == ebmlIDDocType && string(payload) == "webm" {
+			info.Format = "webm"
+		}
+	})
+
+	id, _, err = readEBMLElementHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if id != mkvIDSegment {
+		return nil, errors.New("segment not found")
+	}
+
+	var foundInfo, foundTracks bool
+	for !foundInfo || !foundTracks {
+		id, size, err := readEBMLElementHeader(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		switch id {
+		case mkvIDInfo:
+			data, err := readEBMLPayload(r, size)
+			if err != nil {
+				return nil, fmt.Errorf("read segment info error: %w", err)
+			}
+			parseMKVInfo(data, info)
+			foundInfo = true
+		case mkvIDTracks:
+			data, err := readEBMLPayload(r, size)
+			if err != nil {
+				return nil, fmt.Errorf("read tracks error: %w", err)
+			}
+			parseMKVTracks(data, info)
+			foundTracks = true
+		case mkvIDCluster:
+			// media data starts, the headers should have been read
+			if !foundInfo && !foundTracks {
+				return nil, errors.New("segment info not found")
+			}
+			return info, nil
+		default:
+			if size < 0 {
+				return nil, errors.New("unknown size element")
+			}
+			if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !foundInfo && !foundTracks {
+		return nil, errors.New("segment info not found")
+	}
+	return info, nil
+}
+
+func parseMKVInfo(data []byte, info *Info) {
+	var (
+		timescale uint64 = mkvDefaultTimescale
+		duration  float64
+	)
+	rangeEBMLElements(data, func(id uint64, payload []byte) {
+		switch id {
+		case mkvIDTimecodeScale:
+			timescale = ebmlUint(payload)
+		case mkvIDDuration:
+			duration = ebmlFloat(payload)
+		}
+	})
+	// duration is in timecode scale units, which are nanoseconds
+	info.Duration = duration * float64(timescale) / 1e9
+}
+
+func parseMKVTracks(data []byte, info *Info) {
+	rangeEBMLElements(data, func(id uint64, entry []byte) {
+		if id != mkvIDTrackEntry {
+			return
+		}
+		var (
+			trackType     uint64
+			codec         string
+			width, height int
+		)
+		rangeEBMLElements(entry, func(id uint64, payload []byte) {
+			switch id {
+			case mkvIDTrackType:
+				trackType = ebmlUint(payload)
+			case mkvIDCodecID:
+				codec = NormalizeCodec(string(payload))
+			case mkvIDVideo:
+				rangeEBMLElements(payload, func(id uint64, payload []byte) {
+					switch id {
+					case mkvIDPixelWidth:
+						width = int(ebmlUint(payload))
+					case mkvIDPixelHeight:
+						height = int(ebmlUint(payload))
+					}
+				})
+			}
+		})
+		switch trackType {
+		case mkvTrackTypeVideo:
+			if info.VideoCodec == "" {
+				info.VideoCodec = codec
+				info.Width = width
+				info.Height = height
+			}
+		case mkvTrackTypeAudio:
+			if info.AudioCodec == "" {
+				info.AudioCodec = codec
+			}
+		}
+	})
+}
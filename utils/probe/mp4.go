@@ -0,0 +1,181 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// the moov box of a long movie can be several megabytes
+const maxMP4MoovSize = 64 * 1024 * 1024
+
+func probeMP4(r io.ReadSeeker) (*Info, error) {
+	for {
+		typ, size, err := readMP4BoxHeader(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("moov box not found")
+			}
+			return nil, err
+		}
+		if typ == "moov" {
+			if size < 0 || size > maxMP4MoovSize {
+				return nil, fmt.Errorf("invalid moov box size: %d", size)
+			}
+			moov := make([]byte, size)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, fmt.Errorf("read moov box error: %w", err)
+			}
+			return parseMP4Moov(moov)
+		}
+		// box extends to the end of file
+		if size < 0 {
+			return nil, errors.New("moov box not found")
+		}
+		if _, err := r.Seek(size, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readMP4BoxHeader returns the box type and the payload size, -1 means to the end of file
+func readMP4BoxHeader(r io.Reader) (string, int64, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return "", 0, err
+	}
+	typ := string(hdr[4:8])
+	size := int64(binary.BigEndian.Uint32(hdr[:4]))
+	switch size {
+	case 0:
+		return typ, -1, nil
+	case 1:
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return "", 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(hdr[:]))
+		if size < 16 {
+			return "", 0, fmt.Errorf("invalid box size: %d", size)
+		}
+		return typ, size - 16, nil
+	default:
+		if size < 8 {
+			return "", 0, fmt.Errorf("invalid box size: %d", size)
+		}
+		return typ, size - 8, nil
+	}
+}
+
+// rangeMP4Boxes calls fn for every child box in data
+func rangeMP4Boxes(data []byte, fn func(typ string, payload []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		typ := string(data[4:8])
+		hdr := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			hdr = 16
+		}
+		if size < hdr || size > uint64(len(data)) {
+			return
+		}
+		fn(typ, data[hdr:size])
+		data = data[size:]
+	}
+}
+
+func parseMP4Moov(moov []byte) (*Info, error) {
+	info := &Info{
+		Format: "mp4",
+	}
+	rangeMP4Boxes(moov, func(typ string, payload []byte) {
+		switch typ {
+		case "mvhd":
+			info.Duration = parseMP4Mvhd(payload)
+		case "trak":
+			parseMP4Trak(payload, info)
+		}
+	})
+	if info.Duration == 0 && info.VideoCodec == "" && info.AudioCodec == "" {
+		return nil, errors.New("no media info found in moov box")
+	}
+	return info, nil
+}
+
+func parseMP4Mvhd(data []byte) float64 {
+	var timescale, duration uint64
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+		duration = binary.BigEndian.Uint64(data[24:32])
+	case len(data) >= 20:
+		timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	}
+	// all ones means unknown duration
+	if timescale == 0 || duration == 0xffffffff || duration == 0xffffffffffffffff {
+		return 0
+	}
+	return float64(duration) / float64(timescale)
+}
+
+func parseMP4Trak(trak []byte, info *Info) {
+	var (
+		handler string
+		stsd    []byte
+	)
+	rangeMP4Boxes(trak, func(typ string, payload []byte) {
+		if typ != "mdia" {
+			return
+		}
+		rangeMP4Boxes(payload, func(typ string, payload []byte) {
+			switch typ {
+			case "hdlr":
+				// version and flags, pre_defined, handler_type
+				if len(payload) >= 12 {
+					handler = string(payload[8:12])
+				}
+			case "minf":
+				rangeMP4Boxes(payload, func(typ string, payload []byte) {
+					if typ != "stbl" {
+						return
+					}
+					rangeMP4Boxes(payload, func(typ string, payload []byte) {
+						if typ == "stsd" {
+							stsd = payload
+						}
+					})
+				})
+			}
+		})
+	})
+	// version and flags, entry_count, then the first sample entry
+	if len(stsd) < 16 {
+		return
+	}
+	entry := stsd[8:]
+	codec := NormalizeCodec(string(entry[4:8]))
+	switch handler {
+	case "vide":
+		if info.VideoCodec != "" {
+			return
+		}
+		info.VideoCodec = codec
+		// sample entry header, reserved, data_reference_index, pre_defined and reserved
+		if len(entry) >= 36 {
+			info.Width = int(binary.BigEndian.Uint16(entry[32:34]))
+			info.Height = int(binary.BigEndian.Uint16(entry[34:36]))
+		}
+	case "soun":
+		if info.AudioCodec == "" {
+			info.AudioCodec = codec
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package probe reads the duration, resolution and codecs of a media file
+// from its container headers, without decoding or downloading the whole file.
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrUnknownFormat = errors.New("unknown media format")
+
+type Info struct {
+	Format     string
+	VideoCodec string
+	AudioCodec string
+	// seconds
+	Duration float64
+	Width    int
+	Height   int
+}
+
+// Probe sniffs the container format and parses its headers, the reader is
+// expected to be at the start of the file
+func Probe(r io.ReadSeeker) (*Info, error) {
+	head := make([]byte, 12)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("read header error: %w", err)
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("FLV")):
+		return probeFLV(r)
+	case bytes.HasPrefix(head, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return probeMKV(r)
+	case len(head) >= 8 && isMP4TopBox(string(head[4:8])):
+		return probeMP4(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func isMP4TopBox(typ string) bool {
+	switch typ {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	default:
+		return false
+	}
+}
+
+var codecNames = map[string]string{
+	// mp4 sample entries
+	"avc1": "h264",
+	"avc3": "h264",
+	"hev1": "hevc",
+	"hvc1": "hevc",
+	"av01": "av1",
+	"vp08": "vp8",
+	"vp09": "vp9",
+	"mp4a": "aac",
+	"opus": "opus",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"flac": "flac",
+	".mp3": "mp3",
+	// matroska codec ids
+	"v_mpeg4/iso/avc":  "h264",
+	"v_mpegh/iso/hevc": "hevc",
+	"v_av1":            "av1",
+	"v_vp8":            "vp8",
+	"v_vp9":            "vp9",
+	"a_aac":            "aac",
+	"a_opus":           "opus",
+	"a_vorbis":         "vorbis",
+	"a_ac3":            "ac3",
+	"a_eac3":           "eac3",
+	"a_flac":           "flac",
+	"a_dts":            "dts",
+	"a_mpeg/l3":        "mp3",
+}
+
+// NormalizeCodec maps mp4 sample entries and matroska codec ids to short codec names
+func NormalizeCodec(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	if name, ok := codecNames[codec]; ok {
+		return name
+	}
+	// e.g. A_AAC/MPEG4/LC
+	if strings.HasPrefix(codec, "a_aac") {
+		return "aac"
+	}
+	return codec
+}
@@ -0,0 +1,269 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func be16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func be64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// mp4Box builds a box with the 32 bit size
+func mp4Box(typ string, payload ...[]byte) []byte {
+	p := concat(payload...)
+	return concat(be32(uint32(8+len(p))), []byte(typ), p)
+}
+
+// mp4LargeBox builds a box with the 64 bit size
+func mp4LargeBox(typ string, payload ...[]byte) []byte {
+	p := concat(payload...)
+	return concat(be32(1), []byte(typ), be64(uint64(16+len(p))), p)
+}
+
+func mvhdV0(timescale, duration uint32) []byte {
+	return mp4Box("mvhd", []byte{0, 0, 0, 0}, be32(0), be32(0), be32(timescale), be32(duration), make([]byte, 80))
+}
+
+func mvhdV1(timescale uint32, duration uint64) []byte {
+	return mp4Box("mvhd", []byte{1, 0, 0, 0}, be64(0), be64(0), be32(timescale), be64(duration), make([]byte, 80))
+}
+
+func mp4Trak(handler, entryType string, width, height uint16) []byte {
+	// size, type, reserved, data_reference_index, pre_defined and reserved, width, height
+	entry := concat(be32(86), []byte(entryType), make([]byte, 6), be16(1), make([]byte, 16), be16(width), be16(height), make([]byte, 50))
+	return mp4Box("trak",
+		mp4Box("tkhd", make([]byte, 84)),
+		mp4Box("mdia",
+			mp4Box("hdlr", be32(0), be32(0), []byte(handler), make([]byte, 13)),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", be32(0), be32(1), entry))),
+		),
+	)
+}
+
+// ebmlElement builds an element with the id bytes and an 8 byte size
+func ebmlElement(id []byte, payload ...[]byte) []byte {
+	p := concat(payload...)
+	size := be64(uint64(len(p)))
+	size[0] = 0x01
+	return concat(id, size, p)
+}
+
+// ebmlUnknownSizeElement builds an element with the unknown size
+func ebmlUnknownSizeElement(id []byte, payload ...[]byte) []byte {
+	return concat(id, []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, concat(payload...))
+}
+
+func mkvFile(docType string, durationField []byte, tracks ...[]byte) []byte {
+	return concat(
+		ebmlElement([]byte{0x1a, 0x45, 0xdf, 0xa3}, ebmlElement([]byte{0x42, 0x82}, []byte(docType))),
+		ebmlUnknownSizeElement([]byte{0x18, 0x53, 0x80, 0x67},
+			// seek head is skipped
+			ebmlElement([]byte{0x11, 0x4d, 0x9b, 0x74}, make([]byte, 32)),
+			ebmlElement([]byte{0x15, 0x49, 0xa9, 0x66},
+				ebmlElement([]byte{0x2a, 0xd7, 0xb1}, []byte{0x0f, 0x42, 0x40}),
+				ebmlElement([]byte{0x44, 0x89}, durationField),
+			),
+			ebmlElement([]byte{0x16, 0x54, 0xae, 0x6b}, tracks...),
+			ebmlUnknownSizeElement([]byte{0x1f, 0x43, 0xb6, 0x75}, make([]byte, 16)),
+		),
+	)
+}
+
+func mkvTrack(trackType byte, codec string, width, height uint16) []byte {
+	return ebmlElement([]byte{0xae},
+		ebmlElement([]byte{0x83}, []byte{trackType}),
+		ebmlElement([]byte{0x86}, []byte(codec)),
+		ebmlElement([]byte{0xe0},
+			ebmlElement([]byte{0xb0}, be16(width)),
+			ebmlElement([]byte{0xba}, be16(height)),
+		),
+	)
+}
+
+func amfString(s string) []byte {
+	return concat(be16(uint16(len(s))), []byte(s))
+}
+
+func amfNumber(v float64) []byte {
+	return concat([]byte{amf0Number}, be64(math.Float64bits(v)))
+}
+
+func flvFile(meta ...[]byte) []byte {
+	script := concat(
+		[]byte{amf0String}, amfString("onMetaData"),
+		[]byte{amf0ECMAArray}, be32(uint32(len(meta)/2)),
+		concat(meta...),
+		amfString(""), []byte{amf0ObjectEnd},
+	)
+	size := be32(uint32(len(script)))
+	return concat(
+		[]byte("FLV"), []byte{1, 5}, be32(9), be32(0),
+		[]byte{flvTagTypeScript}, size[1:], make([]byte, 7),
+		script,
+	)
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Info
+	}{
+		{
+			name: "mp4 mvhd v0",
+			data: concat(
+				mp4Box("ftyp", []byte("isom"), be32(512)),
+				mp4Box("moov",
+					mvhdV0(1000, 90500),
+					mp4Trak("vide", "avc1", 1920, 1080),
+					mp4Trak("soun", "mp4a", 0, 0),
+				),
+				mp4Box("mdat", make([]byte, 64)),
+			),
+			want: Info{Format: "mp4", VideoCodec: "h264", AudioCodec: "aac", Duration: 90.5, Width: 1920, Height: 1080},
+		},
+		{
+			name: "mp4 mvhd v1 after mdat",
+			data: concat(
+				mp4Box("ftyp", []byte("isom"), be32(512)),
+				mp4LargeBox("mdat", make([]byte, 64)),
+				mp4Box("moov",
+					mvhdV1(90000, 90000*7200),
+					mp4Trak("soun", "Opus", 0, 0),
+					mp4Trak("vide", "hvc1", 3840, 2160),
+					mp4Trak("vide", "avc1", 640, 360),
+				),
+			),
+			want: Info{Format: "mp4", VideoCodec: "hevc", AudioCodec: "opus", Duration: 7200, Width: 3840, Height: 2160},
+		},
+		{
+			name: "mp4 unknown duration",
+			data: concat(
+				mp4Box("ftyp", []byte("isom")),
+				mp4Box("moov", mvhdV0(1000, 0xffffffff), mp4Trak("soun", "mp4a", 0, 0)),
+			),
+			want: Info{Format: "mp4", AudioCodec: "aac"},
+		},
+		{
+			name: "mkv float64 duration",
+			data: mkvFile("matroska", be64(math.Float64bits(5000)),
+				mkvTrack(1, "V_MPEG4/ISO/AVC", 1280, 720),
+				mkvTrack(2, "A_AAC/MPEG4/LC", 0, 0),
+			),
+			want: Info{Format: "mkv", VideoCodec: "h264", AudioCodec: "aac", Duration: 5, Width: 1280, Height: 720},
+		},
+		{
+			name: "webm float32 duration",
+			data: mkvFile("webm", be32(math.Float32bits(1500)),
+				mkvTrack(2, "A_OPUS", 0, 0),
+				mkvTrack(1, "V_VP9", 854, 480),
+			),
+			want: Info{Format: "webm", VideoCodec: "vp9", AudioCodec: "opus", Duration: 1.5, Width: 854, Height: 480},
+		},
+		{
+			name: "flv codec ids",
+			data: flvFile(
+				amfString("duration"), amfNumber(61.25),
+				amfString("width"), amfNumber(1280),
+				amfString("height"), amfNumber(720),
+				amfString("videocodecid"), amfNumber(7),
+				amfString("audiocodecid"), amfNumber(10),
+				amfString("encoder"), concat([]byte{amf0String}, amfString("Lavf")),
+				amfString("stereo"), []byte{amf0Boolean, 1},
+				amfString("keyframes"), concat([]byte{amf0Object},
+					amfString("times"), concat([]byte{amf0StrictArray}, be32(2), amfNumber(0), amfNumber(2)),
+					amfString(""), []byte{amf0ObjectEnd}),
+				amfString("creationdate"), concat([]byte{amf0Date}, make([]byte, 10)),
+				amfString("extra"), []byte{amf0Null},
+			),
+			want: Info{Format: "flv", VideoCodec: "h264", AudioCodec: "aac", Duration: 61.25, Width: 1280, Height: 720},
+		},
+		{
+			name: "flv codec names",
+			data: flvFile(
+				amfString("videocodecid"), concat([]byte{amf0String}, amfString("hvc1")),
+				amfString("audiocodecid"), concat([]byte{amf0String}, amfString("mp4a")),
+			),
+			want: Info{Format: "flv", VideoCodec: "hevc", AudioCodec: "aac"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Probe(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *info != tt.want {
+				t.Errorf("info = %+v, want %+v", *info, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeError(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"mp4 without moov", concat(mp4Box("ftyp", []byte("isom")), mp4Box("mdat", make([]byte, 16)))},
+		{"mp4 empty moov", concat(mp4Box("ftyp", []byte("isom")), mp4Box("moov", mp4Box("udta")))},
+		{"mp4 truncated moov", concat(mp4Box("ftyp", []byte("isom")), be32(1024), []byte("moov"))},
+		{"mkv without segment", ebmlElement([]byte{0x1a, 0x45, 0xdf, 0xa3}, ebmlElement([]byte{0x42, 0x82}, []byte("matroska")))},
+		{"flv without metadata", concat([]byte("FLV"), []byte{1, 5}, be32(9), be32(0), []byte{9}, make([]byte, 10))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if info, err := Probe(bytes.NewReader(tt.data)); err == nil {
+				t.Errorf("info = %+v, want error", *info)
+			}
+		})
+	}
+	if _, err := Probe(bytes.NewReader([]byte("#EXTM3U\n"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestParseMP4Mvhd(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want float64
+	}{
+		{"v0", mvhdV0(600, 1500)[8:], 2.5},
+		{"v1", mvhdV1(1000, 1<<33)[8:], float64(1<<33) / 1000},
+		{"v0 unknown", mvhdV0(600, 0xffffffff)[8:], 0},
+		{"v1 unknown", mvhdV1(600, 0xffffffffffffffff)[8:], 0},
+		{"zero timescale", mvhdV0(0, 1500)[8:], 0},
+		{"truncated", []byte{0, 0, 0, 0, 1}, 0},
+	}
+	for _, tt := range tests {
+		if got := parseMP4Mvhd(tt.data); got != tt.want {
+			t.Errorf("%s: duration = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCodec(t *testing.T) {
+	tests := map[string]string{
+		"avc1":             "h264",
+		"HEV1":             "hevc",
+		"V_MPEGH/ISO/HEVC": "hevc",
+		"A_AAC/MPEG2/LC":   "aac",
+		" a_flac ":         "flac",
+		"mp4v":             "mp4v",
+	}
+	for in, want := range tests {
+		if got := NormalizeCodec(in); got != want {
+			t.Errorf("NormalizeCodec(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// the default columns of the ass events section
+var assDefaultFormat = []string{
+	"layer", "start", "end", "style", "name",
+	"marginl", "marginr", "marginv", "effect", "text",
+}
+
+var assOverrideReg = regexp.MustCompile(`\{[^}]*\}`)
+
+func parseASS(data []byte) ([]*Cue, error) {
+	var (
+		inEvents    bool
+		foundEvents bool
+		format      = assDefaultFormat
+		cues        []*Cue
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			foundEvents = foundEvents || inEvents
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "format":
+			fields := strings.Split(value, ",")
+			format = make([]string, len(fields))
+			for i, f := range fields {
+				format[i] = strings.ToLower(strings.TrimSpace(f))
+			}
+		case "dialogue":
+			// the text is the last column and may contain commas
+			fields := strings.SplitN(value, ",", len(format))
+			if len(fields) != len(format) {
+				continue
+			}
+			cue := &Cue{}
+			var err error
+			for i, name := range format {
+				switch name {
+				case "start":
+					cue.Start, err = parseTimestamp(fields[i])
+				case "end":
+					cue.End, err = parseTimestamp(fields[i])
+				case "text":
+					cue.Text = assTextToPlain(fields[i])
+				}
+				if err != nil {
+					break
+				}
+			}
+			if err != nil || cue.Text == "" {
+				continue
+			}
+			cues = append(cues, cue)
+		}
+	}
+	if !foundEvents {
+		return nil, errors.New("missing ass events section")
+	}
+	return cues, nil
+}
+
+func assTextToPlain(text string) string {
+	text = assOverrideReg.ReplaceAllString(text, "")
+	text = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(text)
+	return strings.TrimSpace(text)
+}
+
+func plainToASSText(text string) string {
+	text = stripTags(text)
+	// braces start override blocks
+	text = strings.NewReplacer("{", "(", "}", ")").Replace(text)
+	return strings.ReplaceAll(text, "\n", `\N`)
+}
+
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+PlayResX: 1920
+PlayResY: 1080
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,64,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,1,2,20,20,40,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+func encodeASS(cues []*Cue) []byte {
+	buf := bytes.NewBufferString(assHeader)
+	for _, cue := range cues {
+		fmt.Fprintf(buf, "Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n",
+			formatASSTimestamp(cue.Start),
+			formatASSTimestamp(cue.End),
+			plainToASSText(cue.Text),
+		)
+	}
+	return buf.Bytes()
+}
+
+// formatASSTimestamp formats H:MM:SS.cc
+func formatASSTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d",
+		cs/360000,
+		cs/6000%60,
+		cs/100%60,
+		cs%100,
+	)
+}
@@ -0,0 +1,104 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimestamp parses [HH:]MM:SS[.,]fraction, used by all the formats
+func parseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.Replace(s, ",", ".", 1))
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+	sec, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+	total := sec
+	mul := 60.0
+	for i := len(parts) - 2; i >= 0; i-- {
+		v, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		total += float64(v) * mul
+		mul *= 60
+	}
+	return time.Duration(math.Round(total*1000)) * time.Millisecond, nil
+}
+
+// parseTiming parses the `start --> end` line, settings after the end timestamp are ignored
+func parseTiming(line string) (start, end time.Duration, ok bool) {
+	before, after, found := strings.Cut(line, "-->")
+	if !found {
+		return 0, 0, false
+	}
+	fields := strings.Fields(after)
+	if len(fields) == 0 {
+		return 0, 0, false
+	}
+	start, err := parseTimestamp(before)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err = parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func parseSRT(data []byte) ([]*Cue, error) {
+	var cues []*Cue
+	for _, block := range splitBlocks(data) {
+		// the index line is optional in practice
+		i := 0
+		if !strings.Contains(block[0], "-->") {
+			i = 1
+		}
+		if i >= len(block) {
+			continue
+		}
+		start, end, ok := parseTiming(block[i])
+		if !ok {
+			continue
+		}
+		cues = append(cues, &Cue{
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block[i+1:], "\n"),
+		})
+	}
+	if len(cues) == 0 && len(bytes.TrimSpace(data)) != 0 {
+		return nil, errors.New("no srt cue found")
+	}
+	return cues, nil
+}
+
+func encodeSRT(cues []*Cue) []byte {
+	buf := bytes.NewBuffer(nil)
+	for i, cue := range cues {
+		fmt.Fprintf(buf, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatTimestamp(cue.Start, ","),
+			formatTimestamp(cue.End, ","),
+			cue.Text,
+		)
+	}
+	return buf.Bytes()
+}
+
+var htmlTagReg = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// stripTags removes the html like tags used by srt and vtt
+func stripTags(text string) string {
+	return htmlTagReg.ReplaceAllString(text, "")
+}
@@ -0,0 +1,196 @@
+// Package subtitle converts subtitles between SRT, ASS/SSA and WebVTT.
+// Cues keep the text only, styles and positions are dropped on conversion.
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FormatSRT = "srt"
+	FormatASS = "ass"
+	FormatVTT = "vtt"
+)
+
+var ErrUnknownFormat = errors.New("unknown subtitle format")
+
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	// lines are separated by \n
+	Text string
+}
+
+// NormalizeFormat maps a file extension or format name to one of the supported formats,
+// an empty string is returned when the format is not supported
+func NormalizeFormat(format string) string {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "srt":
+		return FormatSRT
+	case "ass", "ssa":
+		return FormatASS
+	case "vtt", "webvtt":
+		return FormatVTT
+	default:
+		return ""
+	}
+}
+
+// Detect sniffs the format from the content
+func Detect(data []byte) string {
+	data = bytes.TrimSpace(trimBOM(data))
+	switch {
+	case bytes.HasPrefix(data, []byte("WEBVTT")):
+		return FormatVTT
+	case bytes.HasPrefix(data, []byte("[Script Info]")),
+		bytes.Contains(data, []byte("\n[Events]")):
+		return FormatASS
+	case bytes.Contains(data, []byte("-->")):
+		return FormatSRT
+	default:
+		return ""
+	}
+}
+
+func ContentType(format string) string {
+	switch NormalizeFormat(format) {
+	case FormatSRT:
+		return "application/x-subrip; charset=utf-8"
+	case FormatASS:
+		return "text/x-ssa; charset=utf-8"
+	case FormatVTT:
+		return "text/vtt; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Parse reads the cues sorted by start time, the format is detected when empty
+func Parse(data []byte, format string) ([]*Cue, error) {
+	data = normalizeNewlines(trimBOM(data))
+	if format == "" {
+		format = Detect(data)
+	}
+	var (
+		cues []*Cue
+		err  error
+	)
+	switch NormalizeFormat(format) {
+	case FormatSRT:
+		cues, err = parseSRT(data)
+	case FormatASS:
+		cues, err = parseASS(data)
+	case FormatVTT:
+		cues, err = parseVTT(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].Start < cues[j].Start
+	})
+	return cues, nil
+}
+
+func Encode(cues []*Cue, format string) ([]byte, error) {
+	switch NormalizeFormat(format) {
+	case FormatSRT:
+		return encodeSRT(cues), nil
+	case FormatASS:
+		return encodeASS(cues), nil
+	case FormatVTT:
+		return encodeVTT(cues), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Convert converts the subtitle to the target format, the data is returned as is
+// when it is already in the target format
+func Convert(data []byte, from, to string) ([]byte, error) {
+	to = NormalizeFormat(to)
+	if to == "" {
+		return nil, ErrUnknownFormat
+	}
+	if from == "" {
+		from = Detect(data)
+	}
+	if NormalizeFormat(from) == to {
+		return data, nil
+	}
+	cues, err := Parse(data, from)
+	if err != nil {
+		return nil, fmt.Errorf("parse subtitle error: %w", err)
+	}
+	return Encode(cues, to)
+}
+
+// Shift delays the cues by d, cues ending before zero are dropped
+func Shift(cues []*Cue, d time.Duration) []*Cue {
+	shifted := make([]*Cue, 0, len(cues))
+	for _, cue := range cues {
+		end := cue.End + d
+		if end <= 0 {
+			continue
+		}
+		shifted = append(shifted, &Cue{
+			Start: max(cue.Start+d, 0),
+			End:   end,
+			Text:  cue.Text,
+		})
+	}
+	return shifted
+}
+
+func trimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+}
+
+func normalizeNewlines(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+}
+
+// splitBlocks splits the content by blank lines
+func splitBlocks(data []byte) [][]string {
+	var (
+		blocks [][]string
+		block  []string
+	)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(block) != 0 {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if len(block) != 0 {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// formatTimestamp formats the duration as HH:MM:SS followed by sep and milliseconds
+func formatTimestamp(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d",
+		ms/3600000,
+		ms/60000%60,
+		ms/1000%60,
+		sep,
+		ms%1000,
+	)
+}
@@ -0,0 +1,293 @@
+package subtitle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	srtFixture = "\xef\xbb\xbf1\r\n00:00:01,500 --> 00:00:03,000\r\nHello\r\n<i>world</i>\r\n\r\n" +
+		"2\r\n00:00:00,250 --> 00:00:01,000\r\nFirst\r\n"
+	vttFixture = "WEBVTT - title\n\nNOTE a comment\n\nSTYLE\n::cue { color: red }\n\n" +
+		"intro\n00:01.000 --> 00:02.500 align:start\nHello\n\n" +
+		"01:00:00.000 --> 01:00:01.000\nLate\n"
+	assFixture = "[Script Info]\nScriptType: v4.00+\n\n[Events]\n" +
+		"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,skipped\n" +
+		"Dialogue: 0,0:00:02.50,0:00:04.00,Default,,0,0,0,,{\\an8\\b1}Hello, {\\i1}world{\\i0}\\Nline two\n" +
+		"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\p1}\n"
+)
+
+type cue struct {
+	start, end time.Duration
+	text       string
+}
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func checkCues(t *testing.T, got []*Cue, want []cue) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("cues = %d, want %d", len(got), len(want))
+	}
+	for i, c := range got {
+		if c.Start != want[i].start || c.End != want[i].end || c.Text != want[i].text {
+			t.Errorf("cue %d = %v %v %q, want %v %v %q", i, c.Start, c.End, c.Text, want[i].start, want[i].end, want[i].text)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   []cue
+	}{
+		{
+			name:   "srt sorted by start",
+			data:   srtFixture,
+			format: "srt",
+			want: []cue{
+				{ms(250), ms(1000), "First"},
+				{ms(1500), ms(3000), "Hello\n<i>world</i>"},
+			},
+		},
+		{
+			name:   "srt without index",
+			data:   "00:00:01,000 --> 00:00:02,000\nno index\n",
+			format: "srt",
+			want:   []cue{{ms(1000), ms(2000), "no index"}},
+		},
+		{
+			name:   "vtt identifier and settings",
+			data:   vttFixture,
+			format: "vtt",
+			want: []cue{
+				{ms(1000), ms(2500), "Hello"},
+				{time.Hour, time.Hour + time.Second, "Late"},
+			},
+		},
+		{
+			name:   "ass override blocks",
+			data:   assFixture,
+			format: "ssa",
+			want:   []cue{{ms(2500), ms(4000), "Hello, world\nline two"}},
+		},
+		{
+			name: "detect vtt",
+			data: vttFixture,
+			want: []cue{
+				{ms(1000), ms(2500), "Hello"},
+				{time.Hour, time.Hour + time.Second, "Late"},
+			},
+		},
+		{
+			name: "detect ass",
+			data: assFixture,
+			want: []cue{{ms(2500), ms(4000), "Hello, world\nline two"}},
+		},
+		{
+			name: "detect srt",
+			data: srtFixture,
+			want: []cue{
+				{ms(250), ms(1000), "First"},
+				{ms(1500), ms(3000), "Hello\n<i>world</i>"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues, err := Parse([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCues(t, cues, tt.want)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"unknown format", "hello", "txt"},
+		{"undetected format", "hello", ""},
+		{"srt without cue", "1\nhello\n", "srt"},
+		{"vtt without header", "00:01.000 --> 00:02.000\nHello\n", "vtt"},
+		{"ass without events", "[Script Info]\nTitle: a\n", "ass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data), tt.format); err == nil {
+				t.Error("error is expected")
+			}
+		})
+	}
+	if _, err := Parse(nil, "txt"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("err = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	cues := []*Cue{
+		{Start: ms(1500), End: ms(3_723_040), Text: "<b>Hello</b> {world}\nline two"},
+		{Start: -time.Second, End: ms(500), Text: "a\n\nb"},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "srt",
+			want: "1\n00:00:01,500 --> 01:02:03,040\n<b>Hello</b> {world}\nline two\n\n" +
+				"2\n00:00:00,000 --> 00:00:00,500\na\n\nb\n\n",
+		},
+		{
+			format: "vtt",
+			want: "WEBVTT\n\n" +
+				"00:00:01.500 --> 01:02:03.040\n<b>Hello</b> {world}\nline two\n\n" +
+				"00:00:00.000 --> 00:00:00.500\na\nb\n\n",
+		},
+		{
+			format: "ass",
+			want: assHeader +
+				"Dialogue: 0,0:00:01.50,1:02:03.04,Default,,0,0,0,,Hello (world)\\Nline two\n" +
+				"Dialogue: 0,0:00:00.00,0:00:00.50,Default,,0,0,0,,a\\N\\Nb\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Encode(cues, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("encode =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	want := []cue{
+		{ms(250), ms(1000), "First"},
+		{ms(1500), ms(3000), "Hello\nworld"},
+	}
+	for _, to := range []string{"vtt", "ass", "srt"} {
+		t.Run(to, func(t *testing.T) {
+			data, err := Convert([]byte(strings.ReplaceAll(srtFixture, "<i>world</i>", "world")), "srt", to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cues, err := Parse(data, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCues(t, cues, want)
+		})
+	}
+	if got, _ := Convert([]byte(vttFixture), "", "webvtt"); string(got) != vttFixture {
+		t.Error("subtitle in the target format is converted")
+	}
+}
+
+func TestShift(t *testing.T) {
+	cues := []*Cue{
+		{Start: ms(0), End: ms(1000), Text: "a"},
+		{Start: ms(1000), End: ms(3000), Text: "b"},
+		{Start: ms(5000), End: ms(6000), Text: "c"},
+	}
+	tests := []struct {
+		name string
+		d    time.Duration
+		want []cue
+	}{
+		{
+			name: "later",
+			d:    ms(500),
+			want: []cue{{ms(500), ms(1500), "a"}, {ms(1500), ms(3500), "b"}, {ms(5500), ms(6500), "c"}},
+		},
+		{
+			name: "earlier drops the ended cues",
+			d:    -ms(2000),
+			want: []cue{{0, ms(1000), "b"}, {ms(3000), ms(4000), "c"}},
+		},
+		{
+			name: "zero",
+			want: []cue{{0, ms(1000), "a"}, {ms(1000), ms(3000), "b"}, {ms(5000), ms(6000), "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCues(t, Shift(cues, tt.d), tt.want)
+		})
+	}
+	if cues[0].Start != 0 || cues[1].End != ms(3000) {
+		t.Error("the cues are modified")
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "00:00:01,500", want: ms(1500)},
+		{in: "01:02:03.040", want: ms(3_723_040)},
+		{in: "02:03.5", want: ms(123_500)},
+		{in: "0:00:02.50", want: ms(2500)},
+		{in: " 00:00:00.001 ", want: ms(1)},
+		{in: "1.5", wantErr: true},
+		{in: "1:2:3:4", wantErr: true},
+		{in: "aa:00.000", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTimestamp(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+
+	formats := []struct {
+		d   time.Duration
+		srt string
+		ass string
+	}{
+		{0, "00:00:00,000", "0:00:00.00"},
+		{-time.Second, "00:00:00,000", "0:00:00.00"},
+		{ms(3_723_049), "01:02:03,049", "1:02:03.04"},
+		{100 * time.Hour, "100:00:00,000", "100:00:00.00"},
+	}
+	for _, tt := range formats {
+		if got := formatTimestamp(tt.d, ","); got != tt.srt {
+			t.Errorf("formatTimestamp(%v) = %s, want %s", tt.d, got, tt.srt)
+		}
+		if got := formatASSTimestamp(tt.d); got != tt.ass {
+			t.Errorf("formatASSTimestamp(%v) = %s, want %s", tt.d, got, tt.ass)
+		}
+	}
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := map[string]string{
+		".SRT":   FormatSRT,
+		"ssa":    FormatASS,
+		".ass":   FormatASS,
+		"WebVTT": FormatVTT,
+		".sub":   "",
+		"":       "",
+	}
+	for in, want := range tests {
+		if got := NormalizeFormat(in); got != want {
+			t.Errorf("NormalizeFormat(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
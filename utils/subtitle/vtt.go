@@ -0,0 +1,55 @@
+package subtitle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func parseVTT(data []byte) ([]*Cue, error) {
+	blocks := splitBlocks(data)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0][0], "WEBVTT") {
+		return nil, errors.New("missing webvtt header")
+	}
+	var cues []*Cue
+	for _, block := range blocks[1:] {
+		switch {
+		case strings.HasPrefix(block[0], "NOTE"),
+			strings.HasPrefix(block[0], "STYLE"),
+			strings.HasPrefix(block[0], "REGION"):
+			continue
+		}
+		// the cue identifier is optional
+		i := 0
+		if !strings.Contains(block[0], "-->") {
+			i = 1
+		}
+		if i >= len(block) {
+			continue
+		}
+		start, end, ok := parseTiming(block[i])
+		if !ok {
+			continue
+		}
+		cues = append(cues, &Cue{
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block[i+1:], "\n"),
+		})
+	}
+	return cues, nil
+}
+
+func encodeVTT(cues []*Cue) []byte {
+	buf := bytes.NewBufferString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(buf, "%s --> %s\n%s\n\n",
+			formatTimestamp(cue.Start, "."),
+			formatTimestamp(cue.End, "."),
+			// a blank line would end the cue
+			strings.ReplaceAll(cue.Text, "\n\n", "\n"),
+		)
+	}
+	return buf.Bytes()
+}
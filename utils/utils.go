@@ -385,6 +385,22 @@ func IsM3u8Url(u string) bool {
 	return strings.HasPrefix(GetURLExtension(u), "m3u")
 }
 
+func IsMpdUrl(u string) bool {
+	return GetURLExtension(u) == "mpd"
+}
+
+var mediaExts = map[string]struct{}{
+	"mp4": {}, "m4v": {}, "mkv": {}, "webm": {}, "mov": {}, "avi": {}, "flv": {},
+	"ts": {}, "m2ts": {}, "wmv": {}, "mpg": {}, "mpeg": {}, "3gp": {}, "ogv": {},
+	"mp3": {}, "flac": {}, "m4a": {}, "aac": {}, "ogg": {}, "opus": {}, "wav": {},
+}
+
+// IsMediaFile reports whether the file name has a common video or audio extension
+func IsMediaFile(name string) bool {
+	_, ok := mediaExts[strings.ToLower(GetFileExtension(name))]
+	return ok
+}
+
 var (
 	needColor     bool
 	needColorOnce sync.Once